@@ -15,6 +15,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	noopacker "github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/noop"
@@ -39,6 +41,7 @@ import (
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
 	mockhandlers "github.com/elastic/elastic-agent/testing/mocks/internal_/pkg/agent/application/actions/handlers"
+	mockinfo "github.com/elastic/elastic-agent/testing/mocks/internal_/pkg/agent/application/info"
 )
 
 func TestPolicyChange(t *testing.T) {
@@ -99,6 +102,49 @@ func TestPolicyChange(t *testing.T) {
 	})
 }
 
+func TestPolicyChange_LogLevelDeniedByCapabilities(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  log_level: debug
+`), log)
+	require.NoError(t, err)
+
+	mockLogLevelSetter := mockhandlers.NewLogLevelSetter(t)
+	settingsHandler := NewSettings(log, mockinfo.NewAgent(t), mockLogLevelSetter, fakeCapabilitiesProvider{caps: caps})
+
+	ch := make(chan coordinator.ConfigChange, 1)
+	conf := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"logging": map[string]interface{}{
+				"level": "debug",
+			},
+		},
+		"inputs": []interface{}{map[string]interface{}{
+			"type": "filestream",
+		}},
+	}
+	action := &fleetapi.ActionPolicyChange{
+		ActionID:   "abc123",
+		ActionType: "POLICY_CHANGE",
+		Data: fleetapi.ActionPolicyChangeData{
+			Policy: conf,
+		},
+	}
+
+	cfg := configuration.DefaultConfiguration()
+	handler := NewPolicyChangeHandler(log, &info.AgentInfo{}, cfg, &storage.NullStore{}, ch, settingsHandler, &coordinator.Coordinator{})
+
+	// the denied log level is not applied, the rest of the policy is
+	err = handler.Handle(context.Background(), action, noopacker.New())
+	require.NoError(t, err)
+	assert.Nil(t, settingsHandler.fallbackLogLevel)
+
+	change := <-ch
+	require.Equal(t, config.MustNewConfigFrom(conf), change.Config())
+}
+
 func TestPolicyAcked(t *testing.T) {
 	log, _ := logger.New("", false)
 
@@ -0,0 +1,56 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// Rollback is a handler for ROLLBACK action.
+// After running Rollback agent switches back to the previous version kept on disk
+// after its last upgrade.
+type Rollback struct {
+	log   *logger.Logger
+	coord rollbackCoordinator
+}
+
+// NewRollback creates a new Rollback handler.
+func NewRollback(log *logger.Logger, coord rollbackCoordinator) *Rollback {
+	return &Rollback{
+		log:   log,
+		coord: coord,
+	}
+}
+
+// Handle handles ROLLBACK action. Returns immediately and the actual rollback
+// happens asynchronously, like an upgrade. If successful, reboot does ACK and
+// check-in, otherwise the action is acked with the error.
+func (h *Rollback) Handle(ctx context.Context, a fleetapi.Action, ack acker.Acker) error {
+	h.log.Debugf("handlerRollback: action '%+v' received", a)
+	action, ok := a.(*fleetapi.ActionRollback)
+	if !ok {
+		return fmt.Errorf("invalid type, expected ActionRollback and received %T", a)
+	}
+
+	go func() {
+		h.log.Info("starting rollback to the previous version in background")
+		if err := h.coord.Rollback(ctx, action); err != nil {
+			h.log.Errorf("rollback to the previous version failed: %v", err)
+			action.Err = err
+			if err := ack.Ack(ctx, action); err != nil {
+				h.log.Errorf("ack of failed rollback failed: %v", err)
+			}
+			if err := ack.Commit(ctx); err != nil {
+				h.log.Errorf("commit of ack for failed rollback failed: %v", err)
+			}
+		}
+	}()
+	return nil
+}
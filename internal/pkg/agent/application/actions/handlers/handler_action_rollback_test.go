@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	mockackers "github.com/elastic/elastic-agent/testing/mocks/internal_/pkg/fleetapi/acker"
+)
+
+type fakeRollbackCoordinator struct {
+	err    error
+	called chan *fleetapi.ActionRollback
+}
+
+func (f *fakeRollbackCoordinator) Rollback(_ context.Context, action *fleetapi.ActionRollback) error {
+	f.called <- action
+	return f.err
+}
+
+func TestRollbackHandler(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	action := &fleetapi.ActionRollback{ActionID: "action-id", ActionType: fleetapi.ActionTypeRollback}
+
+	t.Run("invalid action type", func(t *testing.T) {
+		h := NewRollback(log, &fakeRollbackCoordinator{})
+		err := h.Handle(context.Background(), &fleetapi.ActionUpgrade{}, mockackers.NewAcker(t))
+		assert.Error(t, err)
+	})
+
+	t.Run("rollback triggered, acked after restart", func(t *testing.T) {
+		coord := &fakeRollbackCoordinator{called: make(chan *fleetapi.ActionRollback, 1)}
+		h := NewRollback(log, coord)
+		// no ack expected
+		require.NoError(t, h.Handle(context.Background(), action, mockackers.NewAcker(t)))
+
+		select {
+		case a := <-coord.called:
+			assert.Equal(t, action, a)
+		case <-time.After(time.Second):
+			t.Fatal("coordinator Rollback was not called")
+		}
+	})
+
+	t.Run("rollback failed, acked with the error", func(t *testing.T) {
+		coord := &fakeRollbackCoordinator{err: errors.New("no previous version available to rollback to"), called: make(chan *fleetapi.ActionRollback, 1)}
+		h := NewRollback(log, coord)
+
+		committed := make(chan struct{})
+		ack := mockackers.NewAcker(t)
+		ack.EXPECT().Ack(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, a fleetapi.Action) error {
+			assert.Equal(t, "no previous version available to rollback to", a.AckEvent().Error)
+			return nil
+		})
+		ack.EXPECT().Commit(mock.Anything).RunAndReturn(func(_ context.Context) error {
+			close(committed)
+			return nil
+		})
+		require.NoError(t, h.Handle(context.Background(), action, ack))
+
+		select {
+		case <-committed:
+		case <-time.After(time.Second):
+			t.Fatal("failed rollback was not acked")
+		}
+	})
+}
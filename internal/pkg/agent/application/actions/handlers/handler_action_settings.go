@@ -11,6 +11,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -18,12 +19,18 @@ import (
 
 const clearLogLevelValue = ""
 
+// capabilitiesProvider provides the capabilities currently enforced by the agent.
+type capabilitiesProvider interface {
+	Capabilities() capabilities.Capabilities
+}
+
 // Settings handles settings change coming from fleet and updates log level.
 type Settings struct {
 	log              *logger.Logger
 	agentInfo        info.Agent
 	fallbackLogLevel *logp.Level
 	logLevelSetter   logLevelSetter
+	capsProvider     capabilitiesProvider
 }
 
 // NewSettings creates a new Settings handler.
@@ -31,11 +38,13 @@ func NewSettings(
 	log *logger.Logger,
 	agentInfo info.Agent,
 	logLevelSetter logLevelSetter,
+	capsProvider capabilitiesProvider,
 ) *Settings {
 	return &Settings{
 		log:            log,
 		agentInfo:      agentInfo,
 		logLevelSetter: logLevelSetter,
+		capsProvider:   capsProvider,
 	}
 }
 
@@ -57,6 +66,9 @@ func (h *Settings) handleLogLevel(ctx context.Context, logLevel string, acker ac
 		if !isSupportedLogLevel(logLevel) {
 			return fmt.Errorf("invalid log level, expected debug|info|warning|error and received '%s'", logLevel)
 		}
+		if !h.allowLogLevel(logLevel) {
+			return fmt.Errorf("log level '%s' is denied by capabilities.yml", logLevel)
+		}
 
 		// parse loglvl from the string
 		parsedLvl := logp.InfoLevel
@@ -99,6 +111,11 @@ func (h *Settings) SetLogLevel(ctx context.Context, lvl *logp.Level) error {
 	if lvl != nil && !isSupportedLogLevel(lvl.String()) {
 		return fmt.Errorf("invalid log level, expected debug|info|warning|error and received '%s'", lvl.String())
 	}
+	if lvl != nil && !h.allowLogLevel(lvl.String()) {
+		// a denied policy level must not reject the rest of the policy, the current level is kept
+		h.log.Warnf("log level '%s' from policy is denied by capabilities.yml, keeping the current log level", lvl.String())
+		return nil
+	}
 
 	h.fallbackLogLevel = lvl
 	rawLogLevel := h.agentInfo.RawLogLevel()
@@ -120,6 +137,18 @@ func (h *Settings) SetLogLevel(ctx context.Context, lvl *logp.Level) error {
 	return nil
 }
 
+// allowLogLevel checks the log level against the capabilities, if any.
+func (h *Settings) allowLogLevel(level string) bool {
+	if h.capsProvider == nil {
+		return true
+	}
+	caps := h.capsProvider.Capabilities()
+	if caps == nil {
+		return true
+	}
+	return caps.AllowLogLevel(level)
+}
+
 func isSupportedLogLevel(level string) bool {
 	return level == "error" || level == "debug" || level == "info" || level == "warning"
 }
@@ -7,12 +7,15 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
@@ -213,3 +216,58 @@ func TestSettings_handleLogLevel(t *testing.T) {
 		})
 	}
 }
+
+type fakeCapabilitiesProvider struct {
+	caps capabilities.Capabilities
+}
+
+func (f fakeCapabilitiesProvider) Capabilities() capabilities.Capabilities {
+	return f.caps
+}
+
+func TestSettings_LogLevelDeniedByCapabilities(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  log_level: debug
+`), log)
+	require.NoError(t, err)
+
+	t.Run("settings action", func(t *testing.T) {
+		mockAgentInfo := mockinfo.NewAgent(t)
+		mockLogLevelSetter := mockhandlers.NewLogLevelSetter(t)
+		mockAcker := mockfleetacker.NewAcker(t)
+
+		h := NewSettings(log, mockAgentInfo, mockLogLevelSetter, fakeCapabilitiesProvider{caps: caps})
+		action := &fleetapi.ActionSettings{
+			ActionID:   "someactionid",
+			ActionType: fleetapi.ActionTypeSettings,
+			Data:       fleetapi.ActionSettingsData{LogLevel: "debug"},
+		}
+		err := h.Handle(context.Background(), action, mockAcker)
+		assert.ErrorContains(t, err, "denied by capabilities.yml")
+	})
+
+	t.Run("policy fallback level", func(t *testing.T) {
+		mockAgentInfo := mockinfo.NewAgent(t)
+		mockLogLevelSetter := mockhandlers.NewLogLevelSetter(t)
+
+		h := NewSettings(log, mockAgentInfo, mockLogLevelSetter, fakeCapabilitiesProvider{caps: caps})
+		debugLevel := logp.DebugLevel
+		err := h.SetLogLevel(context.Background(), &debugLevel)
+		assert.NoError(t, err)
+		assert.Nil(t, h.fallbackLogLevel)
+	})
+
+	t.Run("allowed level", func(t *testing.T) {
+		mockAgentInfo := mockinfo.NewAgent(t)
+		mockLogLevelSetter := mockhandlers.NewLogLevelSetter(t)
+		infoLevel := logp.InfoLevel
+		mockAgentInfo.EXPECT().RawLogLevel().Return("").Once()
+		mockLogLevelSetter.EXPECT().SetLogLevel(mock.Anything, &infoLevel).Return(nil).Once()
+
+		h := NewSettings(log, mockAgentInfo, mockLogLevelSetter, fakeCapabilitiesProvider{caps: caps})
+		assert.NoError(t, h.SetLogLevel(context.Background(), &infoLevel))
+	})
+}
@@ -6,10 +6,12 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sync"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -43,6 +45,7 @@ func NewUpgrade(log *logger.Logger, coord upgradeCoordinator) *Upgrade {
 // happen without blocking updates.  If multiple upgrades are sent
 // then we ack them all if there is an error, but only the first actually executes.
 // If successful, reboot does ACK and check-in.
+// Dry-runs do not switch versions, they run independently of the upgrades and are acked with their report.
 func (h *Upgrade) Handle(ctx context.Context, a fleetapi.Action, ack acker.Acker) error {
 	h.log.Debugf("handlerUpgrade: action '%+v' received", a)
 	action, ok := a.(*fleetapi.ActionUpgrade)
@@ -50,6 +53,11 @@ func (h *Upgrade) Handle(ctx context.Context, a fleetapi.Action, ack acker.Acker
 		return fmt.Errorf("invalid type, expected ActionUpgrade and received %T", a)
 	}
 
+	if action.Data.DryRun {
+		go h.dryRun(ctx, action, ack)
+		return nil
+	}
+
 	asyncCtx, runAsync := h.getAsyncContext(ctx, a, ack)
 	if !runAsync {
 		return nil
@@ -75,7 +83,7 @@ func (h *Upgrade) Handle(ctx context.Context, a fleetapi.Action, ack acker.Acker
 
 	go func() {
 		h.log.Infof("starting upgrade to version %s in background", action.Data.Version)
-		if err := h.coord.Upgrade(asyncCtx, action.Data.Version, action.Data.SourceURI, action, false, false); err != nil {
+		if err := h.coord.Upgrade(asyncCtx, action.Data.Version, action.Data.SourceURI, action, false, false, nil); err != nil {
 			h.log.Errorf("upgrade to version %s failed: %v", action.Data.Version, err)
 			// If context is cancelled in getAsyncContext, the actions are acked there
 			if !errors.Is(asyncCtx.Err(), context.Canceled) {
@@ -88,6 +96,37 @@ func (h *Upgrade) Handle(ctx context.Context, a fleetapi.Action, ack acker.Acker
 	return nil
 }
 
+// dryRun runs the upgrade preflight checks and acks the action with the report, the action fails when a
+// check fails.
+func (h *Upgrade) dryRun(ctx context.Context, action *fleetapi.ActionUpgrade, ack acker.Acker) {
+	h.log.Infof("starting upgrade dry-run to version %s in background", action.Data.Version)
+	report, err := h.coord.UpgradePreflight(ctx, action.Data.Version, action.Data.SourceURI, false, false, nil)
+	if err == nil {
+		action.Response, err = preflightResponse(report)
+	}
+	if err == nil {
+		err = report.Err()
+	}
+	if err != nil {
+		h.log.Errorf("upgrade dry-run to version %s failed: %v", action.Data.Version, err)
+	}
+	action.Err = err
+	h.ackAction(ctx, ack, action, true)
+}
+
+// preflightResponse converts the report to the response sent with the ack.
+func preflightResponse(report *upgrade.PreflightReport) (map[string]interface{}, error) {
+	b, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(b, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 // ackActions Acks all the actions in bkgActions, and deletes entries from bkgActions.
 // User is responsible for obtaining and releasing bkgMutex lock
 func (h *Upgrade) ackActions(ctx context.Context, ack acker.Acker) {
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
@@ -38,6 +39,15 @@ type mockUpgradeManager struct {
 		skipVerifyOverride bool,
 		skipDefaultPgp bool,
 		pgpBytes ...string) (reexec.ShutdownCallbackFn, error)
+	RollbackFn func(
+		ctx context.Context,
+		action *fleetapi.ActionRollback,
+		details *details.Details) (reexec.ShutdownCallbackFn, error)
+	PreflightFn func(
+		ctx context.Context,
+		version string,
+		sourceURI string,
+		req upgrade.PreflightRequirements) (*upgrade.PreflightReport, error)
 }
 
 func (u *mockUpgradeManager) Upgradeable() bool {
@@ -56,6 +66,7 @@ func (u *mockUpgradeManager) Upgrade(
 	details *details.Details,
 	skipVerifyOverride bool,
 	skipDefaultPgp bool,
+	_ []string,
 	pgpBytes ...string) (reexec.ShutdownCallbackFn, error) {
 
 	return u.UpgradeFn(
@@ -69,6 +80,14 @@ func (u *mockUpgradeManager) Upgrade(
 		pgpBytes...)
 }
 
+func (u *mockUpgradeManager) Rollback(ctx context.Context, action *fleetapi.ActionRollback, details *details.Details) (reexec.ShutdownCallbackFn, error) {
+	return u.RollbackFn(ctx, action, details)
+}
+
+func (u *mockUpgradeManager) Preflight(ctx context.Context, version string, sourceURI string, req upgrade.PreflightRequirements, _ bool, _ bool, _ []string, _ ...string) (*upgrade.PreflightReport, error) {
+	return u.PreflightFn(ctx, version, sourceURI, req)
+}
+
 func (u *mockUpgradeManager) Ack(_ context.Context, _ acker.Acker) error {
 	return nil
 }
@@ -381,3 +400,74 @@ func (f *fakeAcker) Commit(ctx context.Context) error {
 	args := f.Called(ctx)
 	return args.Error(0)
 }
+
+func TestUpgradeHandlerDryRun(t *testing.T) {
+	// Create a cancellable context that will shut down the coordinator after
+	// the test.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log, _ := logger.New("", false)
+
+	agentInfo := &info.AgentInfo{}
+	report := &upgrade.PreflightReport{
+		Version: "8.3.0",
+		Checks: []upgrade.PreflightCheck{
+			{Name: upgrade.PreflightCheckArtifact, Status: upgrade.PreflightPassed},
+			{Name: upgrade.PreflightCheckDiskSpace, Status: upgrade.PreflightFailed, Message: "not enough space"},
+		},
+	}
+
+	// Create and start the coordinator
+	c := coordinator.New(
+		log,
+		configuration.DefaultConfiguration(),
+		logger.DefaultLogLevel,
+		agentInfo,
+		component.RuntimeSpecs{},
+		nil,
+		&mockUpgradeManager{
+			UpgradeFn: func(
+				ctx context.Context,
+				version string,
+				sourceURI string,
+				action *fleetapi.ActionUpgrade,
+				details *details.Details,
+				skipVerifyOverride bool,
+				skipDefaultPgp bool,
+				pgpBytes ...string) (reexec.ShutdownCallbackFn, error) {
+
+				t.Error("dry-run must not upgrade")
+				return nil, nil
+			},
+			PreflightFn: func(ctx context.Context, version string, sourceURI string, req upgrade.PreflightRequirements) (*upgrade.PreflightReport, error) {
+				return report, nil
+			},
+		},
+		nil, nil, nil, nil, nil, false, nil, nil)
+	//nolint:errcheck // We don't need the termination state of the Coordinator
+	go c.Run(ctx)
+
+	u := NewUpgrade(log, c)
+	a := fleetapi.ActionUpgrade{ActionID: "action-id", ActionType: fleetapi.ActionTypeUpgrade, Data: fleetapi.ActionUpgradeData{
+		Version: "8.3.0", SourceURI: "http://localhost", DryRun: true}}
+
+	committed := make(chan struct{})
+	ack := &fakeAcker{}
+	ack.On("Ack", mock.Anything, mock.Anything).Return(nil).Once()
+	ack.On("Commit", mock.Anything).Run(func(_ mock.Arguments) { close(committed) }).Return(nil).Once()
+
+	err := u.Handle(ctx, &a, ack)
+	require.NoError(t, err)
+
+	select {
+	case <-time.After(time.Second):
+		t.Fatal("dry-run was not acked")
+	case <-committed:
+	}
+
+	event := a.AckEvent()
+	assert.Contains(t, event.Error, "disk_space: not enough space")
+	assert.Equal(t, "8.3.0", event.ActionResponse["version"])
+	assert.Len(t, event.ActionResponse["checks"], 2)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/coordinator"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/core/backoff"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
@@ -28,7 +29,12 @@ type actionCoordinator interface {
 
 type upgradeCoordinator interface {
 	actionCoordinator
-	Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, skipVerifyOverride bool, skipDefaultPgp bool, pgpBytes ...string) error
+	Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) error
+	UpgradePreflight(ctx context.Context, version string, sourceURI string, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*upgrade.PreflightReport, error)
+}
+
+type rollbackCoordinator interface {
+	Rollback(ctx context.Context, action *fleetapi.ActionRollback) error
 }
 
 type performActionFunc func(context.Context, component.Component, component.Unit, string, map[string]interface{}) (map[string]interface{}, error)
@@ -18,6 +18,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/monitoring"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/peer"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/storage"
@@ -122,6 +123,13 @@ func New(
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("failed to create upgrader: %w", err)
 	}
+	// the peer cache runs disabled as well, it is enabled when the configuration changes
+	peerCache, err := peer.NewCache(log.Named("peer_cache"), cfg.Settings.DownloadConfig.PeerCache)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create peer cache: %w", err)
+	}
+	upgrader.SetPeerCache(peerCache)
+	go peerCache.Run(ctx)
 	monitor := monitoring.New(isMonitoringSupported, cfg.Settings.DownloadConfig.OS(), cfg.Settings.MonitoringConfig, agentInfo)
 
 	runtime, err := runtime.NewManager(
@@ -229,10 +237,13 @@ func New(
 		return nil, nil, nil, fmt.Errorf("failed to create otel manager: %w", err)
 	}
 	coord := coordinator.New(log, cfg, logLevel, agentInfo, specs, reexec, upgrader, runtime, configMgr, varsManager, caps, monitor, isManaged, otelManager, actionAcker, compModifiers...)
+	coord.RegisterCapabilitiesWatcher(capabilities.NewFileWatcher(paths.AgentCapabilitiesPath(), log))
 	if managed != nil {
 		// the coordinator requires the config manager as well as in managed-mode the config manager requires the
 		// coordinator, so it must be set here once the coordinator is created
 		managed.coord = coord
+		// upgrade actions are deferred to the upgrade windows of the current configuration
+		coord.RegisterUpgradeWindowsObserver(managed.dispatcher)
 	}
 
 	// every time we change the limits we'll see the log message
@@ -9,7 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/logp"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reexec"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
@@ -70,7 +73,13 @@ type UpgradeManager interface {
 	Reload(rawConfig *config.Config) error
 
 	// Upgrade upgrades running agent.
-	Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, details *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error)
+	Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, details *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error)
+
+	// Rollback rolls back the running agent to the previous version kept on disk.
+	Rollback(ctx context.Context, action *fleetapi.ActionRollback, details *details.Details) (_ reexec.ShutdownCallbackFn, err error)
+
+	// Preflight runs the upgrade steps that do not switch versions and reports whether the upgrade would succeed.
+	Preflight(ctx context.Context, version string, sourceURI string, req upgrade.PreflightRequirements, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*upgrade.PreflightReport, error)
 
 	// Ack is used on startup to check if the agent has upgraded and needs to send an ack for the action
 	Ack(ctx context.Context, acker acker.Acker) error
@@ -197,10 +206,19 @@ type ComponentsModifier func(comps []component.Component, cfg map[string]interfa
 // to receive termination states from its managers.
 const managerShutdownTimeout = time.Second * 5
 
+// rollbackExpiryCheckInterval is how often the coordinator checks if the rollback window of the
+// previous version kept on disk has expired.
+const rollbackExpiryCheckInterval = time.Hour
+
 type configReloader interface {
 	Reload(*config.Config) error
 }
 
+// UpgradeWindowsObserver is notified of the upgrade windows every time the configuration changes.
+type UpgradeWindowsObserver interface {
+	SetUpgradeWindows(*upgrade.Windows)
+}
+
 // Coordinator manages the entire state of the Elastic Agent.
 //
 // All configuration changes, update variables, and upgrade actions are managed and controlled by the coordinator.
@@ -228,8 +246,15 @@ type Coordinator struct {
 	// the final config sent to the manager, contains both config from hybrid mode and from components
 	finalOtelCfg *confmap.Conf
 
-	caps      capabilities.Capabilities
-	modifiers []ComponentsModifier
+	// caps is only written on the main Coordinator goroutine, reads from
+	// external goroutines must hold capsMx.
+	caps        capabilities.Capabilities
+	capsMx      sync.RWMutex
+	capsWatcher capabilities.Watcher
+	// capsReport explains the capability decisions for the last generated
+	// component model, reads from external goroutines must hold capsMx.
+	capsReport capabilities.Report
+	modifiers  []ComponentsModifier
 
 	// The current state of the Coordinator. This value and its subfields are
 	// safe to read directly from within the main Coordinator goroutine.
@@ -263,6 +288,23 @@ type Coordinator struct {
 	// SetUpgradeDetails helper to the Coordinator goroutine.
 	upgradeDetailsChan chan *details.Details
 
+	// scheduledUpgrade runs scheduledUpgradeReq, the upgrade requested through the control protocol outside
+	// the upgrade windows, once the next window opens. The timer only runs while the Coordinator runs,
+	// scheduledUpgradeCtx is the context of the run. They are guarded by scheduledUpgradeMx.
+	scheduledUpgrade    *time.Timer
+	scheduledUpgradeReq *upgrade.ScheduledUpgrade
+	scheduledUpgradeCtx context.Context
+	scheduledUpgradeMx  sync.Mutex
+	// scheduledUpgradeRestore is set when the runner starts, the persisted upgrade is restored once the upgrade
+	// windows of the first configuration are loaded. Only accessed on the main Coordinator goroutine.
+	scheduledUpgradeRestore bool
+
+	// upgradeWindows are the upgrade windows of the current configuration, they are only written on the
+	// main Coordinator goroutine, reads from external goroutines must hold upgradeWindowsMx.
+	upgradeWindows         *upgrade.Windows
+	upgradeWindowsMx       sync.RWMutex
+	upgradeWindowsObserver UpgradeWindowsObserver
+
 	// loglevelCh forwards log level changes from the public API (SetLogLevel)
 	// to the run loop in Coordinator's main goroutine.
 	logLevelCh chan logp.Level
@@ -353,6 +395,8 @@ type managerChans struct {
 	otelManagerError  <-chan error
 
 	upgradeMarkerUpdate <-chan upgrade.UpdateMarker
+
+	capabilitiesUpdate <-chan capabilities.Capabilities
 }
 
 // diffCheck is a container used by checkAndLogUpdate()
@@ -435,6 +479,16 @@ func New(logger *logger.Logger, cfg *configuration.Configuration, logLevel logp.
 
 		fleetAcker: fleetAcker,
 	}
+	if cfg != nil && cfg.Settings != nil && cfg.Settings.Upgrade != nil {
+		// the windows of the startup configuration apply until the first configuration is processed, a configuration
+		// with invalid windows is rejected, so upgrades are refused until valid windows are configured
+		windows, err := upgrade.NewWindows(cfg.Settings.Upgrade.Windows)
+		if err != nil {
+			logger.Errorw("Invalid upgrade windows, upgrades are refused until valid windows are configured", "error.message", err)
+			windows = upgrade.ClosedWindows()
+		}
+		c.upgradeWindows = windows
+	}
 	// Setup communication channels for any non-nil components. This pattern
 	// lets us transparently accept nil managers / simulated events during
 	// unit testing.
@@ -500,6 +554,20 @@ func (c *Coordinator) RegisterMonitoringServer(s configReloader) {
 	c.monitoringServerReloader = s
 }
 
+// RegisterUpgradeWindowsObserver sets the observer notified of the upgrade windows when the configuration
+// changes, it is notified of the current windows right away. Must be called before Run.
+func (c *Coordinator) RegisterUpgradeWindowsObserver(o UpgradeWindowsObserver) {
+	c.upgradeWindowsObserver = o
+	o.SetUpgradeWindows(c.upgradeWindows)
+}
+
+// RegisterCapabilitiesWatcher sets the watcher that reports changes to the
+// capabilities file. Must be called before Run.
+func (c *Coordinator) RegisterCapabilitiesWatcher(w capabilities.Watcher) {
+	c.capsWatcher = w
+	c.managerChans.capabilitiesUpdate = w.Watch()
+}
+
 // StateSubscribe returns a channel that reports changes in Coordinator state.
 //
 // bufferLen specifies how many state changes should be queued in addition to
@@ -549,18 +617,13 @@ func (c *Coordinator) ReExec(callback reexec.ShutdownCallbackFn, argOverrides ..
 
 // Upgrade runs the upgrade process.
 // Called from external goroutines.
-func (c *Coordinator) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, skipVerifyOverride bool, skipDefaultPgp bool, pgpBytes ...string) error {
-	// early check outside of upgrader before overriding the state
-	if !c.upgradeMgr.Upgradeable() {
-		return ErrNotUpgradable
+func (c *Coordinator) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) error {
+	if err := c.checkUpgradeAllowed(version, sourceURI); err != nil {
+		return err
 	}
 
-	// early check capabilities to ensure this upgrade actions is allowed
-	if c.caps != nil {
-		if !c.caps.AllowUpgrade(version, sourceURI) {
-			return ErrNotUpgradable
-		}
-	}
+	// a new upgrade replaces the one deferred to the next upgrade window
+	c.cancelScheduledUpgrade()
 
 	// A previous upgrade may be cancelled and needs some time to
 	// run the callback to clear the state
@@ -589,7 +652,7 @@ func (c *Coordinator) Upgrade(ctx context.Context, version string, sourceURI str
 	det := details.NewDetails(version, details.StateRequested, actionID)
 	det.RegisterObserver(c.SetUpgradeDetails)
 
-	cb, err := c.upgradeMgr.Upgrade(ctx, version, sourceURI, action, det, skipVerifyOverride, skipDefaultPgp, pgpBytes...)
+	cb, err := c.upgradeMgr.Upgrade(ctx, version, sourceURI, action, det, skipVerifyOverride, skipDefaultPgp, cosignKeys, pgpBytes...)
 	if err != nil {
 		c.ClearOverrideState()
 		if errors.Is(err, upgrade.ErrUpgradeSameVersion) {
@@ -607,6 +670,287 @@ func (c *Coordinator) Upgrade(ctx context.Context, version string, sourceURI str
 	return nil
 }
 
+// ScheduleUpgrade runs the upgrade process requested through the control protocol. Outside the upgrade windows
+// the upgrade is persisted and deferred to the start of the next window, which is returned, upgrades from Fleet
+// are deferred by the action dispatcher instead.
+// Called from external goroutines.
+func (c *Coordinator) ScheduleUpgrade(ctx context.Context, version string, sourceURI string, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*time.Time, error) {
+	if err := c.checkUpgradeAllowed(version, sourceURI); err != nil {
+		return nil, err
+	}
+
+	next, err := c.nextUpgradeWindow(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if next.IsZero() {
+		return nil, c.Upgrade(ctx, version, sourceURI, nil, skipVerifyOverride, skipDefaultPgp, cosignKeys, pgpBytes...)
+	}
+
+	c.logger.Infow("Upgrade is outside of the upgrade windows, deferring it to the next window", "version", version, "scheduled_at", next)
+	err = c.scheduleUpgrade(&upgrade.ScheduledUpgrade{
+		Version:        version,
+		SourceURI:      sourceURI,
+		SkipVerify:     skipVerifyOverride,
+		SkipDefaultPgp: skipDefaultPgp,
+		PgpBytes:       pgpBytes,
+		CosignKeys:     cosignKeys,
+		ScheduledAt:    next,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// checkUpgradeAllowed checks if the agent can be upgraded to the version, outside of upgrader before overriding
+// the state.
+func (c *Coordinator) checkUpgradeAllowed(version string, sourceURI string) error {
+	if !c.upgradeMgr.Upgradeable() {
+		return ErrNotUpgradable
+	}
+
+	// early check capabilities to ensure this upgrade actions is allowed
+	if caps := c.Capabilities(); caps != nil {
+		if !caps.AllowUpgrade(version, sourceURI) {
+			return ErrNotUpgradable
+		}
+	}
+	return nil
+}
+
+// nextUpgradeWindow returns the start of the next upgrade window, the zero time when an upgrade can run now.
+func (c *Coordinator) nextUpgradeWindow(now time.Time) (time.Time, error) {
+	c.upgradeWindowsMx.RLock()
+	windows := c.upgradeWindows
+	c.upgradeWindowsMx.RUnlock()
+
+	next, err := windows.Next(now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !next.After(now) {
+		return time.Time{}, nil
+	}
+	return next, nil
+}
+
+// scheduleUpgrade persists the upgrade and runs it once its upgrade window starts.
+func (c *Coordinator) scheduleUpgrade(scheduled *upgrade.ScheduledUpgrade) error {
+	det, err := c.setScheduledUpgrade(scheduled)
+	if err != nil {
+		return err
+	}
+	// the details are sent to the Coordinator goroutine without holding scheduledUpgradeMx, it is also
+	// taken on the Coordinator goroutine when the upgrade windows are reloaded
+	c.SetUpgradeDetails(det)
+	return nil
+}
+
+// setScheduledUpgrade persists the upgrade, replacing the one deferred to the next upgrade window, and starts
+// its timer. It returns the details reporting the upgrade as scheduled.
+func (c *Coordinator) setScheduledUpgrade(scheduled *upgrade.ScheduledUpgrade) (*details.Details, error) {
+	c.scheduledUpgradeMx.Lock()
+	defer c.scheduledUpgradeMx.Unlock()
+	return c.setScheduledUpgradeLocked(scheduled)
+}
+
+// setScheduledUpgradeLocked is setScheduledUpgrade with scheduledUpgradeMx held.
+func (c *Coordinator) setScheduledUpgradeLocked(scheduled *upgrade.ScheduledUpgrade) (*details.Details, error) {
+	if err := upgrade.SaveScheduledUpgrade(paths.Data(), scheduled); err != nil {
+		return nil, fmt.Errorf("failed to persist the scheduled upgrade: %w", err)
+	}
+	c.scheduledUpgradeReq = scheduled
+	c.startScheduledUpgradeTimer()
+
+	det := details.NewDetails(scheduled.Version, details.StateScheduled, "")
+	det.Metadata.ScheduledAt = &scheduled.ScheduledAt
+	return det, nil
+}
+
+// startScheduledUpgradeTimer (re)starts the timer running the scheduled upgrade at the start of its window. The
+// timer is not started when the Coordinator does not run, the persisted upgrade is restored when it runs.
+// Must be called with scheduledUpgradeMx held.
+func (c *Coordinator) startScheduledUpgradeTimer() {
+	if c.scheduledUpgrade != nil {
+		c.scheduledUpgrade.Stop()
+		c.scheduledUpgrade = nil
+	}
+	ctx := c.scheduledUpgradeCtx
+	scheduled := c.scheduledUpgradeReq
+	if ctx == nil || ctx.Err() != nil || scheduled == nil {
+		return
+	}
+	c.scheduledUpgrade = time.AfterFunc(time.Until(scheduled.ScheduledAt), func() {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.Upgrade(ctx, scheduled.Version, scheduled.SourceURI, nil, scheduled.SkipVerify, scheduled.SkipDefaultPgp, scheduled.CosignKeys, scheduled.PgpBytes...); err != nil {
+			c.logger.Errorw("Scheduled upgrade failed", "version", scheduled.Version, "error.message", err)
+		}
+	})
+}
+
+// runScheduledUpgrades lets the scheduled upgrade run during the Coordinator run with the given context. The
+// timer is stopped when the context is done, the upgrade stays persisted to run after a restart.
+// Called on the main Coordinator goroutine, from Coordinator.runner.
+func (c *Coordinator) runScheduledUpgrades(ctx context.Context) {
+	c.scheduledUpgradeMx.Lock()
+	c.scheduledUpgradeCtx = ctx
+	c.scheduledUpgradeMx.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		c.scheduledUpgradeMx.Lock()
+		defer c.scheduledUpgradeMx.Unlock()
+		if c.scheduledUpgrade != nil {
+			c.scheduledUpgrade.Stop()
+			c.scheduledUpgrade = nil
+		}
+	})
+}
+
+// rescheduleUpgrade moves the upgrade deferred to the next upgrade window to the next window of the current
+// upgrade windows, it runs right away when it is now within a window. It returns the details reporting the
+// upgrade as scheduled, nil when the upgrade is not rescheduled.
+func (c *Coordinator) rescheduleUpgrade(now time.Time) *details.Details {
+	c.scheduledUpgradeMx.Lock()
+	defer c.scheduledUpgradeMx.Unlock()
+	scheduled := c.scheduledUpgradeReq
+	if scheduled == nil {
+		return nil
+	}
+
+	next, err := c.nextUpgradeWindow(now)
+	if err != nil {
+		c.logger.Errorw("Failed to determine the next upgrade window, the scheduled upgrade is not rescheduled", "version", scheduled.Version, "error.message", err)
+		return nil
+	}
+	if next.IsZero() {
+		next = now
+	}
+	if next.Equal(scheduled.ScheduledAt) {
+		return nil
+	}
+
+	c.logger.Infow("Upgrade windows changed, rescheduling the deferred upgrade", "version", scheduled.Version, "scheduled_at", next)
+	rescheduled := *scheduled
+	rescheduled.ScheduledAt = next
+	det, err := c.setScheduledUpgradeLocked(&rescheduled)
+	if err != nil {
+		c.logger.Errorw("Failed to reschedule the deferred upgrade", "version", scheduled.Version, "error.message", err)
+		return nil
+	}
+	return det
+}
+
+// restoreScheduledUpgrade schedules again the upgrade persisted before the agent restarted, it runs right away
+// if it is now within an upgrade window. An upgrade that is not allowed anymore is removed.
+// Called on the main Coordinator goroutine, the upgrade is scheduled from its own goroutine as it may run.
+func (c *Coordinator) restoreScheduledUpgrade() {
+	scheduled, err := upgrade.LoadScheduledUpgrade(paths.Data())
+	if err != nil {
+		c.logger.Errorw("Failed to load the scheduled upgrade", "error.message", err)
+		return
+	}
+	if scheduled == nil {
+		return
+	}
+	if err := c.checkUpgradeAllowed(scheduled.Version, scheduled.SourceURI); err != nil {
+		c.logger.Errorw("Scheduled upgrade is not allowed anymore, removing it", "version", scheduled.Version, "error.message", err)
+		c.cancelScheduledUpgrade()
+		return
+	}
+	c.logger.Infow("Restoring the upgrade deferred to the next upgrade window", "version", scheduled.Version, "scheduled_at", scheduled.ScheduledAt)
+	c.scheduledUpgradeMx.Lock()
+	ctx := c.scheduledUpgradeCtx
+	c.scheduledUpgradeMx.Unlock()
+	go func() {
+		_, err := c.ScheduleUpgrade(ctx, scheduled.Version, scheduled.SourceURI, scheduled.SkipVerify, scheduled.SkipDefaultPgp, scheduled.CosignKeys, scheduled.PgpBytes...)
+		if err != nil {
+			c.logger.Errorw("Scheduled upgrade failed", "version", scheduled.Version, "error.message", err)
+		}
+	}()
+}
+
+// cancelScheduledUpgrade cancels the upgrade deferred to the next upgrade window, if any.
+func (c *Coordinator) cancelScheduledUpgrade() {
+	c.scheduledUpgradeMx.Lock()
+	defer c.scheduledUpgradeMx.Unlock()
+	if c.scheduledUpgrade != nil {
+		c.scheduledUpgrade.Stop()
+		c.scheduledUpgrade = nil
+	}
+	c.scheduledUpgradeReq = nil
+	if err := upgrade.CleanScheduledUpgrade(paths.Data()); err != nil {
+		c.logger.Errorw("Failed to remove the scheduled upgrade", "error.message", err)
+	}
+}
+
+// Rollback rolls back the running Elastic Agent to the previous version kept on disk after the last
+// successful upgrade.
+//
+// Capabilities are not checked, the Elastic Agent goes back to a version that was already running.
+// Called from external goroutines.
+func (c *Coordinator) Rollback(ctx context.Context, action *fleetapi.ActionRollback) error {
+	// early check outside of upgrader before overriding the state
+	if !c.upgradeMgr.Upgradeable() {
+		return ErrNotUpgradable
+	}
+
+	if c.State().State == agentclient.Upgrading {
+		return ErrUpgradeInProgress
+	}
+
+	// override the overall state to upgrading until the re-execution is complete
+	c.SetOverrideState(agentclient.Upgrading, "Rolling back to the previous version")
+
+	// initialize upgrade details, the target version is set by the upgrade manager
+	actionID := ""
+	if action != nil {
+		actionID = action.ActionID
+	}
+	det := details.NewDetails("", details.StateRequested, actionID)
+	det.RegisterObserver(c.SetUpgradeDetails)
+
+	cb, err := c.upgradeMgr.Rollback(ctx, action, det)
+	if err != nil {
+		c.ClearOverrideState()
+		det.Fail(err)
+		return err
+	}
+	if cb != nil {
+		det.SetState(details.StateRestarting)
+		c.ReExec(cb)
+	}
+	return nil
+}
+
+// UpgradePreflight runs the preflight checks of an upgrade without switching versions, the report tells whether
+// the upgrade would succeed. Unlike upgrades, dry-runs are not deferred to the upgrade windows.
+// Called from external goroutines.
+func (c *Coordinator) UpgradePreflight(ctx context.Context, version string, sourceURI string, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*upgrade.PreflightReport, error) {
+	if !c.upgradeMgr.Upgradeable() {
+		return nil, ErrNotUpgradable
+	}
+
+	state := c.State()
+	if state.State == agentclient.Upgrading {
+		return nil, ErrUpgradeInProgress
+	}
+
+	req := upgrade.PreflightRequirements{UpgradeAllowed: true}
+	if caps := c.Capabilities(); caps != nil {
+		req.UpgradeAllowed = caps.AllowUpgrade(version, sourceURI)
+	}
+	for _, comp := range state.Components {
+		if comp.Component.InputType != "" {
+			req.InputTypes = append(req.InputTypes, comp.Component.InputType)
+		}
+	}
+
+	return c.upgradeMgr.Preflight(ctx, version, sourceURI, req, skipVerifyOverride, skipDefaultPgp, cosignKeys, pgpBytes...)
+}
+
 func (c *Coordinator) logUpgradeDetails(details *details.Details) {
 	c.logger.Infow("updated upgrade details", "upgrade_details", details)
 }
@@ -635,6 +979,15 @@ func (c *Coordinator) PerformComponentDiagnostics(ctx context.Context, additiona
 	return c.runtimeMgr.PerformComponentDiagnostics(ctx, additionalMetrics, req...)
 }
 
+// Capabilities returns the capabilities the Coordinator enforces. Returns nil
+// when no capabilities are configured.
+// Called from external goroutines.
+func (c *Coordinator) Capabilities() capabilities.Capabilities {
+	c.capsMx.RLock()
+	defer c.capsMx.RUnlock()
+	return c.caps
+}
+
 // SetLogLevel changes the entire log level for the running Elastic Agent.
 // Called from external goroutines.
 func (c *Coordinator) SetLogLevel(ctx context.Context, lvl *logp.Level) error {
@@ -1019,6 +1372,22 @@ func (c *Coordinator) DiagnosticHooks() diagnostics.Hooks {
 				return o
 			},
 		},
+		{
+			Name:        "capabilities",
+			Filename:    "capabilities.yaml",
+			Description: "inputs and outputs allowed or denied by capabilities.yml and the rule that decided",
+			ContentType: "application/yaml",
+			Hook: func(_ context.Context) []byte {
+				c.capsMx.RLock()
+				report := c.capsReport
+				c.capsMx.RUnlock()
+				o, err := yaml.Marshal(report)
+				if err != nil {
+					return []byte(fmt.Sprintf("error: %q", err))
+				}
+				return o
+			},
+		},
 		{
 			Name:        "state",
 			Filename:    "state.yaml",
@@ -1203,6 +1572,26 @@ func (c *Coordinator) runner(ctx context.Context) error {
 		upgradeMarkerWatcherErrCh <- nil
 	}
 
+	if c.upgradeMgr != nil && c.upgradeMgr.Upgradeable() {
+		c.runScheduledUpgrades(ctx)
+		// the windows are not known until the first configuration is processed, in managed mode they only come
+		// from the policy, the persisted upgrade is restored once they are, see reloadUpgradeWindows
+		c.scheduledUpgradeRestore = true
+
+		// the Upgrade Watcher does not run again once an upgrade is done, the running agent removes
+		// the previous version once it can no longer be rolled back to
+		go upgrade.WatchRollbackExpiry(ctx, c.logger, paths.Top(), rollbackExpiryCheckInterval, func() bool {
+			return c.State().State == agentclient.Upgrading
+		})
+	}
+
+	capsWatcherErrCh := make(chan error, 1)
+	if c.capsWatcher != nil {
+		capsWatcherErrCh <- c.capsWatcher.Run(ctx)
+	} else {
+		capsWatcherErrCh <- nil
+	}
+
 	// Keep looping until the context ends.
 	for ctx.Err() == nil {
 		c.runLoopIteration(ctx)
@@ -1210,7 +1599,7 @@ func (c *Coordinator) runner(ctx context.Context) error {
 
 	// If we got fatal errors from any of the managers, return them.
 	// Otherwise, just return the context's closing error.
-	err := collectManagerErrors(managerShutdownTimeout, varsErrCh, runtimeErrCh, configErrCh, otelErrCh, upgradeMarkerWatcherErrCh)
+	err := collectManagerErrors(managerShutdownTimeout, varsErrCh, runtimeErrCh, configErrCh, otelErrCh, upgradeMarkerWatcherErrCh, capsWatcherErrCh)
 	if err != nil {
 		c.logger.Debugf("Manager errors on Coordinator shutdown: %v", err.Error())
 		return err
@@ -1327,6 +1716,11 @@ func (c *Coordinator) runLoopIteration(ctx context.Context) {
 		if ctx.Err() == nil {
 			c.setUpgradeDetails(upgradeMarker.Details)
 		}
+
+	case caps := <-c.managerChans.capabilitiesUpdate:
+		if ctx.Err() == nil {
+			c.processCapabilities(ctx, caps)
+		}
 	}
 
 	// At the end of each iteration, if we made any changes to the state,
@@ -1336,6 +1730,55 @@ func (c *Coordinator) runLoopIteration(ctx context.Context) {
 	}
 }
 
+// processCapabilities swaps the enforced capabilities and regenerates the
+// component model with them, logging the components and units that are
+// filtered or unfiltered as a result.
+// Always called on the main Coordinator goroutine.
+func (c *Coordinator) processCapabilities(ctx context.Context, caps capabilities.Capabilities) {
+	previous := inputUnitKeys(c.componentModel)
+
+	c.capsMx.Lock()
+	c.caps = caps
+	c.capsMx.Unlock()
+
+	if err := c.refreshComponentModel(ctx); err != nil {
+		c.logger.Errorf("refreshing component model after capabilities change: %s", err)
+		return
+	}
+
+	current := inputUnitKeys(c.componentModel)
+	var filtered, unfiltered []string
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			filtered = append(filtered, key)
+		}
+	}
+	for key := range current {
+		if _, ok := previous[key]; !ok {
+			unfiltered = append(unfiltered, key)
+		}
+	}
+	slices.Sort(filtered)
+	slices.Sort(unfiltered)
+	c.logger.Infow("capabilities changed, component model updated",
+		"filtered", filtered,
+		"unfiltered", unfiltered)
+}
+
+// inputUnitKeys returns the set of "<component>/<unit>" keys of all input
+// units in the component model.
+func inputUnitKeys(comps []component.Component) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, comp := range comps {
+		for _, unit := range comp.Units {
+			if unit.Type == client.UnitTypeInput {
+				keys[comp.ID+"/"+unit.ID] = struct{}{}
+			}
+		}
+	}
+	return keys
+}
+
 // Always called on the main Coordinator goroutine.
 func (c *Coordinator) processConfig(ctx context.Context, cfg *config.Config) (err error) {
 	if c.otelMgr != nil {
@@ -1439,10 +1882,61 @@ func (c *Coordinator) generateAST(cfg *config.Config) (err error) {
 		}
 	}
 
+	if err := c.reloadUpgradeWindows(cfg); err != nil {
+		return err
+	}
+	c.checkUpgradeWatcherGates(cfg)
+
 	c.ast = rawAst
 	return nil
 }
 
+// reloadUpgradeWindows rebuilds the upgrade windows from the new configuration and passes them to
+// the upgrade windows observer. A configuration with invalid windows is rejected, the previous windows are kept.
+func (c *Coordinator) reloadUpgradeWindows(cfg *config.Config) error {
+	agentCfg, err := configuration.NewFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to reload upgrade windows configuration: %w", err)
+	}
+	var windowsCfg []configuration.UpgradeWindowConfig
+	if agentCfg.Settings != nil && agentCfg.Settings.Upgrade != nil {
+		windowsCfg = agentCfg.Settings.Upgrade.Windows
+	}
+	windows, err := upgrade.NewWindows(windowsCfg)
+	if err != nil {
+		return fmt.Errorf("failed to reload upgrade windows configuration: %w", err)
+	}
+
+	c.upgradeWindowsMx.Lock()
+	c.upgradeWindows = windows
+	c.upgradeWindowsMx.Unlock()
+	if c.upgradeWindowsObserver != nil {
+		c.upgradeWindowsObserver.SetUpgradeWindows(windows)
+	}
+
+	// the upgrade requested through the control protocol is deferred to the new windows
+	if det := c.rescheduleUpgrade(time.Now()); det != nil {
+		c.setUpgradeDetails(det)
+	}
+	if c.scheduledUpgradeRestore {
+		c.scheduledUpgradeRestore = false
+		c.restoreScheduledUpgrade()
+	}
+	return nil
+}
+
+// checkUpgradeWatcherGates reports an invalid health gates configuration. The configuration is not rejected, the
+// watcher of the next upgrade watches it without the gates.
+func (c *Coordinator) checkUpgradeWatcherGates(cfg *config.Config) {
+	agentCfg, err := configuration.NewFromConfig(cfg)
+	if err != nil || agentCfg.Settings == nil || agentCfg.Settings.Upgrade == nil || agentCfg.Settings.Upgrade.Watcher == nil {
+		return
+	}
+	if err := agentCfg.Settings.Upgrade.Watcher.Gates.Check(); err != nil {
+		c.logger.Warnw("Invalid upgrade watcher health gates, upgrades will be watched without them", "error.message", err)
+	}
+}
+
 // observeASTVars identifies the variables that are referenced in the computed AST and passed to
 // the varsMgr so it knows what providers are being referenced. If a providers is not being
 // referenced then the provider does not need to be running.
@@ -1675,6 +2169,14 @@ func (c *Coordinator) generateComponentModel() (err error) {
 		return fmt.Errorf("failed to render components: %w", err)
 	}
 
+	// Resolve the runtime of the components with the auto runtime before the capabilities filter the runtimes
+	comps = translate.ResolveAutoRuntimeManager(comps, c.caps, c.agentInfo)
+	for _, comp := range comps {
+		if comp.RuntimeFallbackReason != "" {
+			c.logger.Debugf("Component '%s' runs in the process runtime instead of the otel runtime: %s", comp.ID, comp.RuntimeFallbackReason)
+		}
+	}
+
 	// Filter any disallowed inputs/outputs from the components
 	comps = c.filterByCapabilities(comps)
 
@@ -1831,25 +2333,32 @@ func (c *Coordinator) checkAndLogUpdate(lastComponentModel []component.Component
 	c.logger.Infow("component model updated", "changes", logStruct)
 }
 
-// Filter any inputs and outputs in the generated component model
-// based on whether they're excluded by the capabilities config
+// Filter any inputs, outputs, runtimes and namespaces in the generated
+// component model based on whether they're excluded by the capabilities config
 func (c *Coordinator) filterByCapabilities(comps []component.Component) []component.Component {
 	if c.caps == nil {
 		// No active filters, return unchanged
 		return comps
 	}
-	result := []component.Component{}
-	for _, component := range comps {
-		if component.InputSpec != nil && !c.caps.AllowInput(component.InputType) {
-			c.logger.Infof("Component '%v' with input type '%v' filtered by capabilities.yml", component.ID, component.InputType)
+	result, report := capabilities.FilterComponents(c.caps, comps)
+	for _, input := range report.Inputs {
+		if input.Allowed {
 			continue
 		}
-		if !c.caps.AllowOutput(component.OutputType) {
-			c.logger.Infof("Component '%v' with output type '%v' filtered by capabilities.yml", component.ID, component.OutputType)
-			continue
+		switch input.Check {
+		case capabilities.CheckNamespace:
+			c.logger.Infof("Unit '%v' of component '%v' with namespace '%v' filtered by capabilities.yml rule %d", input.Unit, input.Component, input.Namespace, input.Rule)
+		case capabilities.CheckRuntime:
+			c.logger.Infof("Unit '%v' of component '%v' with runtime '%v' filtered by capabilities.yml rule %d", input.Unit, input.Component, input.Runtime, input.Rule)
+		case capabilities.CheckOutput:
+			c.logger.Infof("Unit '%v' of component '%v' with output '%v' filtered by capabilities.yml rule %d", input.Unit, input.Component, input.Output, input.Rule)
+		default:
+			c.logger.Infof("Unit '%v' of component '%v' with input type '%v' filtered by capabilities.yml rule %d", input.Unit, input.Component, input.Type, input.Rule)
 		}
-		result = append(result, component)
 	}
+	c.capsMx.Lock()
+	c.capsReport = report
+	c.capsMx.Unlock()
 	return result
 }
 
@@ -1897,14 +2406,14 @@ func diffUnitList(old, new []component.Unit) map[string]diffCheck {
 
 // collectManagerErrors listens on the shutdown channels for the
 // runtime, config, and vars managers as well as the upgrade marker
-// watcher and waits for up to the specified timeout for them to
+// and capabilities watchers and waits for up to the specified timeout for them to
 // report their final status.
 // It returns any resulting errors as a multierror, or nil if no errors
 // were reported.
 // Called on the main Coordinator goroutine.
-func collectManagerErrors(timeout time.Duration, varsErrCh, runtimeErrCh, configErrCh, otelErrCh, upgradeMarkerWatcherErrCh chan error) error {
-	var runtimeErr, configErr, varsErr, otelErr, upgradeMarkerWatcherErr error
-	var returnedRuntime, returnedConfig, returnedVars, returnedOtel, returnedUpgradeMarkerWatcher bool
+func collectManagerErrors(timeout time.Duration, varsErrCh, runtimeErrCh, configErrCh, otelErrCh, upgradeMarkerWatcherErrCh, capsWatcherErrCh chan error) error {
+	var runtimeErr, configErr, varsErr, otelErr, upgradeMarkerWatcherErr, capsWatcherErr error
+	var returnedRuntime, returnedConfig, returnedVars, returnedOtel, returnedUpgradeMarkerWatcher, returnedCapsWatcher bool
 
 	// in case other components are locked up, let us time out
 	timeoutWait := time.NewTimer(timeout)
@@ -1926,7 +2435,7 @@ func collectManagerErrors(timeout time.Duration, varsErrCh, runtimeErrCh, config
 	var errs []error
 
 waitLoop:
-	for !returnedRuntime || !returnedConfig || !returnedVars || !returnedOtel || !returnedUpgradeMarkerWatcher {
+	for !returnedRuntime || !returnedConfig || !returnedVars || !returnedOtel || !returnedUpgradeMarkerWatcher || !returnedCapsWatcher {
 		select {
 		case runtimeErr = <-runtimeErrCh:
 			returnedRuntime = true
@@ -1938,6 +2447,8 @@ waitLoop:
 			returnedOtel = true
 		case upgradeMarkerWatcherErr = <-upgradeMarkerWatcherErrCh:
 			returnedUpgradeMarkerWatcher = true
+		case capsWatcherErr = <-capsWatcherErrCh:
+			returnedCapsWatcher = true
 		case <-timeoutWait.C:
 			var timeouts []string
 			if !returnedRuntime {
@@ -1955,6 +2466,9 @@ waitLoop:
 			if !returnedUpgradeMarkerWatcher {
 				timeouts = append(timeouts, "no response from upgrade marker watcher")
 			}
+			if !returnedCapsWatcher {
+				timeouts = append(timeouts, "no response from capabilities watcher")
+			}
 			timeoutStr := strings.Join(timeouts, ", ")
 			errs = append(errs, fmt.Errorf("timeout while waiting for managers to shut down: %v", timeoutStr))
 			break waitLoop
@@ -1975,6 +2489,9 @@ waitLoop:
 	if upgradeMarkerWatcherErr != nil && !errors.Is(upgradeMarkerWatcherErr, context.Canceled) {
 		errs = append(errs, fmt.Errorf("upgrade marker watcher: %w", upgradeMarkerWatcherErr))
 	}
+	if capsWatcherErr != nil && !errors.Is(capsWatcherErr, context.Canceled) {
+		errs = append(errs, fmt.Errorf("capabilities watcher: %w", capsWatcherErr))
+	}
 	return errors.Join(errs...)
 }
 
@@ -537,7 +537,7 @@ func TestUpgradeSameErrorAcked(t *testing.T) {
 
 	acker.On("Ack", mock.Anything, actionUpgrade).Return(nil)
 
-	require.NoError(t, coord.Upgrade(t.Context(), "9.0", "http://localhost", actionUpgrade, true, true))
+	require.NoError(t, coord.Upgrade(t.Context(), "9.0", "http://localhost", actionUpgrade, true, true, nil))
 
 	acker.AssertCalled(t, "Ack", mock.Anything, actionUpgrade)
 }
@@ -760,7 +760,7 @@ func TestCoordinator_StateSubscribeIsolatedUnits(t *testing.T) {
 }
 
 func TestCollectManagerErrorsTimeout(t *testing.T) {
-	handlerChan, _, _, _, _, _ := setupManagerShutdownChannels(time.Millisecond)
+	handlerChan, _, _, _, _, _, _ := setupManagerShutdownChannels(time.Millisecond)
 	// Don't send anything to the shutdown channels, causing a timeout
 	// in collectManagerErrors
 	waitAndTestError(t, func(err error) bool {
@@ -770,7 +770,7 @@ func TestCollectManagerErrorsTimeout(t *testing.T) {
 }
 
 func TestCollectManagerErrorsOneResponse(t *testing.T) {
-	handlerChan, _, _, config, _, _ := setupManagerShutdownChannels(10 * time.Millisecond)
+	handlerChan, _, _, config, _, _, _ := setupManagerShutdownChannels(10 * time.Millisecond)
 
 	// Send an error for the config manager -- we should also get a
 	// timeout error since we don't send anything on the other two channels.
@@ -785,33 +785,37 @@ func TestCollectManagerErrorsOneResponse(t *testing.T) {
 }
 
 func TestCollectManagerErrorsAllResponses(t *testing.T) {
-	handlerChan, runtime, varWatcher, config, otel, upgradeMarkerWatcher := setupManagerShutdownChannels(5 * time.Second)
+	handlerChan, runtime, varWatcher, config, otel, upgradeMarkerWatcher, capsWatcher := setupManagerShutdownChannels(5 * time.Second)
 	runtimeErrStr := "runtime error"
 	varsErrStr := "vars error"
 	otelErrStr := "otel error"
 	upgradeMarkerWatcherErrStr := "upgrade marker watcher error"
+	capsWatcherErrStr := "capabilities watcher error"
 	runtime <- errors.New(runtimeErrStr)
 	varWatcher <- errors.New(varsErrStr)
 	config <- nil
 	otel <- errors.New(otelErrStr)
 	upgradeMarkerWatcher <- errors.New(upgradeMarkerWatcherErrStr)
+	capsWatcher <- errors.New(capsWatcherErrStr)
 
 	waitAndTestError(t, func(err error) bool {
 		return err != nil &&
 			strings.Contains(err.Error(), runtimeErrStr) &&
 			strings.Contains(err.Error(), varsErrStr) &&
 			strings.Contains(err.Error(), otelErrStr) &&
-			strings.Contains(err.Error(), upgradeMarkerWatcherErrStr)
+			strings.Contains(err.Error(), upgradeMarkerWatcherErrStr) &&
+			strings.Contains(err.Error(), capsWatcherErrStr)
 	}, handlerChan)
 }
 
 func TestCollectManagerErrorsAllResponsesNoErrors(t *testing.T) {
-	handlerChan, runtime, varWatcher, config, otel, upgradeMarkerWatcher := setupManagerShutdownChannels(5 * time.Second)
+	handlerChan, runtime, varWatcher, config, otel, upgradeMarkerWatcher, capsWatcher := setupManagerShutdownChannels(5 * time.Second)
 	runtime <- nil
 	varWatcher <- nil
 	config <- context.Canceled
 	otel <- nil
 	upgradeMarkerWatcher <- nil
+	capsWatcher <- nil
 
 	// All errors are nil or context.Canceled, so collectManagerErrors
 	// should also return nil.
@@ -841,20 +845,21 @@ func waitAndTestError(t *testing.T, check func(error) bool, handlerErr chan erro
 	}
 }
 
-func setupManagerShutdownChannels(timeout time.Duration) (chan error, chan error, chan error, chan error, chan error, chan error) {
+func setupManagerShutdownChannels(timeout time.Duration) (chan error, chan error, chan error, chan error, chan error, chan error, chan error) {
 	runtime := make(chan error)
 	varWatcher := make(chan error)
 	config := make(chan error)
 	otelWatcher := make(chan error)
 	upgradeMarkerWatcher := make(chan error)
+	capsWatcher := make(chan error)
 
 	handlerChan := make(chan error)
 	go func() {
-		handlerErr := collectManagerErrors(timeout, varWatcher, runtime, config, otelWatcher, upgradeMarkerWatcher)
+		handlerErr := collectManagerErrors(timeout, varWatcher, runtime, config, otelWatcher, upgradeMarkerWatcher, capsWatcher)
 		handlerChan <- handlerErr
 	}()
 
-	return handlerChan, runtime, varWatcher, config, otelWatcher, upgradeMarkerWatcher
+	return handlerChan, runtime, varWatcher, config, otelWatcher, upgradeMarkerWatcher, capsWatcher
 }
 
 func TestCoordinator_ReExec(t *testing.T) {
@@ -919,7 +924,7 @@ func TestCoordinator_Upgrade(t *testing.T) {
 	require.NoError(t, err)
 	cfgMgr.Config(ctx, cfg)
 
-	err = coord.Upgrade(ctx, "9.0.0", "", nil, true, false)
+	err = coord.Upgrade(ctx, "9.0.0", "", nil, true, false, nil)
 	require.ErrorIs(t, err, ErrNotUpgradable)
 	cancel()
 
@@ -956,7 +961,7 @@ func TestCoordinator_UpgradeDetails(t *testing.T) {
 	require.NoError(t, err)
 	cfgMgr.Config(ctx, cfg)
 
-	err = coord.Upgrade(ctx, "9.0.0", "", nil, true, false)
+	err = coord.Upgrade(ctx, "9.0.0", "", nil, true, false, nil)
 	require.ErrorIs(t, expectedErr, err)
 	cancel()
 
@@ -968,6 +973,419 @@ func TestCoordinator_UpgradeDetails(t *testing.T) {
 	require.Equal(t, expectedErr.Error(), coord.state.UpgradeDetails.Metadata.ErrorMsg)
 }
 
+func TestCoordinator_UpgradeOutsideWindows(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o755))
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	// the only upgrade window opens in an hour
+	now := time.Now().UTC()
+	windowsObserver := &fakeUpgradeWindowsObserver{}
+	coord.RegisterUpgradeWindowsObserver(windowsObserver)
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+
+	// no need for anything to really run, the config only has the upgrade window
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now.Add(time.Hour))
+
+	scheduledAt, err := coord.ScheduleUpgrade(ctx, "9.0.0", "", true, false, nil)
+	require.NoError(t, err)
+	assert.False(t, upgradeManager.upgradeCalled, "upgrade must be deferred to the next window")
+	require.NotNil(t, scheduledAt)
+	assert.WithinDuration(t, now.Add(time.Hour), *scheduledAt, time.Minute)
+
+	require.Eventually(t, func() bool {
+		upgradeDetails := coord.State().UpgradeDetails
+		return upgradeDetails != nil && upgradeDetails.State == details.StateScheduled
+	}, 5*time.Second, 100*time.Millisecond)
+	require.NotNil(t, coord.State().UpgradeDetails.Metadata.ScheduledAt)
+	assert.Equal(t, *scheduledAt, *coord.State().UpgradeDetails.Metadata.ScheduledAt)
+
+	// the upgrade is persisted to be scheduled again when the agent restarts
+	persisted, err := upgrade.LoadScheduledUpgrade(paths.Data())
+	require.NoError(t, err)
+	require.NotNil(t, persisted)
+	assert.Equal(t, "9.0.0", persisted.Version)
+	assert.True(t, persisted.SkipVerify)
+	assert.Equal(t, *scheduledAt, persisted.ScheduledAt)
+
+	coord.cancelScheduledUpgrade()
+	persisted, err = upgrade.LoadScheduledUpgrade(paths.Data())
+	require.NoError(t, err)
+	assert.Nil(t, persisted, "cancelled upgrade is no longer persisted")
+	cancel()
+
+	err = <-coordCh
+	require.NoError(t, err)
+}
+
+func TestCoordinator_RestoreScheduledUpgrade(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o755))
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	// persisted before the agent restarted, the only upgrade window opens in an hour
+	now := time.Now().UTC()
+	require.NoError(t, upgrade.SaveScheduledUpgrade(paths.Data(), &upgrade.ScheduledUpgrade{
+		Version:     "9.0.0",
+		ScheduledAt: now.Add(time.Hour),
+	}))
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// the windows are unknown until the first configuration, e.g. the Fleet policy, is processed
+	time.Sleep(500 * time.Millisecond)
+	assert.False(t, upgradeManager.upgradeCalled, "upgrade must not run before the windows are known")
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+
+	// no need for anything to really run, the config only has the upgrade window
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+
+	require.Eventually(t, func() bool {
+		upgradeDetails := coord.State().UpgradeDetails
+		return upgradeDetails != nil && upgradeDetails.State == details.StateScheduled && upgradeDetails.TargetVersion == "9.0.0"
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.False(t, upgradeManager.upgradeCalled, "upgrade must still be deferred to the next window")
+
+	coord.cancelScheduledUpgrade()
+	cancel()
+
+	err := <-coordCh
+	require.NoError(t, err)
+}
+
+func TestCoordinator_RestoreScheduledUpgradeNotAllowed(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o755))
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	// the capabilities changed since the upgrade was persisted
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  upgrade: "${version} == '9.0.0'"
+`), newErrorLogger(t))
+	require.NoError(t, err)
+	coord.caps = caps
+
+	now := time.Now().UTC()
+	require.NoError(t, upgrade.SaveScheduledUpgrade(paths.Data(), &upgrade.ScheduledUpgrade{
+		Version:     "9.0.0",
+		ScheduledAt: now.Add(time.Hour),
+	}))
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+
+	// the upgrade is removed instead of being retried on every restart
+	require.Eventually(t, func() bool {
+		persisted, err := upgrade.LoadScheduledUpgrade(paths.Data())
+		return err == nil && persisted == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.False(t, upgradeManager.upgradeCalled)
+
+	cancel()
+	err = <-coordCh
+	require.NoError(t, err)
+}
+
+func TestCoordinator_UpgradeWindowsReloaded(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	windowsObserver := &fakeUpgradeWindowsObserver{}
+	coord.RegisterUpgradeWindowsObserver(windowsObserver)
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+
+	// the only upgrade window opens in an hour
+	now := time.Now().UTC()
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now.Add(time.Hour))
+
+	// the policy moves the upgrade window to now
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(-time.Hour), now.Add(time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now)
+
+	scheduledAt, err := coord.ScheduleUpgrade(ctx, "9.0.0", "", true, false, nil)
+	require.NoError(t, err)
+	assert.Nil(t, scheduledAt, "upgrade must not be deferred within the new window")
+	assert.True(t, upgradeManager.upgradeCalled, "upgrade must run within the new window")
+
+	cancel()
+
+	err = <-coordCh
+	require.NoError(t, err)
+}
+
+func TestCoordinator_ScheduledUpgradeRescheduledOnWindowsReload(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o755))
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	windowsObserver := &fakeUpgradeWindowsObserver{}
+	coord.RegisterUpgradeWindowsObserver(windowsObserver)
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+
+	// the only upgrade window opens in an hour
+	now := time.Now().UTC()
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now.Add(time.Hour))
+
+	scheduledAt, err := coord.ScheduleUpgrade(ctx, "9.0.0", "", false, false, nil)
+	require.NoError(t, err)
+	require.NotNil(t, scheduledAt)
+
+	// the policy moves the upgrade window three hours from now
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(3*time.Hour), now.Add(4*time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now.Add(3*time.Hour))
+
+	require.Eventually(t, func() bool {
+		upgradeDetails := coord.State().UpgradeDetails
+		return upgradeDetails != nil && upgradeDetails.State == details.StateScheduled &&
+			upgradeDetails.Metadata.ScheduledAt != nil &&
+			upgradeDetails.Metadata.ScheduledAt.Sub(now.Add(3*time.Hour)).Abs() < time.Minute
+	}, 5*time.Second, 100*time.Millisecond, "upgrade must be rescheduled to the new window")
+	persisted, err := upgrade.LoadScheduledUpgrade(paths.Data())
+	require.NoError(t, err)
+	require.NotNil(t, persisted)
+	assert.WithinDuration(t, now.Add(3*time.Hour), persisted.ScheduledAt, time.Minute)
+
+	cancel()
+	err = <-coordCh
+	require.NoError(t, err)
+
+	// the timer stops with the Coordinator, the upgrade stays persisted for the next run
+	require.Eventually(t, func() bool {
+		coord.scheduledUpgradeMx.Lock()
+		defer coord.scheduledUpgradeMx.Unlock()
+		return coord.scheduledUpgrade == nil
+	}, 5*time.Second, 100*time.Millisecond)
+	persisted, err = upgrade.LoadScheduledUpgrade(paths.Data())
+	require.NoError(t, err)
+	assert.NotNil(t, persisted)
+}
+
+func TestCoordinator_InvalidUpgradeWindowsAtStartup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+
+	// an invalid window in elastic-agent.yml must not fail to load the configuration
+	startupCfg, err := configuration.NewFromConfig(config.MustNewConfigFrom(map[string]interface{}{
+		"agent": map[string]interface{}{
+			"upgrade": map[string]interface{}{
+				"windows": []interface{}{map[string]interface{}{"start": "2am", "end": "03:00"}},
+			},
+		},
+	}))
+	require.NoError(t, err)
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, _, _ := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager), WithStartupConfig(startupCfg))
+
+	_, err = coord.ScheduleUpgrade(ctx, "9.0.0", "", true, false, nil)
+	require.ErrorIs(t, err, upgrade.ErrNoUpgradeWindow, "upgrades must be refused with invalid windows")
+	assert.False(t, upgradeManager.upgradeCalled)
+}
+
+func TestCoordinator_InvalidUpgradeWindowsInPolicy(t *testing.T) {
+	coordCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	topDir := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(topDir) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o755))
+
+	upgradeManager := &fakeUpgradeManager{
+		upgradeable: true,
+	}
+	coord, cfgMgr, varsMgr := createCoordinator(t, ctx, WithUpgradeManager(upgradeManager))
+	windowsObserver := &fakeUpgradeWindowsObserver{}
+	coord.RegisterUpgradeWindowsObserver(windowsObserver)
+	go func() {
+		err := coord.Run(ctx)
+		if errors.Is(err, context.Canceled) {
+			// allowed error
+			err = nil
+		}
+		coordCh <- err
+	}()
+
+	// no vars used by the config
+	varsMgr.Vars(ctx, []*transpiler.Vars{{}})
+
+	// the only upgrade window opens in an hour
+	now := time.Now().UTC()
+	cfgMgr.Config(ctx, upgradeWindowConfig(t, now.Add(time.Hour), now.Add(2*time.Hour)))
+	windowsObserver.waitForWindowAt(t, now, now.Add(time.Hour))
+
+	invalidCfg, err := config.NewConfigFrom(map[string]interface{}{
+		"agent": map[string]interface{}{
+			"upgrade": map[string]interface{}{
+				"windows": []interface{}{map[string]interface{}{"start": "2am", "end": "03:00"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	cfgMgr.Config(ctx, invalidCfg)
+
+	// the policy is rejected and the previous windows are kept
+	require.Eventually(t, func() bool {
+		state := coord.State()
+		return state.State == agentclient.Failed && strings.Contains(state.Message, "upgrade windows")
+	}, 5*time.Second, 100*time.Millisecond)
+	windowsObserver.waitForWindowAt(t, now, now.Add(time.Hour))
+
+	scheduledAt, err := coord.ScheduleUpgrade(ctx, "9.0.0", "", true, false, nil)
+	require.NoError(t, err)
+	require.NotNil(t, scheduledAt)
+	assert.False(t, upgradeManager.upgradeCalled, "upgrade must still be deferred to the previous window")
+	coord.cancelScheduledUpgrade()
+
+	cancel()
+	err = <-coordCh
+	require.NoError(t, err)
+}
+
+// upgradeWindowConfig returns a configuration with a single daily upgrade window from start to end.
+func upgradeWindowConfig(t *testing.T, start, end time.Time) *config.Config {
+	cfg, err := config.NewConfigFrom(map[string]interface{}{
+		"agent": map[string]interface{}{
+			"upgrade": map[string]interface{}{
+				"windows": []interface{}{
+					map[string]interface{}{
+						"start": start.Format("15:04"),
+						"end":   end.Format("15:04"),
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	return cfg
+}
+
+// fakeUpgradeWindowsObserver records the last upgrade windows the Coordinator notified.
+type fakeUpgradeWindowsObserver struct {
+	mx      sync.Mutex
+	windows *upgrade.Windows
+}
+
+func (f *fakeUpgradeWindowsObserver) SetUpgradeWindows(windows *upgrade.Windows) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.windows = windows
+}
+
+// waitForWindowAt waits until the notified windows open at the expected time after now.
+func (f *fakeUpgradeWindowsObserver) waitForWindowAt(t *testing.T, now, expected time.Time) {
+	require.Eventually(t, func() bool {
+		f.mx.Lock()
+		defer f.mx.Unlock()
+		next, err := f.windows.Next(now)
+		return err == nil && next.Sub(expected).Abs() < time.Minute
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
 func BenchmarkCoordinator_generateComponentModel(b *testing.B) {
 	// load variables
 	varsMaps := []map[string]any{}
@@ -1015,6 +1433,7 @@ type createCoordinatorOpts struct {
 	upgradeManager UpgradeManager
 	compInputSpec  component.InputSpec
 	acker          acker.Acker
+	cfg            *configuration.Configuration
 }
 
 type CoordinatorOpt func(o *createCoordinatorOpts)
@@ -1037,6 +1456,12 @@ func WithActionAcker(acker acker.Acker) CoordinatorOpt {
 	}
 }
 
+func WithStartupConfig(cfg *configuration.Configuration) CoordinatorOpt {
+	return func(o *createCoordinatorOpts) {
+		o.cfg = cfg
+	}
+}
+
 func WithComponentInputSpec(spec component.InputSpec) CoordinatorOpt {
 	return func(o *createCoordinatorOpts) {
 		o.compInputSpec = spec
@@ -1097,7 +1522,7 @@ func createCoordinator(t testing.TB, ctx context.Context, opts ...CoordinatorOpt
 		acker = &fakeActionAcker{}
 	}
 
-	coord := New(l, nil, logp.DebugLevel, ai, specs, &fakeReExecManager{}, upgradeManager, rm, cfgMgr, varsMgr, caps, monitoringMgr, o.managed, otelMgr, acker)
+	coord := New(l, o.cfg, logp.DebugLevel, ai, specs, &fakeReExecManager{}, upgradeManager, rm, cfgMgr, varsMgr, caps, monitoringMgr, o.managed, otelMgr, acker)
 	return coord, cfgMgr, varsMgr
 }
 
@@ -1153,6 +1578,8 @@ type fakeUpgradeManager struct {
 	upgradeable   bool
 	upgradeErr    error // An error to return when Upgrade is called
 	upgradeCalled bool  // Set when Upgrade is called
+
+	preflightReq *upgrade.PreflightRequirements // Set when Preflight is called
 }
 
 func (f *fakeUpgradeManager) Upgradeable() bool {
@@ -1163,7 +1590,7 @@ func (f *fakeUpgradeManager) Reload(cfg *config.Config) error {
 	return nil
 }
 
-func (f *fakeUpgradeManager) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, details *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error) {
+func (f *fakeUpgradeManager) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, details *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error) {
 	f.upgradeCalled = true
 	if f.upgradeErr != nil {
 		return nil, f.upgradeErr
@@ -1171,6 +1598,15 @@ func (f *fakeUpgradeManager) Upgrade(ctx context.Context, version string, source
 	return func() error { return nil }, nil
 }
 
+func (f *fakeUpgradeManager) Rollback(ctx context.Context, action *fleetapi.ActionRollback, details *details.Details) (_ reexec.ShutdownCallbackFn, err error) {
+	return nil, nil
+}
+
+func (f *fakeUpgradeManager) Preflight(ctx context.Context, version string, sourceURI string, req upgrade.PreflightRequirements, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*upgrade.PreflightReport, error) {
+	f.preflightReq = &req
+	return &upgrade.PreflightReport{Version: version}, nil
+}
+
 func (f *fakeUpgradeManager) Ack(ctx context.Context, acker acker.Acker) error {
 	if acker != nil {
 		return acker.Ack(ctx, fleetapi.NewAction(fleetapi.ActionTypeUnknown))
@@ -15,6 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +37,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	monitoringCfg "github.com/elastic/elastic-agent/internal/pkg/core/monitoring/config"
 	"github.com/elastic/elastic-agent/pkg/component"
@@ -1065,6 +1067,142 @@ service:
 	assert.Equal(t, "elasticsearch", units[1].Config.Type)
 }
 
+func TestCoordinatorPolicyChangeResolvesAutoRuntime(t *testing.T) {
+	// Send a test policy with inputs using the auto runtime to the Coordinator,
+	// verify the components supported by the otel runtime are sent to the otel
+	// manager and the others fall back to the runtime manager.
+	components, otelConfig := applyAutoRuntimePolicy(t, nil)
+
+	require.Len(t, components, 1, "the component with the redis output should fall back to the runtime manager")
+	assert.Equal(t, "filestream-redis", components[0].ID)
+	assert.Equal(t, component.ProcessRuntimeManager, components[0].RuntimeManager)
+	assert.Contains(t, components[0].RuntimeFallbackReason, "redis")
+
+	require.NotNil(t, otelConfig, "the component with the elasticsearch output should run in the otel manager")
+	assert.True(t, otelConfig.IsSet("receivers::filebeatreceiver/_agent-component/filestream-default"))
+}
+
+func TestCoordinatorPolicyChangeAutoRuntimeDeniedByCapabilities(t *testing.T) {
+	// Send a test policy with inputs using the auto runtime to the Coordinator
+	// with capabilities denying the otel runtime, verify all the components fall
+	// back to the runtime manager instead of being filtered.
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  runtime: otel
+`), logp.NewLogger("testing"))
+	require.NoError(t, err)
+
+	components, otelConfig := applyAutoRuntimePolicy(t, caps)
+
+	require.Len(t, components, 2)
+	for _, comp := range components {
+		assert.Equal(t, component.ProcessRuntimeManager, comp.RuntimeManager, "component %s", comp.ID)
+		assert.Len(t, comp.Units, 2, "component %s", comp.ID)
+	}
+	assert.Equal(t, "the otel runtime is denied by capabilities.yml rule 0", components[0].RuntimeFallbackReason)
+	assert.Equal(t, "the otel runtime is denied by capabilities.yml rule 0", components[1].RuntimeFallbackReason)
+	if otelConfig != nil {
+		assert.False(t, otelConfig.IsSet("receivers::filebeatreceiver/_agent-component/filestream-default"))
+	}
+}
+
+// applyAutoRuntimePolicy sends a policy with filestream inputs using the auto runtime, one with an elasticsearch
+// output and one with a redis output, to a Coordinator with the capabilities caps. It returns the components
+// sent to the runtime manager and the configuration sent to the otel manager.
+func applyAutoRuntimePolicy(t *testing.T, caps capabilities.Capabilities) ([]component.Component, *confmap.Conf) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	logger := logp.NewLogger("testing")
+
+	configChan := make(chan ConfigChange, 1)
+
+	var components []component.Component // Set by runtime manager callback
+	runtimeManager := &fakeRuntimeManager{
+		updateCallback: func(comp []component.Component) error {
+			components = comp
+			return nil
+		},
+	}
+	var otelConfig *confmap.Conf // Set by otel manager callback
+	otelManager := &fakeOTelManager{
+		updateCallback: func(cfg *confmap.Conf) error {
+			otelConfig = cfg
+			return nil
+		},
+	}
+
+	componentSpec := component.InputRuntimeSpec{
+		InputType:  "filestream",
+		BinaryName: "agentbeat",
+		Spec: component.InputSpec{
+			Name: "filestream",
+			Command: &component.CommandSpec{
+				Args: []string{"filebeat"},
+			},
+			Outputs: []string{"elasticsearch", "redis"},
+			Platforms: []string{
+				"linux/amd64",
+				"linux/arm64",
+				"darwin/amd64",
+				"darwin/arm64",
+				"windows/amd64",
+				"container/amd64",
+				"container/arm64",
+			},
+		},
+	}
+
+	platform, err := component.LoadPlatformDetail()
+	require.NoError(t, err)
+	specs, err := component.NewRuntimeSpecs(platform, []component.InputRuntimeSpec{componentSpec})
+	require.NoError(t, err)
+
+	coord := &Coordinator{
+		logger:           logger,
+		agentInfo:        &info.AgentInfo{},
+		stateBroadcaster: broadcaster.New(State{}, 0, 0),
+		managerChans: managerChans{
+			configManagerUpdate: configChan,
+		},
+		monitorMgr:         newTestMonitoringMgr(),
+		runtimeMgr:         runtimeManager,
+		otelMgr:            otelManager,
+		specs:              specs,
+		vars:               emptyVars(t),
+		caps:               caps,
+		componentPIDTicker: time.NewTicker(time.Second * 30),
+	}
+
+	cfg := config.MustNewConfigFrom(`
+outputs:
+  default:
+    type: elasticsearch
+    hosts:
+      - localhost:9200
+  redis:
+    type: redis
+    hosts:
+      - localhost:6379
+inputs:
+  - id: test-input
+    type: filestream
+    use_output: default
+    _runtime_experimental: auto
+  - id: test-other-input
+    type: filestream
+    use_output: redis
+    _runtime_experimental: auto
+`)
+
+	cfgChange := &configChange{cfg: cfg}
+	configChan <- cfgChange
+	coord.runLoopIteration(ctx)
+	assert.True(t, cfgChange.acked, "Coordinator should ACK a successful policy change")
+
+	return components, otelConfig
+}
+
 func TestCoordinatorReportsRuntimeManagerUpdateFailure(t *testing.T) {
 	// Set a one-second timeout -- nothing here should block, but if it
 	// does let's report a failure instead of timing out the test runner.
@@ -1570,7 +1708,7 @@ func TestCoordinatorInitiatesUpgrade(t *testing.T) {
 	}
 
 	// Call upgrade and make sure the upgrade manager receives an Upgrade call
-	err := coord.Upgrade(ctx, "1.2.3", "", nil, false, false)
+	err := coord.Upgrade(ctx, "1.2.3", "", nil, false, false, nil)
 	assert.True(t, upgradeMgr.upgradeCalled, "Coordinator Upgrade should call upgrade manager Upgrade")
 	assert.Equal(t, upgradeMgr.upgradeErr, err, "Upgrade should report upgrade manager error")
 
@@ -1631,3 +1769,169 @@ func (fs *fakeMonitoringServer) Reset() {
 func (fs *fakeMonitoringServer) Addr() net.Addr {
 	return nil
 }
+
+func TestCoordinatorFilterByCapabilities(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  runtime: otel
+- rule: deny
+  namespace: testing
+- rule: deny
+  input: "*"
+  condition: "${input.use_output} == 'archive'"
+`), log)
+	require.NoError(t, err)
+
+	inputUnit := func(id string, namespace string) component.Unit {
+		return component.Unit{
+			ID:   id,
+			Type: client.UnitTypeInput,
+			Config: &proto.UnitExpectedConfig{
+				Id:         id,
+				DataStream: &proto.DataStream{Namespace: namespace},
+			},
+		}
+	}
+	outputUnit := component.Unit{ID: "output", Type: client.UnitTypeOutput}
+
+	coord := &Coordinator{logger: log, caps: caps}
+	comps := coord.filterByCapabilities([]component.Component{
+		{
+			ID:             "otel-comp",
+			RuntimeManager: component.OtelRuntimeManager,
+			Units:          []component.Unit{inputUnit("otel-input", ""), outputUnit},
+		},
+		{
+			ID:             "mixed-comp",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("default-input", ""), inputUnit("testing-input", "testing"), outputUnit},
+		},
+		{
+			ID:             "testing-comp",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("testing-input", "testing"), outputUnit},
+		},
+		{
+			ID:             "archive-comp",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputName:     "archive",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("archive-input", ""), outputUnit},
+		},
+	})
+
+	require.Len(t, comps, 1, "only the process component with an allowed namespace should remain")
+	assert.Equal(t, "mixed-comp", comps[0].ID)
+	require.Len(t, comps[0].Units, 2)
+	assert.Equal(t, "default-input", comps[0].Units[0].ID)
+	assert.Equal(t, "output", comps[0].Units[1].ID)
+}
+
+func TestCoordinatorCapabilitiesChangeUpdatesRuntimeManager(t *testing.T) {
+	// Send a test policy to the Coordinator, then reload the capabilities
+	// and verify the component model sent to the runtime manager is filtered
+	// and unfiltered accordingly.
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	log, _ := loggertest.New(t.Name())
+
+	configChan := make(chan ConfigChange, 1)
+	capsChan := make(chan capabilities.Capabilities, 1)
+
+	var components []component.Component // Set by runtime manager callback
+	runtimeManager := &fakeRuntimeManager{
+		updateCallback: func(comp []component.Component) error {
+			components = comp
+			return nil
+		},
+	}
+
+	coord := &Coordinator{
+		logger:           log,
+		agentInfo:        &info.AgentInfo{},
+		stateBroadcaster: broadcaster.New(State{}, 0, 0),
+		managerChans: managerChans{
+			configManagerUpdate: configChan,
+			capabilitiesUpdate:  capsChan,
+		},
+		runtimeMgr:         runtimeManager,
+		otelMgr:            &fakeOTelManager{},
+		vars:               emptyVars(t),
+		componentPIDTicker: time.NewTicker(time.Second * 30),
+	}
+
+	cfg := config.MustNewConfigFrom(`
+outputs:
+  default:
+    type: elasticsearch
+inputs:
+  - id: test-input
+    type: filestream
+    use_output: default
+`)
+	configChan <- &configChange{cfg: cfg}
+	coord.runLoopIteration(ctx)
+	require.Len(t, components, 1, "Test policy should generate one component")
+
+	denyFilestream, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  input: filestream
+`), log)
+	require.NoError(t, err)
+	capsChan <- denyFilestream
+	coord.runLoopIteration(ctx)
+	assert.Empty(t, components, "filestream should be filtered after the capabilities change")
+	assert.Same(t, denyFilestream, coord.Capabilities())
+
+	allowAll, err := capabilities.Load(strings.NewReader(`capabilities: []`), log)
+	require.NoError(t, err)
+	capsChan <- allowAll
+	coord.runLoopIteration(ctx)
+	require.Len(t, components, 1, "filestream should be unfiltered after the capabilities change")
+	assert.Equal(t, "filestream-default", components[0].ID)
+}
+
+func TestCoordinatorUpgradePreflight(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- upgrade: "${version} == '9.1.0'"
+  rule: allow
+- upgrade:
+  rule: deny
+`), log)
+	require.NoError(t, err)
+
+	upgradeMgr := &fakeUpgradeManager{upgradeable: true}
+	coord := &Coordinator{
+		stateBroadcaster: broadcaster.New(State{
+			Components: []runtime.ComponentComponentState{
+				{Component: component.Component{ID: "filestream-default", InputType: "filestream"}},
+				{Component: component.Component{ID: "system/metrics-default", InputType: "system/metrics"}},
+				{Component: component.Component{ID: "otel-collector"}},
+			},
+		}, 0, 0),
+		upgradeMgr: upgradeMgr,
+		logger:     log,
+		caps:       caps,
+	}
+
+	report, err := coord.UpgradePreflight(context.Background(), "9.1.0", "", false, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "9.1.0", report.Version)
+	require.NotNil(t, upgradeMgr.preflightReq, "UpgradePreflight should call upgrade manager Preflight")
+	assert.True(t, upgradeMgr.preflightReq.UpgradeAllowed)
+	assert.Equal(t, []string{"filestream", "system/metrics"}, upgradeMgr.preflightReq.InputTypes)
+
+	_, err = coord.UpgradePreflight(context.Background(), "9.2.0", "", false, false, nil)
+	require.NoError(t, err)
+	assert.False(t, upgradeMgr.preflightReq.UpgradeAllowed, "capabilities should deny the upgrade")
+
+	upgradeMgr.upgradeable = false
+	_, err = coord.UpgradePreflight(context.Background(), "9.1.0", "", false, false, nil)
+	assert.ErrorIs(t, err, ErrNotUpgradable)
+}
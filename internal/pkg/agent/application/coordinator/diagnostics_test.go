@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	monitoringCfg "github.com/elastic/elastic-agent/internal/pkg/core/monitoring/config"
 	"github.com/elastic/elastic-agent/internal/pkg/diagnostics"
 	"github.com/elastic/elastic-agent/internal/pkg/remote"
@@ -42,6 +44,7 @@ func TestCoordinatorExpectedDiagnosticHooks(t *testing.T) {
 		"computed-config",
 		"components-expected",
 		"components-actual",
+		"capabilities",
 		"state",
 		"otel",
 		"otel-final",
@@ -458,6 +461,58 @@ components:
 	assert.YAMLEq(t, expected, string(result), "components-actual diagnostic returned unexpected value")
 }
 
+// TestDiagnosticCapabilities filters a component model and verifies the
+// capabilities diagnostic explains the decisions.
+func TestDiagnosticCapabilities(t *testing.T) {
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: allow
+  output: elasticsearch
+- rule: deny
+  input: system/metrics
+`), logger.NewWithoutConfig(""))
+	require.NoError(t, err)
+
+	coord := &Coordinator{logger: logger.NewWithoutConfig(""), caps: caps}
+	coord.filterByCapabilities([]component.Component{
+		{
+			ID:             "system/metrics-default",
+			InputType:      "system/metrics",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "elasticsearch",
+			OutputName:     "default",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units: []component.Unit{
+				{ID: "system/metrics-default-unit", Type: client.UnitTypeInput},
+				{ID: "system/metrics-default", Type: client.UnitTypeOutput},
+			},
+		},
+	})
+
+	expected := `
+inputs:
+  - component: system/metrics-default
+    unit: system/metrics-default-unit
+    type: system/metrics
+    runtime: process
+    output: default
+    check: input
+    allowed: false
+    rule: 1
+outputs:
+  - name: default
+    type: elasticsearch
+    allowed: true
+    rule: 0
+`
+
+	hook, ok := diagnosticHooksMap(coord)["capabilities"]
+	require.True(t, ok, "diagnostic hooks should have an entry for capabilities")
+
+	result := hook.Hook(context.Background())
+	assert.YAMLEq(t, expected, string(result), "capabilities diagnostic returned unexpected value")
+}
+
 // TestDiagnosticState creates a coordinator with a test state and verify that
 // the state diagnostic reports it.
 func TestDiagnosticState(t *testing.T) {
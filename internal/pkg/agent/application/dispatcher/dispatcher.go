@@ -8,12 +8,15 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"go.elastic.co/apm/v2"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/actions"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
@@ -21,6 +24,10 @@ import (
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
+// ErrOutsideUpgradeWindow is the error of the upgrade actions that expire before the next upgrade window opens, or
+// that have no upcoming upgrade window.
+var ErrOutsideUpgradeWindow = errors.New("outside of the upgrade windows")
+
 type actionHandlers map[reflect.Type]actions.Handler
 
 type priorityQueue interface {
@@ -46,6 +53,9 @@ type ActionDispatcher struct {
 	errCh    chan error
 	topPath  string
 
+	// upgradeWindows are replaced when the configuration changes, they are guarded by upgradeWindowsMx.
+	upgradeWindows     *upgrade.Windows
+	upgradeWindowsMx   sync.RWMutex
 	lastUpgradeDetails *details.Details
 }
 
@@ -78,6 +88,14 @@ func (ad *ActionDispatcher) Errors() <-chan error {
 	return ad.errCh
 }
 
+// SetUpgradeWindows sets the maintenance windows upgrade actions are deferred to.
+// Called from external goroutines when the configuration changes.
+func (ad *ActionDispatcher) SetUpgradeWindows(windows *upgrade.Windows) {
+	ad.upgradeWindowsMx.Lock()
+	defer ad.upgradeWindowsMx.Unlock()
+	ad.upgradeWindows = windows
+}
+
 // Register registers a new handler for action.
 func (ad *ActionDispatcher) Register(a fleetapi.Action, handler actions.Handler) error {
 	k := ad.key(a)
@@ -106,6 +124,7 @@ func (ad *ActionDispatcher) key(a fleetapi.Action) reflect.Type {
 // Dispatch will handle action queue operations, and retries.
 // Any action that implements the ScheduledAction interface may be added/removed from the queue based on StartTime.
 // Any action that implements the RetryableAction interface will be rescheduled if the handler returns an error.
+// Upgrade actions that would run outside the upgrade windows are deferred to the start of the next window.
 func (ad *ActionDispatcher) Dispatch(ctx context.Context, detailsSetter details.Observer, acker acker.Acker, actions ...fleetapi.Action) {
 	var err error
 	span, ctx := apm.StartSpan(ctx, "dispatch", "app.internal")
@@ -116,17 +135,42 @@ func (ad *ActionDispatcher) Dispatch(ctx context.Context, detailsSetter details.
 
 	ad.removeQueuedUpgrades(actions)
 
+	now := time.Now().UTC()
+	_, missed := ad.deferUpgradesOutsideWindows(actions, now)
+	if len(missed) > 0 {
+		actions = slices.DeleteFunc(actions, func(action fleetapi.Action) bool {
+			return slices.Contains(missed, action)
+		})
+		ad.failUpgradesOutsideWindows(ctx, missed, acker, detailsSetter)
+	}
+
 	// set scheduled action as soon as it's received
 	// report it before the scheduled actions go to the queue
 	ad.reportNextScheduledUpgrade(actions, detailsSetter, ad.log)
 
 	actions = ad.queueScheduledActions(actions)
 	actions = ad.dispatchCancelActions(ctx, actions, acker)
-	queued, expired := ad.gatherQueuedActions(time.Now().UTC())
+	queued, expired := ad.gatherQueuedActions(now)
 	ad.log.Debugf("Gathered %d actions from queue, %d actions expired", len(queued), len(expired))
 	ad.log.Debugf("Expired actions: %v", expired)
 
 	ad.handleExpired(expired, detailsSetter)
+
+	// queued upgrades, e.g. retries, may become due outside the upgrade windows
+	deferred, missed := ad.deferUpgradesOutsideWindows(queued, now)
+	if len(missed) > 0 {
+		queued = slices.DeleteFunc(queued, func(action fleetapi.Action) bool {
+			return slices.Contains(missed, action)
+		})
+		ad.failUpgradesOutsideWindows(ctx, missed, acker, detailsSetter)
+	}
+	if len(deferred) > 0 {
+		ad.reportNextScheduledUpgrade(deferred, detailsSetter, ad.log)
+		queued = slices.DeleteFunc(queued, func(action fleetapi.Action) bool {
+			return slices.Contains(deferred, action)
+		})
+		ad.queueScheduledActions(deferred)
+	}
 	actions = append(actions, queued...)
 
 	if err := ad.queue.Save(); err != nil {
@@ -213,6 +257,83 @@ func (ad *ActionDispatcher) queueScheduledActions(input []fleetapi.Action) []fle
 	return actions
 }
 
+// deferUpgradesOutsideWindows sets the start time of the upgrade actions that would run outside the upgrade windows
+// to the start of the next window and returns them, they are then kept in the queue until the window opens. The
+// upgrade actions expiring before the next window opens, or without any upcoming window, are returned as missed with
+// their error set, their start time is not changed.
+func (ad *ActionDispatcher) deferUpgradesOutsideWindows(actions []fleetapi.Action, now time.Time) (deferred []fleetapi.Action, missed []fleetapi.Action) {
+	ad.upgradeWindowsMx.RLock()
+	windows := ad.upgradeWindows
+	ad.upgradeWindowsMx.RUnlock()
+
+	for _, action := range actions {
+		uAction, ok := action.(*fleetapi.ActionUpgrade)
+		// dry-runs do not switch versions, they run right away
+		if !ok || uAction.Data.DryRun {
+			continue
+		}
+
+		start, err := uAction.StartTime()
+		if err != nil && !errors.Is(err, fleetapi.ErrNoStartTime) {
+			// the action is dispatched right away, see queueScheduledActions
+			continue
+		}
+		if start.Before(now) {
+			start = now
+		}
+
+		next, err := windows.Next(start)
+		if err != nil {
+			// the upgrade must not run outside the windows when the next one is unknown
+			ad.log.Warnw("Unable to determine the next upgrade window, upgrade action is not run",
+				"action_id", uAction.ID(), "version", uAction.Data.Version, "error.message", err)
+			uAction.SetError(fmt.Errorf("upgrade action %q has no upcoming upgrade window to run in: %w: %w",
+				uAction.ID(), err, ErrOutsideUpgradeWindow))
+			missed = append(missed, uAction)
+			continue
+		}
+		if !next.After(start) {
+			continue
+		}
+		if exp, err := uAction.Expiration(); err == nil && exp.Before(next) {
+			ad.log.Warnw("Upgrade action expires before the next upgrade window, it is not run",
+				"action_id", uAction.ID(), "version", uAction.Data.Version, "expiration", exp, "next_window", next)
+			uAction.SetError(fmt.Errorf("upgrade action %q expires on %s, before the next upgrade window opens: %w",
+				uAction.ID(), uAction.ActionExpiration, ErrOutsideUpgradeWindow))
+			missed = append(missed, uAction)
+			continue
+		}
+
+		ad.log.Infow("Upgrade action is outside of the upgrade windows, deferring it to the next window",
+			"action_id", uAction.ID(), "version", uAction.Data.Version, "scheduled_at", next)
+		uAction.SetStartTime(next)
+		deferred = append(deferred, uAction)
+	}
+	return deferred, missed
+}
+
+// failUpgradesOutsideWindows acks the upgrade actions that can't run within the upgrade windows as failed with the
+// error set by deferUpgradesOutsideWindows, they are not retried, and reports the failure in the upgrade details.
+func (ad *ActionDispatcher) failUpgradesOutsideWindows(ctx context.Context, missed []fleetapi.Action, acker acker.Acker, detailsSetter details.Observer) {
+	for _, action := range missed {
+		uAction, ok := action.(*fleetapi.ActionUpgrade)
+		if !ok {
+			continue
+		}
+		err := uAction.GetError()
+		uAction.SetRetryAttempt(-1)
+		if ackErr := acker.Ack(ctx, uAction); ackErr != nil {
+			ad.log.Errorf("Unable to ack action failure (id %s) to fleet-server: %v", uAction.ID(), ackErr)
+		} else if commitErr := acker.Commit(ctx); commitErr != nil {
+			ad.log.Errorf("Unable to commit action failure (id %s) to fleet-server: %v", uAction.ID(), commitErr)
+		}
+
+		ad.lastUpgradeDetails = details.NewDetails(uAction.Data.Version, details.StateFailed, uAction.ID())
+		ad.lastUpgradeDetails.Fail(err)
+		detailsSetter(ad.lastUpgradeDetails)
+	}
+}
+
 // dispatchCancelActions will separate and dispatch any cancel actions from the actions list and return the rest of the list.
 // cancel actions are dispatched seperatly as they may remove items from the queue.
 func (ad *ActionDispatcher) dispatchCancelActions(ctx context.Context, actions []fleetapi.Action, acker acker.Acker) []fleetapi.Action {
@@ -245,8 +366,12 @@ func (ad *ActionDispatcher) gatherQueuedActions(ts time.Time) (queued, expired [
 
 // removeQueuedUpgrades will scan the passed actions and if there is an upgrade action it will remove all upgrade actions in the queue but not alter the passed list.
 // this is done to try to only have the most recent upgrade action executed. However it does not eliminate duplicates in retrieved directly from the gateway
+// Dry-runs do not replace the queued upgrades, they do not switch versions.
 func (ad *ActionDispatcher) removeQueuedUpgrades(actions []fleetapi.Action) {
 	for _, action := range actions {
+		if uAction, ok := action.(*fleetapi.ActionUpgrade); ok && uAction.Data.DryRun {
+			continue
+		}
 		if action.Type() == fleetapi.ActionTypeUpgrade {
 			if n := ad.queue.CancelType(fleetapi.ActionTypeUpgrade); n > 0 {
 				ad.log.Debugw("New upgrade action retrieved from gateway, removing queued upgrade actions", "actions_found", n)
@@ -327,7 +452,8 @@ func (ad *ActionDispatcher) reportNextScheduledUpgrade(input []fleetapi.Action,
 		}
 
 		uAction, ok := sAction.(*fleetapi.ActionUpgrade)
-		if !ok {
+		// dry-runs are not scheduled upgrades
+		if !ok || uAction.Data.DryRun {
 			continue
 		}
 
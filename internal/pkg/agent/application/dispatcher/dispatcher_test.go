@@ -16,11 +16,15 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi/acker/noop"
+	"github.com/elastic/elastic-agent/internal/pkg/queue"
 	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	mockfleetacker "github.com/elastic/elastic-agent/testing/mocks/internal_/pkg/fleetapi/acker"
 )
 
 type mockHandler struct {
@@ -623,8 +627,354 @@ func TestActionDispatcher(t *testing.T) {
 		assert.NotEmptyf(t, gotDetails.Metadata.ErrorMsg, "want an error message, got none")
 		assert.Equalf(t, expired.ActionID, gotDetails.ActionID, "action id must be the same")
 	})
+
+	t.Run("defer upgrade outside of the upgrade windows", func(t *testing.T) {
+		def := &mockHandler{}
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(time.Hour).Format("15:04"),
+			End:   now.Add(2 * time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		action := &fleetapi.ActionUpgrade{
+			ActionID:   "id",
+			ActionType: fleetapi.ActionTypeUpgrade,
+			Data:       fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("Add", action, mock.Anything).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+		queue.On("CancelType", mock.Anything).Return(0).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		var gotDetails *details.Details
+		detailsSetter := func(upgradeDetails *details.Details) {
+			gotDetails = upgradeDetails
+		}
+
+		d.Dispatch(context.Background(), detailsSetter, ack, action)
+		select {
+		case err := <-d.Errors():
+			if err != nil {
+				t.Errorf("Unexpected error from Dispatch: %v", err)
+			}
+		default:
+		}
+
+		def.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+		queue.AssertExpectations(t)
+
+		startTime, err := action.StartTime()
+		require.NoError(t, err)
+		assert.WithinDuration(t, now.Add(time.Hour), startTime, time.Minute)
+
+		require.NotNilf(t, gotDetails, "upgrade details should have been set")
+		assert.Equal(t, details.StateScheduled, gotDetails.State)
+		require.NotNil(t, gotDetails.Metadata.ScheduledAt)
+		assert.True(t, startTime.Equal(*gotDetails.Metadata.ScheduledAt))
+	})
+
+	t.Run("dispatch upgrade within the upgrade windows", func(t *testing.T) {
+		def := &mockHandler{}
+		def.On("Handle", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+		queue.On("CancelType", mock.Anything).Return(0).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		action := &fleetapi.ActionUpgrade{
+			ActionID:   "id",
+			ActionType: fleetapi.ActionTypeUpgrade,
+			Data:       fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+
+		go d.Dispatch(context.Background(), detailsSetter, ack, action)
+		if err := <-d.Errors(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		def.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+	})
+
+	t.Run("dispatch upgrade dry-run outside of the upgrade windows", func(t *testing.T) {
+		def := &mockHandler{}
+		def.On("Handle", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(time.Hour).Format("15:04"),
+			End:   now.Add(2 * time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		action := &fleetapi.ActionUpgrade{
+			ActionID:   "id",
+			ActionType: fleetapi.ActionTypeUpgrade,
+			Data:       fleetapi.ActionUpgradeData{Version: "9.0.0", DryRun: true},
+		}
+
+		go d.Dispatch(context.Background(), detailsSetter, ack, action)
+		if err := <-d.Errors(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		def.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		queue.AssertNotCalled(t, "CancelType", mock.Anything)
+	})
+
+	t.Run("upgrade dry-run keeps the queued upgrade", func(t *testing.T) {
+		def := &mockHandler{}
+		def.On("Handle", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		now := time.Now().UTC()
+		// the upgrade deferred to the next window is not due yet, it stays in the queue
+		queued := &fleetapi.ActionUpgrade{
+			ActionID:         "upgrade",
+			ActionType:       fleetapi.ActionTypeUpgrade,
+			ActionStartTime:  now.Add(time.Hour).Format(time.RFC3339),
+			ActionExpiration: now.Add(24 * time.Hour).Format(time.RFC3339),
+			Data:             fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+		actionQueue, err := queue.NewActionQueue([]fleetapi.ScheduledAction{queued}, &noopSaver{})
+		require.NoError(t, err)
+
+		d, err := New(nil, t.TempDir(), def, actionQueue)
+		require.NoError(t, err)
+
+		dryRun := &fleetapi.ActionUpgrade{
+			ActionID:   "dry-run",
+			ActionType: fleetapi.ActionTypeUpgrade,
+			Data:       fleetapi.ActionUpgradeData{Version: "9.1.0", DryRun: true},
+		}
+
+		go d.Dispatch(context.Background(), detailsSetter, ack, dryRun)
+		if err := <-d.Errors(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		def.AssertExpectations(t)
+		assert.Equal(t, []fleetapi.ScheduledAction{queued}, actionQueue.Actions(), "the queued upgrade must not be cancelled by a dry-run")
+	})
+
+	t.Run("scheduled upgrade dry-run is not reported as scheduled", func(t *testing.T) {
+		def := &mockHandler{}
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(time.Hour).Format("15:04"),
+			End:   now.Add(2 * time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		// the upgrade deferred to the next window is not due yet, it stays in the queue
+		dryRun := &fleetapi.ActionUpgrade{
+			ActionID:         "dry-run",
+			ActionType:       fleetapi.ActionTypeUpgrade,
+			ActionStartTime:  now.Add(10 * time.Minute).Format(time.RFC3339),
+			ActionExpiration: now.Add(24 * time.Hour).Format(time.RFC3339),
+			Data:             fleetapi.ActionUpgradeData{Version: "9.1.0", DryRun: true},
+		}
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("Add", dryRun, mock.Anything).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		var gotDetails *details.Details
+		detailsSetter := func(upgradeDetails *details.Details) {
+			gotDetails = upgradeDetails
+		}
+
+		d.Dispatch(context.Background(), detailsSetter, ack, dryRun)
+
+		def.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+		queue.AssertExpectations(t)
+		queue.AssertNotCalled(t, "CancelType", mock.Anything)
+		assert.Nil(t, gotDetails, "a dry-run is not reported as a scheduled upgrade")
+		start, err := dryRun.StartTime()
+		require.NoError(t, err)
+		assert.WithinDuration(t, now.Add(10*time.Minute), start, time.Minute, "a dry-run is not deferred to the next window")
+	})
+
+	t.Run("requeue queued upgrade outside of the upgrade windows", func(t *testing.T) {
+		def := &mockHandler{}
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(time.Hour).Format("15:04"),
+			End:   now.Add(2 * time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		// e.g. a retry that became due outside of the windows
+		queued := &fleetapi.ActionUpgrade{
+			ActionID:         "id",
+			ActionType:       fleetapi.ActionTypeUpgrade,
+			ActionStartTime:  now.Add(-time.Minute).Format(time.RFC3339),
+			ActionExpiration: now.Add(24 * time.Hour).Format(time.RFC3339),
+			Data:             fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("Add", queued, mock.Anything).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{queued}).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		var gotDetails *details.Details
+		detailsSetter := func(upgradeDetails *details.Details) {
+			gotDetails = upgradeDetails
+		}
+
+		d.Dispatch(context.Background(), detailsSetter, ack)
+
+		def.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+		queue.AssertExpectations(t)
+		require.NotNilf(t, gotDetails, "upgrade details should have been set")
+		assert.Equal(t, details.StateScheduled, gotDetails.State)
+	})
+
+	t.Run("fail upgrade expiring before the next upgrade window", func(t *testing.T) {
+		def := &mockHandler{}
+
+		now := time.Now().UTC()
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{
+			Start: now.Add(2 * time.Hour).Format("15:04"),
+			End:   now.Add(3 * time.Hour).Format("15:04"),
+		}})
+		require.NoError(t, err)
+
+		action := &fleetapi.ActionUpgrade{
+			ActionID:         "id",
+			ActionType:       fleetapi.ActionTypeUpgrade,
+			ActionExpiration: now.Add(time.Hour).Format(time.RFC3339),
+			Data:             fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+		queue.On("CancelType", mock.Anything).Return(0).Once()
+
+		mockAcker := mockfleetacker.NewAcker(t)
+		mockAcker.EXPECT().Ack(mock.Anything, action).Return(nil).Once()
+		mockAcker.EXPECT().Commit(mock.Anything).Return(nil).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		var gotDetails *details.Details
+		detailsSetter := func(upgradeDetails *details.Details) {
+			gotDetails = upgradeDetails
+		}
+
+		d.Dispatch(context.Background(), detailsSetter, mockAcker, action)
+
+		def.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+		queue.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+
+		assert.ErrorIs(t, action.Err, ErrOutsideUpgradeWindow)
+		assert.Equal(t, -1, action.RetryAttempt(), "the upgrade must not be retried")
+		_, err = action.StartTime()
+		assert.ErrorIs(t, err, fleetapi.ErrNoStartTime, "the upgrade must not be deferred")
+
+		require.NotNilf(t, gotDetails, "upgrade details should have been set")
+		assert.Equal(t, details.StateFailed, gotDetails.State)
+		assert.Contains(t, gotDetails.Metadata.ErrorMsg, "outside of the upgrade windows")
+	})
+
+	t.Run("fail upgrade without an upcoming upgrade window", func(t *testing.T) {
+		def := &mockHandler{}
+
+		// the window never opens again
+		windows, err := upgrade.NewWindows([]configuration.UpgradeWindowConfig{{Cron: "0 0 1 1 * 2020", Duration: time.Hour}})
+		require.NoError(t, err)
+
+		action := &fleetapi.ActionUpgrade{
+			ActionID:   "id",
+			ActionType: fleetapi.ActionTypeUpgrade,
+			Data:       fleetapi.ActionUpgradeData{Version: "9.0.0"},
+		}
+
+		queue := &mockQueue{}
+		queue.On("Save").Return(nil).Once()
+		queue.On("DequeueActions").Return([]fleetapi.ScheduledAction{}).Once()
+		queue.On("CancelType", mock.Anything).Return(0).Once()
+
+		mockAcker := mockfleetacker.NewAcker(t)
+		mockAcker.EXPECT().Ack(mock.Anything, action).Return(nil).Once()
+		mockAcker.EXPECT().Commit(mock.Anything).Return(nil).Once()
+
+		d, err := New(nil, t.TempDir(), def, queue)
+		require.NoError(t, err)
+		d.SetUpgradeWindows(windows)
+
+		var gotDetails *details.Details
+		detailsSetter := func(upgradeDetails *details.Details) {
+			gotDetails = upgradeDetails
+		}
+
+		d.Dispatch(context.Background(), detailsSetter, mockAcker, action)
+
+		def.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything, mock.Anything)
+		queue.AssertExpectations(t)
+		queue.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+
+		assert.ErrorIs(t, action.Err, ErrOutsideUpgradeWindow)
+		assert.ErrorIs(t, action.Err, upgrade.ErrNoUpgradeWindow)
+		assert.Equal(t, -1, action.RetryAttempt(), "the upgrade must not be retried")
+
+		require.NotNilf(t, gotDetails, "upgrade details should have been set")
+		assert.Equal(t, details.StateFailed, gotDetails.State)
+		assert.Contains(t, gotDetails.Metadata.ErrorMsg, "no upcoming upgrade window")
+	})
 }
 
+// noopSaver is a saver of the action queue that does not persist it.
+type noopSaver struct{}
+
+func (*noopSaver) SetQueue([]fleetapi.ScheduledAction) {}
+
+func (*noopSaver) Save() error { return nil }
+
 func Test_ActionDispatcher_scheduleRetry(t *testing.T) {
 	ack := noop.New()
 	def := &mockHandler{}
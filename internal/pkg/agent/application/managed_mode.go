@@ -321,6 +321,7 @@ func (m *managedConfigManager) initDispatcher(canceller context.CancelFunc) *han
 		m.log,
 		m.agentInfo,
 		m.coord,
+		m.coord,
 	)
 
 	policyChanger := handlers.NewPolicyChangeHandler(
@@ -359,6 +360,11 @@ func (m *managedConfigManager) initDispatcher(canceller context.CancelFunc) *han
 		handlers.NewUpgrade(m.log, m.coord),
 	)
 
+	m.dispatcher.MustRegister(
+		&fleetapi.ActionRollback{},
+		handlers.NewRollback(m.log, m.coord),
+	)
+
 	m.dispatcher.MustRegister(
 		&fleetapi.ActionSettings{},
 		settingsHandler,
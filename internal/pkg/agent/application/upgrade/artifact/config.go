@@ -5,6 +5,8 @@
 package artifact
 
 import (
+	"fmt"
+	"net/url"
 	"reflect"
 	"runtime"
 	"strings"
@@ -25,6 +27,18 @@ const (
 
 	// DefaultSourceURI is the default source URI for downloading artifacts.
 	DefaultSourceURI = "https://artifacts.elastic.co/downloads/"
+
+	// DefaultPeerCacheListen is the default address of the peer cache endpoint.
+	DefaultPeerCacheListen = ":6791"
+	// DefaultPeerAnnounceAddress is the default multicast group the peer cache announces are sent to.
+	DefaultPeerAnnounceAddress = "239.255.67.91:6792"
+	// DefaultPeerAnnounceInterval is the default interval between peer cache announces.
+	DefaultPeerAnnounceInterval = 30 * time.Second
+
+	// VerifierPGP verifies artifacts with their detached PGP signature, it is used when no verifier is set.
+	VerifierPGP = "pgp"
+	// VerifierCosign verifies artifacts with the Sigstore bundle produced by cosign.
+	VerifierCosign = "cosign"
 )
 
 type ConfigReloader interface {
@@ -61,6 +75,15 @@ type configWithoutHTTPTransportSettings struct {
 	// will increase for subsequent retry attempts in a randomized exponential backoff manner.
 	// This key is, for some reason, problematic
 	RetrySleepInitDuration time.Duration `yaml:"retry_sleep_init_duration" config:"retry_sleep_init_duration"`
+
+	// Verifier: type of verification of the downloaded artifacts [pgp, cosign], pgp when empty
+	Verifier string `json:"verifier" yaml:"verifier" config:"verifier"`
+
+	// Cosign: keys used to verify artifacts when the cosign verifier is selected
+	Cosign CosignConfig `json:"cosign" yaml:"cosign" config:"cosign"`
+
+	// PeerCache: cache sharing verified artifacts with the agents of the same network
+	PeerCache PeerCacheConfig `json:"peerCache" yaml:"peer_cache" config:"peer_cache"`
 }
 
 // Config is a configuration used for verifier and downloader
@@ -91,9 +114,66 @@ type Config struct {
 	// will increase for subsequent retry attempts in a randomized exponential backoff manner.
 	RetrySleepInitDuration time.Duration `yaml:"retry_sleep_init_duration" config:"retry_sleep_init_duration"`
 
+	// Verifier: type of verification of the downloaded artifacts [pgp, cosign], pgp when empty
+	Verifier string `json:"verifier" yaml:"verifier" config:"verifier"`
+
+	// Cosign: keys used to verify artifacts when the cosign verifier is selected
+	Cosign CosignConfig `json:"cosign" yaml:"cosign" config:"cosign"`
+
+	// PeerCache: cache sharing verified artifacts with the agents of the same network
+	PeerCache PeerCacheConfig `json:"peerCache" yaml:"peer_cache" config:"peer_cache"`
+
 	httpcommon.HTTPTransportSettings `config:",inline" yaml:",inline"` // Note: use anonymous struct for json inline
 }
 
+// CosignConfig is the configuration of the cosign verifier.
+type CosignConfig struct {
+	// Keys: PEM encoded public keys artifacts are signed with
+	Keys []string `json:"keys" yaml:"keys" config:"keys"`
+
+	// TransparencyLogKeys: PEM encoded public keys of the transparency log. When set the Sigstore bundle
+	// must contain a transparency log entry promised by one of them along with the proof of its inclusion
+	// in the log, both are verified offline.
+	TransparencyLogKeys []string `json:"transparencyLogKeys" yaml:"transparency_log_keys" config:"transparency_log_keys"`
+}
+
+// PeerCacheConfig is the configuration of the artifact cache shared between peers. When enabled, the agent
+// serves the artifacts it verified and downloads artifacts from its peers before the source URI.
+type PeerCacheConfig struct {
+	// Enabled: serve verified artifacts to peers and download artifacts from peers first
+	Enabled bool `json:"enabled" yaml:"enabled" config:"enabled"`
+
+	// Listen: address of the HTTP endpoint serving the cached artifacts, defaults to DefaultPeerCacheListen
+	Listen string `json:"listen" yaml:"listen" config:"listen"`
+
+	// Token: secret shared by the peers, it authenticates the requests and the announces
+	Token string `json:"-" yaml:"token" config:"token"`
+
+	// Path: path to the directory containing the cached artifacts, defaults to a directory of the data path
+	// so the cache is kept across upgrades
+	Path string `json:"path" yaml:"path" config:"path"`
+
+	// Peers: URLs of the peers endpoints, e.g http://10.0.0.5:6791
+	Peers []string `json:"peers" yaml:"peers" config:"peers"`
+
+	// Announce: discovery of the peers of the local network
+	Announce PeerAnnounceConfig `json:"announce" yaml:"announce" config:"announce"`
+}
+
+// PeerAnnounceConfig is the configuration of the discovery of peers through UDP announces.
+type PeerAnnounceConfig struct {
+	// Enabled: announce the endpoint and discover the peers announcing theirs
+	Enabled bool `json:"enabled" yaml:"enabled" config:"enabled"`
+
+	// Address: UDP address, usually a multicast group, announces are sent to and received on, defaults to
+	// DefaultPeerAnnounceAddress
+	Address string `json:"address" yaml:"address" config:"address"`
+
+	// Interval: interval between announces, peers are forgotten after 3 intervals without announce,
+	// defaults to DefaultPeerAnnounceInterval
+	Interval time.Duration `json:"interval" yaml:"interval" config:"interval"`
+}
+
 type Reloader struct {
 	log       *logger.Logger
 	cfg       *Config
@@ -144,6 +224,9 @@ func (r *Reloader) reloadConfig(rawConfig *config.Config) error {
 		TargetDirectory:       tmp.C.TargetDirectory,
 		InstallPath:           tmp.C.InstallPath,
 		DropPath:              tmp.C.DropPath,
+		Verifier:              tmp.C.Verifier,
+		Cosign:                tmp.C.Cosign,
+		PeerCache:             tmp.C.PeerCache,
 		HTTPTransportSettings: tmp.C.HTTPTransportSettings,
 	}
 
@@ -173,17 +256,27 @@ func (r *Reloader) reloadSourceURI(rawConfig *config.Config) error {
 	}
 
 	if newSourceURI != "" {
-		r.log.Infof("Source URI changed from %q to %q", r.cfg.SourceURI, newSourceURI)
+		r.log.Infof("Source URI changed from %q to %q", RedactedSourceURI(r.cfg.SourceURI), RedactedSourceURI(newSourceURI))
 		r.cfg.SourceURI = newSourceURI
 	} else {
 		// source uri unset, reset to default
-		r.log.Infof("Source URI reset from %q to %q", r.cfg.SourceURI, DefaultSourceURI)
+		r.log.Infof("Source URI reset from %q to %q", RedactedSourceURI(r.cfg.SourceURI), DefaultSourceURI)
 		r.cfg.SourceURI = DefaultSourceURI
 	}
 
 	return nil
 }
 
+// RedactedSourceURI returns the source URI without the password it may contain, e.g. to authenticate with an
+// OCI registry.
+func RedactedSourceURI(sourceURI string) string {
+	uri, err := url.Parse(sourceURI)
+	if err != nil {
+		return sourceURI
+	}
+	return uri.Redacted()
+}
+
 // DefaultConfig creates a config with pre-set default values.
 func DefaultConfig() *Config {
 	transport := httpcommon.DefaultHTTPTransportSettings()
@@ -268,6 +361,11 @@ func (c *Config) Unpack(cfg *c.C) error {
 	if err := cfg.Unpack(&tmp); err != nil {
 		return err
 	}
+	switch tmp.Verifier {
+	case "", VerifierPGP, VerifierCosign:
+	default:
+		return errors.New(fmt.Sprintf("unknown verifier %q, expected %q or %q", tmp.Verifier, VerifierPGP, VerifierCosign), errors.TypeConfig)
+	}
 
 	transport := DefaultConfig().HTTPTransportSettings
 	if err := cfg.Unpack(&transport); err != nil {
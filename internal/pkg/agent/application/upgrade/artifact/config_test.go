@@ -310,3 +310,29 @@ func TestConfig_Unpack(t *testing.T) {
 	require.NoError(t, err, "UnpackTo failed")
 	assert.Equal(t, DefaultConfig(), defaultcfg)
 }
+
+func TestConfig_UnpackVerifier(t *testing.T) {
+	t.Run("cosign", func(t *testing.T) {
+		rawcfg, err := agentlibsconfig.NewConfigFrom(`
+verifier: cosign
+cosign:
+  keys: ["key1", "key2"]
+  transparency_log_keys: ["tlog1"]
+`)
+		require.NoError(t, err, "could not create config")
+
+		cfg := DefaultConfig()
+		require.NoError(t, cfg.Unpack(rawcfg))
+		assert.Equal(t, VerifierCosign, cfg.Verifier)
+		assert.Equal(t, []string{"key1", "key2"}, cfg.Cosign.Keys)
+		assert.Equal(t, []string{"tlog1"}, cfg.Cosign.TransparencyLogKeys)
+	})
+
+	t.Run("unknown verifier", func(t *testing.T) {
+		rawcfg, err := agentlibsconfig.NewConfigFrom(`verifier: x509`)
+		require.NoError(t, err, "could not create config")
+
+		err = DefaultConfig().Unpack(rawcfg)
+		assert.ErrorContains(t, err, `unknown verifier "x509"`)
+	})
+}
@@ -0,0 +1,149 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cosign
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	goerrors "errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+// parseBundle parses the Sigstore bundle written by `cosign sign-blob --bundle --new-bundle-format`.
+func parseBundle(data []byte) (*bundle.Bundle, error) {
+	b := &bundle.Bundle{}
+	if err := b.UnmarshalJSON(data); err != nil {
+		return nil, errors.New(err, "invalid Sigstore bundle", errors.TypeSecurity)
+	}
+	if b.GetMessageSignature() == nil {
+		return nil, errors.New("Sigstore bundle has no message signature", errors.TypeSecurity)
+	}
+
+	return b, nil
+}
+
+// parsePublicKeys parses the PEM encoded public keys, a single value may contain several keys.
+func parsePublicKeys(pems ...string) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for _, value := range pems {
+		rest := []byte(strings.TrimSpace(value))
+		for len(rest) > 0 {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				return nil, errors.New("public key is not PEM encoded", errors.TypeSecurity)
+			}
+
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, errors.New(err, "invalid public key", errors.TypeSecurity)
+			}
+			keys = append(keys, key)
+			rest = bytes.TrimSpace(rest)
+		}
+	}
+
+	return keys, nil
+}
+
+// transparencyLogs is the trusted material of the transparency logs (Rekor) signing with the configured keys.
+type transparencyLogs struct {
+	root.BaseTrustedMaterial
+	logs map[string]*root.TransparencyLog
+}
+
+func (t *transparencyLogs) RekorLogs() map[string]*root.TransparencyLog {
+	return t.logs
+}
+
+// newTransparencyLogs returns the transparency logs signing with the keys, a log is identified by the SHA256
+// digest of its DER encoded key.
+func newTransparencyLogs(keys []crypto.PublicKey) (*transparencyLogs, error) {
+	logs := make(map[string]*root.TransparencyLog, len(keys))
+	for _, key := range keys {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return nil, errors.New(err, "invalid transparency log key")
+		}
+		id := sha256.Sum256(der)
+		logs[hex.EncodeToString(id[:])] = &root.TransparencyLog{
+			ID: id[:],
+			// a configured key is trusted whenever the entry was integrated
+			ValidityPeriodStart: time.Unix(0, 0),
+			HashFunc:            crypto.SHA256,
+			PublicKey:           key,
+			SignatureHashFunc:   crypto.SHA256,
+		}
+	}
+	return &transparencyLogs{logs: logs}, nil
+}
+
+// verifyBundle checks the bundle signs the artifact with one of the keys. When logs is set, it also checks one
+// of the transparency log entries of the bundle records the signature and is proven to be included in one of
+// the logs.
+func verifyBundle(b *bundle.Bundle, artifactPath string, keys []crypto.PublicKey, logs *transparencyLogs) error {
+	// the keys are long-lived, the signature is checked at the current time
+	opts := []verify.VerifierOption{verify.WithCurrentTime()}
+	if logs != nil {
+		if !b.HasInclusionProof() {
+			return errors.New("Sigstore bundle has no transparency log inclusion proof")
+		}
+		opts = append(opts, verify.WithTransparencyLog(1))
+	}
+
+	var errs []error
+	for _, key := range keys {
+		err := verifyBundleWithKey(b, artifactPath, key, logs, opts)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return fmt.Errorf("bundle does not verify with any of the %d cosign keys: %w", len(keys), goerrors.Join(errs...))
+}
+
+func verifyBundleWithKey(b *bundle.Bundle, artifactPath string, key crypto.PublicKey, logs *transparencyLogs, opts []verify.VerifierOption) error {
+	keyVerifier, err := signature.LoadVerifier(key, crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("unsupported cosign key: %w", err)
+	}
+	// the key hint of the bundle is ignored, the signature is checked with the key
+	trusted := root.TrustedMaterialCollection{
+		root.NewTrustedPublicKeyMaterial(func(string) (root.TimeConstrainedVerifier, error) {
+			return root.NewExpiringKey(keyVerifier, time.Time{}, time.Time{}), nil
+		}),
+	}
+	if logs != nil {
+		trusted = append(trusted, logs)
+	}
+
+	verifier, err := verify.NewSignedEntityVerifier(trusted, opts...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(artifactPath)
+	if err != nil {
+		return errors.New(err, errors.TypeFilesystem, errors.M(errors.MetaKeyPath, artifactPath))
+	}
+	defer f.Close()
+
+	_, err = verifier.Verify(b, verify.NewPolicy(verify.WithArtifact(f), verify.WithKey()))
+	return err
+}
@@ -0,0 +1,153 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/sigstore/rekor/pkg/util"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey is an ECDSA P-256 key, the default key type of cosign and of the Rekor transparency log.
+type testKey struct {
+	*ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T) testKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKey{key}
+}
+
+// publicPEM returns the PEM encoded public key, as written by `cosign generate-key-pair`.
+func (k testKey) publicPEM(t *testing.T) string {
+	der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func (k testKey) logID(t *testing.T) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+	require.NoError(t, err)
+	id := sha256.Sum256(der)
+	return id[:]
+}
+
+func (k testKey) sign(t *testing.T, digest []byte) []byte {
+	signature, err := ecdsa.SignASN1(rand.Reader, k.PrivateKey, digest)
+	require.NoError(t, err)
+	return signature
+}
+
+// signBundle returns the Sigstore bundle of content signed with signer. When log is set the bundle contains
+// an entry promised by this transparency log along with the proof of its inclusion.
+func signBundle(t *testing.T, content []byte, signer testKey, log *testKey) []byte {
+	return newTestBundle(t, "application/vnd.dev.sigstore.bundle.v0.3+json", content, signer, log, true)
+}
+
+// signBundleWithoutProof returns a v0.1 Sigstore bundle of content signed with signer, its transparency log
+// entry is only promised by the log.
+func signBundleWithoutProof(t *testing.T, content []byte, signer testKey, log testKey) []byte {
+	return newTestBundle(t, "application/vnd.dev.sigstore.bundle+json;version=0.1", content, signer, &log, false)
+}
+
+func newTestBundle(t *testing.T, mediaType string, content []byte, signer testKey, log *testKey, inclusionProof bool) []byte {
+	digest := sha256.Sum256(content)
+	sig := signer.sign(t, digest[:])
+
+	verificationMaterial := map[string]any{
+		"publicKey": map[string]any{"hint": "test"},
+	}
+	if log != nil {
+		verificationMaterial["tlogEntries"] = []any{tlogEntryFor(t, *log, digest[:], sig, signer, inclusionProof)}
+	}
+	b := map[string]any{
+		"mediaType":            mediaType,
+		"verificationMaterial": verificationMaterial,
+		"messageSignature": map[string]any{
+			"messageDigest": map[string]any{"algorithm": "SHA2_256", "digest": digest[:]},
+			"signature":     sig,
+		},
+	}
+
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	return data
+}
+
+func tlogEntryFor(t *testing.T, log testKey, digest, sig []byte, signer testKey, inclusionProof bool) map[string]any {
+	const (
+		logIndex       = 42
+		integratedTime = 1751300000
+	)
+	body := map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data": map[string]any{"hash": map[string]any{"algorithm": "sha256", "value": hex.EncodeToString(digest)}},
+			"signature": map[string]any{
+				"content":   sig,
+				"publicKey": map[string]any{"content": []byte(signer.publicPEM(t))},
+			},
+		},
+	}
+	bodyBytes, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	// the payload of the signed entry timestamp, its fields are in the canonical JSON order
+	payload, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{
+		Body:           base64.StdEncoding.EncodeToString(bodyBytes),
+		IntegratedTime: integratedTime,
+		LogID:          hex.EncodeToString(log.logID(t)),
+		LogIndex:       logIndex,
+	})
+	require.NoError(t, err)
+	payloadDigest := sha256.Sum256(payload)
+
+	entry := map[string]any{
+		"logIndex":          fmt.Sprint(logIndex),
+		"logId":             map[string]any{"keyId": log.logID(t)},
+		"kindVersion":       map[string]any{"kind": "hashedrekord", "version": "0.0.1"},
+		"integratedTime":    fmt.Sprint(integratedTime),
+		"inclusionPromise":  map[string]any{"signedEntryTimestamp": log.sign(t, payloadDigest[:])},
+		"canonicalizedBody": bodyBytes,
+	}
+	if inclusionProof {
+		// the entry is the only leaf of the log tree, the root hash is the hash of the leaf
+		rootHash := sha256.Sum256(append([]byte{0}, bodyBytes...))
+		logSigner, err := signature.LoadECDSASignerVerifier(log.PrivateKey, crypto.SHA256)
+		require.NoError(t, err)
+		checkpoint, err := util.CreateAndSignCheckpoint(context.Background(), "rekor.test", 1, 1, rootHash[:], logSigner)
+		require.NoError(t, err)
+
+		entry["inclusionProof"] = map[string]any{
+			"logIndex":   "0",
+			"rootHash":   rootHash[:],
+			"treeSize":   "1",
+			"hashes":     []any{},
+			"checkpoint": map[string]any{"envelope": string(checkpoint)},
+		}
+	}
+	return entry
+}
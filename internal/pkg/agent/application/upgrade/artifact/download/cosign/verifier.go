@@ -0,0 +1,241 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cosign
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+const (
+	// BundleSuffix is the suffix of the Sigstore bundle published alongside a package.
+	BundleSuffix = ".sigstore.json"
+
+	sha512Suffix = ".sha512"
+)
+
+// Verifier verifies a downloaded package with the Sigstore bundle produced when signing it with cosign.
+// The signature is checked against the configured public keys and, when transparency log keys are
+// configured, against the transparency log entry stored in the bundle.
+type Verifier struct {
+	config *artifact.Config
+	client http.Client
+	log    *logger.Logger
+}
+
+func (v *Verifier) Name() string {
+	return "cosign.verifier"
+}
+
+// NewVerifier creates a verifier checking downloaded package against the Sigstore bundle found alongside
+// the package: in the target directory, the drop path or the source URI.
+func NewVerifier(log *logger.Logger, config *artifact.Config) (*Verifier, error) {
+	client, err := config.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
+			return download.WithHeaders(rt, download.Headers)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Verifier{
+		config: config,
+		client: *client,
+		log:    log,
+	}
+
+	return v, nil
+}
+
+func (v *Verifier) Reload(c *artifact.Config) error {
+	// reload client
+	client, err := c.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
+			return download.WithHeaders(rt, download.Headers)
+		}),
+	)
+	if err != nil {
+		return errors.New(err, "cosign.verifier: failed to generate client out of config")
+	}
+
+	v.client = *client
+	v.config = c
+
+	return nil
+}
+
+// Verify checks the downloaded package is signed by one of the configured cosign keys, PGP sources are ignored.
+// If the signature check fails then Verify returns a *download.InvalidSignatureError.
+func (v *Verifier) Verify(ctx context.Context, a artifact.Artifact, version agtversion.ParsedSemVer, _ bool, _ ...string) error {
+	filename, err := artifact.GetArtifactName(a, version, v.config.OS(), v.config.Arch())
+	if err != nil {
+		return errors.New(err, "retrieving package name")
+	}
+
+	artifactPath, err := artifact.GetArtifactPath(a, version, v.config.OS(), v.config.Arch(), v.config.TargetDirectory)
+	if err != nil {
+		return errors.New(err, "retrieving package path")
+	}
+
+	if _, err := os.Stat(artifactPath + sha512Suffix); err == nil {
+		if err = download.VerifySHA512HashWithCleanup(v.log, artifactPath); err != nil {
+			return fmt.Errorf("failed to verify SHA512 hash: %w", err)
+		}
+	}
+
+	keys, err := v.publicKeys()
+	if err != nil {
+		return err
+	}
+	logKeys, err := parsePublicKeys(v.config.Cosign.TransparencyLogKeys...)
+	if err != nil {
+		return fmt.Errorf("could not parse transparency log keys: %w", err)
+	}
+
+	bundleBytes, err := v.getBundle(ctx, a, filename, artifactPath)
+	if err != nil {
+		return fmt.Errorf("could not get Sigstore bundle: %w", err)
+	}
+
+	if err = v.verifyBundle(artifactPath, bundleBytes, keys, logKeys); err != nil {
+		var invalidSignatureErr *download.InvalidSignatureError
+		if errors.As(err, &invalidSignatureErr) {
+			for _, file := range []string{artifactPath, artifactPath + BundleSuffix} {
+				if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+					v.log.Warnf("failed clean up after signature verification: failed to remove %q: %v", file, err)
+				}
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyBundle(artifactPath string, bundleBytes []byte, keys, logKeys []crypto.PublicKey) error {
+	b, err := parseBundle(bundleBytes)
+	if err != nil {
+		return err
+	}
+
+	var logs *transparencyLogs
+	if len(logKeys) > 0 {
+		logs, err = newTransparencyLogs(logKeys)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := verifyBundle(b, artifactPath, keys, logs); err != nil {
+		v.log.Warnf("Verification with cosign failed: %v", err)
+		return &download.InvalidSignatureError{File: artifactPath, Err: err}
+	}
+
+	if logs == nil {
+		v.log.Infof("Verification with cosign successful, no transparency log key configured")
+		return nil
+	}
+	v.log.Infof("Verification with cosign and transparency log successful")
+	return nil
+}
+
+// publicKeys returns the configured cosign keys.
+func (v *Verifier) publicKeys() ([]crypto.PublicKey, error) {
+	keys, err := parsePublicKeys(v.config.Cosign.Keys...)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse cosign keys: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no cosign key available, set agent.download.cosign.keys or pass --cosign-key", errors.TypeSecurity)
+	}
+
+	v.log.Infof("Using %d cosign keys", len(keys))
+	return keys, nil
+}
+
+// getBundle reads the bundle downloaded along the package or from the drop path, it is fetched from the
+// source URI otherwise.
+func (v *Verifier) getBundle(ctx context.Context, a artifact.Artifact, filename, artifactPath string) ([]byte, error) {
+	localPaths := []string{artifactPath + BundleSuffix}
+	if v.config.DropPath != "" {
+		localPaths = append(localPaths, filepath.Join(v.config.DropPath, filename+BundleSuffix))
+	}
+	for _, localPath := range localPaths {
+		b, err := os.ReadFile(localPath)
+		if err == nil {
+			return b, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, errors.New(err, fmt.Sprintf("reading bundle from '%s'", localPath), errors.TypeFilesystem, errors.M(errors.MetaKeyPath, localPath))
+		}
+	}
+
+	bundleURI, err := v.composeURI(filename, a.Artifact)
+	if err != nil {
+		return nil, err
+	}
+	return v.getRemoteBundle(ctx, bundleURI)
+}
+
+func (v *Verifier) composeURI(filename, artifactName string) (string, error) {
+	upstream := v.config.SourceURI
+	if !strings.Contains(upstream, "://") {
+		// always default to https
+		upstream = fmt.Sprintf("https://%s", upstream)
+	}
+
+	// example: https://artifacts.elastic.co/downloads/beats/elastic-agent/elastic-agent-9.1.0-linux-x86_64.tar.gz.sigstore.json
+	uri, err := url.Parse(upstream)
+	if err != nil {
+		return "", errors.New(err, "invalid upstream URI", errors.TypeNetwork, errors.M(errors.MetaKeyURI, artifact.RedactedSourceURI(upstream)))
+	}
+	if uri.Scheme != "http" && uri.Scheme != "https" {
+		return "", errors.New(fmt.Sprintf("bundle %s not found locally and cannot be fetched from %s source", filename+BundleSuffix, uri.Scheme), errors.TypeSecurity)
+	}
+
+	uri.Path = path.Join(uri.Path, artifactName, filename+BundleSuffix)
+	return uri.String(), nil
+}
+
+func (v *Verifier) getRemoteBundle(ctx context.Context, sourceURI string) ([]byte, error) {
+	ctx, cancelFn := context.WithTimeout(ctx, 30*time.Second)
+	defer cancelFn()
+	redactedURI := artifact.RedactedSourceURI(sourceURI)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURI, nil)
+	if err != nil {
+		return nil, errors.New(err, "failed create request for loading bundle", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, errors.New(err, "failed loading bundle", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", redactedURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
+	}
+
+	return io.ReadAll(resp.Body)
+}
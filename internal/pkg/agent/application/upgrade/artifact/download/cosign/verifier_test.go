@@ -0,0 +1,220 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cosign
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+const testPackage = "elastic-agent-9.1.0-linux-x86_64.tar.gz"
+
+var (
+	agentSpec = artifact.Artifact{
+		Name:     "Elastic Agent",
+		Cmd:      "elastic-agent",
+		Artifact: "beats/elastic-agent",
+	}
+	testVersion = agtversion.NewParsedSemVer(9, 1, 0, "", "")
+)
+
+func newTestVerifier(t *testing.T, keys, logKeys []string) *Verifier {
+	config := artifact.DefaultConfig()
+	config.OperatingSystem = "linux"
+	config.Architecture = "64"
+	config.TargetDirectory = t.TempDir()
+	config.Verifier = artifact.VerifierCosign
+	config.Cosign = artifact.CosignConfig{Keys: keys, TransparencyLogKeys: logKeys}
+
+	log, _ := loggertest.New("verifier")
+	verifier, err := NewVerifier(log, config)
+	require.NoError(t, err)
+	return verifier
+}
+
+func TestVerify(t *testing.T) {
+	content := []byte("elastic agent package")
+	signer := newTestKey(t)
+	otherSigner := newTestKey(t)
+	tlog := newTestKey(t)
+	otherTlog := newTestKey(t)
+
+	isInvalidSignature := func(t *testing.T, err error) {
+		var invalidSignatureErr *download.InvalidSignatureError
+		assert.True(t, errors.As(err, &invalidSignatureErr), "expected an invalid signature, got %v", err)
+	}
+
+	tests := map[string]struct {
+		keys     []string
+		logKeys  []string
+		sources  []string
+		bundle   []byte
+		tamper   bool
+		expected func(t *testing.T, err error)
+	}{
+		"valid signature": {
+			keys:   []string{signer.publicPEM(t)},
+			bundle: signBundle(t, content, signer, nil),
+		},
+		"valid signature with one of the keys": {
+			keys:   []string{otherSigner.publicPEM(t) + signer.publicPEM(t)},
+			bundle: signBundle(t, content, signer, nil),
+		},
+		"pgp sources are ignored": {
+			keys:    []string{signer.publicPEM(t)},
+			sources: []string{download.PgpSourceRawPrefix + "pgp key"},
+			bundle:  signBundle(t, content, signer, nil),
+		},
+		"valid transparency log entry": {
+			keys:    []string{signer.publicPEM(t)},
+			logKeys: []string{otherTlog.publicPEM(t), tlog.publicPEM(t)},
+			bundle:  signBundle(t, content, signer, &tlog),
+		},
+		"no key": {
+			bundle: signBundle(t, content, signer, nil),
+			expected: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "no cosign key available")
+			},
+		},
+		"invalid key": {
+			keys:   []string{"not a key"},
+			bundle: signBundle(t, content, signer, nil),
+			expected: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "public key is not PEM encoded")
+			},
+		},
+		"missing bundle": {
+			keys: []string{signer.publicPEM(t)},
+			expected: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "could not get Sigstore bundle")
+			},
+		},
+		"invalid bundle": {
+			keys:   []string{signer.publicPEM(t)},
+			bundle: []byte(`{"mediaType": "application/vnd.in-toto+json"}`),
+			expected: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "invalid Sigstore bundle")
+			},
+		},
+		"signed with another key": {
+			keys:     []string{signer.publicPEM(t)},
+			bundle:   signBundle(t, content, otherSigner, nil),
+			expected: isInvalidSignature,
+		},
+		"tampered package": {
+			keys:     []string{signer.publicPEM(t)},
+			bundle:   signBundle(t, content, signer, nil),
+			tamper:   true,
+			expected: isInvalidSignature,
+		},
+		"missing transparency log entry": {
+			keys:    []string{signer.publicPEM(t)},
+			logKeys: []string{tlog.publicPEM(t)},
+			bundle:  signBundle(t, content, signer, nil),
+			expected: func(t *testing.T, err error) {
+				isInvalidSignature(t, err)
+				assert.ErrorContains(t, err, "no transparency log inclusion proof")
+			},
+		},
+		"transparency log entry without inclusion proof": {
+			keys:    []string{signer.publicPEM(t)},
+			logKeys: []string{tlog.publicPEM(t)},
+			bundle:  signBundleWithoutProof(t, content, signer, tlog),
+			expected: func(t *testing.T, err error) {
+				isInvalidSignature(t, err)
+				assert.ErrorContains(t, err, "no transparency log inclusion proof")
+			},
+		},
+		"transparency log entry of another key": {
+			keys:    []string{signer.publicPEM(t), otherSigner.publicPEM(t)},
+			logKeys: []string{tlog.publicPEM(t)},
+			bundle:  signBundle(t, content, signer, &tlog),
+		},
+		"unknown transparency log": {
+			keys:    []string{signer.publicPEM(t)},
+			logKeys: []string{tlog.publicPEM(t)},
+			bundle:  signBundle(t, content, signer, &otherTlog),
+			expected: func(t *testing.T, err error) {
+				isInvalidSignature(t, err)
+				assert.ErrorContains(t, err, "not enough verified log entries from transparency log")
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			verifier := newTestVerifier(t, tc.keys, tc.logKeys)
+			artifactPath := filepath.Join(verifier.config.TargetDirectory, testPackage)
+			packageContent := content
+			if tc.tamper {
+				packageContent = []byte("tampered package")
+			}
+			require.NoError(t, os.WriteFile(artifactPath, packageContent, 0o600))
+			if tc.bundle != nil {
+				require.NoError(t, os.WriteFile(artifactPath+BundleSuffix, tc.bundle, 0o600))
+			} else {
+				// the bundle is not looked up remotely
+				verifier.config.SourceURI = "oci://registry.example.com/elastic"
+			}
+
+			err := verifier.Verify(context.Background(), agentSpec, *testVersion, false, tc.sources...)
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			tc.expected(t, err)
+
+			var invalidSignatureErr *download.InvalidSignatureError
+			if errors.As(err, &invalidSignatureErr) {
+				assert.NoFileExists(t, artifactPath, "package with an invalid signature should be removed")
+			}
+		})
+	}
+}
+
+func TestVerifyBundleLocation(t *testing.T) {
+	content := []byte("elastic agent package")
+	signer := newTestKey(t)
+	bundle := signBundle(t, content, signer, nil)
+
+	t.Run("drop path", func(t *testing.T) {
+		verifier := newTestVerifier(t, []string{signer.publicPEM(t)}, nil)
+		verifier.config.DropPath = t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(verifier.config.TargetDirectory, testPackage), content, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(verifier.config.DropPath, testPackage+BundleSuffix), bundle, 0o600))
+
+		require.NoError(t, verifier.Verify(context.Background(), agentSpec, *testVersion, false))
+	})
+
+	t.Run("source URI", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/downloads/beats/elastic-agent/"+testPackage+BundleSuffix {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(bundle)
+		}))
+		defer server.Close()
+
+		verifier := newTestVerifier(t, []string{signer.publicPEM(t)}, nil)
+		verifier.config.SourceURI = server.URL + "/downloads/"
+		require.NoError(t, os.WriteFile(filepath.Join(verifier.config.TargetDirectory, testPackage), content, 0o600))
+
+		require.NoError(t, verifier.Verify(context.Background(), agentSpec, *testVersion, false))
+	})
+}
@@ -6,6 +6,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,6 +18,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/go-units"
+
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
@@ -29,6 +32,12 @@ import (
 const (
 	packagePermissions = 0o660
 
+	// PartialDownloadSuffix is the suffix of the file a package is downloaded to until the download completes.
+	PartialDownloadSuffix = ".part"
+	// PartialValidatorSuffix is the suffix of the file storing the ETag and Last-Modified headers of a partial
+	// download.
+	PartialValidatorSuffix = ".part.validator"
+
 	// downloadProgressIntervalPercentage defines how often to report the current download progress when percentage
 	// of time has passed in the overall interval for the complete download to complete. 5% is a good default, as
 	// the default timeout is 10 minutes and this will have it log every 30 seconds.
@@ -100,7 +109,7 @@ func (e *Downloader) Download(ctx context.Context, a artifact.Artifact, version
 	defer func() {
 		if err != nil {
 			for _, path := range downloadedFiles {
-				if err := os.Remove(path); err != nil {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 					e.log.Warnf("failed to cleanup %s: %v", path, err)
 				}
 			}
@@ -172,11 +181,8 @@ func (e *Downloader) downloadFile(ctx context.Context, artifactName, filename, f
 	if err != nil {
 		return "", err
 	}
-
-	req, err := http.NewRequest("GET", sourceURI, nil)
-	if err != nil {
-		return "", errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
-	}
+	// the source URI can contain credentials, only its redacted form is logged and reported
+	redactedURI := artifact.RedactedSourceURI(sourceURI)
 
 	if destinationDir := filepath.Dir(fullPath); destinationDir != "" && destinationDir != "." {
 		if err := os.MkdirAll(destinationDir, 0o755); err != nil {
@@ -184,42 +190,187 @@ func (e *Downloader) downloadFile(ctx context.Context, artifactName, filename, f
 		}
 	}
 
-	destinationFile, err := os.OpenFile(fullPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, packagePermissions)
+	// the package is downloaded to a partial file first, it is kept when the download fails so the next
+	// attempt can resume it instead of downloading the whole package again
+	partialPath := fullPath + PartialDownloadSuffix
+	validatorPath := fullPath + PartialValidatorSuffix
+	resumeFrom, validator := loadPartialDownload(partialPath, validatorPath)
+
+	req, err := http.NewRequest("GET", sourceURI, nil)
 	if err != nil {
-		return "", errors.New(err, "creating package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, fullPath))
+		return "", errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
+	}
+	if resumeFrom > 0 {
+		e.log.Infof("resuming download from %s at %s", redactedURI, units.HumanSize(float64(resumeFrom)))
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+		// the server sends the whole package if the partial download doesn't match its current content
+		req.Header.Set("If-Range", validator.ifRange())
 	}
-	defer destinationFile.Close()
 
 	resp, err := e.client.Do(req.WithContext(ctx))
 	if err != nil {
-		// return path, file already exists and needs to be cleaned up
-		return fullPath, errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
+		return fullPath, errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		// return path, file already exists and needs to be cleaned up
-		return fullPath, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", sourceURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
-	}
-
+	var destinationFile *os.File
 	fileSize := -1
-	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
-		if length, err := strconv.Atoi(contentLength); err == nil {
-			fileSize = length
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && resumeFrom > 0:
+		start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil || start != resumeFrom {
+			removePartialDownload(e.log, partialPath, validatorPath)
+			return fullPath, errors.New(fmt.Sprintf("call to '%s' returned unexpected content range %q", redactedURI, resp.Header.Get("Content-Range")), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
+		}
+		fileSize = int(total)
+
+		destinationFile, err = os.OpenFile(partialPath, os.O_APPEND|os.O_WRONLY, packagePermissions)
+		if err != nil {
+			return fullPath, errors.New(err, "opening partial package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, partialPath))
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && resumeFrom > 0:
+		// the partial download is larger than the package, download it again
+		e.log.Infof("partial download from %s is not valid anymore, restarting download", redactedURI)
+		resp.Body.Close()
+		removePartialDownload(e.log, partialPath, validatorPath)
+		return e.downloadFile(ctx, artifactName, filename, fullPath)
+	case resp.StatusCode == http.StatusOK:
+		if resumeFrom > 0 {
+			e.log.Infof("partial download from %s is outdated, restarting download", redactedURI)
+			resumeFrom = 0
 		}
+		if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
+			if length, err := strconv.Atoi(contentLength); err == nil {
+				fileSize = length
+			}
+		}
+
+		destinationFile, err = os.OpenFile(partialPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, packagePermissions)
+		if err != nil {
+			return fullPath, errors.New(err, "creating package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, partialPath))
+		}
+
+		validator = partialValidator{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		if err := savePartialValidator(validatorPath, validator); err != nil {
+			e.log.Warnf("failed to save validator of partial download %s, download won't be resumed: %v", partialPath, err)
+			validator = partialValidator{}
+		}
+	default:
+		return fullPath, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", redactedURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
+	defer destinationFile.Close()
 
 	loggingObserver := newLoggingProgressObserver(e.log, e.config.HTTPTransportSettings.Timeout)
 	detailsObserver := newDetailsProgressObserver(e.upgradeDetails)
-	dp := newDownloadProgressReporter(sourceURI, e.config.HTTPTransportSettings.Timeout, fileSize, loggingObserver, detailsObserver)
+	dp := newDownloadProgressReporter(redactedURI, e.config.HTTPTransportSettings.Timeout, fileSize, resumeFrom, loggingObserver, detailsObserver)
 	dp.Report(ctx)
 	_, err = io.Copy(destinationFile, io.TeeReader(resp.Body, dp))
 	if err != nil {
 		dp.ReportFailed(err)
-		// return path, file already exists and needs to be cleaned up
-		return fullPath, errors.New(err, "copying fetched package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
+		if validator.ifRange() == "" {
+			// the partial download cannot be validated by the server, it cannot be resumed
+			removePartialDownload(e.log, partialPath, validatorPath)
+		}
+		return fullPath, errors.New(err, "copying fetched package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 	dp.ReportComplete()
 
+	if err := destinationFile.Close(); err != nil {
+		return fullPath, errors.New(err, "closing package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, partialPath))
+	}
+	if err := os.Rename(partialPath, fullPath); err != nil {
+		return fullPath, errors.New(err, "moving package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, fullPath))
+	}
+	if err := os.Remove(validatorPath); err != nil && !os.IsNotExist(err) {
+		e.log.Warnf("failed to cleanup %s: %v", validatorPath, err)
+	}
+
 	return fullPath, nil
 }
+
+// partialValidator identifies the remote content a partial download was fetched from, the server uses it to
+// check the partial download is still valid when it is resumed.
+type partialValidator struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// ifRange returns the value of the If-Range header of a resumed download, empty when the download cannot be
+// resumed. If-Range only accepts strong ETags.
+func (v partialValidator) ifRange() string {
+	if v.ETag != "" && !strings.HasPrefix(v.ETag, "W/") {
+		return v.ETag
+	}
+	return v.LastModified
+}
+
+// loadPartialDownload returns the size and validator of the partial download left by a previous attempt, the size
+// is 0 when there is no partial download that can be resumed.
+func loadPartialDownload(partialPath, validatorPath string) (int64, partialValidator) {
+	info, err := os.Stat(partialPath)
+	if err != nil || info.Size() == 0 {
+		return 0, partialValidator{}
+	}
+
+	validatorBytes, err := os.ReadFile(validatorPath)
+	if err != nil {
+		return 0, partialValidator{}
+	}
+	var validator partialValidator
+	if err := json.Unmarshal(validatorBytes, &validator); err != nil || validator.ifRange() == "" {
+		return 0, partialValidator{}
+	}
+	return info.Size(), validator
+}
+
+func savePartialValidator(validatorPath string, validator partialValidator) error {
+	if validator.ifRange() == "" {
+		if err := os.Remove(validatorPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	validatorBytes, err := json.Marshal(validator)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(validatorPath, validatorBytes, packagePermissions)
+}
+
+func removePartialDownload(log *logger.Logger, partialPath, validatorPath string) {
+	for _, file := range []string{partialPath, validatorPath} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			log.Warnf("failed to cleanup %s: %v", file, err)
+		}
+	}
+}
+
+// parseContentRange parses the start and total size of a Content-Range header such as "bytes 100-499/500", the
+// total size is -1 when unknown.
+func parseContentRange(contentRange string) (int64, int64, error) {
+	unit, byteRange, ok := strings.Cut(contentRange, " ")
+	if !ok || unit != "bytes" {
+		return 0, 0, fmt.Errorf("unsupported content range %q", contentRange)
+	}
+	byteRange, size, ok := strings.Cut(byteRange, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid content range %q", contentRange)
+	}
+	startValue, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid content range %q", contentRange)
+	}
+	start, err := strconv.ParseInt(startValue, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid content range %q: %w", contentRange, err)
+	}
+	if size == "*" {
+		return start, -1, nil
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid content range %q: %w", contentRange, err)
+	}
+	return start, total, nil
+}
@@ -7,6 +7,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -16,6 +17,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -132,6 +135,231 @@ func TestDownloadBodyError(t *testing.T) {
 	assert.True(t, containsMessage(warnLogs, expectedMsg))
 }
 
+// droppingResponseWriter drops the connection once limit bytes of the body have been written.
+type droppingResponseWriter struct {
+	http.ResponseWriter
+	conn    net.Conn
+	limit   int
+	written int
+}
+
+func (w *droppingResponseWriter) Write(b []byte) (int, error) {
+	if w.written+len(b) <= w.limit {
+		n, err := w.ResponseWriter.Write(b)
+		w.written += n
+		return n, err
+	}
+
+	n, _ := w.ResponseWriter.Write(b[:w.limit-w.written])
+	w.written += n
+	w.ResponseWriter.(http.Flusher).Flush()
+	_ = w.conn.Close()
+	return n, net.ErrClosed
+}
+
+// newResumableServer serves content with the given ETag, honoring Range and If-Range requests. The first request
+// of the package drops the connection after dropAfter bytes of the body. The ranges requested for the package are
+// sent to ranges.
+func newResumableServer(t *testing.T, content []byte, etag string, dropAfter int, ranges chan<- string) *httptest.Server {
+	type connKey struct{}
+	var requests atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if strings.HasSuffix(r.URL.Path, ".sha512") {
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte("hash")))
+			return
+		}
+
+		ranges <- r.Header.Get("Range")
+		if requests.Add(1) == 1 {
+			conn, ok := r.Context().Value(connKey{}).(net.Conn)
+			require.True(t, ok)
+			w = &droppingResponseWriter{ResponseWriter: w, conn: conn, limit: dropAfter}
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	srv.Config.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+		return context.WithValue(ctx, connKey{}, c)
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100*units.KiB)
+	dropAfter := len(content) / 2
+	ranges := make(chan string, 2)
+	srv := newResumableServer(t, content, `"v1"`, dropAfter, ranges)
+	client := srv.Client()
+
+	config := &artifact.Config{
+		SourceURI:       srv.URL,
+		TargetDirectory: t.TempDir(),
+		OperatingSystem: "linux",
+		Architecture:    "64",
+	}
+	fullPath, err := artifact.GetArtifactPath(beatSpec, *version, config.OS(), config.Arch(), config.TargetDirectory)
+	require.NoError(t, err)
+
+	log, obs := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("8.12.0", details.StateRequested, "")
+	testClient := NewDownloaderWithClient(log, config, *client, upgradeDetails)
+
+	_, err = testClient.Download(context.Background(), beatSpec, version)
+	require.Error(t, err, "first download should fail when the connection is dropped")
+	assert.Empty(t, <-ranges, "first download should request the whole package")
+	assert.NoFileExists(t, fullPath)
+
+	partial, err := os.ReadFile(fullPath + PartialDownloadSuffix)
+	require.NoError(t, err, "partial download should be kept")
+	assert.Equal(t, content[:dropAfter], partial)
+	assert.FileExists(t, fullPath+PartialValidatorSuffix)
+
+	artifactPath, err := testClient.Download(context.Background(), beatSpec, version)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("bytes=%d-", dropAfter), <-ranges, "second download should resume the partial download")
+
+	downloaded, err := os.ReadFile(artifactPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	assert.NoFileExists(t, fullPath+PartialDownloadSuffix)
+	assert.NoFileExists(t, fullPath+PartialValidatorSuffix)
+
+	expectedURL := fmt.Sprintf("%s/%s-%s-%s", srv.URL, "beats/agentbeat/agentbeat", version, "linux-x86_64.tar.gz")
+	assert.True(t, containsMessage(obs.FilterLevelExact(zapcore.InfoLevel).TakeAll(), fmt.Sprintf("resuming download from %s at %s", expectedURL, units.HumanSize(float64(dropAfter)))))
+}
+
+func TestDownloadRedactsSourceURI(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100*units.KiB)
+	ranges := make(chan string, 2)
+	srv := newResumableServer(t, content, `"v1"`, len(content)/2, ranges)
+	client := srv.Client()
+	sourceURI := strings.Replace(srv.URL, "http://", "http://user:secret@", 1)
+
+	config := &artifact.Config{
+		SourceURI:       sourceURI,
+		TargetDirectory: t.TempDir(),
+		OperatingSystem: "linux",
+		Architecture:    "64",
+	}
+	log, obs := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("8.12.0", details.StateRequested, "")
+	testClient := NewDownloaderWithClient(log, config, *client, upgradeDetails)
+
+	_, err := testClient.Download(context.Background(), beatSpec, version)
+	require.Error(t, err, "first download should fail when the connection is dropped")
+	assert.NotContains(t, err.Error(), "secret")
+	var metaErr interface{ Meta() map[string]interface{} }
+	require.True(t, errors.As(err, &metaErr))
+	assert.NotContains(t, fmt.Sprint(metaErr.Meta()), "secret")
+
+	_, err = testClient.Download(context.Background(), beatSpec, version)
+	require.NoError(t, err)
+
+	logs := obs.TakeAll()
+	require.NotEmpty(t, logs)
+	for _, entry := range logs {
+		assert.NotContains(t, entry.Message, "secret")
+		for _, field := range entry.Context {
+			assert.NotContains(t, field.String, "secret")
+		}
+	}
+	expectedURL := fmt.Sprintf("%s/%s-%s-%s", strings.Replace(srv.URL, "http://", "http://user:xxxxx@", 1), "beats/agentbeat/agentbeat", version, "linux-x86_64.tar.gz")
+	assert.True(t, containsMessage(logs, fmt.Sprintf("resuming download from %s at %s", expectedURL, units.HumanSize(float64(len(content)/2)))))
+}
+
+func TestDownloadResumeOutdatedPartial(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100*units.KiB)
+	ranges := make(chan string, 1)
+	// the whole package is served as the partial download doesn't match the ETag anymore
+	srv := newResumableServer(t, content, `"v2"`, len(content), ranges)
+	client := srv.Client()
+
+	config := &artifact.Config{
+		SourceURI:       srv.URL,
+		TargetDirectory: t.TempDir(),
+		OperatingSystem: "linux",
+		Architecture:    "64",
+	}
+	fullPath, err := artifact.GetArtifactPath(beatSpec, *version, config.OS(), config.Arch(), config.TargetDirectory)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+	require.NoError(t, os.WriteFile(fullPath+PartialDownloadSuffix, []byte("outdated"), 0o600))
+	require.NoError(t, savePartialValidator(fullPath+PartialValidatorSuffix, partialValidator{ETag: `"v1"`}))
+
+	log, _ := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("8.12.0", details.StateRequested, "")
+	testClient := NewDownloaderWithClient(log, config, *client, upgradeDetails)
+
+	artifactPath, err := testClient.Download(context.Background(), beatSpec, version)
+	require.NoError(t, err)
+	assert.Equal(t, "bytes=8-", <-ranges)
+
+	downloaded, err := os.ReadFile(artifactPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+}
+
+func TestDownloadDroppedWithoutValidator(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100*units.KiB)
+	ranges := make(chan string, 2)
+	// without ETag nor Last-Modified the partial download cannot be validated when resuming
+	srv := newResumableServer(t, content, "", len(content)/2, ranges)
+	client := srv.Client()
+
+	config := &artifact.Config{
+		SourceURI:       srv.URL,
+		TargetDirectory: t.TempDir(),
+		OperatingSystem: "linux",
+		Architecture:    "64",
+	}
+	fullPath, err := artifact.GetArtifactPath(beatSpec, *version, config.OS(), config.Arch(), config.TargetDirectory)
+	require.NoError(t, err)
+
+	log, _ := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("8.12.0", details.StateRequested, "")
+	testClient := NewDownloaderWithClient(log, config, *client, upgradeDetails)
+
+	_, err = testClient.Download(context.Background(), beatSpec, version)
+	require.Error(t, err)
+	assert.Empty(t, <-ranges)
+	assert.NoFileExists(t, fullPath+PartialDownloadSuffix, "partial download that cannot be resumed should be removed")
+
+	_, err = testClient.Download(context.Background(), beatSpec, version)
+	require.NoError(t, err)
+	assert.Empty(t, <-ranges, "download should restart from the beginning")
+}
+
+func TestDownloadProgressReporterResumed(t *testing.T) {
+	obs := &recordingProgressObserver{}
+	dp := newDownloadProgressReporter("http://some/uri", time.Minute, 100, 40, obs)
+	dp.Report(context.Background())
+	_, err := dp.Write(make([]byte, 10))
+	require.NoError(t, err)
+	dp.ReportFailed(errors.New("connection dropped"))
+
+	assert.Equal(t, 50.0, obs.downloaded, "progress should include the resumed bytes")
+	assert.Equal(t, 100.0, obs.total)
+	assert.Equal(t, 50.0, obs.percentComplete)
+}
+
+type recordingProgressObserver struct {
+	downloaded      float64
+	total           float64
+	percentComplete float64
+}
+
+func (r *recordingProgressObserver) Report(_ string, _ time.Duration, downloaded, total, percentComplete, _ float64) {
+	r.downloaded, r.total, r.percentComplete = downloaded, total, percentComplete
+}
+
+func (r *recordingProgressObserver) ReportCompleted(string, time.Duration, float64) {}
+
+func (r *recordingProgressObserver) ReportFailed(_ string, _ time.Duration, downloaded, total, percentComplete, _ float64, _ error) {
+	r.downloaded, r.total, r.percentComplete = downloaded, total, percentComplete
+}
+
 func TestDownloadLogProgressWithLength(t *testing.T) {
 	fileSize := 100 * units.MB
 	chunks := 100
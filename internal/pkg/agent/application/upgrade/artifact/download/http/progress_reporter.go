@@ -15,6 +15,8 @@ type downloadProgressReporter struct {
 	interval    time.Duration
 	warnTimeout time.Duration
 	length      float64
+	// resumed is the amount of bytes downloaded by a previous attempt the download is resumed from
+	resumed float64
 
 	downloaded atomic.Int64
 	started    time.Time
@@ -23,7 +25,7 @@ type downloadProgressReporter struct {
 	done              chan struct{}
 }
 
-func newDownloadProgressReporter(sourceURI string, timeout time.Duration, length int, progressObservers ...progressObserver) *downloadProgressReporter {
+func newDownloadProgressReporter(sourceURI string, timeout time.Duration, length int, resumed int64, progressObservers ...progressObserver) *downloadProgressReporter {
 	interval := time.Duration(float64(timeout) * downloadProgressIntervalPercentage)
 	if interval == 0 {
 		interval = downloadProgressMinInterval
@@ -34,6 +36,7 @@ func newDownloadProgressReporter(sourceURI string, timeout time.Duration, length
 		interval:          interval,
 		warnTimeout:       time.Duration(float64(timeout) * warningProgressIntervalPercentage),
 		length:            float64(length),
+		resumed:           float64(resumed),
 		progressObservers: progressObservers,
 		done:              make(chan struct{}),
 	}
@@ -53,6 +56,7 @@ func (dp *downloadProgressReporter) Report(ctx context.Context) {
 	dp.started = started
 	sourceURI := dp.sourceURI
 	length := dp.length
+	resumed := dp.resumed
 	interval := dp.interval
 
 	// If there are no observers to report progress to, there is nothing to do!
@@ -74,6 +78,8 @@ func (dp *downloadProgressReporter) Report(ctx context.Context) {
 				timePast := now.Sub(started)
 				downloaded := float64(dp.downloaded.Load())
 				bytesPerSecond := downloaded / float64(timePast/time.Second)
+				// the rate only accounts for this attempt, the progress includes the resumed bytes
+				downloaded += resumed
 				var percentComplete float64
 				if length > 0 {
 					percentComplete = downloaded / length * 100.0
@@ -123,6 +129,7 @@ func (dp *downloadProgressReporter) ReportFailed(err error) {
 	timePast := now.Sub(dp.started)
 	downloaded := float64(dp.downloaded.Load())
 	bytesPerSecond := downloaded / float64(timePast/time.Second)
+	downloaded += dp.resumed
 	var percentComplete float64
 	if dp.length > 0 {
 		percentComplete = downloaded / dp.length * 100.0
@@ -137,7 +137,8 @@ func (v *Verifier) verifyAsc(ctx context.Context, a artifact.Artifact, version a
 
 	ascBytes, err := v.getPublicAsc(ctx, ascURI)
 	if err != nil {
-		return errors.New(err, fmt.Sprintf("fetching asc file from %s", ascURI), errors.TypeNetwork, errors.M(errors.MetaKeyURI, ascURI))
+		redactedURI := artifact.RedactedSourceURI(ascURI)
+		return errors.New(err, fmt.Sprintf("fetching asc file from %s", redactedURI), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 
 	pgpBytes, err := download.FetchPGPKeys(
@@ -159,7 +160,7 @@ func (v *Verifier) composeURI(filename, artifactName string) (string, error) {
 	// example: https://artifacts.elastic.co/downloads/beats/filebeat/filebeat-7.1.1-x86_64.rpm
 	uri, err := url.Parse(upstream)
 	if err != nil {
-		return "", errors.New(err, "invalid upstream URI", errors.TypeNetwork, errors.M(errors.MetaKeyURI, upstream))
+		return "", errors.New(err, "invalid upstream URI", errors.TypeNetwork, errors.M(errors.MetaKeyURI, artifact.RedactedSourceURI(upstream)))
 	}
 
 	uri.Path = path.Join(uri.Path, artifactName, filename+ascSuffix)
@@ -169,19 +170,20 @@ func (v *Verifier) composeURI(filename, artifactName string) (string, error) {
 func (v *Verifier) getPublicAsc(ctx context.Context, sourceURI string) ([]byte, error) {
 	ctx, cancelFn := context.WithTimeout(ctx, 30*time.Second)
 	defer cancelFn()
+	redactedURI := artifact.RedactedSourceURI(sourceURI)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURI, nil)
 	if err != nil {
-		return nil, errors.New(err, "failed create request for loading public key", errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
+		return nil, errors.New(err, "failed create request for loading public key", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 
 	resp, err := v.client.Do(req)
 	if err != nil {
-		return nil, errors.New(err, "failed loading public key", errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
+		return nil, errors.New(err, "failed loading public key", errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", sourceURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, sourceURI))
+		return nil, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", redactedURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 
 	return io.ReadAll(resp.Body)
@@ -0,0 +1,176 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package oci
+
+import (
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+const (
+	testUsername = "robot$agent"
+	testPassword = "secret"
+	testToken    = "pull-token"
+	testTag      = "9.1.0"
+	testPackage  = "elastic-agent-9.1.0-linux-x86_64.tar.gz"
+)
+
+var (
+	agentSpec = artifact.Artifact{
+		Name:     "Elastic Agent",
+		Cmd:      "elastic-agent",
+		Artifact: "beats/elastic-agent",
+	}
+	testVersion = agtversion.NewParsedSemVer(9, 1, 0, "", "")
+)
+
+// testRegistry is an in-process OCI registry requiring token authentication.
+type testRegistry struct {
+	*httptest.Server
+
+	mx        sync.Mutex
+	manifests map[string][]byte
+	blobs     map[digest.Digest][]byte
+	// realm is the token realm of the authentication challenge, the /token endpoint of the registry when empty
+	realm string
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{
+		manifests: make(map[string][]byte),
+		blobs:     make(map[digest.Digest][]byte),
+	}
+	r.Server = httptest.NewTLSServer(http.HandlerFunc(r.serveHTTP))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// sourceURI returns the source URI of the elastic namespace of the registry with the given credentials.
+func (r *testRegistry) sourceURI(username, password string) string {
+	uri := url.URL{Scheme: Scheme, User: url.UserPassword(username, password), Host: r.Listener.Addr().String(), Path: "/elastic"}
+	return uri.String()
+}
+
+// push stores the files as layers of a manifest of the repository tagged with tag and returns the manifest
+// digest.
+func (r *testRegistry) push(t *testing.T, repository, tag string, files map[string][]byte) digest.Digest {
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.elastic.agent.package",
+		Config:       ocispec.DescriptorEmptyJSON,
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	for name, data := range files {
+		dgst := digest.FromBytes(data)
+		r.blobs[dgst] = data
+		manifest.Layers = append(manifest.Layers, ocispec.Descriptor{
+			MediaType:   "application/octet-stream",
+			Digest:      dgst,
+			Size:        int64(len(data)),
+			Annotations: map[string]string{ocispec.AnnotationTitle: name},
+		})
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	manifestDigest := digest.FromBytes(manifestBytes)
+	r.manifests[repository+":"+tag] = manifestBytes
+	r.manifests[repository+":"+manifestDigest.String()] = manifestBytes
+	return manifestDigest
+}
+
+// corrupt replaces the content of the blob with data of the same size.
+func (r *testRegistry) corrupt(dgst digest.Digest) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.blobs[dgst] = []byte(strings.Repeat("x", len(r.blobs[dgst])))
+}
+
+func (r *testRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != testUsername || password != testPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": testToken})
+		return
+	}
+
+	resource, ok := strings.CutPrefix(req.URL.Path, "/v2/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var repository, kind, reference string
+	if repository, reference, ok = strings.Cut(resource, "/manifests/"); ok {
+		kind = "manifests"
+	} else if repository, reference, ok = strings.Cut(resource, "/blobs/"); ok {
+		kind = "blobs"
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer "+testToken {
+		r.mx.Lock()
+		realm := r.realm
+		r.mx.Unlock()
+		if realm == "" {
+			realm = r.URL + "/token"
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s",service="test-registry",scope="repository:%s:pull"`, realm, repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	switch kind {
+	case "manifests":
+		manifest, found := r.manifests[repository+":"+reference]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+		_, _ = w.Write(manifest)
+	case "blobs":
+		blob, found := r.blobs[digest.Digest(reference)]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(blob)
+	}
+}
+
+// packageFiles returns the package and its checksum, as published on elastic.co.
+func packageFiles(content []byte) map[string][]byte {
+	hash := sha512.Sum512(content)
+	return map[string][]byte{
+		testPackage:             content,
+		testPackage + ".sha512": []byte(fmt.Sprintf("%x  %s\n", hash, testPackage)),
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/cosign"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+const (
+	packagePermissions = 0o660
+
+	sha512Suffix = ".sha512"
+	ascSuffix    = ".asc"
+)
+
+// Downloader is a downloader able to pull artifacts stored in an OCI registry.
+type Downloader struct {
+	log            *logger.Logger
+	config         *artifact.Config
+	client         http.Client
+	upgradeDetails *details.Details
+}
+
+// NewDownloader creates and configures OCI registry Downloader
+func NewDownloader(log *logger.Logger, config *artifact.Config, upgradeDetails *details.Details) (*Downloader, error) {
+	client, err := config.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		httpcommon.WithKeepaliveSettings{Disable: false, IdleConnTimeout: 30 * time.Second},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client.Transport = download.WithHeaders(client.Transport, download.Headers)
+	return NewDownloaderWithClient(log, config, *client, upgradeDetails), nil
+}
+
+// NewDownloaderWithClient creates OCI registry Downloader with specific client used
+func NewDownloaderWithClient(log *logger.Logger, config *artifact.Config, client http.Client, upgradeDetails *details.Details) *Downloader {
+	return &Downloader{
+		log:            log,
+		config:         config,
+		client:         client,
+		upgradeDetails: upgradeDetails,
+	}
+}
+
+func (e *Downloader) Reload(c *artifact.Config) error {
+	// reload client
+	client, err := c.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+	)
+	if err != nil {
+		return errors.New(err, "oci.downloader: failed to generate client out of config")
+	}
+
+	client.Transport = download.WithHeaders(client.Transport, download.Headers)
+
+	e.client = *client
+	e.config = c
+
+	return nil
+}
+
+// Download pulls the package from the configured registry, the package and its checksum are layers of the
+// manifest tagged with the version. Each layer is verified against its digest.
+// Returns absolute path to downloaded package and an error.
+func (e *Downloader) Download(ctx context.Context, a artifact.Artifact, version *agtversion.ParsedSemVer) (_ string, err error) {
+	src, err := parseSource(e.config.SourceURI)
+	if err != nil {
+		return "", err
+	}
+
+	filename, err := artifact.GetArtifactName(a, *version, e.config.OS(), e.config.Arch())
+	if err != nil {
+		return "", errors.New(err, "generating package name failed")
+	}
+
+	fullPath, err := artifact.GetArtifactPath(a, *version, e.config.OS(), e.config.Arch(), e.config.TargetDirectory)
+	if err != nil {
+		return "", errors.New(err, "generating package path failed")
+	}
+
+	downloadedFiles := make([]string, 0, 3)
+	defer func() {
+		if err != nil {
+			for _, path := range downloadedFiles {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					e.log.Warnf("failed to cleanup %s: %v", path, err)
+				}
+			}
+		}
+	}()
+
+	registry := newRegistryClient(e.client, src)
+	repository := src.repository(a)
+	reference := src.reference(*version)
+	e.log.Infow("Pulling package from OCI registry", "registry", src.registry, "repository", repository, "reference", reference)
+
+	manifest, err := registry.manifest(ctx, repository, reference)
+	if err != nil {
+		return "", err
+	}
+
+	packageLayer, found := findLayer(manifest, filename)
+	if !found {
+		return "", errors.New(fmt.Sprintf("manifest %s:%s has no layer for package %s", repository, reference, filename), errors.TypeNetwork)
+	}
+
+	started := time.Now()
+	downloadedFiles = append(downloadedFiles, fullPath)
+	if err := e.downloadLayer(ctx, registry, repository, packageLayer, fullPath); err != nil {
+		return "", err
+	}
+	if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+		e.upgradeDetails.SetDownloadProgress(1, float64(packageLayer.Size)/elapsed)
+	}
+
+	// the checksum is optional as the package is verified against its digest already
+	if hashLayer, found := findLayer(manifest, filename+sha512Suffix); found {
+		downloadedFiles = append(downloadedFiles, fullPath+sha512Suffix)
+		if err := e.downloadLayer(ctx, registry, repository, hashLayer, fullPath+sha512Suffix); err != nil {
+			return "", err
+		}
+	}
+
+	// the Sigstore bundle is used by the cosign verifier, it is pulled whenever it is published
+	if bundleLayer, found := findLayer(manifest, filename+cosign.BundleSuffix); found {
+		downloadedFiles = append(downloadedFiles, fullPath+cosign.BundleSuffix)
+		if err := e.downloadLayer(ctx, registry, repository, bundleLayer, fullPath+cosign.BundleSuffix); err != nil {
+			return "", err
+		}
+	}
+
+	return fullPath, nil
+}
+
+func (e *Downloader) downloadLayer(ctx context.Context, registry *registryClient, repository string, layer ocispec.Descriptor, fullPath string) error {
+	if destinationDir := filepath.Dir(fullPath); destinationDir != "" && destinationDir != "." {
+		if err := os.MkdirAll(destinationDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	destinationFile, err := os.OpenFile(fullPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, packagePermissions)
+	if err != nil {
+		return errors.New(err, "creating package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, fullPath))
+	}
+	defer destinationFile.Close()
+
+	if err := registry.blob(ctx, repository, layer, destinationFile); err != nil {
+		return fmt.Errorf("pulling %s failed: %w", filepath.Base(fullPath), err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/cosign"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+)
+
+func newTestDownloader(t *testing.T, registry *testRegistry, sourceURI string) *Downloader {
+	config := &artifact.Config{
+		SourceURI:       sourceURI,
+		TargetDirectory: t.TempDir(),
+		OperatingSystem: "linux",
+		Architecture:    "64",
+	}
+
+	log, _ := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("9.1.0", details.StateRequested, "")
+	return NewDownloaderWithClient(log, config, *registry.Client(), upgradeDetails)
+}
+
+func TestDownload(t *testing.T) {
+	registry := newTestRegistry(t)
+	content := []byte("elastic agent package")
+	registry.push(t, "elastic/elastic-agent", testTag, packageFiles(content))
+
+	downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword))
+	artifactPath, err := downloader.Download(context.Background(), agentSpec, testVersion)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(downloader.config.TargetDirectory, testPackage), artifactPath)
+
+	downloaded, err := os.ReadFile(artifactPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	require.NoError(t, download.VerifySHA512Hash(artifactPath), "checksum should be downloaded alongside the package")
+	assert.Equal(t, 1.0, downloader.upgradeDetails.Metadata.DownloadPercent)
+}
+
+func TestDownloadSigstoreBundle(t *testing.T) {
+	registry := newTestRegistry(t)
+	files := packageFiles([]byte("elastic agent package"))
+	files[testPackage+cosign.BundleSuffix] = []byte(`{"mediaType": "application/vnd.dev.sigstore.bundle.v0.3+json"}`)
+	registry.push(t, "elastic/elastic-agent", testTag, files)
+
+	downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword))
+	artifactPath, err := downloader.Download(context.Background(), agentSpec, testVersion)
+	require.NoError(t, err)
+
+	bundle, err := os.ReadFile(artifactPath + cosign.BundleSuffix)
+	require.NoError(t, err, "bundle should be downloaded alongside the package")
+	assert.Equal(t, files[testPackage+cosign.BundleSuffix], bundle)
+}
+
+func TestDownloadPinnedDigest(t *testing.T) {
+	registry := newTestRegistry(t)
+	pinned := registry.push(t, "elastic/elastic-agent", "pinned", packageFiles([]byte("pinned package")))
+	registry.push(t, "elastic/elastic-agent", testTag, packageFiles([]byte("tagged package")))
+
+	downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword)+"@"+pinned.String())
+	artifactPath, err := downloader.Download(context.Background(), agentSpec, testVersion)
+	require.NoError(t, err)
+
+	downloaded, err := os.ReadFile(artifactPath)
+	require.NoError(t, err)
+	assert.Equal(t, "pinned package", string(downloaded))
+}
+
+func TestDownloadDigestMismatch(t *testing.T) {
+	registry := newTestRegistry(t)
+	content := []byte("elastic agent package")
+	registry.push(t, "elastic/elastic-agent", testTag, packageFiles(content))
+	registry.corrupt(digest.FromBytes(content))
+
+	downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword))
+	_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+
+	var checksumMismatchErr *download.ChecksumMismatchError
+	require.True(t, errors.As(err, &checksumMismatchErr), "expected a checksum mismatch, got %v", err)
+	assert.Equal(t, digest.FromBytes(content).String(), checksumMismatchErr.Expected)
+	assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+}
+
+func TestDownloadErrors(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.push(t, "elastic/elastic-agent", testTag, map[string][]byte{"other-package.tar.gz": []byte("other")})
+
+	tests := map[string]struct {
+		sourceURI string
+		version   string
+	}{
+		"invalid credentials": {
+			sourceURI: registry.sourceURI(testUsername, "wrong"),
+		},
+		"unknown tag": {
+			sourceURI: registry.sourceURI(testUsername, testPassword) + "@" + digest.FromString("unknown").String(),
+		},
+		"package missing from manifest": {
+			sourceURI: registry.sourceURI(testUsername, testPassword),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			downloader := newTestDownloader(t, registry, tc.sourceURI)
+			_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), testPassword, "credentials should not leak in errors")
+			assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+		})
+	}
+}
+
+func TestDownloadInsecureTokenRealm(t *testing.T) {
+	// the token server of another host is reached over http
+	var credentialsSent atomic.Bool
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, _, ok := req.BasicAuth(); ok {
+			credentialsSent.Store(true)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": testToken})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	registry := newTestRegistry(t)
+	registry.realm = tokenServer.URL + "/token"
+	registry.push(t, "elastic/elastic-agent", testTag, packageFiles([]byte("elastic agent package")))
+
+	downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword))
+	_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+	require.ErrorContains(t, err, "not using https")
+	assert.False(t, credentialsSent.Load(), "credentials must not be sent to an http token realm")
+	assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+}
@@ -0,0 +1,187 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+// Verifier verifies a downloaded package against the digest of its layer in the OCI registry and the PGP
+// signature stored alongside it.
+type Verifier struct {
+	config     *artifact.Config
+	client     http.Client
+	defaultKey []byte
+	log        *logger.Logger
+}
+
+func (v *Verifier) Name() string {
+	return "oci.verifier"
+}
+
+// NewVerifier creates a verifier checking downloaded package against the manifest and signature stored in the
+// configured OCI registry.
+func NewVerifier(log *logger.Logger, config *artifact.Config, pgp []byte) (*Verifier, error) {
+	if len(pgp) == 0 {
+		return nil, errors.New("expecting PGP key received none", errors.TypeSecurity)
+	}
+
+	client, err := config.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
+			return download.WithHeaders(rt, download.Headers)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Verifier{
+		config:     config,
+		client:     *client,
+		defaultKey: pgp,
+		log:        log,
+	}
+
+	return v, nil
+}
+
+func (v *Verifier) Reload(c *artifact.Config) error {
+	// reload client
+	client, err := c.HTTPTransportSettings.Client(
+		httpcommon.WithAPMHTTPInstrumentation(),
+		httpcommon.WithModRoundtripper(func(rt http.RoundTripper) http.RoundTripper {
+			return download.WithHeaders(rt, download.Headers)
+		}),
+	)
+	if err != nil {
+		return errors.New(err, "oci.verifier: failed to generate client out of config")
+	}
+
+	v.client = *client
+	v.config = c
+
+	return nil
+}
+
+// Verify checks the downloaded package matches the digest of its layer, its SHA512 checksum when it was
+// downloaded, and the PGP signature stored as a layer of the same manifest.
+func (v *Verifier) Verify(ctx context.Context, a artifact.Artifact, version agtversion.ParsedSemVer, skipDefaultPgp bool, pgpBytes ...string) error {
+	src, err := parseSource(v.config.SourceURI)
+	if err != nil {
+		return err
+	}
+
+	filename, err := artifact.GetArtifactName(a, version, v.config.OS(), v.config.Arch())
+	if err != nil {
+		return errors.New(err, "retrieving package name")
+	}
+
+	artifactPath, err := artifact.GetArtifactPath(a, version, v.config.OS(), v.config.Arch(), v.config.TargetDirectory)
+	if err != nil {
+		return errors.New(err, "retrieving package path")
+	}
+
+	registry := newRegistryClient(v.client, src)
+	repository := src.repository(a)
+	reference := src.reference(version)
+	manifest, err := registry.manifest(ctx, repository, reference)
+	if err != nil {
+		return err
+	}
+
+	packageLayer, found := findLayer(manifest, filename)
+	if !found {
+		return errors.New(fmt.Sprintf("manifest %s:%s has no layer for package %s", repository, reference, filename), errors.TypeNetwork)
+	}
+	if err := v.verifyDigest(artifactPath, packageLayer); err != nil {
+		return fmt.Errorf("failed to verify digest: %w", err)
+	}
+
+	if _, err := os.Stat(artifactPath + sha512Suffix); err == nil {
+		if err = download.VerifySHA512HashWithCleanup(v.log, artifactPath); err != nil {
+			return fmt.Errorf("failed to verify SHA512 hash: %w", err)
+		}
+	}
+
+	if err = v.verifyAsc(ctx, registry, repository, manifest, filename, artifactPath, skipDefaultPgp, pgpBytes...); err != nil {
+		var invalidSignatureErr *download.InvalidSignatureError
+		if errors.As(err, &invalidSignatureErr) {
+			if err := os.Remove(artifactPath); err != nil {
+				v.log.Warnf("failed clean up after signature verification: failed to remove %q: %v",
+					artifactPath, err)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// verifyDigest checks the package matches the digest of its layer, the package is removed when it doesn't.
+func (v *Verifier) verifyDigest(artifactPath string, layer ocispec.Descriptor) error {
+	if err := layer.Digest.Validate(); err != nil {
+		return errors.New(err, fmt.Sprintf("invalid layer digest %q", layer.Digest), errors.TypeSecurity)
+	}
+
+	f, err := os.Open(artifactPath)
+	if err != nil {
+		return errors.New(err, errors.TypeFilesystem, errors.M(errors.MetaKeyPath, artifactPath))
+	}
+	defer f.Close()
+
+	computed, err := layer.Digest.Algorithm().FromReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to read file to calculate digest: %w", err)
+	}
+	if computed == layer.Digest {
+		return nil
+	}
+
+	f.Close()
+	for _, file := range []string{artifactPath, artifactPath + sha512Suffix} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			v.log.Warnf("failed clean up after digest verification: failed to remove %q: %v", file, err)
+		}
+	}
+	return &download.ChecksumMismatchError{
+		Expected: layer.Digest.String(),
+		Computed: computed.String(),
+		File:     artifactPath,
+	}
+}
+
+func (v *Verifier) verifyAsc(ctx context.Context, registry *registryClient, repository string, manifest ocispec.Manifest, filename, fullPath string, skipDefaultKey bool, pgpSources ...string) error {
+	ascLayer, found := findLayer(manifest, filename+ascSuffix)
+	if !found {
+		return errors.New(fmt.Sprintf("manifest of %s has no layer for signature %s", repository, filename+ascSuffix), errors.TypeSecurity)
+	}
+
+	ascBytes := &bytes.Buffer{}
+	if err := registry.blob(ctx, repository, ascLayer, ascBytes); err != nil {
+		return fmt.Errorf("could not pull %s: %w", filename+ascSuffix, err)
+	}
+
+	pgpBytes, err := download.FetchPGPKeys(
+		v.log, v.client, v.defaultKey, skipDefaultKey, pgpSources)
+	if err != nil {
+		return fmt.Errorf("could not fetch pgp keys: %w", err)
+	}
+
+	return download.VerifyPGPSignatureWithKeys(v.log, fullPath, ascBytes.Bytes(), pgpBytes)
+}
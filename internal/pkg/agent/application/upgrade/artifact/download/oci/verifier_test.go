@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	"github.com/elastic/elastic-agent/testing/pgptest"
+)
+
+func TestVerify(t *testing.T) {
+	content := []byte("elastic agent package")
+	pub, asc := pgptest.Sign(t, bytes.NewReader(content))
+	_, otherAsc := pgptest.Sign(t, bytes.NewReader(content))
+
+	tests := map[string]struct {
+		asc      []byte
+		tamper   bool
+		expected func(t *testing.T, err error)
+	}{
+		"valid package": {
+			asc: asc,
+			expected: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		"tampered package": {
+			asc:    asc,
+			tamper: true,
+			expected: func(t *testing.T, err error) {
+				var checksumMismatchErr *download.ChecksumMismatchError
+				assert.True(t, errors.As(err, &checksumMismatchErr), "expected a checksum mismatch, got %v", err)
+			},
+		},
+		"invalid signature": {
+			asc: otherAsc,
+			expected: func(t *testing.T, err error) {
+				var invalidSignatureErr *download.InvalidSignatureError
+				assert.True(t, errors.As(err, &invalidSignatureErr), "expected an invalid signature, got %v", err)
+			},
+		},
+		"missing signature": {
+			expected: func(t *testing.T, err error) {
+				assert.ErrorContains(t, err, "no layer for signature")
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			registry := newTestRegistry(t)
+			files := packageFiles(content)
+			if tc.asc != nil {
+				files[testPackage+".asc"] = tc.asc
+			}
+			registry.push(t, "elastic/elastic-agent", testTag, files)
+
+			downloader := newTestDownloader(t, registry, registry.sourceURI(testUsername, testPassword))
+			artifactPath, err := downloader.Download(context.Background(), agentSpec, testVersion)
+			require.NoError(t, err)
+			if tc.tamper {
+				require.NoError(t, os.WriteFile(artifactPath, []byte("tampered package"), 0o600))
+			}
+
+			log, _ := loggertest.New("verifier")
+			verifier, err := NewVerifier(log, downloader.config, pub)
+			require.NoError(t, err)
+			verifier.client = *registry.Client()
+
+			err = verifier.Verify(context.Background(), agentSpec, *testVersion, false)
+			tc.expected(t, err)
+			if tc.tamper {
+				assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage), "tampered package should be removed")
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+const (
+	announceMaxSize = 1024
+
+	// announceTTL is the number of announce intervals a discovered peer is kept without announce.
+	announceTTL = 3
+
+	// announceMaxSkew is the difference tolerated between the time of an announcement and the time it is
+	// received at, older announcements are rejected as replayed.
+	announceMaxSkew = 30 * time.Second
+)
+
+// announcement is the UDP datagram a cache announces its endpoint with. The endpoint is reached on the
+// port of the announcement at the source address of the datagram. The MAC proves the sender knows the
+// token, so caches of other sites sharing the network are ignored. It covers the source address and the
+// time of the announcement so it cannot be replayed from another address or later on.
+type announcement struct {
+	ID   string `json:"id"`
+	Port int    `json:"port"`
+	Time int64  `json:"time"`
+	MAC  string `json:"mac"`
+}
+
+func newInstanceID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate peer cache id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func announcementMAC(token string, id string, ip net.IP, port int, t int64) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(strings.Join([]string{id, ip.String(), strconv.Itoa(port), strconv.FormatInt(t, 10)}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newAnnouncement returns the announcement of the endpoint listening on port, sent from ip at t.
+func (c *Cache) newAnnouncement(ip net.IP, port int, t time.Time) ([]byte, error) {
+	return json.Marshal(announcement{
+		ID:   c.id,
+		Port: port,
+		Time: t.UnixNano(),
+		MAC:  announcementMAC(c.currentConfig().Token, c.id, ip, port, t.UnixNano()),
+	})
+}
+
+// announce sends the announcement of the endpoint listening on port every interval until ctx is cancelled.
+func (c *Cache) announce(ctx context.Context, config artifact.PeerCacheConfig, port int) {
+	addr, err := net.ResolveUDPAddr("udp4", config.Announce.Address)
+	if err != nil {
+		c.log.Errorw("Invalid peer cache announce address, not announcing", "address", config.Announce.Address, "error.message", err)
+		return
+	}
+
+	ticker := time.NewTicker(config.Announce.Interval)
+	defer ticker.Stop()
+	for {
+		if err := c.sendAnnouncement(addr, port); err != nil {
+			c.log.Warnw("Failed to announce peer cache", "address", addr.String(), "error.message", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cache) sendAnnouncement(addr *net.UDPAddr, port int) error {
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// the peers receive the announcement from the local address of the connection
+	msg, err := c.newAnnouncement(conn.LocalAddr().(*net.UDPAddr).IP, port, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to encode peer cache announce: %w", err)
+	}
+	_, err = conn.Write(msg)
+	return err
+}
+
+// discover records the peers announcing their endpoint until ctx is cancelled.
+func (c *Cache) discover(ctx context.Context, config artifact.PeerCacheConfig) {
+	conn, err := listenAnnouncements(config.Announce.Address)
+	if err != nil {
+		c.log.Errorw("Failed to listen to peer cache announces, peers are not discovered", "address", config.Announce.Address, "error.message", err)
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, announceMaxSize)
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.log.Errorw("Failed to read peer cache announce, peers are not discovered anymore", "error.message", err)
+			}
+			return
+		}
+
+		peer, err := c.parseAnnouncement(buf[:n], src, time.Now())
+		if err != nil {
+			c.log.Debugw("Ignoring peer cache announce", "source", src.String(), "error.message", err)
+			continue
+		}
+		if peer == "" {
+			continue
+		}
+
+		c.mx.Lock()
+		if _, found := c.discovered[peer]; !found {
+			c.log.Infow("Discovered peer cache", "peer", peer)
+		}
+		c.discovered[peer] = time.Now().Add(announceTTL * config.Announce.Interval)
+		c.mx.Unlock()
+	}
+}
+
+func listenAnnouncements(address string) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp4", address)
+	if err != nil {
+		return nil, err
+	}
+	if addr.IP.IsMulticast() {
+		return net.ListenMulticastUDP("udp4", nil, addr)
+	}
+	return net.ListenUDP("udp4", &net.UDPAddr{Port: addr.Port})
+}
+
+// parseAnnouncement returns the URL of the endpoint announced by src, the URL is empty when the announcement
+// comes from this cache. Announcements not sent from src, outside of announceMaxSkew of now or not newer than
+// the last one of the same peer are rejected.
+func (c *Cache) parseAnnouncement(msg []byte, src *net.UDPAddr, now time.Time) (string, error) {
+	a := announcement{}
+	if err := json.Unmarshal(msg, &a); err != nil {
+		return "", err
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if !hmac.Equal([]byte(a.MAC), []byte(announcementMAC(c.config.Token, a.ID, src.IP, a.Port, a.Time))) {
+		return "", errors.New("invalid announce MAC")
+	}
+	if a.ID == c.id {
+		return "", nil
+	}
+	if skew := now.Sub(time.Unix(0, a.Time)); skew > announceMaxSkew || skew < -announceMaxSkew {
+		return "", fmt.Errorf("announce time is %s away from the local time", skew)
+	}
+	if a.Time <= c.lastAnnounces[a.ID] {
+		return "", errors.New("replayed announce")
+	}
+	if a.Port <= 0 || a.Port > 65535 {
+		return "", fmt.Errorf("invalid announced port %d", a.Port)
+	}
+
+	// announcements older than the skew are rejected, so are the last ones of the peers gone since
+	for id, last := range c.lastAnnounces {
+		if now.Sub(time.Unix(0, last)) > announceMaxSkew {
+			delete(c.lastAnnounces, id)
+		}
+	}
+	c.lastAnnounces[a.ID] = a.Time
+
+	return "http://" + net.JoinHostPort(src.IP.String(), strconv.Itoa(a.Port)), nil
+}
@@ -0,0 +1,80 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAnnouncement(t *testing.T) {
+	cache := newTestCache(t)
+	other := newTestCache(t)
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 5), Port: 40000}
+	now := time.Now()
+
+	msg, err := other.newAnnouncement(src.IP, 6791, now)
+	require.NoError(t, err)
+	peer, err := cache.parseAnnouncement(msg, src, now)
+	require.NoError(t, err)
+	assert.Equal(t, "http://10.0.0.5:6791", peer)
+
+	_, err = cache.parseAnnouncement(msg, src, now)
+	assert.ErrorContains(t, err, "replayed announce")
+
+	msg, err = cache.newAnnouncement(src.IP, 6791, now)
+	require.NoError(t, err)
+	peer, err = cache.parseAnnouncement(msg, src, now)
+	require.NoError(t, err)
+	assert.Empty(t, peer, "own announces should be ignored")
+
+	otherSite := newTestCache(t)
+	otherSite.config.Token = "other-site-secret"
+	msg, err = otherSite.newAnnouncement(src.IP, 6791, now)
+	require.NoError(t, err)
+	_, err = cache.parseAnnouncement(msg, src, now)
+	assert.ErrorContains(t, err, "invalid announce MAC")
+
+	msg, err = other.newAnnouncement(net.IPv4(10, 0, 0, 6), 6791, now.Add(time.Second))
+	require.NoError(t, err)
+	_, err = cache.parseAnnouncement(msg, src, now)
+	assert.ErrorContains(t, err, "invalid announce MAC", "announces sent from another address should be rejected")
+
+	msg, err = other.newAnnouncement(src.IP, 6791, now.Add(-time.Minute))
+	require.NoError(t, err)
+	_, err = cache.parseAnnouncement(msg, src, now)
+	assert.ErrorContains(t, err, "away from the local time")
+
+	_, err = cache.parseAnnouncement([]byte("not json"), src, now)
+	assert.Error(t, err)
+}
+
+func TestDiscover(t *testing.T) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	address := conn.LocalAddr().String()
+	require.NoError(t, conn.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cache := newTestCache(t)
+	cache.config.Announce.Address = address
+	go cache.discover(ctx, cache.currentConfig())
+
+	other := newTestCache(t)
+	other.config.Announce.Address = address
+	go other.announce(ctx, other.currentConfig(), 6791)
+
+	require.Eventually(t, func() bool {
+		peers := cache.Peers()
+		return len(peers) == 1 && peers[0] == "http://127.0.0.1:6791"
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+const (
+	// authScheme is the HTTP authentication scheme of the peer caches. A request without authorization is
+	// answered with a challenge, a single use nonce the next request proves the knowledge of the token with,
+	// the token itself never travels over the network.
+	authScheme = "PeerCache"
+
+	// challengeTTL is the time a peer has to answer a challenge.
+	challengeTTL = 30 * time.Second
+
+	// maxChallenges is the maximum number of challenges waiting for an answer.
+	maxChallenges = 1024
+)
+
+var errTooManyChallenges = errors.New("too many pending peer cache challenges")
+
+// challenges are the nonces issued to the peers and not answered yet.
+type challenges struct {
+	mx     sync.Mutex
+	nonces map[string]time.Time
+}
+
+// issue returns a new nonce expiring after challengeTTL.
+func (c *challenges) issue(now time.Time) (string, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.nonces == nil {
+		c.nonces = make(map[string]time.Time)
+	}
+	for nonce, expires := range c.nonces {
+		if now.After(expires) {
+			delete(c.nonces, nonce)
+		}
+	}
+	if len(c.nonces) >= maxChallenges {
+		return "", errTooManyChallenges
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate peer cache challenge: %w", err)
+	}
+	nonce := hex.EncodeToString(b)
+	c.nonces[nonce] = now.Add(challengeTTL)
+	return nonce, nil
+}
+
+// consume returns whether the nonce was issued and has not expired, it cannot be used again.
+func (c *challenges) consume(nonce string, now time.Time) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	expires, found := c.nonces[nonce]
+	if !found {
+		return false
+	}
+	delete(c.nonces, nonce)
+	return !now.After(expires)
+}
+
+// challengeResponse returns the answer to the challenge nonce for a request of method on path.
+func challengeResponse(token, nonce, method, path string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(strings.Join([]string{nonce, method, path}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// challengeHeader returns the WWW-Authenticate header of the challenge nonce.
+func challengeHeader(nonce string) string {
+	return fmt.Sprintf("%s nonce=%q", authScheme, nonce)
+}
+
+// authorizationHeader returns the Authorization header answering the challenge nonce.
+func authorizationHeader(token, nonce, method, path string) string {
+	return fmt.Sprintf("%s nonce=%q, mac=%q", authScheme, nonce, challengeResponse(token, nonce, method, path))
+}
+
+// parseAuthParams returns the parameters of a WWW-Authenticate or Authorization header of authScheme.
+func parseAuthParams(header string) (map[string]string, bool) {
+	rest, found := strings.CutPrefix(header, authScheme+" ")
+	if !found {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(rest, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			return nil, false
+		}
+		params[key] = strings.Trim(value, `"`)
+	}
+	return params, true
+}
@@ -0,0 +1,352 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/cosign"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const (
+	// artifactsPath is the path the cached artifacts are served under.
+	artifactsPath = "/artifacts/"
+
+	cachePermissions = 0o640
+	tempFilePrefix   = ".partial-"
+)
+
+// sidecarSuffixes are the suffixes of the files cached along a package when they exist.
+var sidecarSuffixes = []string{".sha512", ".asc", cosign.BundleSuffix}
+
+// Cache is the artifact cache shared with the peers. It serves the artifacts verified by this agent over an
+// authenticated HTTP endpoint and keeps track of the peers serving theirs.
+type Cache struct {
+	log        *logger.Logger
+	id         string
+	challenges challenges
+	reload     chan struct{}
+
+	mx            sync.Mutex
+	config        artifact.PeerCacheConfig
+	discovered    map[string]time.Time
+	lastAnnounces map[string]int64
+}
+
+// NewCache creates the peer cache, it only serves and discovers peers once running and enabled.
+func NewCache(log *logger.Logger, config artifact.PeerCacheConfig) (*Cache, error) {
+	config, err := withDefaults(config)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newInstanceID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cache{
+		log:           log,
+		id:            id,
+		reload:        make(chan struct{}, 1),
+		config:        config,
+		discovered:    make(map[string]time.Time),
+		lastAnnounces: make(map[string]int64),
+	}, nil
+}
+
+func withDefaults(config artifact.PeerCacheConfig) (artifact.PeerCacheConfig, error) {
+	if config.Enabled && config.Token == "" {
+		return config, errors.New("peer cache requires a token shared by the peers", errors.TypeConfig)
+	}
+	if config.Listen == "" {
+		config.Listen = artifact.DefaultPeerCacheListen
+	}
+	if config.Path == "" {
+		config.Path = filepath.Join(paths.Data(), "peer_cache")
+	}
+	if config.Announce.Address == "" {
+		config.Announce.Address = artifact.DefaultPeerAnnounceAddress
+	}
+	if config.Announce.Interval <= 0 {
+		config.Announce.Interval = artifact.DefaultPeerAnnounceInterval
+	}
+	return config, nil
+}
+
+// Reload applies the configuration, the running cache is restarted when it changed.
+func (c *Cache) Reload(config artifact.PeerCacheConfig) error {
+	config, err := withDefaults(config)
+	if err != nil {
+		return err
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if reflect.DeepEqual(c.config, config) {
+		return nil
+	}
+	c.log.Infow("Peer cache configuration changed", "enabled", config.Enabled, "listen", config.Listen, "path", config.Path)
+	c.config = config
+	c.discovered = make(map[string]time.Time)
+	c.lastAnnounces = make(map[string]int64)
+	select {
+	case c.reload <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Enabled returns whether the packages are downloaded from the peers and added to the cache.
+func (c *Cache) Enabled() bool {
+	return c.currentConfig().Enabled
+}
+
+func (c *Cache) currentConfig() artifact.PeerCacheConfig {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.config
+}
+
+// Run serves the cached artifacts and, when enabled, announces the endpoint and discovers the peers until
+// ctx is cancelled. The cache is idle while disabled and restarted when its configuration changes.
+func (c *Cache) Run(ctx context.Context) {
+	for {
+		config := c.currentConfig()
+		runCtx, cancel := context.WithCancel(ctx)
+		errCh := make(chan error, 1)
+		if config.Enabled {
+			go func() {
+				errCh <- c.serve(runCtx, config)
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			if config.Enabled {
+				<-errCh
+			}
+			return
+		case <-c.reload:
+			cancel()
+			if config.Enabled {
+				<-errCh
+			}
+		case err := <-errCh:
+			cancel()
+			c.log.Errorw("Peer cache stopped, verified packages are not served to peers until its configuration changes", "error.message", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.reload:
+			}
+		}
+	}
+}
+
+// serve serves the cached artifacts and, when enabled, announces the endpoint and discovers the peers until
+// ctx is cancelled.
+func (c *Cache) serve(ctx context.Context, config artifact.PeerCacheConfig) error {
+	if err := os.MkdirAll(config.Path, 0o750); err != nil {
+		return errors.New(err, "failed to create peer cache directory", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, config.Path))
+	}
+
+	listener, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		return errors.New(err, fmt.Sprintf("failed to listen on %s", config.Listen), errors.TypeNetwork)
+	}
+	server := &http.Server{Handler: c, ReadHeaderTimeout: 10 * time.Second}
+	c.log.Infow("Serving peer cache", "address", listener.Addr().String(), "path", config.Path)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	if config.Announce.Enabled {
+		port := listener.Addr().(*net.TCPAddr).Port
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			c.announce(ctx, config, port)
+		}()
+		go func() {
+			defer wg.Done()
+			c.discover(ctx, config)
+		}()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+		// the listener is closed once Serve returns, the cache can then be served again on its address
+		<-errCh
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Peers returns the URLs of the configured peers followed by the URLs of the discovered ones.
+func (c *Cache) Peers() []string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	peers := slices.Clone(c.config.Peers)
+	var discovered []string
+	now := time.Now()
+	for peer, expires := range c.discovered {
+		if now.After(expires) {
+			delete(c.discovered, peer)
+			continue
+		}
+		if !slices.Contains(peers, peer) {
+			discovered = append(discovered, peer)
+		}
+	}
+	slices.Sort(discovered)
+
+	return append(peers, discovered...)
+}
+
+// Add caches the verified package along with its sidecar files, any other cached file is removed, including the
+// artifacts of other versions.
+func (c *Cache) Add(version string, packagePath string) error {
+	cachePath := c.currentConfig().Path
+	if err := os.MkdirAll(cachePath, 0o750); err != nil {
+		return errors.New(err, "failed to create peer cache directory", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, cachePath))
+	}
+
+	if err := c.copyFile(cachePath, packagePath); err != nil {
+		return err
+	}
+	cached := []string{filepath.Base(packagePath)}
+	for _, suffix := range sidecarSuffixes {
+		if _, err := os.Stat(packagePath + suffix); err != nil {
+			continue
+		}
+		if err := c.copyFile(cachePath, packagePath+suffix); err != nil {
+			return err
+		}
+		cached = append(cached, filepath.Base(packagePath+suffix))
+	}
+	c.log.Infow("Added package to peer cache", "package", filepath.Base(packagePath), "version", version)
+
+	entries, err := os.ReadDir(cachePath)
+	if err != nil {
+		return fmt.Errorf("unable to read directory %q: %w", cachePath, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || slices.Contains(cached, entry.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cachePath, entry.Name())); err != nil {
+			c.log.Warnf("failed to remove %q from peer cache: %v", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// copyFile copies the file to the cache directory, it is written to a temporary file first so it is never
+// served partially.
+func (c *Cache) copyFile(cachePath string, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return errors.New(err, errors.TypeFilesystem, errors.M(errors.MetaKeyPath, path))
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(cachePath, tempFilePrefix+"*")
+	if err != nil {
+		return errors.New(err, "failed to create file in peer cache", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, cachePath))
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to copy %q to peer cache: %w", path, err)
+	}
+	if err := tmp.Chmod(cachePermissions); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(cachePath, filepath.Base(path)))
+}
+
+// ServeHTTP serves the cached artifacts to the peers answering the challenge with the token.
+func (c *Cache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	config := c.currentConfig()
+	if !c.authorized(r, config.Token) {
+		nonce, err := c.challenges.issue(time.Now())
+		if err != nil {
+			c.log.Warnw("Failed to issue peer cache challenge", "peer", r.RemoteAddr, "error.message", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", challengeHeader(nonce))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	name, found := strings.CutPrefix(r.URL.Path, artifactsPath)
+	if !found || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f, err := os.Open(filepath.Join(config.Path, name))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	c.log.Debugw("Serving cached artifact to peer", "artifact", name, "peer", r.RemoteAddr)
+	http.ServeContent(w, r, name, stat.ModTime(), f)
+}
+
+// authorized returns whether the request answers a pending challenge with the token.
+func (c *Cache) authorized(r *http.Request, token string) bool {
+	params, found := parseAuthParams(r.Header.Get("Authorization"))
+	if !found || !c.challenges.consume(params["nonce"], time.Now()) {
+		return false
+	}
+	return hmac.Equal([]byte(params["mac"]), []byte(challengeResponse(token, params["nonce"], r.Method, r.URL.Path)))
+}
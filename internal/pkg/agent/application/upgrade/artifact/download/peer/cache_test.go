@@ -0,0 +1,243 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+)
+
+const (
+	testToken   = "site-secret"
+	testPackage = "elastic-agent-9.1.0-linux-x86_64.tar.gz"
+)
+
+func newTestCache(t *testing.T, peers ...string) *Cache {
+	log, _ := loggertest.New("peer_cache")
+	cache, err := NewCache(log, artifact.PeerCacheConfig{
+		Enabled: true,
+		Listen:  "127.0.0.1:0",
+		Token:   testToken,
+		Path:    t.TempDir(),
+		Peers:   peers,
+		Announce: artifact.PeerAnnounceConfig{
+			Interval: 50 * time.Millisecond,
+		},
+	})
+	require.NoError(t, err)
+	return cache
+}
+
+// writePackage writes a package and its checksum in a new directory and returns the package path.
+func writePackage(t *testing.T, name string, content string) string {
+	packagePath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(packagePath, []byte(content), 0o600))
+	require.NoError(t, os.WriteFile(packagePath+".sha512", []byte("checksum  "+name), 0o600))
+	return packagePath
+}
+
+func TestNewCache(t *testing.T) {
+	log, _ := loggertest.New("peer_cache")
+	_, err := NewCache(log, artifact.PeerCacheConfig{Enabled: true, Path: t.TempDir()})
+	assert.ErrorContains(t, err, "requires a token")
+}
+
+func TestCacheAdd(t *testing.T) {
+	cache := newTestCache(t)
+	require.NoError(t, cache.Add("9.0.0", writePackage(t, "elastic-agent-9.0.0-linux-x86_64.tar.gz", "old package")))
+	// the names of the packages of these versions contain the version of the last package
+	require.NoError(t, cache.Add("19.1.0", writePackage(t, "elastic-agent-19.1.0-linux-x86_64.tar.gz", "other package")))
+	require.NoError(t, cache.Add("9.1.0", writePackage(t, "elastic-agent-9.1.0-SNAPSHOT-linux-x86_64.tar.gz", "snapshot package")))
+	require.NoError(t, cache.Add("9.1.0", writePackage(t, testPackage, "new package")))
+
+	entries, err := os.ReadDir(cache.config.Path)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{testPackage, testPackage + ".sha512"}, names, "only the packages of the last version should be cached")
+
+	content, err := os.ReadFile(filepath.Join(cache.config.Path, testPackage))
+	require.NoError(t, err)
+	assert.Equal(t, "new package", string(content))
+}
+
+func TestCacheServeHTTP(t *testing.T) {
+	cache := newTestCache(t)
+	require.NoError(t, cache.Add("9.1.0", writePackage(t, testPackage, "package")))
+	require.NoError(t, os.WriteFile(filepath.Join(cache.config.Path, tempFilePrefix+"9.1.0"), []byte("partial"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(cache.config.Path), "secret-9.1.0"), []byte("secret"), 0o600))
+
+	tests := map[string]struct {
+		path           string
+		token          string
+		expectedStatus int
+		expectedBody   string
+	}{
+		"cached package": {
+			path:           artifactsPath + testPackage,
+			token:          testToken,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "package",
+		},
+		"no token": {
+			path:           artifactsPath + testPackage,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"invalid token": {
+			path:           artifactsPath + testPackage,
+			token:          "other-site-secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"not cached": {
+			path:           artifactsPath + "elastic-agent-9.2.0-linux-x86_64.tar.gz",
+			token:          testToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		"partial file": {
+			path:           artifactsPath + tempFilePrefix + "9.1.0",
+			token:          testToken,
+			expectedStatus: http.StatusNotFound,
+		},
+		"outside of the cache": {
+			path:           artifactsPath + "../secret-9.1.0",
+			token:          testToken,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://peer"+artifactsPath, nil)
+			req.URL.Path = tc.path
+			if tc.token != "" {
+				req.Header.Set("Authorization", authorizationHeader(tc.token, challenge(t, cache, tc.path), http.MethodGet, tc.path))
+			}
+			rec := httptest.NewRecorder()
+
+			cache.ServeHTTP(rec, req)
+			resp := rec.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedBody != "" {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, string(body))
+			}
+		})
+	}
+
+	t.Run("replayed answer", func(t *testing.T) {
+		path := artifactsPath + testPackage
+		authorization := authorizationHeader(testToken, challenge(t, cache, path), http.MethodGet, path)
+		for _, expectedStatus := range []int{http.StatusOK, http.StatusUnauthorized} {
+			req := httptest.NewRequest(http.MethodGet, "http://peer"+path, nil)
+			req.Header.Set("Authorization", authorization)
+			rec := httptest.NewRecorder()
+
+			cache.ServeHTTP(rec, req)
+			assert.Equal(t, expectedStatus, rec.Code)
+		}
+	})
+
+	t.Run("answer for another path", func(t *testing.T) {
+		path := artifactsPath + testPackage
+		req := httptest.NewRequest(http.MethodGet, "http://peer"+path, nil)
+		req.Header.Set("Authorization", authorizationHeader(testToken, challenge(t, cache, path), http.MethodGet, path+".sha512"))
+		rec := httptest.NewRecorder()
+
+		cache.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+// challenge returns the nonce of the challenge the cache answers an unauthorized request on path with.
+func challenge(t *testing.T, cache *Cache, path string) string {
+	req := httptest.NewRequest(http.MethodGet, "http://peer"+path, nil)
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	params, found := parseAuthParams(rec.Header().Get("WWW-Authenticate"))
+	require.True(t, found, "the unauthorized response should contain a challenge")
+	require.NotEmpty(t, params["nonce"])
+	return params["nonce"]
+}
+
+func TestCacheReload(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	log, _ := loggertest.New("peer_cache")
+	cache, err := NewCache(log, artifact.PeerCacheConfig{Listen: address, Path: t.TempDir()})
+	require.NoError(t, err)
+	assert.False(t, cache.Enabled())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Run(ctx)
+	}()
+
+	serving := func() bool {
+		resp, err := http.Get("http://" + address + artifactsPath + testPackage)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusUnauthorized
+	}
+
+	config := cache.currentConfig()
+	config.Enabled = true
+	assert.ErrorContains(t, cache.Reload(config), "requires a token")
+	assert.False(t, cache.Enabled())
+
+	config.Token = testToken
+	require.NoError(t, cache.Reload(config))
+	assert.True(t, cache.Enabled())
+	require.Eventually(t, serving, 5*time.Second, 50*time.Millisecond, "the enabled cache should be served")
+
+	config.Enabled = false
+	require.NoError(t, cache.Reload(config))
+	assert.False(t, cache.Enabled())
+	require.Eventually(t, func() bool { return !serving() }, 5*time.Second, 50*time.Millisecond, "the disabled cache should not be served")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the cache should stop running once the context is cancelled")
+	}
+}
+
+func TestCachePeers(t *testing.T) {
+	cache := newTestCache(t, "http://10.0.0.5:6791")
+	cache.discovered["http://10.0.0.7:6791"] = time.Now().Add(time.Minute)
+	cache.discovered["http://10.0.0.6:6791"] = time.Now().Add(time.Minute)
+	cache.discovered["http://10.0.0.5:6791"] = time.Now().Add(time.Minute)
+	cache.discovered["http://10.0.0.8:6791"] = time.Now().Add(-time.Second)
+
+	assert.Equal(t, []string{"http://10.0.0.5:6791", "http://10.0.0.6:6791", "http://10.0.0.7:6791"}, cache.Peers())
+	assert.NotContains(t, cache.discovered, "http://10.0.0.8:6791", "expired peers should be forgotten")
+}
@@ -0,0 +1,220 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+const (
+	packagePermissions = 0o660
+
+	// maxPackageSize is the largest package downloaded from a peer.
+	maxPackageSize = 4 << 30
+	// maxSidecarSize is the largest sidecar file downloaded from a peer.
+	maxSidecarSize = 1 << 20
+)
+
+var errNotCached = errors.New("artifact not cached by peer")
+
+// VerifyFunc verifies the package downloaded from a peer.
+type VerifyFunc func(ctx context.Context, a artifact.Artifact, version agtversion.ParsedSemVer) error
+
+// Downloader is a downloader fetching artifacts from the caches of the peers, the peers are tried in the
+// order returned by the cache. A package failing verification is removed and the next peer is tried, so
+// that a stale or tampered cache does not prevent the download from the other sources.
+type Downloader struct {
+	log            *logger.Logger
+	config         *artifact.Config
+	client         http.Client
+	cache          *Cache
+	verify         VerifyFunc
+	upgradeDetails *details.Details
+}
+
+// NewDownloader creates a downloader fetching artifacts from the peers known by the cache. Peers are on
+// the local network, the proxy settings do not apply. The downloaded packages are verified with verify,
+// they are not verified when it is nil.
+func NewDownloader(log *logger.Logger, config *artifact.Config, cache *Cache, verify VerifyFunc, upgradeDetails *details.Details) (*Downloader, error) {
+	transport := config.HTTPTransportSettings
+	transport.Proxy.Disable = true
+	client, err := transport.Client(httpcommon.WithAPMHTTPInstrumentation())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Downloader{
+		log:            log,
+		config:         config,
+		client:         *client,
+		cache:          cache,
+		verify:         verify,
+		upgradeDetails: upgradeDetails,
+	}, nil
+}
+
+// Download fetches the package and its sidecar files from the first peer caching it.
+// Returns absolute path to downloaded package and an error.
+func (d *Downloader) Download(ctx context.Context, a artifact.Artifact, version *agtversion.ParsedSemVer) (string, error) {
+	filename, err := artifact.GetArtifactName(a, *version, d.config.OS(), d.config.Arch())
+	if err != nil {
+		return "", errors.New(err, "generating package name failed")
+	}
+
+	fullPath, err := artifact.GetArtifactPath(a, *version, d.config.OS(), d.config.Arch(), d.config.TargetDirectory)
+	if err != nil {
+		return "", errors.New(err, "generating package path failed")
+	}
+
+	peers := d.cache.Peers()
+	if len(peers) == 0 {
+		return "", errors.New("no peer cache known", errors.TypeNetwork)
+	}
+
+	var errs []error
+	for _, peer := range peers {
+		started := time.Now()
+		size, err := d.downloadFromPeer(ctx, peer, a, *version, filename, fullPath)
+		if err != nil {
+			d.log.Infow("Failed to download package from peer cache", "peer", peer, "package", filename, "error.message", err)
+			errs = append(errs, fmt.Errorf("peer %s: %w", peer, err))
+			continue
+		}
+
+		d.log.Infow("Downloaded package from peer cache", "peer", peer, "package", filename)
+		if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+			d.upgradeDetails.SetDownloadProgress(1, float64(size)/elapsed)
+		}
+		return fullPath, nil
+	}
+
+	return "", errors.New(goerrors.Join(errs...), "no peer cache could provide the package", errors.TypeNetwork)
+}
+
+// downloadFromPeer downloads and verifies the package and the sidecar files the peer caches, it returns the
+// size of the package. The downloaded files are removed when it fails.
+func (d *Downloader) downloadFromPeer(ctx context.Context, peer string, a artifact.Artifact, version agtversion.ParsedSemVer, filename, fullPath string) (_ int64, err error) {
+	downloadedFiles := make([]string, 0, len(sidecarSuffixes)+1)
+	defer func() {
+		if err != nil {
+			for _, path := range downloadedFiles {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					d.log.Warnf("failed to cleanup %s: %v", path, err)
+				}
+			}
+		}
+	}()
+
+	if destinationDir := filepath.Dir(fullPath); destinationDir != "" && destinationDir != "." {
+		if err := os.MkdirAll(destinationDir, 0o755); err != nil {
+			return 0, err
+		}
+	}
+
+	downloadedFiles = append(downloadedFiles, fullPath)
+	size, err := d.downloadFile(ctx, peer, filename, fullPath, maxPackageSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// sidecar files are cached when the peer got them, the verifier decides whether they are needed
+	for _, suffix := range sidecarSuffixes {
+		downloadedFiles = append(downloadedFiles, fullPath+suffix)
+		if _, err := d.downloadFile(ctx, peer, filename+suffix, fullPath+suffix, maxSidecarSize); err != nil && !errors.Is(err, errNotCached) {
+			return 0, err
+		}
+	}
+
+	if d.verify != nil {
+		if err := d.verify(ctx, a, version); err != nil {
+			return 0, errors.New(err, "verification of the package failed")
+		}
+	}
+	return size, nil
+}
+
+// downloadFile downloads filename from the peer to fullPath, it fails when the file is larger than maxSize.
+func (d *Downloader) downloadFile(ctx context.Context, peer, filename, fullPath string, maxSize int64) (int64, error) {
+	uri, err := url.JoinPath(peer, artifactsPath, filename)
+	if err != nil {
+		return 0, errors.New(err, "invalid peer URI", errors.TypeNetwork, errors.M(errors.MetaKeyURI, peer))
+	}
+
+	resp, err := d.get(ctx, uri, "")
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		params, found := parseAuthParams(resp.Header.Get("WWW-Authenticate"))
+		if !found || params["nonce"] == "" {
+			return 0, errors.New(fmt.Sprintf("call to '%s' returned no peer cache challenge", uri), errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+		}
+		resp, err = d.get(ctx, uri, params["nonce"])
+		if err != nil {
+			return 0, err
+		}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, errNotCached
+	default:
+		return 0, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", uri, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+	if resp.ContentLength > maxSize {
+		return 0, errors.New(fmt.Sprintf("call to '%s' returned %d bytes, more than the %d allowed", uri, resp.ContentLength, maxSize), errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+
+	destinationFile, err := os.OpenFile(fullPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, packagePermissions)
+	if err != nil {
+		return 0, errors.New(err, "creating package file failed", errors.TypeFilesystem, errors.M(errors.MetaKeyPath, fullPath))
+	}
+	defer destinationFile.Close()
+
+	// one more byte than allowed is read to detect the larger files
+	size, err := io.Copy(destinationFile, io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return 0, errors.New(err, "copying fetched package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+	if size > maxSize {
+		return 0, errors.New(fmt.Sprintf("call to '%s' returned more than the %d bytes allowed", uri, maxSize), errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+	return size, nil
+}
+
+// get requests the uri, the request answers the challenge nonce when it is set.
+func (d *Downloader) get(ctx context.Context, uri, nonce string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+	if nonce != "" {
+		req.Header.Set("Authorization", authorizationHeader(d.cache.currentConfig().Token, nonce, req.Method, req.URL.Path))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, errors.New(err, "fetching package failed", errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+	}
+	return resp, nil
+}
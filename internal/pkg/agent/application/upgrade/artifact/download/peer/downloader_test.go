@@ -0,0 +1,130 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package peer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+var (
+	agentSpec = artifact.Artifact{
+		Name:     "Elastic Agent",
+		Cmd:      "elastic-agent",
+		Artifact: "beats/elastic-agent",
+	}
+	testVersion = agtversion.NewParsedSemVer(9, 1, 0, "", "")
+)
+
+func newTestDownloader(t *testing.T, cache *Cache) *Downloader {
+	return newTestVerifyingDownloader(t, cache, nil)
+}
+
+func newTestVerifyingDownloader(t *testing.T, cache *Cache, verify VerifyFunc) *Downloader {
+	config := artifact.DefaultConfig()
+	config.OperatingSystem = "linux"
+	config.Architecture = "64"
+	config.TargetDirectory = t.TempDir()
+
+	log, _ := loggertest.New("downloader")
+	upgradeDetails := details.NewDetails("9.1.0", details.StateRequested, "")
+	downloader, err := NewDownloader(log, config, cache, verify, upgradeDetails)
+	require.NoError(t, err)
+	return downloader
+}
+
+func TestDownload(t *testing.T) {
+	servingCache := newTestCache(t)
+	require.NoError(t, servingCache.Add("9.1.0", writePackage(t, testPackage, "package")))
+	server := httptest.NewServer(servingCache)
+	defer server.Close()
+
+	emptyCache := newTestCache(t)
+	emptyServer := httptest.NewServer(emptyCache)
+	defer emptyServer.Close()
+
+	downloader := newTestDownloader(t, newTestCache(t, emptyServer.URL, server.URL))
+	artifactPath, err := downloader.Download(context.Background(), agentSpec, testVersion)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(downloader.config.TargetDirectory, testPackage), artifactPath)
+
+	content, err := os.ReadFile(artifactPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package", string(content))
+	assert.FileExists(t, artifactPath+".sha512")
+	assert.NoFileExists(t, artifactPath+".asc", "sidecar files not cached by the peer are not downloaded")
+	assert.Equal(t, 1.0, downloader.upgradeDetails.Metadata.DownloadPercent)
+}
+
+func TestDownloadErrors(t *testing.T) {
+	t.Run("no peer", func(t *testing.T) {
+		downloader := newTestDownloader(t, newTestCache(t))
+		_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+		assert.ErrorContains(t, err, "no peer cache known")
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		servingCache := newTestCache(t)
+		servingCache.config.Token = "other-site-secret"
+		require.NoError(t, servingCache.Add("9.1.0", writePackage(t, testPackage, "package")))
+		server := httptest.NewServer(servingCache)
+		defer server.Close()
+
+		downloader := newTestDownloader(t, newTestCache(t, server.URL))
+		_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+		assert.ErrorContains(t, err, "status code: 401")
+		assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+	})
+
+	t.Run("tampered package", func(t *testing.T) {
+		servingCache := newTestCache(t)
+		require.NoError(t, servingCache.Add("9.1.0", writePackage(t, testPackage, "tampered package")))
+		server := httptest.NewServer(servingCache)
+		defer server.Close()
+
+		downloader := newTestVerifyingDownloader(t, newTestCache(t, server.URL), func(_ context.Context, _ artifact.Artifact, _ agtversion.ParsedSemVer) error {
+			return errors.New("checksum mismatch")
+		})
+		_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+		assert.ErrorContains(t, err, "checksum mismatch")
+		assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+		assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage+".sha512"))
+	})
+
+	t.Run("sidecar too large", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, ".sha512") {
+				_, _ = w.Write(bytes.Repeat([]byte("a"), maxSidecarSize+1))
+				return
+			}
+			if r.URL.Path == artifactsPath+testPackage {
+				_, _ = w.Write([]byte("package"))
+				return
+			}
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+
+		downloader := newTestDownloader(t, newTestCache(t, server.URL))
+		_, err := downloader.Download(context.Background(), agentSpec, testVersion)
+		assert.ErrorContains(t, err, "bytes allowed")
+		assert.NoFileExists(t, filepath.Join(downloader.config.TargetDirectory, testPackage))
+	})
+}
@@ -293,10 +293,10 @@ func PgpBytesFromSource(log warnLogger, source string, client HTTPClient) ([]byt
 	if strings.HasPrefix(source, PgpSourceURIPrefix) {
 		pgpBytes, err := fetchPgpFromURI(strings.TrimPrefix(source, PgpSourceURIPrefix), client)
 		if errors.Is(err, ErrRemotePGPDownloadFailed) || errors.Is(err, ErrInvalidLocation) {
-			log.Warnf("Skipped remote PGP located at %q because it's unavailable: %v", strings.TrimPrefix(source, PgpSourceURIPrefix), err)
+			log.Warnf("Skipped remote PGP located at %q because it's unavailable: %v", artifact.RedactedSourceURI(strings.TrimPrefix(source, PgpSourceURIPrefix)), err)
 		} else if err != nil {
 			log.Warnf("Failed to fetch remote PGP key from %q: %v",
-				strings.TrimPrefix(source, PgpSourceURIPrefix), err)
+				artifact.RedactedSourceURI(strings.TrimPrefix(source, PgpSourceURIPrefix)), err)
 		}
 
 		return pgpBytes, nil
@@ -312,7 +312,7 @@ func CheckValidDownloadUri(rawURI string) error {
 	}
 
 	if !strings.EqualFold(uri.Scheme, "https") {
-		return fmt.Errorf("failed to check URI %q: HTTPS is required: %w", rawURI, ErrInvalidLocation)
+		return fmt.Errorf("failed to check URI %q: HTTPS is required: %w", uri.Redacted(), ErrInvalidLocation)
 	}
 
 	return nil
@@ -337,7 +337,8 @@ func fetchPgpFromURI(uri string, client HTTPClient) ([]byte, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", uri, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, uri))
+		redactedURI := artifact.RedactedSourceURI(uri)
+		return nil, errors.New(fmt.Sprintf("call to '%s' returned unsuccessful status code: %d", redactedURI, resp.StatusCode), errors.TypeNetwork, errors.M(errors.MetaKeyURI, redactedURI))
 	}
 
 	return io.ReadAll(resp.Body)
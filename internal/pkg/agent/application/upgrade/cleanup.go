@@ -12,13 +12,15 @@ import (
 	"strings"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/http"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 // cleanNonMatchingVersionsFromDownloads will remove files that do not have the passed version number from the downloads directory.
-func cleanNonMatchingVersionsFromDownloads(log *logger.Logger, version string) error {
+// The partial downloads of `targetVersion` are kept, so that downloading it again resumes them.
+func cleanNonMatchingVersionsFromDownloads(log *logger.Logger, version string, targetVersion string) error {
 	downloadsPath := paths.Downloads()
-	log.Infow("Cleaning up non-matching downloaded versions", "version", version, "downloads.path", downloadsPath)
+	log.Infow("Cleaning up non-matching downloaded versions", "version", version, "target_version", targetVersion, "downloads.path", downloadsPath)
 
 	files, err := os.ReadDir(downloadsPath)
 	if os.IsNotExist(err) {
@@ -34,7 +36,7 @@ func cleanNonMatchingVersionsFromDownloads(log *logger.Logger, version string) e
 		if file.IsDir() {
 			continue
 		}
-		if !strings.Contains(file.Name(), version) {
+		if !strings.Contains(file.Name(), version) && !isPartialDownload(file.Name(), targetVersion) {
 			if err := os.Remove(filepath.Join(paths.Downloads(), file.Name())); err != nil {
 				errs = append(errs, fmt.Errorf("unable to remove file %q: %w", filepath.Join(paths.Downloads(), file.Name()), err))
 			}
@@ -42,3 +44,11 @@ func cleanNonMatchingVersionsFromDownloads(log *logger.Logger, version string) e
 	}
 	return errors.Join(errs...)
 }
+
+// isPartialDownload returns true if the file is the partial download, or its validator, of a package of the version.
+func isPartialDownload(name string, version string) bool {
+	if version == "" || !strings.Contains(name, version) {
+		return false
+	}
+	return strings.HasSuffix(name, http.PartialDownloadSuffix) || strings.HasSuffix(name, http.PartialValidatorSuffix)
+}
@@ -5,13 +5,27 @@
 package upgrade
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	httpdownloader "github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/http"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
 
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +48,7 @@ func setupDir(t *testing.T) {
 func TestPreUpgradeCleanup(t *testing.T) {
 	setupDir(t)
 	log := newErrorLogger(t)
-	err := cleanNonMatchingVersionsFromDownloads(log, "8.4.0")
+	err := cleanNonMatchingVersionsFromDownloads(log, "8.4.0", "")
 	require.NoError(t, err)
 
 	files, err := os.ReadDir(paths.Downloads())
@@ -46,6 +60,122 @@ func TestPreUpgradeCleanup(t *testing.T) {
 	require.Equal(t, []byte("hello, world!"), p)
 }
 
+func TestPreUpgradeCleanupKeepsPartialDownloads(t *testing.T) {
+	setupDir(t)
+	for _, name := range []string{
+		"elastic-agent-8.5.0-linux-x86_64.tar.gz.part",
+		"elastic-agent-8.5.0-linux-x86_64.tar.gz.part.validator",
+		"elastic-agent-8.3.0-linux-x86_64.tar.gz.part",
+		"elastic-agent-8.3.0-linux-x86_64.tar.gz.part.validator",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(paths.Downloads(), name), []byte("partial"), 0600))
+	}
+	log := newErrorLogger(t)
+	err := cleanNonMatchingVersionsFromDownloads(log, "8.4.0", "8.5.0")
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(paths.Downloads())
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	require.ElementsMatch(t, []string{
+		"test-8.4.0-file",
+		"elastic-agent-8.5.0-linux-x86_64.tar.gz.part",
+		"elastic-agent-8.5.0-linux-x86_64.tar.gz.part.validator",
+	}, names)
+}
+
+func TestUpgradeRetryResumesPartialDownload(t *testing.T) {
+	paths.SetDownloads(t.TempDir())
+	const version = "9.9.9"
+	content := bytes.Repeat([]byte("package"), 1024)
+	half := len(content) / 2
+
+	var mx sync.Mutex
+	failing := true
+	var ranges []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".sha512") {
+			_, _ = w.Write([]byte("hash"))
+			return
+		}
+
+		mx.Lock()
+		defer mx.Unlock()
+		w.Header().Set("ETag", `"package"`)
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(content[:half])
+			if failing {
+				// the connection drops in the middle of the package
+				panic(http.ErrAbortHandler)
+			}
+			_, _ = w.Write(content[half:])
+			return
+		}
+
+		ranges = append(ranges, rangeHeader)
+		var start int
+		_, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start)
+		if err != nil || start > len(content) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		w.Header().Set("Content-Length", fmt.Sprint(len(content)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		if failing {
+			panic(http.ErrAbortHandler)
+		}
+		_, _ = w.Write(content[start:])
+	}))
+	defer srv.Close()
+
+	settings := artifact.Config{
+		SourceURI:              srv.URL,
+		TargetDirectory:        paths.Downloads(),
+		RetrySleepInitDuration: 20 * time.Millisecond,
+		HTTPTransportSettings: httpcommon.HTTPTransportSettings{
+			Timeout: 500 * time.Millisecond,
+		},
+	}
+	log := newErrorLogger(t)
+	u, err := NewUpgrader(log, &settings, &info.AgentInfo{})
+	require.NoError(t, err)
+
+	_, err = u.Upgrade(context.Background(), version, srv.URL, nil, details.NewDetails(version, details.StateRequested, ""), true, false, nil)
+	require.ErrorContains(t, err, "failed download of agent binary")
+
+	// the cleanup after the failed upgrade keeps the partial download of the target version
+	packagePath, err := artifact.GetArtifactPath(agentArtifact, *agtversion.NewParsedSemVer(9, 9, 9, "", ""), settings.OS(), settings.Arch(), paths.Downloads())
+	require.NoError(t, err)
+	partial, err := os.ReadFile(packagePath + httpdownloader.PartialDownloadSuffix)
+	require.NoError(t, err)
+	require.Equal(t, content[:half], partial)
+	require.FileExists(t, packagePath+httpdownloader.PartialValidatorSuffix)
+
+	mx.Lock()
+	failing = false
+	ranges = nil
+	mx.Unlock()
+
+	// the retry resumes the partial download, it then fails on the package which is not a real one
+	_, err = u.Upgrade(context.Background(), version, srv.URL, nil, details.NewDetails(version, details.StateRequested, ""), true, false, nil)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "failed download of agent binary")
+
+	mx.Lock()
+	require.Equal(t, []string{fmt.Sprintf("bytes=%d-", half)}, ranges)
+	mx.Unlock()
+	downloaded, err := os.ReadFile(packagePath)
+	require.NoError(t, err)
+	require.Equal(t, content, downloaded)
+}
+
 func newErrorLogger(t *testing.T) *logger.Logger {
 	t.Helper()
 
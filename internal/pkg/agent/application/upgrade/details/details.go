@@ -59,6 +59,9 @@ type Metadata struct {
 	// Reason is a string that may give out more information about transitioning to the current state. It has been
 	// introduced initially to distinguish between manual and automatic rollbacks
 	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+
+	// FailedGate is the name of the health gate that failed if the upgrade was rolled back because of it.
+	FailedGate string `json:"failed_gate,omitempty" yaml:"failed_gate,omitempty"`
 }
 
 func NewDetails(targetVersion string, initialState State, actionID string) *Details {
@@ -112,6 +115,35 @@ func (d *Details) SetStateWithReason(s State, reason string) {
 	d.notifyObservers()
 }
 
+// SetStateWithFailedGate is a convenience method to set the state of the upgrade, the metadata.reason to
+// ReasonHealthGateFailed, the metadata.failed_gate and notify all observers.
+// Do NOT call SetStateWithFailedGate with StateFailed; call the Fail method instead.
+func (d *Details) SetStateWithFailedGate(s State, gate string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.State = s
+	d.Metadata.Reason = ReasonHealthGateFailed
+	d.Metadata.FailedGate = gate
+
+	if s != StateFailed {
+		d.Metadata.ErrorMsg = ""
+		d.Metadata.FailedState = ""
+	}
+
+	d.notifyObservers()
+}
+
+// SetTargetVersion sets the version the agent is upgraded to, for upgrades that only know it once
+// started, and notifies all observers.
+func (d *Details) SetTargetVersion(version string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.TargetVersion = version
+	d.notifyObservers()
+}
+
 // SetDownloadProgress is a convenience method to set the download percent
 // and download rate when the upgrade is in UPG_DOWNLOADING state.
 func (d *Details) SetDownloadProgress(percent, rateBytesPerSecond float64) {
@@ -231,7 +263,8 @@ func (m Metadata) Equals(otherM Metadata) bool {
 		m.DownloadPercent == otherM.DownloadPercent &&
 		m.DownloadRate == otherM.DownloadRate &&
 		equalTimePointers(m.RetryUntil, otherM.RetryUntil) &&
-		m.RetryErrorMsg == otherM.RetryErrorMsg
+		m.RetryErrorMsg == otherM.RetryErrorMsg &&
+		m.FailedGate == otherM.FailedGate
 }
 
 func equalTimePointers(t, otherT *time.Time) bool {
@@ -40,6 +40,31 @@ func TestDetailsSetStateWithReason(t *testing.T) {
 	assert.Equal(t, ReasonWatchFailed, det.Metadata.Reason)
 }
 
+func TestDetailsSetStateWithFailedGate(t *testing.T) {
+	det := NewDetails("99.999.9999", StateWatching, "test_action_id")
+	require.Equal(t, StateWatching, det.State)
+
+	det.SetStateWithFailedGate(StateRollback, "min_healthy_components")
+	assert.Equal(t, StateRollback, det.State)
+	assert.Equal(t, ReasonHealthGateFailed, det.Metadata.Reason)
+	assert.Equal(t, "min_healthy_components", det.Metadata.FailedGate)
+}
+
+func TestDetailsSetTargetVersion(t *testing.T) {
+	det := NewDetails("", StateRequested, "test_action_id")
+
+	var observed *Details
+	det.RegisterObserver(func(d *Details) {
+		observed = d
+	})
+	require.NotNil(t, observed)
+	require.Empty(t, observed.TargetVersion)
+
+	det.SetTargetVersion("99.999.9999")
+	assert.Equal(t, "99.999.9999", det.TargetVersion)
+	assert.Equal(t, "99.999.9999", observed.TargetVersion)
+}
+
 func TestDetailsFail(t *testing.T) {
 	det := NewDetails("99.999.9999", StateRequested, "test_action_id")
 	require.Equal(t, StateRequested, det.State)
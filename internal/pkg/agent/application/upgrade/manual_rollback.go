@@ -0,0 +1,280 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reexec"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	"github.com/elastic/elastic-agent/pkg/utils"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+	currentagtversion "github.com/elastic/elastic-agent/version"
+)
+
+const availableRollbackFilename = ".available-rollback"
+
+var (
+	ErrNoRollbackAvailable   = errors.New("no previous version available to rollback to")
+	ErrRollbackWindowExpired = errors.New("rollback window of the previous version has expired")
+	ErrWatcherRunning        = errors.New("upgrade watcher is running, rollback is possible once it has finished")
+)
+
+// AvailableRollback is the previous version of the agent kept on disk after a successful upgrade, the agent can
+// be rolled back to it until ValidUntil.
+type AvailableRollback struct {
+	// Version is the version of the previous agent
+	Version string `json:"version" yaml:"version"`
+	// Hash is the hash of the previous agent
+	Hash string `json:"hash" yaml:"hash"`
+	// VersionedHome represents the path where the previous agent is located relative to top path
+	VersionedHome string `json:"versioned_home" yaml:"versioned_home"`
+	// ValidUntil marks a date when the previous agent is removed
+	ValidUntil time.Time `json:"valid_until" yaml:"valid_until"`
+}
+
+// versionedHome returns the path of the previous agent relative to top path, including for agents that
+// didn't use the manifest and path remapping.
+func (r *AvailableRollback) versionedHome() string {
+	if r.VersionedHome != "" {
+		return r.VersionedHome
+	}
+	return filepath.Join("data", fmt.Sprintf("%s-%s", agentName, r.Hash))
+}
+
+func availableRollbackFilePath(dataDirPath string) string {
+	return filepath.Join(dataDirPath, availableRollbackFilename)
+}
+
+// SaveAvailableRollback persists the previous version the agent can be rolled back to.
+func SaveAvailableRollback(dataDirPath string, rollback *AvailableRollback) error {
+	rollbackBytes, err := yaml.Marshal(rollback)
+	if err != nil {
+		return errors.New(err, errors.TypeConfig, "failed to parse available rollback file")
+	}
+
+	rollbackPath := availableRollbackFilePath(dataDirPath)
+	if err := os.WriteFile(rollbackPath, rollbackBytes, 0600); err != nil {
+		return errors.New(err, errors.TypeFilesystem, "failed to create available rollback file", errors.M(errors.MetaKeyPath, rollbackPath))
+	}
+	return nil
+}
+
+// LoadAvailableRollback loads the previous version the agent can be rolled back to. If the file does not exist it
+// returns nil and no error.
+func LoadAvailableRollback(dataDirPath string) (*AvailableRollback, error) {
+	rollbackBytes, err := os.ReadFile(availableRollbackFilePath(dataDirPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := &AvailableRollback{}
+	if err := yaml.Unmarshal(rollbackBytes, rollback); err != nil {
+		return nil, err
+	}
+	return rollback, nil
+}
+
+// CleanAvailableRollback removes the available rollback from disk, the previous version is then removed by the
+// next cleanup.
+func CleanAvailableRollback(log *logger.Logger, dataDirPath string) error {
+	rollbackFile := availableRollbackFilePath(dataDirPath)
+	log.Infow("Removing available rollback file", "file.path", rollbackFile)
+	if err := os.Remove(rollbackFile); !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveExpiredRollback removes the previous version kept on disk once its rollback window has expired.
+func RemoveExpiredRollback(log *logger.Logger, topDirPath string, now time.Time) error {
+	dataDirPath := paths.DataFrom(topDirPath)
+	rollback, err := LoadAvailableRollback(dataDirPath)
+	if err != nil {
+		return fmt.Errorf("failed to load available rollback: %w", err)
+	}
+	if rollback == nil || now.Before(rollback.ValidUntil) {
+		return nil
+	}
+
+	rollbackHome := filepath.Join(topDirPath, rollback.versionedHome())
+	log.Infow("Removing previous version, rollback window expired", "file.path", rollbackHome, "valid_until", rollback.ValidUntil)
+	if err := install.RemoveBut(rollbackHome, true); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove previous version at %q: %w", rollbackHome, err)
+	}
+	return CleanAvailableRollback(log, dataDirPath)
+}
+
+// WatchRollbackExpiry removes the previous version kept on disk once its rollback window has expired, it checks
+// right away and then every `interval` until `ctx` is cancelled. The check is skipped while `busy` returns true,
+// e.g. while the running agent is upgrading or rolling back. The Upgrade Watcher only removes it when it is
+// started again, so the running agent keeps checking.
+func WatchRollbackExpiry(ctx context.Context, log *logger.Logger, topDirPath string, interval time.Duration, busy func() bool) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if !busy() {
+			if err := RemoveExpiredRollback(log, topDirPath, time.Now()); err != nil {
+				log.Errorw("Removing expired rollback failed", "error.message", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Rollback rolls back the running agent to the previous version kept on disk after the last successful upgrade,
+// the rollback is then watched like an upgrade. The function returns shutdown callback that must be called by reexec.
+func (u *Upgrader) Rollback(ctx context.Context, action *fleetapi.ActionRollback, det *details.Details) (_ reexec.ShutdownCallbackFn, err error) {
+	watcherPIDs, err := utils.GetWatcherPIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine if upgrade watcher is running: %w", err)
+	}
+	if len(watcherPIDs) > 0 {
+		return nil, ErrWatcherRunning
+	}
+
+	rollback, err := LoadAvailableRollback(paths.Data())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load available rollback: %w", err)
+	}
+	if rollback == nil {
+		return nil, ErrNoRollbackAvailable
+	}
+	if !time.Now().Before(rollback.ValidUntil) {
+		return nil, ErrRollbackWindowExpired
+	}
+
+	rollbackHome := rollback.versionedHome()
+	newHome := filepath.Join(paths.Top(), rollbackHome)
+	if _, err := os.Stat(newHome); err != nil {
+		return nil, fmt.Errorf("previous version %s is not available at %q: %w", rollback.Version, newHome, err)
+	}
+
+	parsedVersion, err := agtversion.ParseVersion(rollback.Version)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing version %q: %w", rollback.Version, err)
+	}
+
+	u.log.Infow("Rolling back agent", "version", rollback.Version, "versioned_home", rollbackHome)
+	det.SetTargetVersion(rollback.Version)
+
+	// see Upgrade
+	u.markerWatcher.SetUpgradeStarted()
+
+	if err := copyActionStore(u.log, newHome); err != nil {
+		return nil, errors.New(err, "failed to copy action store")
+	}
+
+	if err := copyRunDirectory(u.log, paths.Run(), filepath.Join(newHome, "run")); err != nil {
+		return nil, errors.New(err, "failed to copy run directory")
+	}
+
+	det.SetState(details.StateReplacing)
+
+	symlinkPath := filepath.Join(paths.Top(), agentName)
+	newPath := paths.BinaryPath(newHome, agentName)
+
+	currentVersionedHome, err := filepath.Rel(paths.Top(), paths.Home())
+	if err != nil {
+		return nil, fmt.Errorf("calculating home path relative to top, home: %q top: %q : %w", paths.Home(), paths.Top(), err)
+	}
+
+	// unlike rollbackInstall, the previous version is kept when failing as it can still be rolled back to
+	restoreSymlink := func() error {
+		oldAgentPath := paths.BinaryPath(filepath.Join(paths.Top(), currentVersionedHome), agentName)
+		if err := changeSymlink(u.log, paths.Top(), symlinkPath, oldAgentPath); err != nil {
+			return fmt.Errorf("restoring symlink to %q failed: %w", oldAgentPath, err)
+		}
+		return nil
+	}
+
+	if err := changeSymlink(u.log, paths.Top(), symlinkPath, newPath); err != nil {
+		u.log.Errorw("Rolling back: changing symlink failed", "error.message", err)
+		return nil, goerrors.Join(err, restoreSymlink())
+	}
+
+	// In update marker the `current` agent install is the previous version we're rolling back into, while the
+	// `previous` install is the currently executing elastic-agent, the watcher rolls back to it if the previous
+	// version fails.
+	current := agentInstall{
+		parsedVersion: parsedVersion,
+		version:       rollback.Version,
+		hash:          rollback.Hash,
+		versionedHome: rollbackHome,
+	}
+
+	previous := agentInstall{
+		parsedVersion: currentagtversion.GetParsedAgentPackageVersion(),
+		version:       release.VersionWithSnapshot(),
+		hash:          release.Commit(),
+		versionedHome: currentVersionedHome,
+	}
+
+	if err := markUpgrade(u.log,
+		paths.Data(), // data dir to place the marker in
+		current,      // previous agent version data
+		previous,     // running agent version data
+		rollbackMarkerAction(action, rollback.Version), det, OUTCOME_ROLLBACK); err != nil {
+		u.log.Errorw("Rolling back: marking rollback failed", "error.message", err)
+		return nil, goerrors.Join(err, restoreSymlink())
+	}
+
+	watcherExecutable := selectWatcherExecutable(paths.Top(), previous, current)
+
+	watcherCmd, err := InvokeWatcher(u.log, watcherExecutable)
+	if err != nil {
+		u.log.Errorw("Rolling back: starting watcher failed", "error.message", err)
+		return nil, goerrors.Join(err, restoreSymlink())
+	}
+
+	watcherWaitErr := waitForWatcher(ctx, u.log, markerFilePath(paths.Data()), watcherMaxWaitTime)
+	if watcherWaitErr != nil {
+		killWatcherErr := watcherCmd.Process.Kill()
+		return nil, goerrors.Join(watcherWaitErr, killWatcherErr, restoreSymlink())
+	}
+
+	// the version rolled back from is removed once the watcher is done, it cannot be rolled back to
+	if err := CleanAvailableRollback(u.log, paths.Data()); err != nil {
+		u.log.Errorw("Unable to remove available rollback file", "error.message", err)
+	}
+
+	return shutdownCallback(u.log, paths.Home(), release.Version(), rollback.Version, newHome), nil
+}
+
+// rollbackMarkerAction returns the rollback action as recorded in the update marker, it is acked with its own
+// ID and type once the agent restarts.
+func rollbackMarkerAction(action *fleetapi.ActionRollback, version string) *fleetapi.ActionUpgrade {
+	if action == nil {
+		return nil
+	}
+	return &fleetapi.ActionUpgrade{
+		ActionID:   action.ActionID,
+		ActionType: action.ActionType,
+		Data: fleetapi.ActionUpgradeData{
+			Version: version,
+		},
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+)
+
+func TestAvailableRollback(t *testing.T) {
+	dataDir := t.TempDir()
+
+	rollback, err := LoadAvailableRollback(dataDir)
+	require.NoError(t, err)
+	assert.Nil(t, rollback, "no available rollback when the file does not exist")
+
+	expected := &AvailableRollback{
+		Version:       "1.2.3",
+		Hash:          "abcdef",
+		VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-abcdef"),
+		ValidUntil:    time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+	require.NoError(t, SaveAvailableRollback(dataDir, expected))
+
+	rollback, err = LoadAvailableRollback(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, rollback)
+
+	log, _ := loggertest.New(t.Name())
+	require.NoError(t, CleanAvailableRollback(log, dataDir))
+	assert.NoFileExists(t, availableRollbackFilePath(dataDir))
+	// cleaning twice is a no-op
+	require.NoError(t, CleanAvailableRollback(log, dataDir))
+}
+
+func TestAvailableRollbackVersionedHome(t *testing.T) {
+	rollback := &AvailableRollback{Hash: "abcdef"}
+	assert.Equal(t, filepath.Join("data", "elastic-agent-abcdef"), rollback.versionedHome(), "legacy upgrades use the hash")
+
+	rollback.VersionedHome = filepath.Join("data", "elastic-agent-1.2.3-abcdef")
+	assert.Equal(t, rollback.VersionedHome, rollback.versionedHome())
+}
+
+func TestRemoveExpiredRollback(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	topDir := t.TempDir()
+	dataDir := paths.DataFrom(topDir)
+	rollbackHome := filepath.Join(topDir, "data", "elastic-agent-1.2.3-abcdef")
+	require.NoError(t, os.MkdirAll(filepath.Join(rollbackHome, "logs"), 0755))
+
+	validUntil := time.Now()
+	require.NoError(t, SaveAvailableRollback(dataDir, &AvailableRollback{
+		Version:       "1.2.3",
+		Hash:          "abcdef",
+		VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-abcdef"),
+		ValidUntil:    validUntil,
+	}))
+
+	require.NoError(t, RemoveExpiredRollback(log, topDir, validUntil.Add(-time.Minute)))
+	assert.DirExists(t, rollbackHome, "previous version is kept within the rollback window")
+	assert.FileExists(t, availableRollbackFilePath(dataDir))
+
+	require.NoError(t, RemoveExpiredRollback(log, topDir, validUntil))
+	assert.NoDirExists(t, rollbackHome, "previous version is removed once the rollback window expired")
+	assert.NoFileExists(t, availableRollbackFilePath(dataDir))
+
+	// nothing to remove
+	require.NoError(t, RemoveExpiredRollback(log, topDir, validUntil))
+}
+
+func TestWatchRollbackExpiry(t *testing.T) {
+	log, _ := loggertest.New(t.Name())
+	topDir := t.TempDir()
+	dataDir := paths.DataFrom(topDir)
+	rollbackHome := filepath.Join(topDir, "data", "elastic-agent-1.2.3-prvver")
+	require.NoError(t, os.MkdirAll(rollbackHome, 0o755))
+	require.NoError(t, SaveAvailableRollback(dataDir, &AvailableRollback{
+		Version:       "1.2.3",
+		Hash:          "prvver",
+		VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-prvver"),
+		ValidUntil:    time.Now().Add(100 * time.Millisecond),
+	}))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	var busy atomic.Bool
+	busy.Store(true)
+	go func() {
+		defer close(done)
+		WatchRollbackExpiry(ctx, log, topDir, 10*time.Millisecond, busy.Load)
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	assert.DirExists(t, rollbackHome, "previous version is kept while busy")
+
+	busy.Store(false)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(rollbackHome)
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond, "previous version is removed by the running agent once expired")
+	assert.NoFileExists(t, availableRollbackFilePath(dataDir))
+
+	cancel()
+	<-done
+}
+
+func TestRollbackMarkerAction(t *testing.T) {
+	assert.Nil(t, rollbackMarkerAction(nil, "1.2.3"))
+
+	action := rollbackMarkerAction(&fleetapi.ActionRollback{ActionID: "action-id", ActionType: fleetapi.ActionTypeRollback}, "1.2.3")
+	assert.Equal(t, "action-id", action.ActionID)
+	assert.Equal(t, fleetapi.ActionTypeRollback, action.Type())
+	assert.Equal(t, "1.2.3", action.Data.Version)
+}
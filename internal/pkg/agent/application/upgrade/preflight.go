@@ -0,0 +1,332 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/component"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+// PreflightStatus is the outcome of an upgrade preflight check.
+type PreflightStatus string
+
+const (
+	PreflightPassed  PreflightStatus = "passed"
+	PreflightFailed  PreflightStatus = "failed"
+	PreflightSkipped PreflightStatus = "skipped"
+)
+
+// Names of the upgrade preflight checks, in the order they run.
+const (
+	// PreflightCheckCapabilities checks the capabilities allow the upgrade.
+	PreflightCheckCapabilities = "capabilities"
+	// PreflightCheckArtifact downloads and verifies the package.
+	PreflightCheckArtifact = "artifact"
+	// PreflightCheckPackage checks the package can replace the running agent (version, FIPS).
+	PreflightCheckPackage = "package"
+	// PreflightCheckDiskSpace checks the free disk space in the top directory is enough to unpack the package.
+	PreflightCheckDiskSpace = "disk_space"
+	// PreflightCheckBinary checks the binary of the unpacked package runs.
+	PreflightCheckBinary = "binary"
+	// PreflightCheckComponents checks the components of the current policy are in the package.
+	PreflightCheckComponents = "components"
+)
+
+// preflightBinaryTimeout is the time the binary of the new version has to report its version.
+const preflightBinaryTimeout = 30 * time.Second
+
+var errPreviousCheckFailed = goerrors.New("a previous check failed")
+
+// PreflightRequirements are the requirements of the running agent the new version is checked against.
+type PreflightRequirements struct {
+	// UpgradeAllowed reports whether the capabilities allow the upgrade.
+	UpgradeAllowed bool
+	// InputTypes are the input types of the components of the current policy.
+	InputTypes []string
+}
+
+// PreflightCheck is the result of an upgrade preflight check.
+type PreflightCheck struct {
+	Name    string          `json:"name" yaml:"name"`
+	Status  PreflightStatus `json:"status" yaml:"status"`
+	Message string          `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// PreflightReport is the report of an upgrade dry-run.
+type PreflightReport struct {
+	Version string           `json:"version" yaml:"version"`
+	Checks  []PreflightCheck `json:"checks" yaml:"checks"`
+}
+
+// Passed returns true when none of the checks failed.
+func (r *PreflightReport) Passed() bool {
+	return r.Err() == nil
+}
+
+// Err returns the failures of the checks, nil when none failed.
+func (r *PreflightReport) Err() error {
+	var errs []error
+	for _, check := range r.Checks {
+		if check.Status == PreflightFailed {
+			errs = append(errs, fmt.Errorf("%s: %s", check.Name, check.Message))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("upgrade preflight checks failed: %w", goerrors.Join(errs...))
+}
+
+// run runs the check unless a previous check failed, every check depends on the previous ones.
+func (r *PreflightReport) run(name string, check func() (string, error)) {
+	if !r.Passed() {
+		r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: PreflightSkipped, Message: errPreviousCheckFailed.Error()})
+		return
+	}
+
+	msg, err := check()
+	if err != nil {
+		r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: PreflightFailed, Message: err.Error()})
+		return
+	}
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: PreflightPassed, Message: msg})
+}
+
+// Preflight runs the steps of an upgrade that do not switch versions and reports whether the upgrade would
+// succeed. The package is downloaded, verified and unpacked in temporary directories removed once checked,
+// the downloads of the upgrades are left untouched so a dry-run can run along an upgrade.
+func (u *Upgrader) Preflight(ctx context.Context, version string, sourceURI string, req PreflightRequirements, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*PreflightReport, error) {
+	u.log.Infow("Running upgrade preflight checks", "version", version, "source_uri", artifact.RedactedSourceURI(sourceURI))
+
+	parsedVersion, err := agtversion.ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing version %q: %w", version, err)
+	}
+
+	currentVersion := agentVersion{
+		version:  release.Version(),
+		snapshot: release.Snapshot(),
+		hash:     release.Commit(),
+		fips:     release.FIPSDistribution(),
+	}
+
+	downloadDir, err := os.MkdirTemp(paths.Data(), "upgrade-preflight-download-")
+	if err != nil {
+		return nil, fmt.Errorf("creating upgrade preflight download directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(downloadDir); err != nil {
+			u.log.Errorw("Unable to remove upgrade preflight download directory", "error.message", err, "file.path", downloadDir)
+		}
+	}()
+
+	report := &PreflightReport{Version: version}
+
+	report.run(PreflightCheckCapabilities, func() (string, error) {
+		if !req.UpgradeAllowed {
+			return "", goerrors.New("upgrade is not allowed by the capabilities")
+		}
+		return "", nil
+	})
+
+	var archivePath string
+	report.run(PreflightCheckArtifact, func() (string, error) {
+		if isSameReleaseVersion(u.log, currentVersion, version) {
+			return "", ErrUpgradeSameVersion
+		}
+
+		// progress of the download is not reported, the agent is not upgrading
+		det := details.NewDetails(version, details.StateDownloading, "")
+		path, err := u.downloadArtifact(ctx, parsedVersion, u.sourceURI(sourceURI), downloadDir, det, skipVerifyOverride, skipDefaultPgp, cosignKeys, pgpBytes...)
+		if err != nil {
+			return "", err
+		}
+		archivePath = path
+		if skipVerifyOverride {
+			return fmt.Sprintf("%s downloaded, verification skipped", filepath.Base(archivePath)), nil
+		}
+		return fmt.Sprintf("%s downloaded and verified", filepath.Base(archivePath)), nil
+	})
+
+	report.run(PreflightCheckPackage, func() (string, error) {
+		metadata, err := u.getPackageMetadata(archivePath)
+		if err != nil {
+			return "", fmt.Errorf("reading package metadata: %w", err)
+		}
+		newVersion := extractAgentVersion(metadata, version)
+		if err := checkUpgrade(u.log, currentVersion, newVersion, metadata); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("upgrade from %s to %s", currentVersion, newVersion), nil
+	})
+
+	report.run(PreflightCheckDiskSpace, func() (string, error) {
+		return checkDiskSpace(paths.Top(), archivePath)
+	})
+
+	unpackDir, err := os.MkdirTemp(paths.Data(), "upgrade-preflight-")
+	if err != nil {
+		return nil, fmt.Errorf("creating upgrade preflight directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(unpackDir); err != nil {
+			u.log.Errorw("Unable to remove upgrade preflight directory", "error.message", err, "file.path", unpackDir)
+		}
+	}()
+
+	var newHome string
+	report.run(PreflightCheckBinary, func() (string, error) {
+		detectedFlavor, err := install.UsedFlavor(paths.Top(), "")
+		if err != nil {
+			u.log.Warnf("error encountered when detecting used flavor with top path %q: %v", paths.Top(), err)
+		}
+		// the temporary directory stands for the top directory, the package is unpacked in its data directory
+		unpackRes, err := u.unpack(version, archivePath, filepath.Join(unpackDir, "data"), detectedFlavor)
+		if err != nil {
+			return "", err
+		}
+		newHome = filepath.Join(unpackDir, unpackRes.VersionedHome)
+		return checkBinary(ctx, paths.BinaryPath(newHome, agentName), parsedVersion)
+	})
+
+	report.run(PreflightCheckComponents, func() (string, error) {
+		return checkComponents(filepath.Join(newHome, "components"), req.InputTypes)
+	})
+
+	if err := report.Err(); err != nil {
+		u.log.Warnw("Upgrade preflight checks failed", "version", version, "error.message", err)
+	} else {
+		u.log.Infow("Upgrade preflight checks passed", "version", version)
+	}
+	return report, nil
+}
+
+// checkDiskSpace checks the free disk space of the filesystem of topDir is enough to unpack the package.
+func checkDiskSpace(topDir string, archivePath string) (string, error) {
+	required, err := unpackedSize(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("computing unpacked package size: %w", err)
+	}
+	available, err := diskFree(topDir)
+	if err != nil {
+		return "", fmt.Errorf("reading free disk space of %s: %w", topDir, err)
+	}
+
+	msg := fmt.Sprintf("%d MiB available in %s, %d MiB required", available>>20, topDir, required>>20)
+	if available < required {
+		return "", goerrors.New(msg)
+	}
+	return msg, nil
+}
+
+// unpackedSize returns the size of the files of the package.
+func unpackedSize(archivePath string) (uint64, error) {
+	if strings.HasSuffix(archivePath, ".zip") {
+		r, err := zip.OpenReader(archivePath)
+		if err != nil {
+			return 0, err
+		}
+		defer r.Close()
+
+		var size uint64
+		for _, f := range r.File {
+			size += f.UncompressedSize64
+		}
+		return size, nil
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, err
+	}
+	tr := tar.NewReader(zr)
+
+	var size uint64
+	for {
+		hdr, err := tr.Next()
+		if goerrors.Is(err, io.EOF) {
+			return size, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			size += uint64(hdr.Size) //nolint:gosec // size of a regular file is never negative
+		}
+	}
+}
+
+// checkBinary checks the binary reports the expected version.
+func checkBinary(ctx context.Context, binaryPath string, version *agtversion.ParsedSemVer) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, preflightBinaryTimeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, binaryPath, "version", "--binary-only")
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("running %s version: %w: %s", binaryPath, err, strings.TrimSpace(out.String()))
+	}
+
+	output := strings.TrimSpace(out.String())
+	if !strings.Contains(output, version.CoreVersion()) {
+		return "", fmt.Errorf("%s reported an unexpected version: %s", binaryPath, output)
+	}
+	return output, nil
+}
+
+// checkComponents checks the package provides the input types on this platform.
+func checkComponents(componentsDir string, inputTypes []string) (string, error) {
+	platform, err := component.LoadPlatformDetail()
+	if err != nil {
+		return "", fmt.Errorf("detecting platform: %w", err)
+	}
+	specs, err := component.LoadRuntimeSpecs(componentsDir, platform)
+	if err != nil {
+		return "", fmt.Errorf("loading component specifications of the package: %w", err)
+	}
+
+	inputTypes = slices.Clone(inputTypes)
+	slices.Sort(inputTypes)
+	inputTypes = slices.Compact(inputTypes)
+
+	var missing []string
+	for _, inputType := range inputTypes {
+		if _, err := specs.GetInput(inputType); err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%v)", inputType, err))
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("inputs of the current policy are not supported by the package: %s", strings.Join(missing, ", "))
+	}
+	return fmt.Sprintf("%d inputs of the current policy are supported by the package", len(inputTypes)), nil
+}
@@ -0,0 +1,166 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	agtversion "github.com/elastic/elastic-agent/pkg/version"
+)
+
+func TestPreflightReport(t *testing.T) {
+	report := &PreflightReport{Version: "9.1.0"}
+	report.run(PreflightCheckCapabilities, func() (string, error) { return "", nil })
+	require.True(t, report.Passed())
+	require.NoError(t, report.Err())
+
+	report.run(PreflightCheckArtifact, func() (string, error) { return "", errors.New("signature mismatch") })
+	report.run(PreflightCheckPackage, func() (string, error) {
+		t.Error("checks after a failed check should not run")
+		return "", nil
+	})
+
+	assert.False(t, report.Passed())
+	assert.ErrorContains(t, report.Err(), "artifact: signature mismatch")
+	assert.Equal(t, []PreflightCheck{
+		{Name: PreflightCheckCapabilities, Status: PreflightPassed},
+		{Name: PreflightCheckArtifact, Status: PreflightFailed, Message: "signature mismatch"},
+		{Name: PreflightCheckPackage, Status: PreflightSkipped, Message: errPreviousCheckFailed.Error()},
+	}, report.Checks)
+}
+
+func TestPreflightLeavesUpgradeDownloads(t *testing.T) {
+	top := paths.Top()
+	paths.SetTop(t.TempDir())
+	t.Cleanup(func() { paths.SetTop(top) })
+	require.NoError(t, os.MkdirAll(paths.Data(), 0o750))
+	setupDir(t)
+	upgradeDownload := "elastic-agent-9.8.0-linux-x86_64.tar.gz.part"
+	require.NoError(t, os.WriteFile(filepath.Join(paths.Downloads(), upgradeDownload), []byte("partial"), 0o600))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not a package"))
+	}))
+	defer srv.Close()
+
+	settings := artifact.Config{
+		SourceURI:       srv.URL,
+		TargetDirectory: paths.Downloads(),
+		HTTPTransportSettings: httpcommon.HTTPTransportSettings{
+			Timeout: 5 * time.Second,
+		},
+	}
+	u, err := NewUpgrader(newErrorLogger(t), &settings, &info.AgentInfo{})
+	require.NoError(t, err)
+
+	report, err := u.Preflight(context.Background(), "9.9.9", srv.URL, PreflightRequirements{UpgradeAllowed: true}, true, false, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 6)
+	assert.Equal(t, PreflightPassed, report.Checks[1].Status, "the package should be downloaded: %s", report.Checks[1].Message)
+	assert.Equal(t, PreflightFailed, report.Checks[2].Status, "the package should not be a valid one")
+
+	// the downloads of the upgrades are untouched, the package is downloaded in a directory removed afterwards
+	files, err := os.ReadDir(paths.Downloads())
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	assert.ElementsMatch(t, []string{"test-8.3.0-file", "test-8.4.0-file", "test-8.5.0-file", "test-hash-file", upgradeDownload}, names)
+
+	files, err = os.ReadDir(paths.Data())
+	require.NoError(t, err)
+	assert.Empty(t, files, "the preflight directories should be removed")
+}
+
+func TestUnpackedSize(t *testing.T) {
+	var expected uint64
+	for _, f := range archiveFilesWithMoreComponents {
+		if f.fType == REGULAR {
+			expected += uint64(len(f.content))
+		}
+	}
+
+	tarPath, err := createTarArchive(t, "elastic-agent-1.2.3-SNAPSHOT-someos-x86_64.tar.gz", archiveFilesWithMoreComponents)
+	require.NoError(t, err)
+	size, err := unpackedSize(tarPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, size)
+
+	zipPath, err := createZipArchive(t, "elastic-agent-1.2.3-SNAPSHOT-someos-x86_64.zip", archiveFilesWithMoreComponents)
+	require.NoError(t, err)
+	size, err = unpackedSize(zipPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, size)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	archivePath, err := createTarArchive(t, "elastic-agent-1.2.3-SNAPSHOT-someos-x86_64.tar.gz", archiveFilesWithMoreComponents)
+	require.NoError(t, err)
+
+	msg, err := checkDiskSpace(t.TempDir(), archivePath)
+	require.NoError(t, err)
+	assert.Contains(t, msg, "MiB available")
+}
+
+func TestCheckBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake binary is a shell script")
+	}
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+
+	writeBinary := func(t *testing.T, script string) string {
+		binaryPath := filepath.Join(t.TempDir(), agentName)
+		require.NoError(t, os.WriteFile(binaryPath, []byte("#!/bin/sh\n"+script+"\n"), 0o750))
+		return binaryPath
+	}
+
+	t.Run("expected version", func(t *testing.T) {
+		msg, err := checkBinary(context.Background(), writeBinary(t, `echo "Binary: 9.1.0 (build: abcdef at 2025-06-30 00:00:00 +0000 UTC)"`), version)
+		require.NoError(t, err)
+		assert.Contains(t, msg, "Binary: 9.1.0")
+	})
+
+	t.Run("unexpected version", func(t *testing.T) {
+		_, err := checkBinary(context.Background(), writeBinary(t, `echo "Binary: 9.0.0"`), version)
+		assert.ErrorContains(t, err, "unexpected version")
+	})
+
+	t.Run("binary fails", func(t *testing.T) {
+		_, err := checkBinary(context.Background(), writeBinary(t, `echo "exec format error"; exit 1`), version)
+		assert.ErrorContains(t, err, "exec format error")
+	})
+}
+
+func TestCheckComponents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test component specification does not support windows")
+	}
+	componentsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(componentsDir, "comp1"), []byte("Placeholder for component"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(componentsDir, "comp1.spec.yml"), []byte(foo_component_spec), 0o640))
+
+	msg, err := checkComponents(componentsDir, []string{"foobar", "foobar"})
+	require.NoError(t, err)
+	assert.Equal(t, "1 inputs of the current policy are supported by the package", msg)
+
+	_, err = checkComponents(componentsDir, []string{"foobar", "filestream"})
+	assert.ErrorContains(t, err, "filestream")
+}
@@ -118,9 +118,22 @@ func cleanup(log *logger.Logger, topDirPath, currentVersionedHome, currentHash s
 		currentDir = fmt.Sprintf("%s-%s", agentName, currentHash)
 	}
 
+	// keep the previous version while the agent can be rolled back to it
+	var rollbackDir string
+	rollback, err := LoadAvailableRollback(dataDirPath)
+	if err != nil {
+		log.Warnw("Failed to load available rollback, previous version is not kept", "error.message", err)
+	} else if rollback != nil {
+		if time.Now().Before(rollback.ValidUntil) {
+			rollbackDir = filepath.Base(rollback.versionedHome())
+		} else if err := CleanAvailableRollback(log, dataDirPath); err != nil {
+			log.Warnw("Failed to remove expired available rollback", "error.message", err)
+		}
+	}
+
 	var errs []error
 	for _, dir := range subdirs {
-		if dir == currentDir {
+		if dir == currentDir || dir == rollbackDir {
 			continue
 		}
 
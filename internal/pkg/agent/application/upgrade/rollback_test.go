@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -202,6 +203,91 @@ func TestCleanup(t *testing.T) {
 				checkFilesAfterCleanup(t, topDir, newAgentHome, oldAgentHomes...)
 			},
 		},
+		"cleanup keeps the previous version within its rollback window": {
+			args: args{
+				currentVersionedHome: "data/elastic-agent-4.5.6-SNAPSHOT-ghijkl",
+				currentHash:          "ghijkl",
+				removeMarker:         true,
+				keepLogs:             false,
+			},
+			agentInstallsSetup: setupAgentInstallations{
+				installedAgents: []testAgentInstall{
+					{
+						version: testAgentVersion{
+							version: "1.1.1",
+							hash:    "aaabbb",
+						},
+						useVersionInPath: false,
+					},
+					{
+						version:          version123Snapshot,
+						useVersionInPath: true,
+					},
+					{
+						version:          version456Snapshot,
+						useVersionInPath: true,
+					},
+				},
+				upgradeFrom:  version123Snapshot,
+				upgradeTo:    version456Snapshot,
+				currentAgent: version456Snapshot,
+			},
+			additionalSetup: func(t *testing.T, topDir string) {
+				err := SaveAvailableRollback(paths.DataFrom(topDir), &AvailableRollback{
+					Version:       version123Snapshot.version,
+					Hash:          version123Snapshot.hash,
+					VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-SNAPSHOT-abcdef"),
+					ValidUntil:    time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+			},
+			wantErr: assert.NoError,
+			checkAfterCleanup: func(t *testing.T, topDir string) {
+				newAgentHome := filepath.Join("data", "elastic-agent-4.5.6-SNAPSHOT-ghijkl")
+				checkFilesAfterCleanup(t, topDir, newAgentHome, filepath.Join("data", "elastic-agent-aaabbb"))
+				assert.DirExists(t, filepath.Join(topDir, "data", "elastic-agent-1.2.3-SNAPSHOT-abcdef"), "previous agent directory should be kept for rollback")
+				assert.FileExists(t, availableRollbackFilePath(paths.DataFrom(topDir)))
+			},
+		},
+		"cleanup removes the previous version once its rollback window expired": {
+			args: args{
+				currentVersionedHome: "data/elastic-agent-4.5.6-SNAPSHOT-ghijkl",
+				currentHash:          "ghijkl",
+				removeMarker:         true,
+				keepLogs:             false,
+			},
+			agentInstallsSetup: setupAgentInstallations{
+				installedAgents: []testAgentInstall{
+					{
+						version:          version123Snapshot,
+						useVersionInPath: true,
+					},
+					{
+						version:          version456Snapshot,
+						useVersionInPath: true,
+					},
+				},
+				upgradeFrom:  version123Snapshot,
+				upgradeTo:    version456Snapshot,
+				currentAgent: version456Snapshot,
+			},
+			additionalSetup: func(t *testing.T, topDir string) {
+				err := SaveAvailableRollback(paths.DataFrom(topDir), &AvailableRollback{
+					Version:       version123Snapshot.version,
+					Hash:          version123Snapshot.hash,
+					VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-SNAPSHOT-abcdef"),
+					ValidUntil:    time.Now().Add(-time.Hour),
+				})
+				require.NoError(t, err)
+			},
+			wantErr: assert.NoError,
+			checkAfterCleanup: func(t *testing.T, topDir string) {
+				oldAgentHome := filepath.Join("data", "elastic-agent-1.2.3-SNAPSHOT-abcdef")
+				newAgentHome := filepath.Join("data", "elastic-agent-4.5.6-SNAPSHOT-ghijkl")
+				checkFilesAfterCleanup(t, topDir, newAgentHome, oldAgentHome)
+				assert.NoFileExists(t, availableRollbackFilePath(paths.DataFrom(topDir)))
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
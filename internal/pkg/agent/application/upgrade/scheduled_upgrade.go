@@ -0,0 +1,77 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+)
+
+const scheduledUpgradeFilename = ".scheduled-upgrade"
+
+// ScheduledUpgrade is an upgrade requested through the control protocol outside the upgrade windows, it is
+// persisted so that it still runs at the start of the next window when the agent restarts in between.
+// Upgrades from Fleet are persisted by the action queue instead.
+type ScheduledUpgrade struct {
+	// Version is the version to upgrade to
+	Version string `json:"version" yaml:"version"`
+	// SourceURI is the URI to download the version from, the configured one when empty
+	SourceURI      string   `json:"source_uri,omitempty" yaml:"source_uri,omitempty"`
+	SkipVerify     bool     `json:"skip_verify,omitempty" yaml:"skip_verify,omitempty"`
+	SkipDefaultPgp bool     `json:"skip_default_pgp,omitempty" yaml:"skip_default_pgp,omitempty"`
+	PgpBytes       []string `json:"pgp_bytes,omitempty" yaml:"pgp_bytes,omitempty"`
+	CosignKeys     []string `json:"cosign_keys,omitempty" yaml:"cosign_keys,omitempty"`
+	// ScheduledAt is the start of the upgrade window the upgrade runs in
+	ScheduledAt time.Time `json:"scheduled_at" yaml:"scheduled_at"`
+}
+
+func scheduledUpgradeFilePath(dataDirPath string) string {
+	return filepath.Join(dataDirPath, scheduledUpgradeFilename)
+}
+
+// SaveScheduledUpgrade persists the upgrade deferred to the next upgrade window, replacing any previous one.
+func SaveScheduledUpgrade(dataDirPath string, scheduled *ScheduledUpgrade) error {
+	scheduledBytes, err := yaml.Marshal(scheduled)
+	if err != nil {
+		return errors.New(err, errors.TypeConfig, "failed to parse scheduled upgrade file")
+	}
+
+	scheduledPath := scheduledUpgradeFilePath(dataDirPath)
+	if err := os.WriteFile(scheduledPath, scheduledBytes, 0600); err != nil {
+		return errors.New(err, errors.TypeFilesystem, "failed to create scheduled upgrade file", errors.M(errors.MetaKeyPath, scheduledPath))
+	}
+	return nil
+}
+
+// LoadScheduledUpgrade loads the upgrade deferred to the next upgrade window. If the file does not exist it
+// returns nil and no error.
+func LoadScheduledUpgrade(dataDirPath string) (*ScheduledUpgrade, error) {
+	scheduledBytes, err := os.ReadFile(scheduledUpgradeFilePath(dataDirPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := &ScheduledUpgrade{}
+	if err := yaml.Unmarshal(scheduledBytes, scheduled); err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// CleanScheduledUpgrade removes the upgrade deferred to the next upgrade window, if any.
+func CleanScheduledUpgrade(dataDirPath string) error {
+	if err := os.Remove(scheduledUpgradeFilePath(dataDirPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledUpgrade(t *testing.T) {
+	dataDir := t.TempDir()
+
+	scheduled, err := LoadScheduledUpgrade(dataDir)
+	require.NoError(t, err)
+	assert.Nil(t, scheduled, "no scheduled upgrade when the file does not exist")
+
+	expected := &ScheduledUpgrade{
+		Version:     "9.1.0",
+		SourceURI:   "https://artifacts.example.com",
+		SkipVerify:  true,
+		PgpBytes:    []string{"pgp-key"},
+		CosignKeys:  []string{"cosign-key"},
+		ScheduledAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+	require.NoError(t, SaveScheduledUpgrade(dataDir, expected))
+
+	scheduled, err = LoadScheduledUpgrade(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, scheduled)
+
+	require.NoError(t, CleanScheduledUpgrade(dataDir))
+	assert.NoFileExists(t, scheduledUpgradeFilePath(dataDir))
+	// cleaning twice is a no-op
+	require.NoError(t, CleanScheduledUpgrade(dataDir))
+}
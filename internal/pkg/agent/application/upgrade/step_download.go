@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,9 +21,12 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/composed"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/cosign"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/fs"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/http"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/localremote"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/oci"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/peer"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/snapshot"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
@@ -40,7 +44,9 @@ type downloaderFactory func(*agtversion.ParsedSemVer, *logger.Logger, *artifact.
 
 type downloader func(context.Context, downloaderFactory, *agtversion.ParsedSemVer, *artifact.Config, *details.Details) (string, error)
 
-func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversion.ParsedSemVer, sourceURI string, upgradeDetails *details.Details, skipVerifyOverride, skipDefaultPgp bool, pgpBytes ...string) (_ string, err error) {
+// downloadArtifact downloads and verifies the package in targetDirectory, the configured target directory when
+// empty.
+func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversion.ParsedSemVer, sourceURI string, targetDirectory string, upgradeDetails *details.Details, skipVerifyOverride, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (_ string, err error) {
 	span, ctx := apm.StartSpan(ctx, "downloadArtifact", "app.internal")
 	defer func() {
 		apm.CaptureError(ctx, err).Send()
@@ -51,6 +57,15 @@ func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversi
 
 	// do not update source config
 	settings := *u.settings
+	if targetDirectory != "" {
+		settings.TargetDirectory = targetDirectory
+	}
+	if len(cosignKeys) > 0 {
+		if settings.Verifier != artifact.VerifierCosign {
+			return "", errors.New("cosign keys require agent.download.verifier to be cosign", errors.TypeConfig)
+		}
+		settings.Cosign.Keys = append(slices.Clone(settings.Cosign.Keys), cosignKeys...)
+	}
 	var downloaderFunc downloader
 	var factory downloaderFactory
 	var verifier download.Verifier
@@ -71,7 +86,11 @@ func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversi
 			}
 
 			// set specific verifier, local file verifies locally only
-			verifier, err = fs.NewVerifier(u.log, &settings, release.PGP())
+			if settings.Verifier == artifact.VerifierCosign {
+				verifier, err = cosign.NewVerifier(u.log, &settings)
+			} else {
+				verifier, err = fs.NewVerifier(u.log, &settings, release.PGP())
+			}
 			if err != nil {
 				return "", errors.New(err, "initiating verifier")
 			}
@@ -88,8 +107,20 @@ func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversi
 	if factory == nil {
 		// set the factory to the newDownloader factory
 		factory = newDownloader
+		// the peers are only used when their packages can be verified, a package failing verification falls
+		// back to the other peers and to the source URI
+		if u.peerCache != nil && u.peerCache.Enabled() && !skipVerifyOverride {
+			verifyPeer := func(ctx context.Context, a artifact.Artifact, version agtversion.ParsedSemVer) error {
+				verifier, err := newVerifier(&version, u.log, &settings)
+				if err != nil {
+					return err
+				}
+				return verifier.Verify(ctx, a, version, skipDefaultPgp, pgpBytes...)
+			}
+			factory = withPeerDownloader(factory, u.peerCache, verifyPeer)
+		}
 		u.log.Infow("Downloading upgrade artifact", "version", parsedVersion,
-			"source_uri", settings.SourceURI, "drop_path", settings.DropPath,
+			"source_uri", artifact.RedactedSourceURI(settings.SourceURI), "drop_path", settings.DropPath,
 			"target_path", settings.TargetDirectory, "install_path", settings.InstallPath)
 	}
 	if downloaderFunc == nil {
@@ -119,6 +150,13 @@ func (u *Upgrader) downloadArtifact(ctx context.Context, parsedVersion *agtversi
 	if err := verifier.Verify(ctx, agentArtifact, *parsedVersion, skipDefaultPgp, pgpBytes...); err != nil {
 		return "", errors.New(err, "failed verification of agent binary")
 	}
+
+	if u.peerCache != nil && u.peerCache.Enabled() {
+		// only verified packages are served to the peers
+		if err := u.peerCache.Add(parsedVersion.CoreVersion(), path); err != nil {
+			u.log.Warnw("Failed to add package to peer cache", "error.message", err)
+		}
+	}
 	return path, nil
 }
 
@@ -148,7 +186,33 @@ func (u *Upgrader) appendFallbackPGP(targetVersion *agtversion.ParsedSemVer, pgp
 	return pgpBytes
 }
 
+// withPeerDownloader returns a factory of downloaders trying the peers of the cache before the downloaders of
+// factory, the packages of the peers are verified with verify.
+func withPeerDownloader(factory downloaderFactory, cache *peer.Cache, verify peer.VerifyFunc) downloaderFactory {
+	return func(version *agtversion.ParsedSemVer, log *logger.Logger, settings *artifact.Config, upgradeDetails *details.Details) (download.Downloader, error) {
+		downloader, err := factory(version, log, settings, upgradeDetails)
+		if err != nil {
+			return nil, err
+		}
+
+		peerDownloader, err := peer.NewDownloader(log, settings, cache, verify, upgradeDetails)
+		if err != nil {
+			return nil, err
+		}
+		return composed.NewDownloader(peerDownloader, downloader), nil
+	}
+}
+
 func newDownloader(version *agtversion.ParsedSemVer, log *logger.Logger, settings *artifact.Config, upgradeDetails *details.Details) (download.Downloader, error) {
+	if oci.IsSourceURI(settings.SourceURI) {
+		// packages are pulled from the OCI registry only, there is no snapshot or elastic.co fallback
+		ociDownloader, err := oci.NewDownloader(log, settings, upgradeDetails)
+		if err != nil {
+			return nil, err
+		}
+		return composed.NewDownloader(fs.NewDownloader(settings), ociDownloader), nil
+	}
+
 	if !version.IsSnapshot() {
 		return localremote.NewDownloader(log, settings, upgradeDetails)
 	}
@@ -170,8 +234,27 @@ func newDownloader(version *agtversion.ParsedSemVer, log *logger.Logger, setting
 }
 
 func newVerifier(version *agtversion.ParsedSemVer, log *logger.Logger, settings *artifact.Config) (download.Verifier, error) {
+	if settings.Verifier == artifact.VerifierCosign {
+		// the Sigstore bundle is looked up alongside the package, whatever the source is
+		return cosign.NewVerifier(log, settings)
+	}
+
 	pgp := release.PGP()
 
+	if oci.IsSourceURI(settings.SourceURI) {
+		fsVerifier, err := fs.NewVerifier(log, settings, pgp)
+		if err != nil {
+			return nil, err
+		}
+
+		ociVerifier, err := oci.NewVerifier(log, settings, pgp)
+		if err != nil {
+			return nil, err
+		}
+
+		return composed.NewVerifier(log, fsVerifier, ociVerifier), nil
+	}
+
 	if !version.IsSnapshot() {
 		return localremote.NewVerifier(log, settings, pgp)
 	}
@@ -6,8 +6,12 @@ package upgrade
 
 import (
 	"context"
+	"crypto/sha512"
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -16,8 +20,10 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/peer"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
@@ -74,6 +80,137 @@ func TestFallbackIsAppended(t *testing.T) {
 	}
 }
 
+func TestNewVerifier(t *testing.T) {
+	log, _ := loggertest.New("TestNewVerifier")
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+
+	settings := artifact.DefaultConfig()
+	verifier, err := newVerifier(version, log, settings)
+	require.NoError(t, err)
+	require.NotEqual(t, "cosign.verifier", verifier.Name())
+
+	settings.Verifier = artifact.VerifierCosign
+	verifier, err = newVerifier(version, log, settings)
+	require.NoError(t, err)
+	require.Equal(t, "cosign.verifier", verifier.Name())
+}
+
+func TestDownloadArtifactCosignKeysRequireCosignVerifier(t *testing.T) {
+	log, _ := loggertest.New("TestDownloadArtifactCosignKeysRequireCosignVerifier")
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+
+	u, err := NewUpgrader(log, artifact.DefaultConfig(), &info.AgentInfo{})
+	require.NoError(t, err)
+
+	_, err = u.downloadArtifact(context.Background(), version, "", "", details.NewDetails("9.1.0", details.StateRequested, ""), false, false, []string{"cosign-key"})
+	require.ErrorContains(t, err, "cosign keys require agent.download.verifier to be cosign")
+}
+
+func TestWithPeerDownloader(t *testing.T) {
+	log, _ := loggertest.New("TestWithPeerDownloader")
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+	expectedDownloadPath := "https://artifacts.elastic.co/downloads/beats/elastic-agent"
+
+	cache, err := peer.NewCache(log, artifact.PeerCacheConfig{Enabled: true, Token: "secret", Path: t.TempDir()})
+	require.NoError(t, err)
+	factory := withPeerDownloader(func(version *agtversion.ParsedSemVer, log *logger.Logger, settings *artifact.Config, upgradeDetails *details.Details) (download.Downloader, error) {
+		return &mockDownloader{expectedDownloadPath, nil}, nil
+	}, cache, nil)
+
+	settings := artifact.DefaultConfig()
+	settings.TargetDirectory = t.TempDir()
+	downloader, err := factory(version, log, settings, details.NewDetails("9.1.0", details.StateRequested, ""))
+	require.NoError(t, err)
+
+	// no peer is known, the package is downloaded from the source URI
+	path, err := downloader.Download(context.Background(), agentArtifact, version)
+	require.NoError(t, err)
+	require.Equal(t, expectedDownloadPath, path)
+}
+
+func TestWithPeerDownloaderTamperedPackage(t *testing.T) {
+	log, _ := loggertest.New("TestWithPeerDownloaderTamperedPackage")
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+	expectedDownloadPath := "https://artifacts.elastic.co/downloads/beats/elastic-agent"
+	const packageName = "elastic-agent-9.1.0-linux-x86_64.tar.gz"
+
+	// the peer serves a package that does not match its checksum
+	tamperedPath := filepath.Join(t.TempDir(), packageName)
+	require.NoError(t, os.WriteFile(tamperedPath, []byte("tampered package"), 0o600))
+	require.NoError(t, os.WriteFile(tamperedPath+".sha512", []byte(fmt.Sprintf("%x  %s", sha512.Sum512([]byte("package")), packageName)), 0o600))
+	servingCache, err := peer.NewCache(log, artifact.PeerCacheConfig{Enabled: true, Token: "secret", Path: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, servingCache.Add("9.1.0", tamperedPath))
+	server := httptest.NewServer(servingCache)
+	defer server.Close()
+
+	cache, err := peer.NewCache(log, artifact.PeerCacheConfig{Enabled: true, Token: "secret", Path: t.TempDir(), Peers: []string{server.URL}})
+	require.NoError(t, err)
+
+	settings := artifact.DefaultConfig()
+	settings.OperatingSystem = "linux"
+	settings.Architecture = "64"
+	settings.TargetDirectory = t.TempDir()
+	peerPath := filepath.Join(settings.TargetDirectory, packageName)
+	verified := false
+	factory := withPeerDownloader(func(version *agtversion.ParsedSemVer, log *logger.Logger, settings *artifact.Config, upgradeDetails *details.Details) (download.Downloader, error) {
+		return &mockDownloader{expectedDownloadPath, nil}, nil
+	}, cache, func(_ context.Context, _ artifact.Artifact, _ agtversion.ParsedSemVer) error {
+		verified = true
+		return download.VerifySHA512Hash(peerPath)
+	})
+
+	downloader, err := factory(version, log, settings, details.NewDetails("9.1.0", details.StateRequested, ""))
+	require.NoError(t, err)
+
+	// the tampered package fails verification, the package is downloaded from the source URI
+	path, err := downloader.Download(context.Background(), agentArtifact, version)
+	require.NoError(t, err)
+	require.True(t, verified, "the package of the peer must be verified")
+	require.Equal(t, expectedDownloadPath, path)
+	require.NoFileExists(t, peerPath)
+	require.NoFileExists(t, peerPath+".sha512")
+}
+
+func TestDownloadArtifactSkipVerifyIgnoresPeers(t *testing.T) {
+	log, _ := loggertest.New("TestDownloadArtifactSkipVerifyIgnoresPeers")
+	version := agtversion.NewParsedSemVer(9, 1, 0, "", "")
+	const packageName = "elastic-agent-9.1.0-linux-x86_64.tar.gz"
+
+	// the peer serves a package that would not pass verification
+	tamperedPath := filepath.Join(t.TempDir(), packageName)
+	require.NoError(t, os.WriteFile(tamperedPath, []byte("tampered package"), 0o600))
+	servingCache, err := peer.NewCache(log, artifact.PeerCacheConfig{Enabled: true, Token: "secret", Path: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, servingCache.Add("9.1.0", tamperedPath))
+	server := httptest.NewServer(servingCache)
+	defer server.Close()
+
+	cache, err := peer.NewCache(log, artifact.PeerCacheConfig{Enabled: true, Token: "secret", Path: t.TempDir(), Peers: []string{server.URL}})
+	require.NoError(t, err)
+
+	paths.SetDownloads(t.TempDir())
+	settings := artifact.DefaultConfig()
+	settings.OperatingSystem = "linux"
+	settings.Architecture = "64"
+	settings.DropPath = t.TempDir()
+	settings.TargetDirectory = t.TempDir()
+	settings.Timeout = 5 * time.Second
+	require.NoError(t, os.WriteFile(filepath.Join(settings.DropPath, packageName), []byte("package"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(settings.DropPath, packageName+".sha512"), []byte("checksum  "+packageName), 0o600))
+
+	u, err := NewUpgrader(log, settings, &info.AgentInfo{})
+	require.NoError(t, err)
+	u.SetPeerCache(cache)
+
+	// verification is skipped, the package is not downloaded from the peer
+	path, err := u.downloadArtifact(context.Background(), version, "", "", details.NewDetails("9.1.0", details.StateRequested, ""), true, false, nil)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "package", string(content))
+}
+
 func TestDownloadWithRetries(t *testing.T) {
 	expectedDownloadPath := "https://artifacts.elastic.co/downloads/beats/elastic-agent"
 	testLogger, obs := loggertest.New("TestDownloadWithRetries")
@@ -23,6 +23,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/reexec"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/peer"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
@@ -76,6 +77,7 @@ type Upgrader struct {
 	upgradeable    bool
 	fleetServerURI string
 	markerWatcher  MarkerWatcher
+	peerCache      *peer.Cache
 }
 
 // IsUpgradeable when agent is installed and running as a service or flag was provided.
@@ -96,6 +98,12 @@ func NewUpgrader(log *logger.Logger, settings *artifact.Config, agentInfo info.A
 	}, nil
 }
 
+// SetPeerCache sets the cache packages are downloaded from first and verified packages are added to when it
+// is enabled, it is reloaded along with the upgrader.
+func (u *Upgrader) SetPeerCache(c *peer.Cache) {
+	u.peerCache = c
+}
+
 // SetClient reloads URI based on up to date fleet client
 func (u *Upgrader) SetClient(c fleetclient.Sender) {
 	if c == nil {
@@ -133,16 +141,22 @@ func (u *Upgrader) Reload(rawConfig *config.Config) error {
 
 	if cfg.Settings.DownloadConfig.SourceURI != "" {
 		u.log.Infof("Source URI changed from %q to %q",
-			u.settings.SourceURI,
-			cfg.Settings.DownloadConfig.SourceURI)
+			artifact.RedactedSourceURI(u.settings.SourceURI),
+			artifact.RedactedSourceURI(cfg.Settings.DownloadConfig.SourceURI))
 	} else {
 		// source uri unset, reset to default
 		u.log.Infof("Source URI reset from %q to %q",
-			u.settings.SourceURI,
+			artifact.RedactedSourceURI(u.settings.SourceURI),
 			artifact.DefaultSourceURI)
 		cfg.Settings.DownloadConfig.SourceURI = artifact.DefaultSourceURI
 	}
 
+	if u.peerCache != nil {
+		if err := u.peerCache.Reload(cfg.Settings.DownloadConfig.PeerCache); err != nil {
+			return fmt.Errorf("invalid peer cache config: %w", err)
+		}
+	}
+
 	u.settings = cfg.Settings.DownloadConfig
 	return nil
 }
@@ -194,8 +208,8 @@ func checkUpgrade(log *logger.Logger, currentVersion, newVersion agentVersion, m
 }
 
 // Upgrade upgrades running agent, function returns shutdown callback that must be called by reexec.
-func (u *Upgrader) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, det *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error) {
-	u.log.Infow("Upgrading agent", "version", version, "source_uri", sourceURI)
+func (u *Upgrader) Upgrade(ctx context.Context, version string, sourceURI string, action *fleetapi.ActionUpgrade, det *details.Details, skipVerifyOverride bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (_ reexec.ShutdownCallbackFn, err error) {
+	u.log.Infow("Upgrading agent", "version", version, "source_uri", artifact.RedactedSourceURI(sourceURI))
 
 	currentVersion := agentVersion{
 		version:  release.Version(),
@@ -223,7 +237,7 @@ func (u *Upgrader) Upgrade(ctx context.Context, version string, sourceURI string
 	span, ctx := apm.StartSpan(ctx, "upgrade", "app.internal")
 	defer span.End()
 
-	err = cleanNonMatchingVersionsFromDownloads(u.log, u.agentInfo.Version())
+	err = cleanNonMatchingVersionsFromDownloads(u.log, u.agentInfo.Version(), version)
 	if err != nil {
 		u.log.Errorw("Unable to clean downloads before update", "error.message", err, "downloads.path", paths.Downloads())
 	}
@@ -237,11 +251,11 @@ func (u *Upgrader) Upgrade(ctx context.Context, version string, sourceURI string
 		return nil, fmt.Errorf("error parsing version %q: %w", version, err)
 	}
 
-	archivePath, err := u.downloadArtifact(ctx, parsedVersion, sourceURI, det, skipVerifyOverride, skipDefaultPgp, pgpBytes...)
+	archivePath, err := u.downloadArtifact(ctx, parsedVersion, sourceURI, "", det, skipVerifyOverride, skipDefaultPgp, cosignKeys, pgpBytes...)
 	if err != nil {
 		// Run the same pre-upgrade cleanup task to get rid of any newly downloaded files
 		// This may have an issue if users are upgrading to the same version number.
-		if dErr := cleanNonMatchingVersionsFromDownloads(u.log, u.agentInfo.Version()); dErr != nil {
+		if dErr := cleanNonMatchingVersionsFromDownloads(u.log, u.agentInfo.Version(), version); dErr != nil {
 			u.log.Errorw("Unable to remove file after verification failure", "error.message", dErr)
 		}
 
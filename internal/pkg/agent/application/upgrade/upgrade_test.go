@@ -25,6 +25,7 @@ import (
 	"github.com/elastic/elastic-agent-libs/transport/tlscommon"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/artifact/download/peer"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
 	"github.com/elastic/elastic-agent/internal/pkg/config"
@@ -281,6 +282,40 @@ func TestUpgraderReload(t *testing.T) {
 	assert.Equal(t, &want, u.settings)
 }
 
+func TestUpgraderReloadPeerCache(t *testing.T) {
+	log, _ := loggertest.New("")
+	cache, err := peer.NewCache(log, artifact.PeerCacheConfig{})
+	require.NoError(t, err)
+	u := Upgrader{
+		log:       log,
+		settings:  artifact.DefaultConfig(),
+		peerCache: cache,
+	}
+
+	err = u.Reload(config.MustNewConfigFrom(`
+agent.download.peer_cache:
+  enabled: true
+`))
+	require.ErrorContains(t, err, "invalid peer cache config")
+	assert.False(t, cache.Enabled())
+
+	err = u.Reload(config.MustNewConfigFrom(`
+agent.download.peer_cache:
+  enabled: true
+  token: site-secret
+  path: ` + t.TempDir() + `
+`))
+	require.NoError(t, err)
+	assert.True(t, cache.Enabled(), "the peer cache should be enabled by the new configuration")
+
+	err = u.Reload(config.MustNewConfigFrom(`
+agent.download.peer_cache:
+  enabled: false
+`))
+	require.NoError(t, err)
+	assert.False(t, cache.Enabled(), "the peer cache should be disabled by the new configuration")
+}
+
 func TestUpgraderAckAction(t *testing.T) {
 	log, _ := loggertest.New("")
 	u := Upgrader{
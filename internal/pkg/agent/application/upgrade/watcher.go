@@ -13,6 +13,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/pkg/control/v2/client"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
@@ -47,6 +48,7 @@ type AgentWatcher struct {
 	log           *logger.Logger
 	agentClient   client.Client
 	checkInterval time.Duration
+	gates         *healthGates
 }
 
 // NewAgentWatcher creates a new agent watcher.
@@ -66,6 +68,21 @@ func NewAgentWatcher(ch chan error, log *logger.Logger, checkInterval time.Durat
 	return ec
 }
 
+// SetHealthGates sets the health gates the agent must pass, in addition to not failing, or the agent
+// is reported as failed.
+func (ch *AgentWatcher) SetHealthGates(cfg configuration.UpgradeWatcherGatesConfig) error {
+	if !cfg.Enabled() {
+		ch.gates = nil
+		return nil
+	}
+	gates, err := newHealthGates(cfg)
+	if err != nil {
+		return err
+	}
+	ch.gates = gates
+	return nil
+}
+
 // Run runs the checking loop.
 func (ch *AgentWatcher) Run(ctx context.Context) {
 	ch.log.Info("Agent watcher started")
@@ -73,6 +90,30 @@ func (ch *AgentWatcher) Run(ctx context.Context) {
 	ch.connectCounter = 0
 	ch.lostCounter = 0
 
+	if ch.gates != nil {
+		// gates are checked on the interval, their timeouts elapse even if no state is received
+		go func() {
+			t := time.NewTicker(ch.checkInterval)
+			defer t.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-t.C:
+					if err := ch.gates.check(now); err != nil {
+						ch.log.Error(err)
+						// the watch may have already ended because of another failure
+						select {
+						case ch.notifyChan <- err:
+						case <-ctx.Done():
+						}
+						return
+					}
+				}
+			}
+		}()
+	}
+
 	// tracking of an error runs in a separate goroutine, because
 	// the call to `watch.Recv` blocks and a timer is needed
 	// to determine if an error last longer than the checkInterval.
@@ -191,6 +232,9 @@ LOOP:
 					// agent has crashed or exited
 					stateCancel()
 					ch.agentClient.Disconnect()
+					if ch.gates != nil {
+						ch.gates.setState(nil, time.Now())
+					}
 					ch.log.Errorf("Lost connection: failed reading next state: %s", err)
 					ch.lostCounter++
 					if ch.checkFailures() {
@@ -220,6 +264,10 @@ LOOP:
 					}
 				}
 
+				if ch.gates != nil {
+					ch.gates.setState(state, time.Now())
+				}
+
 				if state.State == client.Failed {
 					// top-level failure (something is really wrong)
 					failedCh <- fmt.Errorf("%w: %s", ErrAgentStatusFailed, state.Message)
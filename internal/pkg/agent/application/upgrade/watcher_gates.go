@@ -0,0 +1,220 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/mapstr"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
+	"github.com/elastic/elastic-agent/pkg/control/v2/client"
+)
+
+const (
+	// GateMinHealthyComponents fails when less components than configured are HEALTHY.
+	GateMinHealthyComponents = "min_healthy_components"
+	// GateUnitFailed fails when a unit stays FAILED for longer than configured.
+	GateUnitFailed = "unit_failed"
+	// GateCondition fails when the configured EQL expression evaluates to false.
+	GateCondition = "condition"
+)
+
+// ErrHealthGateFailed is returned when the agent fails one of the configured health gates.
+var ErrHealthGateFailed = errors.New("agent failed health gate")
+
+// HealthGateError is returned when the agent fails a health gate.
+type HealthGateError struct {
+	// Gate is the name of the failed gate.
+	Gate string
+	Err  error
+}
+
+func (e *HealthGateError) Error() string {
+	return fmt.Sprintf("%s %s: %s", ErrHealthGateFailed, e.Gate, e.Err)
+}
+
+func (e *HealthGateError) Unwrap() []error {
+	return []error{ErrHealthGateFailed, e.Err}
+}
+
+// healthGates checks the states reported by the agent against the configured health gates.
+//
+// The gates are checked on an interval and not on every state, so a gate fails once its timeout elapses even
+// if the agent stops reporting new states.
+type healthGates struct {
+	cfg       configuration.UpgradeWatcherGatesConfig
+	condition *eql.Expression
+
+	mx    sync.Mutex
+	state *client.AgentState
+	// unhealthySince is when the min_healthy_components or condition gate started failing.
+	unhealthySince time.Time
+	unhealthyErr   *HealthGateError
+	// unitFailedSince is when each FAILED unit started failing, by component and unit ID.
+	unitFailedSince map[string]time.Time
+}
+
+func newHealthGates(cfg configuration.UpgradeWatcherGatesConfig) (*healthGates, error) {
+	g := &healthGates{
+		cfg:             cfg,
+		unitFailedSince: make(map[string]time.Time),
+	}
+	if cfg.Condition != "" {
+		condition, err := eql.New(cfg.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("invalid health gate condition %q: %w", cfg.Condition, err)
+		}
+		g.condition = condition
+	}
+	return g, nil
+}
+
+// setState sets the last state reported by the agent. A nil state means the connection to the agent was lost,
+// the gates start over once it reports a state again.
+func (g *healthGates) setState(state *client.AgentState, now time.Time) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+
+	g.state = state
+	if state == nil {
+		g.unhealthySince = time.Time{}
+		g.unhealthyErr = nil
+		clear(g.unitFailedSince)
+		return
+	}
+
+	failed := make(map[string]bool)
+	for _, comp := range state.Components {
+		for _, unit := range comp.Units {
+			if unit.State != client.Failed {
+				continue
+			}
+			key := comp.ID + "/" + unit.UnitID
+			failed[key] = true
+			if _, ok := g.unitFailedSince[key]; !ok {
+				g.unitFailedSince[key] = now
+			}
+		}
+	}
+	for key := range g.unitFailedSince {
+		if !failed[key] {
+			delete(g.unitFailedSince, key)
+		}
+	}
+
+	if err := g.unhealthy(state); err != nil {
+		if g.unhealthySince.IsZero() {
+			g.unhealthySince = now
+		}
+		g.unhealthyErr = err
+	} else {
+		g.unhealthySince = time.Time{}
+		g.unhealthyErr = nil
+	}
+}
+
+// check returns the error of the first gate that failed for longer than its timeout.
+func (g *healthGates) check(now time.Time) error {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+	if g.state == nil {
+		return nil
+	}
+
+	if g.cfg.UnitFailedTimeout > 0 {
+		for key, since := range g.unitFailedSince {
+			if now.Sub(since) >= g.cfg.UnitFailedTimeout {
+				return &HealthGateError{
+					Gate: GateUnitFailed,
+					Err:  fmt.Errorf("unit %s failed for more than %s", key, g.cfg.UnitFailedTimeout),
+				}
+			}
+		}
+	}
+	if g.unhealthyErr != nil && now.Sub(g.unhealthySince) >= g.cfg.Timeout {
+		return g.unhealthyErr
+	}
+	return nil
+}
+
+// unhealthy returns the error of the min_healthy_components or condition gate if the state does not pass it.
+func (g *healthGates) unhealthy(state *client.AgentState) *HealthGateError {
+	if g.cfg.MinHealthyComponents > 0 {
+		healthy := 0
+		for _, comp := range state.Components {
+			if comp.State == client.Healthy {
+				healthy++
+			}
+		}
+		if healthy < g.cfg.MinHealthyComponents {
+			return &HealthGateError{
+				Gate: GateMinHealthyComponents,
+				Err:  fmt.Errorf("%d components are healthy, at least %d required", healthy, g.cfg.MinHealthyComponents),
+			}
+		}
+	}
+	if g.condition != nil {
+		ok, err := g.condition.Eval(newStateVars(state), true)
+		if err != nil {
+			return &HealthGateError{
+				Gate: GateCondition,
+				Err:  fmt.Errorf("failed to evaluate %q: %w", g.cfg.Condition, err),
+			}
+		}
+		if !ok {
+			return &HealthGateError{
+				Gate: GateCondition,
+				Err:  fmt.Errorf("%q evaluated to false", g.cfg.Condition),
+			}
+		}
+	}
+	return nil
+}
+
+// stateVars is the state of the agent as variables for the condition gate, e.g.
+// ${components.filestream-default.units.filestream-default-logs.state}.
+type stateVars mapstr.M
+
+func newStateVars(state *client.AgentState) stateVars {
+	healthy := 0
+	components := mapstr.M{}
+	for _, comp := range state.Components {
+		if comp.State == client.Healthy {
+			healthy++
+		}
+		units := mapstr.M{}
+		for _, unit := range comp.Units {
+			units[unit.UnitID] = mapstr.M{
+				"type":    unit.UnitType.String(),
+				"state":   unit.State.String(),
+				"message": unit.Message,
+			}
+		}
+		components[comp.ID] = mapstr.M{
+			"name":    comp.Name,
+			"state":   comp.State.String(),
+			"message": comp.Message,
+			"units":   units,
+		}
+	}
+	return stateVars{
+		"state":              state.State.String(),
+		"message":            state.Message,
+		"fleet_state":        state.FleetState.String(),
+		"version":            state.Info.Version,
+		"components":         components,
+		"healthy_components": healthy,
+	}
+}
+
+// Lookup implements eql.VarStore.
+func (v stateVars) Lookup(name string) (interface{}, bool) {
+	value, err := mapstr.M(v).GetValue(name)
+	return value, err == nil
+}
@@ -0,0 +1,157 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+	"github.com/elastic/elastic-agent/pkg/control/v2/client"
+	"github.com/elastic/elastic-agent/pkg/control/v2/cproto"
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+)
+
+func gatesState(unitState client.State, componentStates ...client.State) *client.AgentState {
+	state := &client.AgentState{State: client.Healthy}
+	for i, s := range componentStates {
+		comp := client.ComponentState{
+			ID:    "filestream-" + string(rune('a'+i)),
+			Name:  "filestream",
+			State: s,
+		}
+		comp.Units = []client.ComponentUnitState{{
+			UnitID:   comp.ID + "-logs",
+			UnitType: client.UnitTypeInput,
+			State:    unitState,
+		}}
+		state.Components = append(state.Components, comp)
+	}
+	return state
+}
+
+func TestHealthGates(t *testing.T) {
+	start := time.Now()
+
+	t.Run("min healthy components", func(t *testing.T) {
+		g, err := newHealthGates(configuration.UpgradeWatcherGatesConfig{MinHealthyComponents: 2, Timeout: time.Minute})
+		require.NoError(t, err)
+
+		// not failed before a state is received
+		assert.NoError(t, g.check(start.Add(time.Hour)))
+
+		g.setState(gatesState(client.Healthy, client.Healthy, client.Starting), start)
+		assert.NoError(t, g.check(start.Add(30*time.Second)))
+		g.setState(gatesState(client.Healthy, client.Healthy, client.Configuring), start.Add(30*time.Second))
+		err = g.check(start.Add(time.Minute))
+		var gateErr *HealthGateError
+		require.ErrorAs(t, err, &gateErr)
+		assert.Equal(t, GateMinHealthyComponents, gateErr.Gate)
+		assert.ErrorIs(t, err, ErrHealthGateFailed)
+
+		// healthy again resets the timeout
+		g.setState(gatesState(client.Healthy, client.Healthy, client.Healthy), start.Add(time.Minute))
+		assert.NoError(t, g.check(start.Add(2*time.Minute)))
+
+		// lost connection resets the gates
+		g.setState(gatesState(client.Healthy, client.Healthy), start.Add(2*time.Minute))
+		g.setState(nil, start.Add(2*time.Minute))
+		assert.NoError(t, g.check(start.Add(4*time.Minute)))
+	})
+
+	t.Run("unit failed", func(t *testing.T) {
+		g, err := newHealthGates(configuration.UpgradeWatcherGatesConfig{UnitFailedTimeout: 5 * time.Minute})
+		require.NoError(t, err)
+
+		g.setState(gatesState(client.Failed, client.Degraded), start)
+		g.setState(gatesState(client.Failed, client.Degraded), start.Add(4*time.Minute))
+		assert.NoError(t, g.check(start.Add(4*time.Minute)))
+		err = g.check(start.Add(5 * time.Minute))
+		var gateErr *HealthGateError
+		require.ErrorAs(t, err, &gateErr)
+		assert.Equal(t, GateUnitFailed, gateErr.Gate)
+		assert.ErrorContains(t, err, "filestream-a/filestream-a-logs")
+
+		// recovering unit resets its timeout
+		g.setState(gatesState(client.Healthy, client.Healthy), start.Add(5*time.Minute))
+		g.setState(gatesState(client.Failed, client.Degraded), start.Add(6*time.Minute))
+		assert.NoError(t, g.check(start.Add(10*time.Minute)))
+	})
+
+	t.Run("condition", func(t *testing.T) {
+		g, err := newHealthGates(configuration.UpgradeWatcherGatesConfig{
+			Condition: "${components.filestream-a.units.filestream-a-logs.state} == 'HEALTHY' and ${healthy_components} >= 1",
+		})
+		require.NoError(t, err)
+
+		g.setState(gatesState(client.Healthy, client.Healthy), start)
+		assert.NoError(t, g.check(start))
+
+		g.setState(gatesState(client.Degraded, client.Healthy), start)
+		err = g.check(start)
+		var gateErr *HealthGateError
+		require.ErrorAs(t, err, &gateErr)
+		assert.Equal(t, GateCondition, gateErr.Gate)
+	})
+
+	t.Run("invalid condition", func(t *testing.T) {
+		_, err := newHealthGates(configuration.UpgradeWatcherGatesConfig{Condition: "${state} =="})
+		assert.Error(t, err)
+	})
+}
+
+func TestWatcher_HealthGateFailed(t *testing.T) {
+	// timeout ensures that if it doesn't work; it doesn't block forever
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	errCh := make(chan error)
+	logger, _ := loggertest.New("watcher")
+	w := NewAgentWatcher(errCh, logger, 100*time.Millisecond)
+	require.NoError(t, w.SetHealthGates(configuration.UpgradeWatcherGatesConfig{
+		MinHealthyComponents: 2,
+		Timeout:              200 * time.Millisecond,
+	}))
+
+	// reports a healthy state, but with only one healthy component
+	mockHandler := func(srv cproto.ElasticAgentControl_StateWatchServer) error {
+		err := srv.Send(&cproto.StateResponse{
+			Info:    &cproto.StateAgentInfo{},
+			State:   cproto.State_HEALTHY,
+			Message: "healthy",
+			Components: []*cproto.ComponentState{{
+				Id:    "filestream-default",
+				Name:  "filestream",
+				State: cproto.State_HEALTHY,
+			}},
+		})
+		if err != nil {
+			return err
+		}
+		// keep open until end (exiting will count as a lost connection)
+		<-ctx.Done()
+		return nil
+	}
+	mock := &mockDaemon{watch: mockHandler}
+	require.NoError(t, mock.Start())
+	defer mock.Stop()
+
+	// set client to mock; before running
+	w.agentClient = mock.Client()
+	go w.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+		require.NoError(t, ctx.Err())
+	case err := <-errCh:
+		var gateErr *HealthGateError
+		require.ErrorAs(t, err, &gateErr)
+		assert.Equal(t, GateMinHealthyComponents, gateErr.Gate)
+	}
+}
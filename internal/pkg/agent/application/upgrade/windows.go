@@ -0,0 +1,157 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/cronexpr"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+)
+
+// ErrNoUpgradeWindow is returned when none of the upgrade windows opens anymore.
+var ErrNoUpgradeWindow = errors.New("no upcoming upgrade window")
+
+// Windows are the maintenance windows during which upgrades can run.
+type Windows struct {
+	windows []upgradeWindow
+}
+
+// upgradeWindow is a single maintenance window.
+type upgradeWindow interface {
+	// next returns the window open at t, or the next window opening after t. A zero start means the window
+	// does not open anymore.
+	next(t time.Time) (start, end time.Time)
+}
+
+// NewWindows creates the upgrade windows from their configuration, upgrades can run at any time when there is none.
+func NewWindows(cfgs []configuration.UpgradeWindowConfig) (*Windows, error) {
+	windows := make([]upgradeWindow, 0, len(cfgs))
+	for i, cfg := range cfgs {
+		w, err := newUpgradeWindow(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upgrade window %d: %w", i, err)
+		}
+		windows = append(windows, w)
+	}
+	return &Windows{windows: windows}, nil
+}
+
+// ClosedWindows returns upgrade windows that never open, upgrades are refused until valid windows replace them.
+func ClosedWindows() *Windows {
+	return &Windows{windows: []upgradeWindow{closedWindow{}}}
+}
+
+// Next returns now when it is within an upgrade window, otherwise the start of the next window.
+func (w *Windows) Next(now time.Time) (time.Time, error) {
+	if w == nil || len(w.windows) == 0 {
+		return now, nil
+	}
+
+	var next time.Time
+	for _, window := range w.windows {
+		start, _ := window.next(now)
+		if start.IsZero() {
+			continue
+		}
+		if !start.After(now) {
+			return now, nil
+		}
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	if next.IsZero() {
+		return time.Time{}, ErrNoUpgradeWindow
+	}
+	return next, nil
+}
+
+// closedWindow is an upgrade window that never opens.
+type closedWindow struct{}
+
+func (closedWindow) next(time.Time) (start, end time.Time) {
+	return time.Time{}, time.Time{}
+}
+
+func newUpgradeWindow(cfg configuration.UpgradeWindowConfig) (upgradeWindow, error) {
+	if err := cfg.Check(); err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Cron != "" {
+		expr, err := cronexpr.Parse(cfg.Cron)
+		if err != nil {
+			return nil, err
+		}
+		return &cronWindow{expr: expr, duration: cfg.Duration, loc: loc}, nil
+	}
+
+	start, err := cfg.StartTime()
+	if err != nil {
+		return nil, err
+	}
+	end, err := cfg.EndTime()
+	if err != nil {
+		return nil, err
+	}
+	weekdays, err := cfg.Weekdays()
+	if err != nil {
+		return nil, err
+	}
+	return &rangeWindow{start: start, end: end, weekdays: weekdays, loc: loc}, nil
+}
+
+// cronWindow is a window opening at the times of a cron expression and staying open for a duration.
+type cronWindow struct {
+	expr     *cronexpr.Expression
+	duration time.Duration
+	loc      *time.Location
+}
+
+func (w *cronWindow) next(t time.Time) (time.Time, time.Time) {
+	// the first start after t-duration is either the window open at t or the next one
+	start := w.expr.Next(t.In(w.loc).Add(-w.duration))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	return start, start.Add(w.duration)
+}
+
+// rangeWindow is a window open between two times of the day, on some days of the week.
+type rangeWindow struct {
+	start    time.Time
+	end      time.Time
+	weekdays []time.Weekday
+	loc      *time.Location
+}
+
+func (w *rangeWindow) next(t time.Time) (time.Time, time.Time) {
+	t = t.In(w.loc)
+	// starts from the day before as a window spanning midnight may still be open
+	for days := -1; days <= 7; days++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+days, 0, 0, 0, 0, w.loc)
+		if len(w.weekdays) > 0 && !slices.Contains(w.weekdays, day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), w.start.Hour(), w.start.Minute(), 0, 0, w.loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), w.end.Hour(), w.end.Minute(), 0, 0, w.loc)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if end.After(t) {
+			return start, end
+		}
+	}
+	return time.Time{}, time.Time{}
+}
@@ -0,0 +1,111 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package upgrade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
+)
+
+func TestWindowsNext(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	// Wednesday
+	wednesday := time.Date(2025, time.July, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		windows  []configuration.UpgradeWindowConfig
+		now      time.Time
+		expected time.Time
+	}{
+		"no windows": {
+			now:      wednesday,
+			expected: wednesday,
+		},
+		"within daily range": {
+			windows:  []configuration.UpgradeWindowConfig{{Start: "11:00", End: "13:00"}},
+			now:      wednesday,
+			expected: wednesday,
+		},
+		"before daily range": {
+			windows:  []configuration.UpgradeWindowConfig{{Start: "14:00", End: "16:00"}},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 2, 14, 0, 0, 0, time.UTC),
+		},
+		"after daily range": {
+			windows:  []configuration.UpgradeWindowConfig{{Start: "09:00", End: "12:00"}},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 3, 9, 0, 0, 0, time.UTC),
+		},
+		"within range spanning midnight": {
+			windows:  []configuration.UpgradeWindowConfig{{Start: "22:00", End: "02:00"}},
+			now:      time.Date(2025, time.July, 2, 1, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, time.July, 2, 1, 0, 0, 0, time.UTC),
+		},
+		"range on weekend days": {
+			windows:  []configuration.UpgradeWindowConfig{{Days: []string{"saturday", "Sun"}, Start: "02:00", End: "05:00"}},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 5, 2, 0, 0, 0, time.UTC),
+		},
+		"range in timezone": {
+			windows:  []configuration.UpgradeWindowConfig{{Start: "15:00", End: "16:00", Timezone: "Europe/Paris"}},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 2, 15, 0, 0, 0, paris),
+		},
+		"within cron window": {
+			windows:  []configuration.UpgradeWindowConfig{{Cron: "30 11 * * *", Duration: time.Hour}},
+			now:      wednesday,
+			expected: wednesday,
+		},
+		"before cron window": {
+			windows:  []configuration.UpgradeWindowConfig{{Cron: "0 3 * * SAT", Duration: 2 * time.Hour, Timezone: "Europe/Paris"}},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 5, 3, 0, 0, 0, paris),
+		},
+		"earliest of several windows": {
+			windows: []configuration.UpgradeWindowConfig{
+				{Cron: "0 3 * * SAT", Duration: 2 * time.Hour},
+				{Start: "20:00", End: "21:00"},
+			},
+			now:      wednesday,
+			expected: time.Date(2025, time.July, 2, 20, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			windows, err := NewWindows(tc.windows)
+			require.NoError(t, err)
+
+			next, err := windows.Next(tc.now)
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(next), "expected %s, got %s", tc.expected, next)
+		})
+	}
+}
+
+func TestWindowsNextNoUpcomingWindow(t *testing.T) {
+	windows, err := NewWindows([]configuration.UpgradeWindowConfig{{Cron: "0 0 1 1 * 2020", Duration: time.Hour}})
+	require.NoError(t, err)
+
+	_, err = windows.Next(time.Now())
+	assert.ErrorIs(t, err, ErrNoUpgradeWindow)
+}
+
+func TestNewWindowsInvalid(t *testing.T) {
+	_, err := NewWindows([]configuration.UpgradeWindowConfig{{Start: "25:00", End: "02:00"}})
+	assert.Error(t, err)
+}
+
+func TestClosedWindows(t *testing.T) {
+	_, err := ClosedWindows().Next(time.Now())
+	assert.ErrorIs(t, err, ErrNoUpgradeWindow)
+}
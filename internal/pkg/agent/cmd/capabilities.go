@@ -0,0 +1,153 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent-libs/service"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/component"
+	"github.com/elastic/elastic-agent/pkg/version"
+)
+
+func newCapabilitiesCommandWithArgs(s []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capabilities",
+		Short: "Inspect the capabilities of the Elastic Agent",
+		Long:  "This command groups the subcommands that inspect the capabilities.yml of the Elastic Agent.",
+		Args:  cobra.ExactArgs(0),
+	}
+
+	cmd.AddCommand(newCapabilitiesCheckCommandWithArgs(s, streams))
+
+	return cmd
+}
+
+func newCapabilitiesCheckCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Explains which inputs, outputs and upgrades capabilities.yml allows",
+		Long: `Loads capabilities.yml and computes the components model for the current configuration, or for the
+policy given with --policy, then prints every input unit and output with whether it is allowed or denied and the
+index of the capabilities.yml rule that decided it, starting at 0. A rule of -1 means that no rule applied and the
+default allowed it.
+
+An input is denied when its output, its runtime, its input type or one of its namespaces is denied; the check field
+reports which one decided.
+
+The upgrade to the version given with --upgrade-version, or to the current version when omitted, is explained too.
+
+Variable substitution is always performed when computing the components. The --variables-wait allows an amount of time
+to be provided for variable discovery, when set it will wait that amount of time before using the variables for the
+configuration.
+`,
+		Args: cobra.ExactArgs(0),
+		Run: func(c *cobra.Command, args []string) {
+			var opts capabilitiesCheckOpts
+			opts.policy, _ = c.Flags().GetString("policy")
+			opts.upgradeVersion, _ = c.Flags().GetString("upgrade-version")
+			opts.sourceURI, _ = c.Flags().GetString("source-uri")
+			opts.variablesWait, _ = c.Flags().GetDuration("variables-wait")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			service.HandleSignals(func() {}, cancel)
+
+			if err := capabilitiesCheck(ctx, opts, streams); err != nil {
+				fmt.Fprintf(streams.Err, "Error: %v\n%s\n", err, troubleshootMessage())
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().String("policy", "", "policy file to check instead of the current configuration")
+	cmd.Flags().String("upgrade-version", "", "version of a proposed upgrade to check (defaults to the current version)")
+	cmd.Flags().String("source-uri", "", "source URI of a proposed upgrade to check")
+	cmd.Flags().Duration("variables-wait", time.Duration(0), "wait this amount of time for variables before performing substitution")
+
+	return cmd
+}
+
+type capabilitiesCheckOpts struct {
+	policy         string
+	upgradeVersion string
+	sourceURI      string
+	variablesWait  time.Duration
+}
+
+func (o capabilitiesCheckOpts) validate() error {
+	if o.upgradeVersion != "" {
+		if _, err := version.ParseVersion(o.upgradeVersion); err != nil {
+			return fmt.Errorf("invalid --upgrade-version %q: %w", o.upgradeVersion, err)
+		}
+	}
+	if o.sourceURI != "" {
+		if _, err := url.Parse(o.sourceURI); err != nil {
+			return fmt.Errorf("invalid --source-uri: %w", err)
+		}
+	}
+	if o.variablesWait < 0 {
+		return fmt.Errorf("invalid --variables-wait %s, must not be negative", o.variablesWait)
+	}
+	return nil
+}
+
+func capabilitiesCheck(ctx context.Context, opts capabilitiesCheckOpts, streams *cli.IOStreams) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	l, err := newErrorLogger()
+	if err != nil {
+		return err
+	}
+
+	caps, err := capabilities.LoadFile(paths.AgentCapabilitiesPath(), l)
+	if err != nil {
+		return fmt.Errorf("failed to load capabilities: %w", err)
+	}
+
+	cfgPath := paths.ConfigFile()
+	if opts.policy != "" {
+		cfgPath = opts.policy
+	}
+	comps, err := getComponentsFromPolicy(ctx, l, caps, cfgPath, opts.variablesWait)
+	if err != nil {
+		// error already includes the context
+		return err
+	}
+
+	return writeCapabilitiesReport(streams.Out, caps, comps, opts)
+}
+
+// writeCapabilitiesReport writes the decisions of caps for the components and the upgrade in opts.
+func writeCapabilitiesReport(w io.Writer, caps capabilities.Capabilities, comps []component.Component, opts capabilitiesCheckOpts) error {
+	_, report := capabilities.FilterComponents(caps, comps)
+
+	upgradeVersion := opts.upgradeVersion
+	if upgradeVersion == "" {
+		upgradeVersion = release.VersionWithSnapshot()
+	}
+	report.Upgrade = capabilities.ExplainUpgradeTo(caps, upgradeVersion, opts.sourceURI)
+
+	data, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal capabilities report: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,144 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/component"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestCapabilitiesCheckCommandFlags(t *testing.T) {
+	cmd := newCapabilitiesCommandWithArgs(nil, cli.NewIOStreams())
+	check, _, err := cmd.Find([]string{"check"})
+	require.NoError(t, err)
+	require.Equal(t, "check", check.Name())
+
+	for _, name := range []string{"policy", "upgrade-version", "source-uri", "variables-wait"} {
+		assert.NotNil(t, check.Flags().Lookup(name), "missing flag %q", name)
+	}
+}
+
+func TestCapabilitiesCheckInvalidInput(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		opts        capabilitiesCheckOpts
+		expectedErr string
+	}{
+		{
+			name:        "invalid upgrade version",
+			opts:        capabilitiesCheckOpts{upgradeVersion: "not-a-version"},
+			expectedErr: `invalid --upgrade-version "not-a-version"`,
+		},
+		{
+			name:        "invalid source uri",
+			opts:        capabilitiesCheckOpts{sourceURI: "https://example.com/%zz"},
+			expectedErr: "invalid --source-uri",
+		},
+		{
+			name:        "negative variables wait",
+			opts:        capabilitiesCheckOpts{variablesWait: -time.Second},
+			expectedErr: "invalid --variables-wait -1s, must not be negative",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, _ := cli.NewTestingIOStreams()
+			err := capabilitiesCheck(context.Background(), tc.opts, streams)
+			require.ErrorContains(t, err, tc.expectedErr)
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestWriteCapabilitiesReport(t *testing.T) {
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  input: log
+- rule: deny
+  upgrade: "${version} == '9.0.0'"
+`), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err)
+
+	comps := []component.Component{
+		{
+			ID:             "system-comp",
+			InputType:      "system/metrics",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "elasticsearch",
+			OutputName:     "default",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units: []component.Unit{
+				{ID: "system-default", Type: client.UnitTypeInput},
+				{ID: "output", Type: client.UnitTypeOutput},
+			},
+		},
+		{
+			ID:             "log-comp",
+			InputType:      "log",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "elasticsearch",
+			OutputName:     "default",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units: []component.Unit{
+				{ID: "log-default", Type: client.UnitTypeInput},
+				{ID: "output", Type: client.UnitTypeOutput},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name            string
+		opts            capabilitiesCheckOpts
+		expectedUpgrade *capabilities.UpgradeDecision
+	}{
+		{
+			name: "upgrade allowed",
+			opts: capabilitiesCheckOpts{upgradeVersion: "9.1.0", sourceURI: "https://example.com"},
+			expectedUpgrade: &capabilities.UpgradeDecision{
+				Version:   "9.1.0",
+				SourceURI: "https://example.com",
+				Decision:  capabilities.Decision{Allowed: true, Rule: -1},
+			},
+		},
+		{
+			name: "upgrade denied",
+			opts: capabilitiesCheckOpts{upgradeVersion: "9.0.0"},
+			expectedUpgrade: &capabilities.UpgradeDecision{
+				Version:  "9.0.0",
+				Decision: capabilities.Decision{Allowed: false, Rule: 1},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, writeCapabilitiesReport(&out, caps, comps, tc.opts))
+
+			var report capabilities.Report
+			require.NoError(t, yaml.Unmarshal(out.Bytes(), &report))
+			assert.Equal(t, []capabilities.InputDecision{
+				{Component: "system-comp", Unit: "system-default", Type: "system/metrics", Runtime: "process", Output: "default", Check: capabilities.CheckInput, Decision: capabilities.Decision{Allowed: true, Rule: -1}},
+				{Component: "log-comp", Unit: "log-default", Type: "log", Runtime: "process", Output: "default", Check: capabilities.CheckInput, Decision: capabilities.Decision{Allowed: false, Rule: 0}},
+			}, report.Inputs)
+			assert.Equal(t, []capabilities.OutputDecision{
+				{Name: "default", Type: "elasticsearch", Decision: capabilities.Decision{Allowed: true, Rule: -1}},
+			}, report.Outputs)
+			assert.Equal(t, tc.expectedUpgrade, report.Upgrade)
+		})
+	}
+}
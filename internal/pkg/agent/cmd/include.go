@@ -11,10 +11,13 @@ import (
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/env"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/filesource"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/host"
+	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/http"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/kubernetes"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/kubernetesleaderelection"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/kubernetessecrets"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/local"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/localdynamic"
 	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/path"
+	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/process"
+	_ "github.com/elastic/elastic-agent/internal/pkg/composable/providers/systemd"
 )
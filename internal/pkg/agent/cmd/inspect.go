@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/config"
 	"github.com/elastic/elastic-agent/internal/pkg/config/operations"
 	"github.com/elastic/elastic-agent/internal/pkg/diagnostics"
+	"github.com/elastic/elastic-agent/internal/pkg/otel/translate"
 	"github.com/elastic/elastic-agent/pkg/component"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/utils"
@@ -261,23 +263,34 @@ type inspectComponentsOpts struct {
 	variablesWait time.Duration
 }
 
-// returns true if the given Capabilities config blocks the given component.
-func blockedByCaps(c component.Component, caps capabilities.Capabilities) bool {
-	return !caps.AllowInput(c.InputType) || !caps.AllowOutput(c.OutputType)
-}
-
 func inspectComponents(ctx context.Context, cfgPath string, opts inspectComponentsOpts, streams *cli.IOStreams) error {
 	l, err := newErrorLogger()
 	if err != nil {
 		return err
 	}
 
-	comps, err := getComponentsFromPolicy(ctx, l, cfgPath, opts.variablesWait)
+	caps, err := capabilities.LoadFile(paths.AgentCapabilitiesPath(), l)
+	if err != nil {
+		return err
+	}
+
+	comps, err := getComponentsFromPolicy(ctx, l, caps, cfgPath, opts.variablesWait)
 	if err != nil {
 		// error already includes the context
 		return err
 	}
 
+	// Capabilities conditions can depend on the unit configuration, filter
+	// the components before it is hidden.
+	filtered, _ := capabilities.FilterComponents(caps, comps)
+	allowedUnits := make(map[string]map[string]bool, len(filtered))
+	for _, c := range filtered {
+		allowedUnits[c.ID] = make(map[string]bool, len(c.Units))
+		for _, u := range c.Units {
+			allowedUnits[c.ID][u.ID] = true
+		}
+	}
+
 	// Hide configuration unless toggled on.
 	if !opts.showConfig {
 		for i, comp := range comps {
@@ -320,24 +333,24 @@ func inspectComponents(ctx context.Context, cfgPath string, opts inspectComponen
 	}
 
 	// Separate any components that are blocked by capabilities config
-	caps, err := capabilities.LoadFile(paths.AgentCapabilitiesPath(), l)
-	if err != nil {
-		return err
-	}
 	allowed := []component.Component{}
 	blocked := []component.Component{}
 	for _, c := range comps {
-		if blockedByCaps(c, caps) {
+		units, ok := allowedUnits[c.ID]
+		if !ok {
 			blocked = append(blocked, c)
-		} else {
-			allowed = append(allowed, c)
+			continue
 		}
+		c.Units = slices.DeleteFunc(slices.Clone(c.Units), func(u component.Unit) bool {
+			return !units[u.ID]
+		})
+		allowed = append(allowed, c)
 	}
 
 	return printComponents(allowed, blocked, streams)
 }
 
-func getComponentsFromPolicy(ctx context.Context, l *logger.Logger, cfgPath string, variablesWait time.Duration, platformModifiers ...component.PlatformModifier) ([]component.Component, error) {
+func getComponentsFromPolicy(ctx context.Context, l *logger.Logger, caps capabilities.Capabilities, cfgPath string, variablesWait time.Duration, platformModifiers ...component.PlatformModifier) ([]component.Component, error) {
 	// Load the requirements before trying to load the configuration. These should always load
 	// even if the configuration is wrong.
 	platform, err := component.LoadPlatformDetail(platformModifiers...)
@@ -375,7 +388,8 @@ func getComponentsFromPolicy(ctx context.Context, l *logger.Logger, cfgPath stri
 		return nil, fmt.Errorf("failed to render components: %w", err)
 	}
 
-	return comps, nil
+	// Resolve the runtime of the components with the auto runtime like the coordinator does.
+	return translate.ResolveAutoRuntimeManager(comps, caps, agentInfo), nil
 }
 
 func getMonitoringFn(ctx context.Context, cfg map[string]interface{}) (component.GenerateMonitoringCfgFn, error) {
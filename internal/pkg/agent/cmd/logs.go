@@ -7,23 +7,24 @@ package cmd
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/control/v2/client"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
@@ -32,12 +33,12 @@ const (
 	// from the end of the file
 	logBufferSize = 1024
 	// when follow logs, on each interval we check log file updates and if a new file appeared
-	watchInterval = 500 * time.Millisecond
+	watchInterval = logger.LogWatchInterval
 )
 
 var (
-	logFilePattern  = regexp.MustCompile(`elastic-agent(-event-log)?-(\d+)(-\d+)?\.ndjson$`)
-	errLineFiltered = errors.New("this line was filtered out")
+	errLineFiltered          = errors.New("this line was filtered out")
+	errDaemonLogsUnavailable = errors.New("the logs cannot be streamed from the daemon")
 )
 
 // filter for each log line, returns `true` if we print the line
@@ -48,48 +49,84 @@ type filterFunc func([]byte) bool
 // the new value must be allocated (byte slice).
 type modifierFunc func([]byte) []byte
 
-// logEntry represents a part of the elastic agent log entry
-type logEntry struct {
-	Component struct {
-		ID string `json:"id"`
-	} `json:"component"`
-	LogLevel string `json:"log.level"`
-}
-
 // createComponentFilter creates a new log entry filter that
 // lets print only the log lines that contain the given component ID.
 func createComponentFilter(id string) filterFunc {
-	return func(entry []byte) bool {
-		var e logEntry
-		err := json.Unmarshal(entry, &e)
-		if err != nil {
-			return false
-		}
-		return e.Component.ID == id
+	return logger.EntryFilter{ComponentID: id}.Match
+}
+
+// parseLogTime parses the value of --since and --until, either a duration
+// before now or an RFC3339 timestamp.
+func parseLogTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor an RFC3339 timestamp", s)
 	}
+	return t, nil
 }
 
 func addColorModifier(entry []byte) []byte {
-	var e logEntry
-	err := json.Unmarshal(entry, &e)
+	e, err := logger.ParseEntry(entry)
 	if err != nil {
 		return entry
 	}
-	switch strings.ToLower(e.LogLevel) {
+	return colorByLevel(e.LogLevel, entry)
+}
 
-	case logp.InfoLevel.String():
+func colorByLevel(logLevel string, entry []byte) []byte {
+	level, ok := logger.ParseEntryLevel(logLevel)
+	if !ok {
+		return entry
+	}
+	switch level {
+	case logp.InfoLevel:
 		return []byte(color.CyanString(string(entry)))
-	case logp.WarnLevel.String():
+	case logp.WarnLevel:
 		return []byte(color.YellowString(string(entry)))
-	case logp.ErrorLevel.String():
+	case logp.ErrorLevel:
 		return []byte(color.RedString(string(entry)))
-	case logp.CriticalLevel.String():
+	case logp.CriticalLevel:
 		return []byte(color.HiRedString(string(entry)))
 	default:
 		return entry
 	}
 }
 
+// createCompactModifier creates a modifier that prints each log entry on a
+// single human readable line: timestamp, level, component and unit, message.
+// Lines that are not valid JSON are printed as they are.
+func createCompactModifier(colored bool) modifierFunc {
+	return func(entry []byte) []byte {
+		e, err := logger.ParseEntry(entry)
+		if err != nil {
+			return entry
+		}
+		var b strings.Builder
+		b.WriteString(e.Timestamp)
+		b.WriteString(" ")
+		b.WriteString(strings.ToUpper(e.LogLevel))
+		if e.Component.ID != "" {
+			b.WriteString(" [")
+			b.WriteString(e.Component.ID)
+			if unitID := e.UnitID(); unitID != "" {
+				b.WriteString("/")
+				b.WriteString(unitID)
+			}
+			b.WriteString("]")
+		}
+		b.WriteString(" ")
+		b.WriteString(e.Message)
+		line := []byte(b.String())
+		if colored {
+			return colorByLevel(e.LogLevel, line)
+		}
+		return line
+	}
+}
+
 // stackWriter collects written byte slices and then pops them in
 // the reversed (LIFO) order.
 // Supports filtering and modification of each written byte slice.
@@ -180,8 +217,15 @@ func newLogsCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
 	cmd.Flags().BoolP("no-color", "", false, "Do not apply colors to different log levels.")
 	cmd.Flags().IntP("number", "n", 10, "Maximum number of lines at the end of logs to output.")
 	cmd.Flags().Bool("exclude-events", false, "Excludes events log files")
+	cmd.Flags().Bool("local", false, "Read the log files even when the Elastic Agent is running, instead of streaming the logs from it.")
 
 	cmd.Flags().StringP("component", "C", "", "Filter logs and output only logs for the given component ID.")
+	cmd.Flags().StringP("unit", "U", "", "Filter logs and output only logs for the given unit ID.")
+	cmd.Flags().StringP("level", "l", "", "Filter logs and output only logs of the given level or above (debug, info, warning, error, critical).")
+	cmd.Flags().String("since", "", "Filter logs and output only logs written since the given duration ago (e.g. 1h) or RFC3339 timestamp.")
+	cmd.Flags().String("until", "", "Filter logs and output only logs written until the given duration ago (e.g. 10m) or RFC3339 timestamp.")
+	cmd.Flags().StringP("message", "m", "", "Filter logs and output only logs with a message matching the given regular expression.")
+	cmd.Flags().StringP("output", "o", "", "Output format: ndjson prints the raw log lines without colors, compact prints one human readable line per log entry.")
 
 	return cmd
 }
@@ -192,18 +236,34 @@ func logsCmd(streams *cli.IOStreams, cmd *cobra.Command, logsDir, eventLogsDir s
 	follow, _ := cmd.Flags().GetBool("follow")
 	noColor, _ := cmd.Flags().GetBool("no-color")
 	excludeEvents, _ := cmd.Flags().GetBool("exclude-events")
+	output, _ := cmd.Flags().GetString("output")
+	local, _ := cmd.Flags().GetBool("local")
+
+	lf, err := newLogFilter(cmd, time.Now())
+	if err != nil {
+		return err
+	}
+	lf.ComponentID = component
 
 	var (
 		filter   filterFunc
 		modifier modifierFunc
 	)
 
-	if component != "" {
-		filter = createComponentFilter(component)
+	if !lf.Empty() {
+		filter = lf.Match
 	}
 
-	if !noColor {
-		modifier = addColorModifier
+	switch output {
+	case "":
+		if !noColor {
+			modifier = addColorModifier
+		}
+	case "ndjson":
+	case "compact":
+		modifier = createCompactModifier(!noColor)
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of ndjson or compact", output)
 	}
 
 	// uncomment for debugging
@@ -212,7 +272,18 @@ func logsCmd(streams *cli.IOStreams, cmd *cobra.Command, logsDir, eventLogsDir s
 	errChan := make(chan error)
 
 	go func() {
-		err := printLogs(cmd.Context(), streams.Out, logsDir, lines, follow, filter, modifier)
+		// the running daemon filters its logs, event logs included, before streaming them, the log files
+		// are only read when it is not running or too old to stream them
+		err := errDaemonLogsUnavailable
+		if !local {
+			err = streamDaemonLogs(cmd.Context(), streams.Out, streams.Err, newDaemonLogsRequest(cmd, lf, lines, follow, excludeEvents), modifier)
+		}
+		if errors.Is(err, errDaemonLogsUnavailable) {
+			if !excludeEvents {
+				go printEventLogs(cmd.Context(), streams.Out, eventLogsDir, lines, follow, filter, modifier, errChan)
+			}
+			err = printLogs(cmd.Context(), streams.Out, logsDir, lines, follow, filter, modifier)
+		}
 		if err != nil {
 			errChan <- fmt.Errorf("failed to get logs: %w", err)
 			return
@@ -220,30 +291,141 @@ func logsCmd(streams *cli.IOStreams, cmd *cobra.Command, logsDir, eventLogsDir s
 		errChan <- nil
 	}()
 
-	if !excludeEvents {
-		go func() {
-			done := false
-			// The event log folder might not exist, so we keep trying every five seconds
-			for !done {
-				err := printLogs(cmd.Context(), streams.Out, eventLogsDir, lines, follow, filter, modifier)
-				if err != nil {
-					if !strings.Contains(err.Error(), "logs/events: no such file or directory") {
-						errChan <- fmt.Errorf("failed to get event logs: %w", err)
-						return
-					}
-					time.Sleep(5 * time.Second)
-				}
+	if err := <-errChan; err != nil {
+		return err
+	}
 
-				done = true
+	return nil
+}
+
+// printEventLogs prints the event log files like printLogs, errors are sent to `errChan`.
+func printEventLogs(ctx context.Context, w io.Writer, dir string, lines int, follow bool, filter filterFunc, modifier modifierFunc, errChan chan<- error) {
+	done := false
+	// The event log folder might not exist, so we keep trying every five seconds
+	for !done {
+		err := printLogs(ctx, w, dir, lines, follow, filter, modifier)
+		if err != nil {
+			if !strings.Contains(err.Error(), "logs/events: no such file or directory") {
+				errChan <- fmt.Errorf("failed to get event logs: %w", err)
+				return
 			}
-		}()
+			time.Sleep(5 * time.Second)
+		}
+
+		done = true
 	}
+}
 
-	if err := <-errChan; err != nil {
-		return err
+// newLogFilter creates the log filter from the unit, level, since, until
+// and message flags of the command.
+func newLogFilter(cmd *cobra.Command, now time.Time) (logger.EntryFilter, error) {
+	var f logger.EntryFilter
+	f.UnitID, _ = cmd.Flags().GetString("unit")
+
+	if level, _ := cmd.Flags().GetString("level"); level != "" {
+		l, ok := logger.ParseEntryLevel(level)
+		if !ok {
+			return f, fmt.Errorf("invalid log level %q", level)
+		}
+		f.Level = &l
 	}
 
-	return nil
+	if since, _ := cmd.Flags().GetString("since"); since != "" {
+		t, err := parseLogTime(since, now)
+		if err != nil {
+			return f, fmt.Errorf("invalid --since: %w", err)
+		}
+		f.Since = t
+	}
+	if until, _ := cmd.Flags().GetString("until"); until != "" {
+		t, err := parseLogTime(until, now)
+		if err != nil {
+			return f, fmt.Errorf("invalid --until: %w", err)
+		}
+		f.Until = t
+	}
+
+	if message, _ := cmd.Flags().GetString("message"); message != "" {
+		re, err := regexp.Compile(message)
+		if err != nil {
+			return f, fmt.Errorf("invalid --message regular expression: %w", err)
+		}
+		f.Message = re
+	}
+	return f, nil
+}
+
+// newDaemonLogsRequest creates the request streaming the logs selected by the flags of the command.
+func newDaemonLogsRequest(cmd *cobra.Command, lf logger.EntryFilter, lines int, follow, excludeEvents bool) client.LogsRequest {
+	level, _ := cmd.Flags().GetString("level")
+	message, _ := cmd.Flags().GetString("message")
+	return client.LogsRequest{
+		Lines:         lines,
+		Follow:        follow,
+		ComponentID:   lf.ComponentID,
+		UnitID:        lf.UnitID,
+		Level:         level,
+		Since:         lf.Since,
+		Until:         lf.Until,
+		Message:       message,
+		ExcludeEvents: excludeEvents,
+	}
+}
+
+// streamDaemonLogs streams the logs of the running daemon to `w` applying the `modifier`, the
+// entries dropped by the daemon are reported to `errW`.
+// errDaemonLogsUnavailable is returned when the daemon is not running or does not support
+// streaming its logs.
+func streamDaemonLogs(ctx context.Context, w, errW io.Writer, req client.LogsRequest, modifier modifierFunc) error {
+	daemon := client.New()
+	err := daemon.Connect(ctx)
+	if err != nil {
+		return errDaemonLogsUnavailable
+	}
+	defer daemon.Disconnect()
+	return receiveDaemonLogs(ctx, daemon, w, errW, req, modifier)
+}
+
+func receiveDaemonLogs(ctx context.Context, daemon client.Client, w, errW io.Writer, req client.LogsRequest, modifier modifierFunc) error {
+	logs, err := daemon.Logs(ctx, req)
+	if err != nil {
+		return daemonLogsError(err)
+	}
+	for received := false; ; received = true {
+		resp, err := logs.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if !received {
+				return daemonLogsError(err)
+			}
+			return err
+		}
+		if resp.Dropped > 0 {
+			_, _ = fmt.Fprintf(errW, "%d log entries were dropped, they were not received fast enough\n", resp.Dropped)
+		}
+		line := resp.Entry
+		if line == nil {
+			continue
+		}
+		if modifier != nil {
+			line = modifier(line)
+		}
+		_, _ = w.Write(line)
+		_, _ = w.Write([]byte{'\n'})
+	}
+}
+
+// daemonLogsError returns errDaemonLogsUnavailable if the error means the logs
+// cannot be streamed from the daemon.
+func daemonLogsError(err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Unimplemented:
+		return errDaemonLogsUnavailable
+	default:
+		return err
+	}
 }
 
 // printLogs prints the last `lines` number of log lines from the log files in `dir`
@@ -312,7 +494,7 @@ func printLogs(ctx context.Context, w io.Writer, dir string, lines int, follow b
 		if filter != nil || modifier != nil {
 			output = newWrappedWriter(ctx, w, filter, modifier)
 		}
-		err = watchLogsDir(ctx, dir, fileToFollow, followOffset, output)
+		err = logger.WatchLogFiles(ctx, dir, fileToFollow, followOffset, output)
 		if err != nil {
 			return fmt.Errorf("failed to follow the logs: %w", err)
 		}
@@ -429,97 +611,12 @@ func printLogFile(filename string, maxLines int, w *stackWriter, buf []byte) (li
 
 // getLogFilenames returns absolute paths to all log files in `dir` sorted in the log rotation order.
 func getLogFilenames(dir string) ([]string, error) {
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list logs directory: %w", err)
-	}
-
-	paths := make([]string, 0, len(entries))
-	for _, e := range entries {
-		if e.IsDir() || !logFilePattern.MatchString(e.Name()) {
-			continue
-		}
-		paths = append(paths, filepath.Join(dir, e.Name()))
-	}
-
-	sortLogFilenames(paths)
-
-	return paths, nil
+	return logger.LogFilenames(dir)
 }
 
 // sortLogFilenames sorts filenames in the order of log rotation
 func sortLogFilenames(filenames []string) {
-	sort.Slice(filenames, func(i, j int) bool {
-		// e.g. elastic-agent-20230515.ndjson => ["elastic-agent-20230515-1.ndjson", "20230515", "-1"]
-		iGroups := logFilePattern.FindStringSubmatch(filenames[i])
-		jGroups := logFilePattern.FindStringSubmatch(filenames[j])
-
-		switch {
-
-		// e.g. elastic-agent-20230515-1.ndjson vs elastic-agent-20230515-2.ndjson
-		case iGroups[2] == jGroups[2] && iGroups[3] != "" && jGroups[3] != "":
-			return iGroups[3] < jGroups[3]
-
-		// e.g. elastic-agent-20230515.ndjson vs elastic-agent-20230515-1.ndjson
-		case iGroups[2] == jGroups[2] && iGroups[3] != "":
-			return false
-
-		// e.g. elastic-agent-20230515-1.ndjson vs elastic-agent-20230515.ndjson
-		case iGroups[2] == jGroups[2] && jGroups[3] != "":
-			return true
-
-		// e.g. elastic-agent-20230515.ndjson vs elastic-agent-20230516.ndjson
-		default:
-			return iGroups[2] < jGroups[2]
-		}
-	})
-}
-
-// watchLogsDir watches the log directory `dir` for new log lines, starting with the given `startFile` at
-// its `startOffset` printing all new content to `w` until the `ctx` is cancelled.
-// Once new log lines are written to `startFile` they are printed to `w`.
-// Once a new log file is created it switches to watching the new file instead.
-// The new state is checked every `watchInterval`.
-func watchLogsDir(ctx context.Context, dir, startFile string, startOffset int64, w io.Writer) (err error) {
-	curFile := startFile
-	curOffset := startOffset
-
-	ticker := time.NewTicker(watchInterval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("watching %s interrupted: %w", startFile, ctx.Err())
-		case <-ticker.C:
-			size, err := getFileSize(curFile)
-			if err != nil {
-				return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
-			}
-			if curOffset != size {
-				curOffset, err = tailFile(curFile, curOffset, w)
-				if err != nil {
-					return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
-				}
-			}
-
-			files, err := getLogFilenames(dir)
-			if err != nil {
-				return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
-			}
-
-			i := len(files) - 1
-			for ; i >= 0; i-- {
-				if files[i] == curFile {
-					break
-				}
-			}
-			if i == len(files)-1 {
-				continue
-			}
-			curFile = files[i+1]
-			curOffset = 0
-		}
-	}
+	logger.SortLogFilenames(filenames)
 }
 
 // getFileSize returns a file size of the given file.
@@ -530,29 +627,3 @@ func getFileSize(file string) (int64, error) {
 	}
 	return info.Size(), nil
 }
-
-// tailFile prints the tail of the `file` to `w` starting from `offset`.
-func tailFile(file string, offset int64, w io.Writer) (size int64, err error) {
-	f, err := os.Open(file)
-	if err != nil {
-		return 0, fmt.Errorf("failed to open file %q: %w", file, err)
-	}
-	defer f.Close()
-
-	_, err = f.Seek(offset, io.SeekStart)
-	if err != nil {
-		return 0, fmt.Errorf("failed to seek to %d in file %q: %w", offset, file, err)
-	}
-
-	_, err = io.Copy(w, f)
-	if err != nil {
-		return size, fmt.Errorf("failed to print file %s: %w", file, err)
-	}
-
-	size, err = getFileSize(file)
-	if err != nil {
-		return size, fmt.Errorf("failed to get file size %s: %w", file, err)
-	}
-
-	return size, nil
-}
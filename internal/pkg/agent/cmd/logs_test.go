@@ -17,9 +17,17 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/elastic/elastic-agent-libs/logp"
 
 	"github.com/elastic/elastic-agent/internal/pkg/cli"
+	"github.com/elastic/elastic-agent/pkg/control/v2/client"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	clientmocks "github.com/elastic/elastic-agent/testing/mocks/pkg/control/v2/client"
 )
 
 const (
@@ -611,6 +619,97 @@ func TestCreateComponentFilter(t *testing.T) {
 	}
 }
 
+func TestLogFilter(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	entry := func(ts, level, component, unit, message string) []byte {
+		return []byte(fmt.Sprintf(`{"@timestamp":%q,"log.level":%q,"component":{"id":%q},"unit":{"id":%q},"message":%q}`, ts, level, component, unit, message))
+	}
+	infoEntry := entry("2025-06-30T11:30:00.000Z", "info", "filestream-default", "filestream-default-logs", "harvester started")
+	warnEntry := entry("2025-06-30T10:00:00.000Z", "warn", "filestream-default", "filestream-default-logs", "harvester closed")
+	dottedUnit := []byte(`{"@timestamp":"2025-06-30T11:59:00.000Z","log.level":"error","component":{"id":"system/metrics-default"},"unit.id":"system/metrics-default-cpu","message":"failed to fetch"}`)
+
+	cases := []struct {
+		name  string
+		flags map[string]string
+		exp   []bool
+	}{
+		{
+			name:  "no flags",
+			flags: map[string]string{},
+			exp:   []bool{true, true, true},
+		},
+		{
+			name:  "unit",
+			flags: map[string]string{"unit": "system/metrics-default-cpu"},
+			exp:   []bool{false, false, true},
+		},
+		{
+			name:  "level",
+			flags: map[string]string{"level": "warning"},
+			exp:   []bool{false, true, true},
+		},
+		{
+			name:  "since duration",
+			flags: map[string]string{"since": "1h"},
+			exp:   []bool{true, false, true},
+		},
+		{
+			name:  "until timestamp",
+			flags: map[string]string{"until": "2025-06-30T11:45:00Z"},
+			exp:   []bool{true, true, false},
+		},
+		{
+			name:  "message",
+			flags: map[string]string{"message": "^harvester (started|stopped)$"},
+			exp:   []bool{true, false, false},
+		},
+		{
+			name:  "combined",
+			flags: map[string]string{"level": "info", "since": "3h", "message": "harvester"},
+			exp:   []bool{true, true, false},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newLogsCommandWithArgs(nil, nil)
+			for k, v := range tc.flags {
+				require.NoError(t, cmd.Flags().Set(k, v))
+			}
+			f, err := newLogFilter(cmd, now)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.flags) == 0, f.Empty())
+			assert.Equal(t, tc.exp, []bool{f.Match(infoEntry), f.Match(warnEntry), f.Match(dottedUnit)})
+		})
+	}
+
+	t.Run("not JSON", func(t *testing.T) {
+		level := logp.DebugLevel
+		f := logger.EntryFilter{Level: &level}
+		assert.False(t, f.Match([]byte("not json")))
+	})
+
+	t.Run("invalid flags", func(t *testing.T) {
+		for flag, value := range map[string]string{"level": "verbose", "since": "yesterday", "until": "2025-06-30", "message": "(harvester"} {
+			cmd := newLogsCommandWithArgs(nil, nil)
+			require.NoError(t, cmd.Flags().Set(flag, value))
+			_, err := newLogFilter(cmd, now)
+			assert.Error(t, err, flag)
+		}
+	})
+}
+
+func TestCompactModifier(t *testing.T) {
+	modifier := createCompactModifier(false)
+	assert.Equal(t,
+		"2025-06-30T11:30:00.000Z INFO [filestream-default/filestream-default-logs] harvester started",
+		string(modifier([]byte(`{"@timestamp":"2025-06-30T11:30:00.000Z","log.level":"info","component":{"id":"filestream-default"},"unit":{"id":"filestream-default-logs"},"message":"harvester started"}`))))
+	assert.Equal(t,
+		"2025-06-30T11:30:00.000Z WARN Unit state changed",
+		string(modifier([]byte(`{"@timestamp":"2025-06-30T11:30:00.000Z","log.level":"warn","message":"Unit state changed"}`))))
+	assert.Equal(t, "not json", string(modifier([]byte("not json"))))
+}
+
 func generateLines(prefix string, start, end int) string {
 	b := strings.Builder{}
 	for i := start; i <= end; i++ {
@@ -677,6 +776,69 @@ func (cw *chanWriter) waitUntilMatch(
 	}
 }
 
+type fakeClientLogs struct {
+	responses []client.LogsResponse
+	err       error
+}
+
+func (f *fakeClientLogs) Recv() (client.LogsResponse, error) {
+	if len(f.responses) == 0 {
+		return client.LogsResponse{}, f.err
+	}
+	resp := f.responses[0]
+	f.responses = f.responses[1:]
+	return resp, nil
+}
+
+func TestReceiveDaemonLogs(t *testing.T) {
+	req := client.LogsRequest{Lines: 10, Level: "warning"}
+	entry := []byte(`{"log.level":"warn","message":"disk almost full"}`)
+
+	t.Run("entries are written with the modifier", func(t *testing.T) {
+		daemon := clientmocks.NewClient(t)
+		daemon.EXPECT().Logs(mock.Anything, req).Return(&fakeClientLogs{responses: []client.LogsResponse{{Entry: entry}, {Entry: entry}}, err: io.EOF}, nil)
+		var out bytes.Buffer
+		err := receiveDaemonLogs(t.Context(), daemon, &out, io.Discard, req, bytes.ToUpper)
+		require.NoError(t, err)
+		upper := string(bytes.ToUpper(entry)) + "\n"
+		assert.Equal(t, upper+upper, out.String())
+	})
+
+	t.Run("dropped entries are reported", func(t *testing.T) {
+		daemon := clientmocks.NewClient(t)
+		daemon.EXPECT().Logs(mock.Anything, req).Return(&fakeClientLogs{responses: []client.LogsResponse{{Entry: entry}, {Dropped: 12}, {Entry: entry}}, err: io.EOF}, nil)
+		var out, errOut bytes.Buffer
+		err := receiveDaemonLogs(t.Context(), daemon, &out, &errOut, req, nil)
+		require.NoError(t, err)
+		assert.Equal(t, string(entry)+"\n"+string(entry)+"\n", out.String())
+		assert.Equal(t, "12 log entries were dropped, they were not received fast enough\n", errOut.String())
+	})
+
+	t.Run("daemon not supporting logs streaming", func(t *testing.T) {
+		daemon := clientmocks.NewClient(t)
+		daemon.EXPECT().Logs(mock.Anything, req).Return(&fakeClientLogs{err: status.Error(codes.Unimplemented, "method Logs not implemented")}, nil)
+		err := receiveDaemonLogs(t.Context(), daemon, io.Discard, io.Discard, req, nil)
+		assert.ErrorIs(t, err, errDaemonLogsUnavailable)
+	})
+
+	t.Run("daemon not running", func(t *testing.T) {
+		daemon := clientmocks.NewClient(t)
+		daemon.EXPECT().Logs(mock.Anything, req).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+		err := receiveDaemonLogs(t.Context(), daemon, io.Discard, io.Discard, req, nil)
+		assert.ErrorIs(t, err, errDaemonLogsUnavailable)
+	})
+
+	t.Run("daemon stopping while streaming", func(t *testing.T) {
+		daemon := clientmocks.NewClient(t)
+		daemon.EXPECT().Logs(mock.Anything, req).Return(&fakeClientLogs{responses: []client.LogsResponse{{Entry: entry}}, err: status.Error(codes.Unavailable, "transport is closing")}, nil)
+		var out bytes.Buffer
+		err := receiveDaemonLogs(t.Context(), daemon, &out, io.Discard, req, nil)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, errDaemonLogsUnavailable)
+		assert.Equal(t, string(entry)+"\n", out.String())
+	})
+}
+
 func TestCobraCmd(t *testing.T) {
 	expectedLines := 10
 	testingStreams, _, out, _ := cli.NewTestingIOStreams()
@@ -688,6 +850,10 @@ func TestCobraCmd(t *testing.T) {
 	if err := cmd.Flags().Set("number", "10"); err != nil {
 		t.Fatalf("could not set flags: %s", err)
 	}
+	// read the files even if an agent is running
+	if err := cmd.Flags().Set("local", "true"); err != nil {
+		t.Fatalf("could not set flags: %s", err)
+	}
 
 	filename := fmt.Sprintf("elastic-agent-%s.ndjson", time.Now().Format("20060102"))
 	createFileContent(t, logsDir, filename, bytes.NewBuffer([]byte(generateLines("foo", 1, 10))))
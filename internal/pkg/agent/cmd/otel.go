@@ -8,12 +8,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/otelcol"
 
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -27,6 +29,9 @@ import (
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
+// supervisedConfigSocketConnectTimeout is the time a supervised collector has to connect to the agent config server.
+const supervisedConfigSocketConnectTimeout = 10 * time.Second
+
 func newOtelCommandWithArgs(args []string, streams *cli.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "otel",
@@ -45,10 +50,14 @@ func newOtelCommandWithArgs(args []string, streams *cli.IOStreams) *cobra.Comman
 			if err != nil {
 				return err
 			}
+			supervisedConfigSocket, err := cmd.Flags().GetString(manager.OtelSupervisedConfigSocketFlagName)
+			if err != nil {
+				return err
+			}
 			if err := prepareEnv(); err != nil {
 				return err
 			}
-			return RunCollector(cmd.Context(), cfgFiles, supervised, supervisedLoggingLevel)
+			return RunCollector(cmd.Context(), cfgFiles, supervised, supervisedLoggingLevel, supervisedConfigSocket)
 		},
 		PreRun: func(c *cobra.Command, args []string) {
 			// hide inherited flags not to bloat help with flags not related to otel
@@ -76,8 +85,8 @@ func hideInheritedFlags(c *cobra.Command) {
 	})
 }
 
-func RunCollector(cmdCtx context.Context, configFiles []string, supervised bool, supervisedLoggingLevel string) error {
-	settings, err := prepareCollectorSettings(configFiles, supervised, supervisedLoggingLevel)
+func RunCollector(cmdCtx context.Context, configFiles []string, supervised bool, supervisedLoggingLevel string, supervisedConfigSocket string) error {
+	settings, observers, err := prepareCollectorSettings(cmdCtx, configFiles, supervised, supervisedLoggingLevel, supervisedConfigSocket)
 	if err != nil {
 		return fmt.Errorf("failed to prepare collector settings: %w", err)
 	}
@@ -102,20 +111,25 @@ func RunCollector(cmdCtx context.Context, configFiles []string, supervised bool,
 	defer cancel()
 	go service.ProcessWindowsControlEvents(stopCollector)
 
-	return otel.Run(ctx, stop, settings)
+	return otel.Run(ctx, stop, settings, observers...)
 }
 
-func prepareCollectorSettings(configFiles []string, supervised bool, supervisedLoggingLevel string) (*otelcol.CollectorSettings, error) {
+// prepareCollectorSettings returns the settings of the collector and the observers of its run.
+func prepareCollectorSettings(ctx context.Context, configFiles []string, supervised bool, supervisedLoggingLevel string, supervisedConfigSocket string) (*otelcol.CollectorSettings, []otel.CollectorObserver, error) {
 	var settings *otelcol.CollectorSettings
+	var observers []otel.CollectorObserver
 	if supervised {
-		// add stdin config provider
-		configProvider, err := agentprovider.NewBufferProvider(os.Stdin)
+		configProvider, err := newSupervisedConfigProvider(ctx, supervisedConfigSocket)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create config provider: %w", err)
+			return nil, nil, fmt.Errorf("failed to create config provider: %w", err)
 		}
 		settings = otel.NewSettings(release.Version(), []string{configProvider.URI()},
 			otel.WithConfigProviderFactory(configProvider.NewFactory()),
 		)
+		// the socket provider acknowledges the configurations once the collector applied them
+		if observer, ok := configProvider.(otel.CollectorObserver); ok {
+			observers = append(observers, observer)
+		}
 
 		// setup logger
 		defaultCfg := logger.DefaultLoggingConfig()
@@ -138,7 +152,7 @@ func prepareCollectorSettings(configFiles []string, supervised bool, supervisedL
 
 		l, err := logger.NewFromConfig("edot", defaultCfg, defaultEventLogCfg, false)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create logger: %w", err)
+			return nil, nil, fmt.Errorf("failed to create logger: %w", err)
 		}
 
 		if logLevelSettingErr != nil {
@@ -153,7 +167,30 @@ func prepareCollectorSettings(configFiles []string, supervised bool, supervisedL
 	} else {
 		settings = otel.NewSettings(release.Version(), configFiles)
 	}
-	return settings, nil
+	return settings, observers, nil
+}
+
+// supervisedConfigProvider is the config provider of a supervised collector.
+type supervisedConfigProvider interface {
+	URI() string
+	NewFactory() confmap.ProviderFactory
+}
+
+// newSupervisedConfigProvider returns the provider of the configuration served by the agent on the socket, the
+// collector then reloads configuration changes in place. Without socket the configuration is read from stdin.
+func newSupervisedConfigProvider(ctx context.Context, configSocket string) (supervisedConfigProvider, error) {
+	if configSocket == "" {
+		// add stdin config provider
+		return agentprovider.NewBufferProvider(os.Stdin)
+	}
+
+	socketProvider := agentprovider.NewSocketProvider(configSocket)
+	connectCtx, cancel := context.WithTimeout(ctx, supervisedConfigSocketConnectTimeout)
+	defer cancel()
+	if err := socketProvider.Connect(connectCtx); err != nil {
+		return nil, err
+	}
+	return socketProvider, nil
 }
 
 func prepareEnv() error {
@@ -6,6 +6,8 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -27,7 +29,7 @@ func TestPrepareCollectorSettings(t *testing.T) {
 		require.NoError(t, w.Close(), "failed to close pipe")
 		os.Stdin = r
 
-		settings, err := prepareCollectorSettings(nil, true, "info")
+		settings, _, err := prepareCollectorSettings(t.Context(), nil, true, "info", "")
 		require.NoError(t, err, "failed to prepare collector settings")
 		require.NotNil(t, settings, "settings should not be nil")
 		require.NotNil(t, settings.ConfigProviderSettings.ResolverSettings.URIs, "URIs should not be nil")
@@ -43,7 +45,7 @@ func TestPrepareCollectorSettings(t *testing.T) {
 	})
 
 	t.Run("returns valid settings in standalone mode", func(t *testing.T) {
-		settings, err := prepareCollectorSettings([]string{"fake-config.yaml"}, false, "info")
+		settings, _, err := prepareCollectorSettings(t.Context(), []string{"fake-config.yaml"}, false, "info", "")
 		require.NoError(t, err, "failed to prepare collector settings")
 		require.NotNil(t, settings, "settings should not be nil")
 		require.Contains(t, settings.ConfigProviderSettings.ResolverSettings.URIs, "fake-config.yaml", "fake-config.yaml not found in the URIS of ConfigProviderSettings")
@@ -59,7 +61,18 @@ func TestPrepareCollectorSettings(t *testing.T) {
 		require.NoError(t, w.Close(), "failed to close pipe")
 		os.Stdin = r
 
-		settings, err := prepareCollectorSettings(nil, true, "info")
+		settings, _, err := prepareCollectorSettings(t.Context(), nil, true, "info", "")
+		require.Error(t, err)
+		require.Nil(t, settings)
+	})
+
+	t.Run("fails when supervised mode cannot connect to the config socket", func(t *testing.T) {
+		configSocket := "unix://" + filepath.Join(t.TempDir(), "missing.sock")
+		if runtime.GOOS == "windows" {
+			configSocket = "npipe:///elastic-agent-missing-otel-config"
+		}
+
+		settings, _, err := prepareCollectorSettings(t.Context(), nil, true, "info", configSocket)
 		require.Error(t, err)
 		require.Nil(t, settings)
 	})
@@ -74,7 +87,7 @@ func TestPrepareCollectorSettings(t *testing.T) {
 		require.NoError(t, w.Close(), "failed to close pipe")
 		os.Stdin = r
 
-		settings, err := prepareCollectorSettings(nil, false, "info")
+		settings, _, err := prepareCollectorSettings(t.Context(), nil, false, "info", "")
 		require.NoError(t, err)
 		require.NotNil(t, settings)
 	})
@@ -121,6 +121,9 @@ func listComponentState(l list.Writer, components []client.ComponentState, all b
 		l.AppendItem(c.ID)
 		l.Indent()
 		l.AppendItem(formatStatus(c.State, c.Message))
+		if c.RuntimeFallbackReason != "" {
+			l.AppendItem(fmt.Sprintf("process runtime: %s", c.RuntimeFallbackReason))
+		}
 		l.UnIndent()
 		for _, u := range c.Units {
 			if !all && (u.State == client.Healthy) {
@@ -233,6 +236,9 @@ func listUpgradeDetails(l list.Writer, upgradeDetails *cproto.UpgradeDetails) {
 		if upgradeDetails.Metadata.Reason != "" {
 			l.AppendItem("reason: " + upgradeDetails.Metadata.Reason)
 		}
+		if upgradeDetails.Metadata.FailedGate != "" {
+			l.AppendItem("failed_gate: " + upgradeDetails.Metadata.FailedGate)
+		}
 		l.UnIndent()
 	}
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/install"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 	"github.com/elastic/elastic-agent/internal/pkg/cli"
 	"github.com/elastic/elastic-agent/pkg/component"
 	"github.com/elastic/elastic-agent/pkg/control/v2/client/wait"
@@ -126,9 +127,13 @@ func ensureNoServiceComponentIssues() error {
 	if err != nil {
 		return fmt.Errorf("failed to create error logger: %w", err)
 	}
+	caps, err := capabilities.LoadFile(paths.AgentCapabilitiesPath(), l)
+	if err != nil {
+		return fmt.Errorf("failed to load capabilities: %w", err)
+	}
 	// this forces the component calculation to always compute with no root
 	// this allows any runtime preventions to error for a component when it has a no root support
-	comps, err := getComponentsFromPolicy(ctx, l, paths.ConfigFile(), 0, forceNonRoot)
+	comps, err := getComponentsFromPolicy(ctx, l, caps, paths.ConfigFile(), 0, forceNonRoot)
 	if err != nil {
 		return fmt.Errorf("failed to create component model from policy: %w", err)
 	}
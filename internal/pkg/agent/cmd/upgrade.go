@@ -32,7 +32,10 @@ const (
 	flagPGPBytes       = "pgp"
 	flagPGPBytesPath   = "pgp-path"
 	flagPGPBytesURI    = "pgp-uri"
+	flagCosignKey      = "cosign-key"
 	flagForce          = "force"
+	flagRollback       = "rollback"
+	flagDryRun         = "dry-run"
 )
 
 var (
@@ -40,14 +43,23 @@ var (
 	nonRootExecutionError           = errors.New("upgrade command needs to be executed as root for fleet managed agents")
 	skipVerifyNotAllowedError       = errors.New(fmt.Sprintf("\"%s\" flag is not allowed when upgrading a fleet managed agent using the cli", flagSkipVerify))
 	skipVerifyNotRootError          = errors.New(fmt.Sprintf("user needs to be root to use \"%s\" flag when upgrading standalone agents", flagSkipVerify))
+	rollbackVersionError            = errors.New(fmt.Sprintf("version argument is not allowed with \"%s\" flag, the agent is rolled back to the previous version", flagRollback))
+	missingVersionError             = errors.New(fmt.Sprintf("version argument is required, unless \"%s\" flag is set", flagRollback))
 )
 
 func newUpgradeCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "upgrade <version>",
+		Use:   "upgrade [<version>]",
 		Short: "Upgrade the currently installed Elastic Agent to the specified version",
-		Long:  "This command upgrades the currently installed Elastic Agent to the specified version.",
-		Args:  cobra.ExactArgs(1),
+		Long: `This command upgrades the currently installed Elastic Agent to the specified version.
+
+With --rollback the Elastic Agent is rolled back to the version it was upgraded from, as long as that version
+is still kept on disk (see agent.upgrade.rollback.window).
+
+With --dry-run the new version is downloaded, verified and unpacked in a temporary directory and checked against
+the running agent, without switching versions. A report of the checks is printed. Fleet managed agents can run a
+dry-run without --force.`,
+		Args: cobra.RangeArgs(0, 1),
 		Run: func(c *cobra.Command, args []string) {
 			c.SetContext(context.Background())
 			if err := upgradeCmd(streams, c, args); err != nil {
@@ -63,7 +75,10 @@ func newUpgradeCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Comman
 	cmd.Flags().String(flagPGPBytes, "", "PGP to use for package verification")
 	cmd.Flags().String(flagPGPBytesURI, "", "Path to a web location containing PGP to use for package verification")
 	cmd.Flags().String(flagPGPBytesPath, "", "Path to a file containing PGP to use for package verification")
+	cmd.Flags().String(flagCosignKey, "", "Path to a file containing a cosign public key to use for package verification, requires agent.download.verifier to be cosign")
 	cmd.Flags().BoolP(flagForce, "", false, "Advanced option to force an upgrade on a fleet managed agent")
+	cmd.Flags().BoolP(flagRollback, "", false, "Rollback to the version the Elastic Agent was upgraded from")
+	cmd.Flags().BoolP(flagDryRun, "", false, "Run the upgrade preflight checks without switching versions")
 	err := cmd.Flags().MarkHidden(flagForce)
 	if err != nil {
 		fmt.Fprintf(streams.Err, "error while setting upgrade force flag attributes: %s", err.Error())
@@ -118,11 +133,13 @@ type upgradeCond struct {
 	force      bool
 	isRoot     bool
 	skipVerify bool
+	dryRun     bool
 }
 
 func checkUpgradable(cond upgradeCond) error {
 	checkManaged := func() error {
-		if !cond.force {
+		// a dry-run does not switch versions, it does not conflict with Fleet
+		if !cond.force && !cond.dryRun {
 			return unsupportedUpgradeError
 		}
 
@@ -154,6 +171,18 @@ func checkUpgradable(cond upgradeCond) error {
 func upgradeCmdWithClient(input *upgradeInput) error {
 	cmd := input.cmd
 	c := input.c
+
+	rollback, err := cmd.Flags().GetBool(flagRollback)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s flag information while upgrading the agent: %w", flagRollback, err)
+	}
+	if rollback {
+		return rollbackCmdWithClient(input)
+	}
+
+	if len(input.args) == 0 {
+		return missingVersionError
+	}
 	version := input.args[0]
 	sourceURI, _ := cmd.Flags().GetString(flagSourceURI)
 
@@ -167,11 +196,17 @@ func upgradeCmdWithClient(input *upgradeInput) error {
 		return fmt.Errorf("failed to retrieve %s flag information while upgrading the agent: %w", flagSkipVerify, err)
 	}
 
+	dryRun, err := cmd.Flags().GetBool(flagDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s flag information while upgrading the agent: %w", flagDryRun, err)
+	}
+
 	err = checkUpgradable(upgradeCond{
 		isManaged:  input.agentInfo.IsManaged,
 		force:      force,
 		isRoot:     input.isRoot,
 		skipVerify: skipVerification,
+		dryRun:     dryRun,
 	})
 	if err != nil {
 		return fmt.Errorf("aborting upgrade: %w", err)
@@ -185,7 +220,8 @@ func upgradeCmdWithClient(input *upgradeInput) error {
 		return errors.New("an upgrade is already in progress; please try again later.")
 	}
 
-	var pgpChecks []string
+	var verificationChecks []string
+	var cosignKeys []string
 	if !skipVerification {
 		// get local PGP
 		pgpPath, _ := cmd.Flags().GetString(flagPGPBytesPath)
@@ -195,13 +231,13 @@ func upgradeCmdWithClient(input *upgradeInput) error {
 				return errors.New(err, "failed to read pgp file")
 			}
 			if len(content) > 0 {
-				pgpChecks = append(pgpChecks, download.PgpSourceRawPrefix+string(content))
+				verificationChecks = append(verificationChecks, download.PgpSourceRawPrefix+string(content))
 			}
 		}
 
 		pgpBytes, _ := cmd.Flags().GetString(flagPGPBytes)
 		if len(pgpBytes) > 0 {
-			pgpChecks = append(pgpChecks, download.PgpSourceRawPrefix+pgpBytes)
+			verificationChecks = append(verificationChecks, download.PgpSourceRawPrefix+pgpBytes)
 		}
 
 		pgpUri, _ := cmd.Flags().GetString(flagPGPBytesURI)
@@ -211,11 +247,25 @@ func upgradeCmdWithClient(input *upgradeInput) error {
 			}
 
 			// URI is parsed later with proper TLS and Proxy config within downloader
-			pgpChecks = append(pgpChecks, download.PgpSourceURIPrefix+pgpUri)
+			verificationChecks = append(verificationChecks, download.PgpSourceURIPrefix+pgpUri)
+		}
+
+		cosignKeyPath, _ := cmd.Flags().GetString(flagCosignKey)
+		if len(cosignKeyPath) > 0 {
+			content, err := os.ReadFile(cosignKeyPath)
+			if err != nil {
+				return errors.New(err, "failed to read cosign key file")
+			}
+			if len(content) > 0 {
+				cosignKeys = append(cosignKeys, string(content))
+			}
 		}
 	}
 	skipDefaultPgp, _ := cmd.Flags().GetBool(flagSkipDefaultPgp)
-	version, err = c.Upgrade(context.Background(), version, sourceURI, skipVerification, skipDefaultPgp, pgpChecks...)
+	if dryRun {
+		return upgradeDryRun(input, version, sourceURI, skipVerification, skipDefaultPgp, cosignKeys, verificationChecks...)
+	}
+	result, err := c.Upgrade(context.Background(), version, sourceURI, skipVerification, skipDefaultPgp, cosignKeys, verificationChecks...)
 	if err != nil {
 		s, ok := status.FromError(err)
 		// Sometimes the gRPC server shuts down before replying to the command which is expected
@@ -226,6 +276,77 @@ func upgradeCmdWithClient(input *upgradeInput) error {
 			return errors.New(err, "Failed trigger upgrade of daemon")
 		}
 	}
-	fmt.Fprintf(input.streams.Out, "Upgrade triggered to version %s, Elastic Agent is currently restarting\n", version)
+	if result.ScheduledAt != nil {
+		fmt.Fprintf(input.streams.Out, "Upgrade to version %s scheduled at %s, the start of the next upgrade window\n", result.Version, result.ScheduledAt.Local().Format(time.RFC3339))
+		return nil
+	}
+	fmt.Fprintf(input.streams.Out, "Upgrade triggered to version %s, Elastic Agent is currently restarting\n", result.Version)
+	return nil
+}
+
+func upgradeDryRun(input *upgradeInput, version string, sourceURI string, skipVerification bool, skipDefaultPgp bool, cosignKeys []string, verificationChecks ...string) error {
+	report, err := input.c.UpgradePreflight(context.Background(), version, sourceURI, skipVerification, skipDefaultPgp, cosignKeys, verificationChecks...)
+	if report != nil {
+		fmt.Fprintf(input.streams.Out, "Upgrade preflight checks for version %s:\n", report.Version)
+		for _, check := range report.Checks {
+			if check.Message == "" {
+				fmt.Fprintf(input.streams.Out, "  [%s] %s\n", check.Status, check.Name)
+				continue
+			}
+			fmt.Fprintf(input.streams.Out, "  [%s] %s: %s\n", check.Status, check.Name, check.Message)
+		}
+	}
+	if err != nil {
+		return errors.New(err, "Upgrade dry-run failed")
+	}
+	fmt.Fprintf(input.streams.Out, "Upgrade to version %s would succeed\n", version)
+	return nil
+}
+
+func rollbackCmdWithClient(input *upgradeInput) error {
+	cmd := input.cmd
+	c := input.c
+
+	if len(input.args) > 0 {
+		return fmt.Errorf("aborting rollback: %w", rollbackVersionError)
+	}
+	for _, flag := range []string{flagSourceURI, flagSkipVerify, flagSkipDefaultPgp, flagPGPBytes, flagPGPBytesPath, flagPGPBytesURI, flagCosignKey, flagDryRun} {
+		if cmd.Flags().Changed(flag) {
+			return fmt.Errorf("aborting rollback: \"%s\" flag is not allowed with \"%s\" flag", flag, flagRollback)
+		}
+	}
+
+	force, err := cmd.Flags().GetBool(flagForce)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve command flag information while trying to rollback the agent: %w", err)
+	}
+
+	err = checkUpgradable(upgradeCond{
+		isManaged: input.agentInfo.IsManaged,
+		force:     force,
+		isRoot:    input.isRoot,
+	})
+	if err != nil {
+		return fmt.Errorf("aborting rollback: %w", err)
+	}
+
+	isBeingUpgraded, err := upgrade.IsInProgress(c, utils.GetWatcherPIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check if upgrade is already in progress: %w", err)
+	}
+	if isBeingUpgraded {
+		return errors.New("an upgrade is already in progress; please try again later.")
+	}
+
+	err = c.Rollback(context.Background())
+	if err != nil {
+		s, ok := status.FromError(err)
+		// see upgradeCmdWithClient, the server may shut down before replying
+		isConnectionInterrupted := ok && s.Code() == codes.Unavailable && strings.Contains(s.Message(), "EOF")
+		if !isConnectionInterrupted {
+			return errors.New(err, "Failed trigger rollback of daemon")
+		}
+	}
+	fmt.Fprintln(input.streams.Out, "Rollback triggered, Elastic Agent is currently restarting")
 	return nil
 }
@@ -6,8 +6,11 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
+	"os"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -143,7 +146,7 @@ func TestUpgradeCmd(t *testing.T) {
 	t.Run("proceed with upgrade if fleet managed, privileged, --force is set", func(t *testing.T) {
 		mockClient := clientmocks.NewClient(t)
 		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
-		mockClient.EXPECT().Upgrade(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("mockVersion", nil)
+		mockClient.EXPECT().Upgrade(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(client.UpgradeResult{Version: "mockVersion"}, nil)
 
 		args := []string{"8.13.0"} // Version argument
 		streams := cli.NewIOStreams()
@@ -231,7 +234,7 @@ func TestUpgradeCmd(t *testing.T) {
 	t.Run("proceed with upgrade if agent is standalone, user is privileged and skip-verify flag is set", func(t *testing.T) {
 		mockClient := clientmocks.NewClient(t)
 		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
-		mockClient.EXPECT().Upgrade(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return("mockVersion", nil)
+		mockClient.EXPECT().Upgrade(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(client.UpgradeResult{Version: "mockVersion"}, nil)
 
 		args := []string{"8.13.0"} // Version argument
 		streams := cli.NewIOStreams()
@@ -260,6 +263,217 @@ func TestUpgradeCmd(t *testing.T) {
 		err = upgradeCmdWithClient(commandInput)
 		assert.NoError(t, err)
 	})
+	t.Run("upgrade scheduled outside the upgrade windows", func(t *testing.T) {
+		scheduledAt := time.Date(2025, 7, 5, 2, 0, 0, 0, time.UTC)
+		mockClient := clientmocks.NewClient(t)
+		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
+		mockClient.EXPECT().Upgrade(mock.Anything, "8.13.0", "", false, false, []string(nil)).Return(client.UpgradeResult{Version: "8.13.0", ScheduledAt: &scheduledAt}, nil)
+
+		args := []string{"8.13.0"} // Version argument
+		streams, _, out, _ := cli.NewTestingIOStreams()
+
+		cmd := newUpgradeCommandWithArgs(args, streams)
+		cmd.SetContext(context.Background())
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			args,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			false,
+		}
+
+		err := upgradeCmdWithClient(commandInput)
+		require.NoError(t, err)
+		assert.Equal(t, "Upgrade to version 8.13.0 scheduled at "+scheduledAt.Local().Format(time.RFC3339)+", the start of the next upgrade window\n", out.String())
+	})
+	t.Run("pass the cosign key to the daemon", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+		require.NoError(t, os.WriteFile(keyPath, []byte("cosign-public-key"), 0o600))
+
+		mockClient := clientmocks.NewClient(t)
+		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
+		mockClient.EXPECT().Upgrade(mock.Anything, "8.13.0", "", false, false, []string{"cosign-public-key"}).Return(client.UpgradeResult{Version: "8.13.0"}, nil)
+
+		args := []string{"8.13.0"} // Version argument
+		streams := cli.NewIOStreams()
+
+		cmd := newUpgradeCommandWithArgs(args, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagCosignKey, keyPath)
+		require.NoError(t, err)
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			args,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			false,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.NoError(t, err)
+	})
+
+	t.Run("run a dry-run on a fleet managed agent without force flag", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
+		mockClient.EXPECT().UpgradePreflight(mock.Anything, "8.13.0", "", false, false, []string(nil)).Return(&client.UpgradePreflightReport{
+			Version: "8.13.0",
+			Checks: []client.UpgradePreflightCheck{
+				{Name: "capabilities", Status: "passed"},
+				{Name: "artifact", Status: "failed", Message: "signature mismatch"},
+			},
+		}, errors.New("upgrade preflight checks failed"))
+
+		args := []string{"8.13.0"} // Version argument
+		streams, _, out, _ := cli.NewTestingIOStreams()
+
+		cmd := newUpgradeCommandWithArgs(args, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagDryRun, "true")
+		require.NoError(t, err)
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			args,
+			mockClient,
+			client.AgentStateInfo{IsManaged: true},
+			true,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.ErrorContains(t, err, "upgrade preflight checks failed")
+		assert.Contains(t, out.String(), "[passed] capabilities")
+		assert.Contains(t, out.String(), "[failed] artifact: signature mismatch")
+	})
+
+	t.Run("fail if version is missing without rollback flag", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+
+		streams := cli.NewIOStreams()
+		cmd := newUpgradeCommandWithArgs(nil, streams)
+		cmd.SetContext(context.Background())
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			nil,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			true,
+		}
+
+		err := upgradeCmdWithClient(commandInput)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), missingVersionError.Error())
+	})
+	t.Run("fail if rollback flag is set with a version", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+
+		args := []string{"8.13.0"} // Version argument
+		streams := cli.NewIOStreams()
+		cmd := newUpgradeCommandWithArgs(args, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagRollback, "true")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			args,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			true,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), rollbackVersionError.Error())
+	})
+	t.Run("fail if rollback flag is set with a source uri", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+
+		streams := cli.NewIOStreams()
+		cmd := newUpgradeCommandWithArgs(nil, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagRollback, "true")
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = cmd.Flags().Set(flagSourceURI, "https://example.com")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			nil,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			true,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), flagSourceURI)
+	})
+	t.Run("fail rollback if fleet managed without force flag", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+
+		streams := cli.NewIOStreams()
+		cmd := newUpgradeCommandWithArgs(nil, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagRollback, "true")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			nil,
+			mockClient,
+			client.AgentStateInfo{IsManaged: true},
+			true,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), unsupportedUpgradeError.Error())
+	})
+	t.Run("proceed with rollback if agent is standalone", func(t *testing.T) {
+		mockClient := clientmocks.NewClient(t)
+		mockClient.EXPECT().State(mock.Anything).Return(&client.AgentState{State: cproto.State_HEALTHY}, nil)
+		mockClient.EXPECT().Rollback(mock.Anything).Return(nil)
+
+		streams, _, out, _ := cli.NewTestingIOStreams()
+		cmd := newUpgradeCommandWithArgs(nil, streams)
+		cmd.SetContext(context.Background())
+		err := cmd.Flags().Set(flagRollback, "true")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		commandInput := &upgradeInput{
+			streams,
+			cmd,
+			nil,
+			mockClient,
+			client.AgentStateInfo{IsManaged: false},
+			true,
+		}
+
+		err = upgradeCmdWithClient(commandInput)
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "Rollback triggered")
+	})
 }
 
 type mockServer struct {
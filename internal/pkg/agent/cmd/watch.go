@@ -53,7 +53,7 @@ func newWatchCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command
 			// Make sure to flush any buffered logs before we're done.
 			defer log.Sync() //nolint:errcheck // flushing buffered logs is best effort.
 
-			if err := watchCmd(log, paths.Top(), cfg.Settings.Upgrade.Watcher, new(upgradeAgentWatcher), new(upgradeInstallationModifier)); err != nil {
+			if err := watchCmd(log, paths.Top(), cfg.Settings.Upgrade.Watcher, cfg.Settings.Upgrade.Rollback, new(upgradeAgentWatcher), new(upgradeInstallationModifier)); err != nil {
 				log.Errorw("Watch command failed", "error.message", err)
 				fmt.Fprintf(streams.Err, "Watch command failed: %v\n%s\n", err, troubleshootMessage())
 				os.Exit(4)
@@ -65,7 +65,7 @@ func newWatchCommandWithArgs(_ []string, streams *cli.IOStreams) *cobra.Command
 }
 
 type agentWatcher interface {
-	Watch(ctx context.Context, tilGrace, errorCheckInterval time.Duration, log *logp.Logger) error
+	Watch(ctx context.Context, tilGrace, errorCheckInterval time.Duration, gates configuration.UpgradeWatcherGatesConfig, log *logp.Logger) error
 }
 
 type installationModifier interface {
@@ -73,8 +73,15 @@ type installationModifier interface {
 	Rollback(ctx context.Context, log *logger.Logger, c client.Client, topDirPath, prevVersionedHome, prevHash string) error
 }
 
-func watchCmd(log *logp.Logger, topDir string, cfg *configuration.UpgradeWatcherConfig, watcher agentWatcher, installModifier installationModifier) error {
+func watchCmd(log *logp.Logger, topDir string, cfg *configuration.UpgradeWatcherConfig, rollbackCfg *configuration.UpgradeRollbackConfig, watcher agentWatcher, installModifier installationModifier) error {
 	log.Infow("Upgrade Watcher started", "process.pid", os.Getpid(), "agent.version", version.GetAgentPackageVersion(), "config", cfg)
+	// an invalid health gates configuration must not leave the upgrade unwatched,
+	// the built-in crash and error checks still apply without the gates.
+	gates := cfg.Gates
+	if err := gates.Check(); err != nil {
+		log.Errorw("Invalid upgrade watcher health gates, watching without them", "error.message", err)
+		gates = configuration.UpgradeWatcherGatesConfig{}
+	}
 	dataDir := paths.DataFrom(topDir)
 	marker, err := upgrade.LoadMarker(dataDir)
 	if err != nil {
@@ -84,6 +91,10 @@ func watchCmd(log *logp.Logger, topDir string, cfg *configuration.UpgradeWatcher
 	if marker == nil {
 		// no marker found we're not in upgrade process
 		log.Infof("update marker not present at '%s'", dataDir)
+		// the previous version kept for a manual rollback is removed once its rollback window has expired
+		if err := upgrade.RemoveExpiredRollback(log, topDir, time.Now()); err != nil {
+			log.Error("removing expired rollback failed", err)
+		}
 		return nil
 	}
 
@@ -129,10 +140,15 @@ func watchCmd(log *logp.Logger, topDir string, cfg *configuration.UpgradeWatcher
 
 	errorCheckInterval := cfg.ErrorCheck.Interval
 	ctx := context.Background()
-	if err := watcher.Watch(ctx, tilGrace, errorCheckInterval, log); err != nil {
+	if err := watcher.Watch(ctx, tilGrace, errorCheckInterval, gates, log); err != nil {
 		log.Error("Error detected, proceeding to rollback: %v", err)
 
-		upgradeDetails.SetStateWithReason(details.StateRollback, details.ReasonWatchFailed)
+		var gateErr *upgrade.HealthGateError
+		if errors.As(err, &gateErr) {
+			upgradeDetails.SetStateWithFailedGate(details.StateRollback, gateErr.Gate)
+		} else {
+			upgradeDetails.SetStateWithReason(details.StateRollback, details.ReasonWatchFailed)
+		}
 		err = installModifier.Rollback(ctx, log, client.New(), paths.Top(), marker.PrevVersionedHome, marker.PrevHash)
 		if err != nil {
 			log.Error("rollback failed", err)
@@ -144,6 +160,20 @@ func watchCmd(log *logp.Logger, topDir string, cfg *configuration.UpgradeWatcher
 	// watch succeeded - upgrade was successful!
 	upgradeDetails.SetState(details.StateCompleted)
 
+	// keep the previous version around so that it can be manually rolled back to within the rollback window,
+	// cleanup below skips it.
+	if marker.DesiredOutcome == upgrade.OUTCOME_UPGRADE && rollbackCfg != nil && rollbackCfg.Window > 0 {
+		err = upgrade.SaveAvailableRollback(dataDir, &upgrade.AvailableRollback{
+			Version:       marker.PrevVersion,
+			Hash:          marker.PrevHash,
+			VersionedHome: marker.PrevVersionedHome,
+			ValidUntil:    time.Now().Add(rollbackCfg.Window),
+		})
+		if err != nil {
+			log.Error("saving available rollback failed", err)
+		}
+	}
+
 	// cleanup older versions,
 	// in windows it might leave self untouched, this will get cleaned up
 	// later at the start, because for windows we leave marker untouched.
@@ -180,7 +210,7 @@ func isWindows() bool {
 	return runtime.GOOS == "windows"
 }
 
-func watch(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, log *logger.Logger) error {
+func watch(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, gates configuration.UpgradeWatcherGatesConfig, log *logger.Logger) error {
 	errChan := make(chan error)
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -192,6 +222,10 @@ func watch(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.
 	}()
 
 	agentWatcher := upgrade.NewAgentWatcher(errChan, log, errorCheckInterval)
+	if err := agentWatcher.SetHealthGates(gates); err != nil {
+		// the gates are validated before watching, an upgrade is not rolled back because of them
+		log.Errorf("Ignoring the upgrade health gates: %s", err)
+	}
 	go agentWatcher.Run(ctx)
 
 	signals := make(chan os.Signal, 1)
@@ -265,7 +299,7 @@ func getConfig(streams *cli.IOStreams) *configuration.Configuration {
 
 	cfg, err := configuration.NewFromConfig(rawConfig)
 	if err != nil {
-		fmt.Fprintf(streams.Err, "could not parse configuration file %s", pathConfigFile)
+		fmt.Fprintf(streams.Err, "could not parse configuration file %s: %v", pathConfigFile, err)
 		return defaultCfg
 	}
 
@@ -10,14 +10,15 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/pkg/control/v2/client"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 type upgradeAgentWatcher struct{}
 
-func (a upgradeAgentWatcher) Watch(ctx context.Context, tilGrace, errorCheckInterval time.Duration, log *logp.Logger) error {
-	return watch(ctx, tilGrace, errorCheckInterval, log)
+func (a upgradeAgentWatcher) Watch(ctx context.Context, tilGrace, errorCheckInterval time.Duration, gates configuration.UpgradeWatcherGatesConfig, log *logp.Logger) error {
+	return watch(ctx, tilGrace, errorCheckInterval, gates, log)
 }
 
 type upgradeInstallationModifier struct{}
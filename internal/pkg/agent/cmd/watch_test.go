@@ -5,8 +5,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -21,8 +23,11 @@ import (
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade/details"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/cli"
 	"github.com/elastic/elastic-agent/internal/pkg/fleetapi"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
+	"github.com/elastic/elastic-agent/pkg/control/v2/client"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/upgrade"
@@ -85,9 +90,33 @@ func TestInitUpgradeDetails(t *testing.T) {
 	require.Equal(t, `unable to save upgrade marker after clearing upgrade details: some error`, logs[0].Message)
 }
 
+func TestGetConfigInvalidGates(t *testing.T) {
+	configDir := t.TempDir()
+	prevConfig := paths.Config()
+	paths.SetConfig(configDir)
+	t.Cleanup(func() { paths.SetConfig(prevConfig) })
+
+	cfgContent := `
+agent.upgrade.watcher:
+  grace_period: 3m
+  error_check.interval: 5s
+  gates:
+    condition: "$${state} =="
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, paths.DefaultConfigName), []byte(cfgContent), 0o600))
+
+	// an invalid health gates configuration must not reset the rest of the watcher configuration
+	cfg := getConfig(cli.NewIOStreams())
+	watcherCfg := cfg.Settings.Upgrade.Watcher
+	assert.Equal(t, 3*time.Minute, watcherCfg.GracePeriod)
+	assert.Equal(t, 5*time.Second, watcherCfg.ErrorCheck.Interval)
+	assert.ErrorContains(t, watcherCfg.Gates.Check(), "invalid condition")
+}
+
 func Test_watchCmd(t *testing.T) {
 	type args struct {
-		cfg *configuration.UpgradeWatcherConfig
+		cfg         *configuration.UpgradeWatcherConfig
+		rollbackCfg *configuration.UpgradeRollbackConfig
 	}
 	tests := []struct {
 		name               string
@@ -107,6 +136,52 @@ func Test_watchCmd(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "invalid health gates are ignored, crash loop still rolls back",
+			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
+				dataDirPath := paths.DataFrom(topDir)
+				err := os.MkdirAll(dataDirPath, 0755)
+				require.NoError(t, err)
+				err = upgrade.SaveMarker(
+					dataDirPath,
+					&upgrade.UpdateMarker{
+						Version:           "4.5.6",
+						Hash:              "newver",
+						VersionedHome:     "elastic-agent-4.5.6-newver",
+						UpdatedOn:         time.Now(),
+						PrevVersion:       "1.2.3",
+						PrevHash:          "prvver",
+						PrevVersionedHome: "elastic-agent-prvver",
+						DesiredOutcome:    upgrade.OUTCOME_UPGRADE,
+					},
+					true,
+				)
+				require.NoError(t, err)
+
+				// the watcher runs without any gate and detects the crash loop
+				watcher.EXPECT().
+					Watch(mock.Anything, mock.Anything, mock.Anything, configuration.UpgradeWatcherGatesConfig{}, mock.Anything).
+					Return(errors.New("service restarted 3 times"))
+				installModifier.EXPECT().
+					Rollback(mock.Anything, mock.Anything, mock.Anything, paths.Top(), "elastic-agent-prvver", "prvver").
+					RunAndReturn(func(_ context.Context, _ *logger.Logger, _ client.Client, _, _, _ string) error {
+						marker, err := upgrade.LoadMarker(dataDirPath)
+						require.NoError(t, err)
+						require.NotNil(t, marker.Details)
+						assert.Equal(t, details.StateRollback, marker.Details.State)
+						assert.Equal(t, details.ReasonWatchFailed, marker.Details.Metadata.Reason)
+						return nil
+					})
+			},
+			args: args{
+				cfg: &configuration.UpgradeWatcherConfig{
+					GracePeriod: time.Minute,
+					ErrorCheck:  configuration.UpgradeWatcherCheckConfig{Interval: time.Second},
+					Gates:       configuration.UpgradeWatcherGatesConfig{Condition: "${state} =="},
+				},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "happy path: no error watching, cleanup prev install",
 			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
@@ -133,7 +208,7 @@ func Test_watchCmd(t *testing.T) {
 				require.NoError(t, err)
 
 				watcher.EXPECT().
-					Watch(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Watch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 					Return(nil)
 
 				// on windows the marker is not removed immediately to allow for cleanup on restart
@@ -148,6 +223,80 @@ func Test_watchCmd(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "no upgrade marker, expired rollback is removed",
+			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
+				dataDirPath := paths.DataFrom(topDir)
+				prevHome := filepath.Join(topDir, "data", "elastic-agent-1.2.3-prvver")
+				err := os.MkdirAll(prevHome, 0755)
+				require.NoError(t, err)
+				err = upgrade.SaveAvailableRollback(dataDirPath, &upgrade.AvailableRollback{
+					Version:       "1.2.3",
+					Hash:          "prvver",
+					VersionedHome: filepath.Join("data", "elastic-agent-1.2.3-prvver"),
+					ValidUntil:    time.Now().Add(-time.Minute),
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					assert.NoDirExists(t, prevHome, "previous install must be removed")
+					rollback, err := upgrade.LoadAvailableRollback(dataDirPath)
+					assert.NoError(t, err)
+					assert.Nil(t, rollback)
+				})
+			},
+			args: args{
+				cfg:         configuration.DefaultUpgradeConfig().Watcher,
+				rollbackCfg: configuration.DefaultUpgradeConfig().Rollback,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "happy path: no error watching, prev install is kept for rollback",
+			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
+				dataDirPath := paths.DataFrom(topDir)
+				err := os.MkdirAll(dataDirPath, 0755)
+				require.NoError(t, err)
+				err = upgrade.SaveMarker(
+					dataDirPath,
+					&upgrade.UpdateMarker{
+						Version:           "4.5.6",
+						Hash:              "newver",
+						VersionedHome:     "elastic-agent-4.5.6-newver",
+						UpdatedOn:         time.Now(),
+						PrevVersion:       "1.2.3",
+						PrevHash:          "prvver",
+						PrevVersionedHome: "elastic-agent-prvver",
+						DesiredOutcome:    upgrade.OUTCOME_UPGRADE,
+					},
+					true,
+				)
+				require.NoError(t, err)
+
+				watcher.EXPECT().
+					Watch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return(nil)
+
+				expectedRemoveMarkerFlag := runtime.GOOS != "windows"
+				installModifier.EXPECT().
+					Cleanup(mock.Anything, topDir, "elastic-agent-4.5.6-newver", "newver", expectedRemoveMarkerFlag, false).
+					RunAndReturn(func(_ *logger.Logger, _, _, _ string, _, _ bool) error {
+						// the available rollback must be saved before cleanup runs
+						rollback, err := upgrade.LoadAvailableRollback(dataDirPath)
+						require.NoError(t, err)
+						require.NotNil(t, rollback)
+						assert.Equal(t, "1.2.3", rollback.Version)
+						assert.Equal(t, "prvver", rollback.Hash)
+						assert.Equal(t, "elastic-agent-prvver", rollback.VersionedHome)
+						assert.True(t, rollback.ValidUntil.After(time.Now().Add(time.Hour)))
+						return nil
+					})
+			},
+			args: args{
+				cfg:         configuration.DefaultUpgradeConfig().Watcher,
+				rollbackCfg: &configuration.UpgradeRollbackConfig{Window: 2 * time.Hour},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "unhappy path: error watching, rollback to previous install",
 			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
@@ -174,7 +323,7 @@ func Test_watchCmd(t *testing.T) {
 				require.NoError(t, err)
 
 				watcher.EXPECT().
-					Watch(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Watch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 					Return(errors.New("some watch error due to agent misbehaving"))
 				installModifier.EXPECT().
 					Rollback(mock.Anything, mock.Anything, mock.Anything, paths.Top(), "elastic-agent-prvver", "prvver").
@@ -185,6 +334,53 @@ func Test_watchCmd(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "unhappy path: health gate failed, rollback records the failed gate",
+			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
+				dataDirPath := paths.DataFrom(topDir)
+				err := os.MkdirAll(dataDirPath, 0755)
+				require.NoError(t, err)
+				err = upgrade.SaveMarker(
+					dataDirPath,
+					&upgrade.UpdateMarker{
+						Version:           "4.5.6",
+						Hash:              "newver",
+						VersionedHome:     "elastic-agent-4.5.6-newver",
+						UpdatedOn:         time.Now(),
+						PrevVersion:       "1.2.3",
+						PrevHash:          "prvver",
+						PrevVersionedHome: "elastic-agent-prvver",
+						DesiredOutcome:    upgrade.OUTCOME_UPGRADE,
+					},
+					true,
+				)
+				require.NoError(t, err)
+
+				gates := configuration.UpgradeWatcherGatesConfig{MinHealthyComponents: 2, Timeout: time.Minute}
+				watcher.EXPECT().
+					Watch(mock.Anything, mock.Anything, mock.Anything, gates, mock.Anything).
+					Return(&upgrade.HealthGateError{Gate: upgrade.GateMinHealthyComponents, Err: errors.New("1 components are healthy, at least 2 required")})
+				installModifier.EXPECT().
+					Rollback(mock.Anything, mock.Anything, mock.Anything, paths.Top(), "elastic-agent-prvver", "prvver").
+					RunAndReturn(func(_ context.Context, _ *logger.Logger, _ client.Client, _, _, _ string) error {
+						marker, err := upgrade.LoadMarker(dataDirPath)
+						require.NoError(t, err)
+						require.NotNil(t, marker.Details)
+						assert.Equal(t, details.StateRollback, marker.Details.State)
+						assert.Equal(t, details.ReasonHealthGateFailed, marker.Details.Metadata.Reason)
+						assert.Equal(t, upgrade.GateMinHealthyComponents, marker.Details.Metadata.FailedGate)
+						return nil
+					})
+			},
+			args: args{
+				cfg: &configuration.UpgradeWatcherConfig{
+					GracePeriod: time.Minute,
+					ErrorCheck:  configuration.UpgradeWatcherCheckConfig{Interval: time.Second},
+					Gates:       configuration.UpgradeWatcherGatesConfig{MinHealthyComponents: 2, Timeout: time.Minute},
+				},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "upgrade rolled back: no watching, cleanup must be called",
 			setupUpgradeMarker: func(t *testing.T, topDir string, watcher *cmdmocks.AgentWatcher, installModifier *cmdmocks.InstallationModifier) {
@@ -276,7 +472,7 @@ func Test_watchCmd(t *testing.T) {
 			mockWatcher := cmdmocks.NewAgentWatcher(t)
 			mockInstallModifier := cmdmocks.NewInstallationModifier(t)
 			tt.setupUpgradeMarker(t, tmpDir, mockWatcher, mockInstallModifier)
-			tt.wantErr(t, watchCmd(log, tmpDir, tt.args.cfg, mockWatcher, mockInstallModifier), fmt.Sprintf("watchCmd(%v, ...)", tt.args.cfg))
+			tt.wantErr(t, watchCmd(log, tmpDir, tt.args.cfg, tt.args.rollbackCfg, mockWatcher, mockInstallModifier), fmt.Sprintf("watchCmd(%v, ...)", tt.args.cfg))
 			t.Logf("watchCmd logs:\n%v", obs.All())
 		})
 	}
@@ -4,7 +4,16 @@
 
 package configuration
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/cronexpr"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
+)
 
 const (
 	// period during which we monitor for failures resulting in a rollback.
@@ -13,6 +22,10 @@ const (
 	// interval between checks for new (upgraded) Agent returning an error status.
 	defaultStatusCheckInterval = 30 * time.Second
 
+	// period during which the min_healthy_components and condition health gates can fail
+	// before the upgrade is rolled back.
+	defaultGatesTimeout = 2 * time.Minute
+
 	// period during which an upgraded Agent can be asked to rollback to the previous
 	// Agent version on disk.
 	defaultRollbackWindowDuration = 7 * 24 * time.Hour // 7 days
@@ -22,16 +35,155 @@ const (
 type UpgradeConfig struct {
 	Watcher  *UpgradeWatcherConfig  `yaml:"watcher" config:"watcher" json:"watcher"`
 	Rollback *UpgradeRollbackConfig `yaml:"rollback" config:"rollback" json:"rollback"`
+	// Windows are the maintenance windows during which upgrades can run, upgrades requested outside of them are
+	// deferred to the start of the next window. Upgrades can run at any time when empty.
+	Windows []UpgradeWindowConfig `yaml:"windows,omitempty" config:"windows" json:"windows,omitempty"`
 }
 
 type UpgradeWatcherConfig struct {
 	GracePeriod time.Duration             `yaml:"grace_period" config:"grace_period" json:"grace_period"`
 	ErrorCheck  UpgradeWatcherCheckConfig `yaml:"error_check" config:"error_check" json:"error_check"`
+	Gates       UpgradeWatcherGatesConfig `yaml:"gates" config:"gates" json:"gates"`
 }
 type UpgradeWatcherCheckConfig struct {
 	Interval time.Duration `yaml:"interval" config:"interval" json:"interval"`
 }
 
+// UpgradeWatcherGatesConfig is the configuration of the health gates the upgraded Agent must pass
+// until the grace period ends, failing any of them rolls back the upgrade.
+type UpgradeWatcherGatesConfig struct {
+	// MinHealthyComponents is the minimum number of HEALTHY components, disabled when 0.
+	MinHealthyComponents int `yaml:"min_healthy_components" config:"min_healthy_components" json:"min_healthy_components"`
+	// UnitFailedTimeout is how long a unit can stay FAILED, disabled when 0.
+	UnitFailedTimeout time.Duration `yaml:"unit_failed_timeout" config:"unit_failed_timeout" json:"unit_failed_timeout"`
+	// Condition is an EQL expression over the state of the Agent that must evaluate to true, disabled when empty.
+	// Its variables must be escaped in the configuration, e.g. `$${state} == 'HEALTHY'`.
+	Condition string `yaml:"condition" config:"condition" json:"condition"`
+	// Timeout is how long the min_healthy_components and condition gates can fail, including while the
+	// upgraded Agent starts.
+	Timeout time.Duration `yaml:"timeout" config:"timeout" json:"timeout"`
+}
+
+// Enabled returns true if any of the health gates is enabled.
+func (c *UpgradeWatcherGatesConfig) Enabled() bool {
+	return c.MinHealthyComponents > 0 || c.UnitFailedTimeout > 0 || c.Condition != ""
+}
+
+// Check validates the health gates configuration. It is not named Validate on purpose, an invalid health gates
+// configuration must not fail to load the whole configuration, the watcher then watches without the gates.
+func (c *UpgradeWatcherGatesConfig) Check() error {
+	if c.MinHealthyComponents < 0 {
+		return errors.New("min_healthy_components cannot be negative", errors.TypeConfig)
+	}
+	if c.UnitFailedTimeout < 0 {
+		return errors.New("unit_failed_timeout cannot be negative", errors.TypeConfig)
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout cannot be negative", errors.TypeConfig)
+	}
+	if c.Condition != "" {
+		if _, err := eql.New(c.Condition); err != nil {
+			return errors.New(err, fmt.Sprintf("invalid condition %q", c.Condition), errors.TypeConfig)
+		}
+	}
+	return nil
+}
+
+// UpgradeWindowConfig is a maintenance window during which upgrades can run. It is either defined by a cron
+// expression of when the window starts and its duration, or by a daily time range optionally limited to some days
+// of the week.
+type UpgradeWindowConfig struct {
+	// Cron is the cron expression of when the window starts, e.g. `0 2 * * SAT`.
+	Cron string `yaml:"cron,omitempty" config:"cron" json:"cron,omitempty"`
+	// Duration is how long the window started by Cron stays open.
+	Duration time.Duration `yaml:"duration,omitempty" config:"duration" json:"duration,omitempty"`
+	// Days are the days of the week the time range applies to, e.g. [saturday, sunday], every day when empty.
+	Days []string `yaml:"days,omitempty" config:"days" json:"days,omitempty"`
+	// Start is the time of the day the window opens, formatted as HH:MM.
+	Start string `yaml:"start,omitempty" config:"start" json:"start,omitempty"`
+	// End is the time of the day the window closes, formatted as HH:MM. A window ending before it starts spans
+	// midnight.
+	End string `yaml:"end,omitempty" config:"end" json:"end,omitempty"`
+	// Timezone is the IANA name of the timezone the window is defined in, e.g. `Europe/Paris`, defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty" config:"timezone" json:"timezone,omitempty"`
+}
+
+// upgradeWindowTimeLayout is the layout of the start and end of an upgrade window.
+const upgradeWindowTimeLayout = "15:04"
+
+// Check validates the upgrade window configuration. It is not named Validate on purpose, an invalid upgrade window
+// must not fail to load the whole configuration, it is reported when the upgrade windows are created instead.
+func (c *UpgradeWindowConfig) Check() error {
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		return errors.New(err, fmt.Sprintf("invalid timezone %q", c.Timezone), errors.TypeConfig)
+	}
+
+	if c.Cron != "" {
+		if c.Start != "" || c.End != "" || len(c.Days) > 0 {
+			return errors.New("cron cannot be combined with days, start or end", errors.TypeConfig)
+		}
+		if _, err := cronexpr.Parse(c.Cron); err != nil {
+			return errors.New(err, fmt.Sprintf("invalid cron %q", c.Cron), errors.TypeConfig)
+		}
+		if c.Duration <= 0 {
+			return errors.New("duration must be positive when cron is set", errors.TypeConfig)
+		}
+		return nil
+	}
+
+	if c.Start == "" || c.End == "" {
+		return errors.New("either cron or start and end must be set", errors.TypeConfig)
+	}
+	if _, err := c.StartTime(); err != nil {
+		return errors.New(err, fmt.Sprintf("invalid start %q, expected HH:MM", c.Start), errors.TypeConfig)
+	}
+	if _, err := c.EndTime(); err != nil {
+		return errors.New(err, fmt.Sprintf("invalid end %q, expected HH:MM", c.End), errors.TypeConfig)
+	}
+	if c.Start == c.End {
+		return errors.New("start and end cannot be equal", errors.TypeConfig)
+	}
+	if _, err := c.Weekdays(); err != nil {
+		return errors.New(err, errors.TypeConfig)
+	}
+	return nil
+}
+
+// StartTime returns the time of the day the window opens, as a time on January 1, year 0.
+func (c *UpgradeWindowConfig) StartTime() (time.Time, error) {
+	return time.Parse(upgradeWindowTimeLayout, c.Start)
+}
+
+// EndTime returns the time of the day the window closes, as a time on January 1, year 0.
+func (c *UpgradeWindowConfig) EndTime() (time.Time, error) {
+	return time.Parse(upgradeWindowTimeLayout, c.End)
+}
+
+// Weekdays returns the days of the week the window applies to, empty when it applies to every day.
+func (c *UpgradeWindowConfig) Weekdays() ([]time.Weekday, error) {
+	weekdays := make([]time.Weekday, 0, len(c.Days))
+	for _, day := range c.Days {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", day)
+		}
+		weekdays = append(weekdays, weekday)
+	}
+	return weekdays, nil
+}
+
+// parseWeekday parses the full or 3 letters name of a day of the week, case insensitive.
+func parseWeekday(day string) (time.Weekday, bool) {
+	day = strings.ToLower(day)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if day == name || day == name[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
 type UpgradeRollbackConfig struct {
 	Window time.Duration `yaml:"window" config:"window" json:"window"`
 }
@@ -43,6 +195,9 @@ func DefaultUpgradeConfig() *UpgradeConfig {
 			ErrorCheck: UpgradeWatcherCheckConfig{
 				Interval: defaultStatusCheckInterval,
 			},
+			Gates: UpgradeWatcherGatesConfig{
+				Timeout: defaultGatesTimeout,
+			},
 		},
 		Rollback: &UpgradeRollbackConfig{
 			Window: defaultRollbackWindowDuration,
@@ -26,6 +26,9 @@ func TestParseUpgradeConfig(t *testing.T) {
 					ErrorCheck: UpgradeWatcherCheckConfig{
 						Interval: defaultStatusCheckInterval,
 					},
+					Gates: UpgradeWatcherGatesConfig{
+						Timeout: defaultGatesTimeout,
+					},
 				},
 				Rollback: &UpgradeRollbackConfig{
 					Window: defaultRollbackWindowDuration,
@@ -44,6 +47,9 @@ func TestParseUpgradeConfig(t *testing.T) {
 					ErrorCheck: UpgradeWatcherCheckConfig{
 						Interval: defaultStatusCheckInterval,
 					},
+					Gates: UpgradeWatcherGatesConfig{
+						Timeout: defaultGatesTimeout,
+					},
 				},
 				Rollback: &UpgradeRollbackConfig{
 					Window: defaultRollbackWindowDuration,
@@ -64,6 +70,38 @@ func TestParseUpgradeConfig(t *testing.T) {
 					ErrorCheck: UpgradeWatcherCheckConfig{
 						Interval: 1 * time.Hour,
 					},
+					Gates: UpgradeWatcherGatesConfig{
+						Timeout: defaultGatesTimeout,
+					},
+				},
+				Rollback: &UpgradeRollbackConfig{
+					Window: defaultRollbackWindowDuration,
+				},
+			},
+		},
+		"watcher_gates": {
+			cfg: map[string]any{
+				"watcher": map[string]any{
+					"gates": map[string]any{
+						"min_healthy_components": 3,
+						"unit_failed_timeout":    "5m",
+						"condition":              "$${state} == 'HEALTHY'",
+						"timeout":                "1m",
+					},
+				},
+			},
+			expected: UpgradeConfig{
+				Watcher: &UpgradeWatcherConfig{
+					GracePeriod: defaultGracePeriodDuration,
+					ErrorCheck: UpgradeWatcherCheckConfig{
+						Interval: defaultStatusCheckInterval,
+					},
+					Gates: UpgradeWatcherGatesConfig{
+						MinHealthyComponents: 3,
+						UnitFailedTimeout:    5 * time.Minute,
+						Condition:            "${state} == 'HEALTHY'",
+						Timeout:              time.Minute,
+					},
 				},
 				Rollback: &UpgradeRollbackConfig{
 					Window: defaultRollbackWindowDuration,
@@ -80,12 +118,57 @@ func TestParseUpgradeConfig(t *testing.T) {
 					ErrorCheck: UpgradeWatcherCheckConfig{
 						Interval: defaultStatusCheckInterval,
 					},
+					Gates: UpgradeWatcherGatesConfig{
+						Timeout: defaultGatesTimeout,
+					},
 				},
 				Rollback: &UpgradeRollbackConfig{
 					Window: 8 * time.Hour,
 				},
 			},
 		},
+		"windows": {
+			cfg: map[string]any{
+				"windows": []any{
+					map[string]any{
+						"cron":     "0 2 * * SAT",
+						"duration": "3h",
+						"timezone": "Europe/Paris",
+					},
+					map[string]any{
+						"days":  []any{"saturday", "sun"},
+						"start": "22:00",
+						"end":   "04:00",
+					},
+				},
+			},
+			expected: UpgradeConfig{
+				Watcher: &UpgradeWatcherConfig{
+					GracePeriod: defaultGracePeriodDuration,
+					ErrorCheck: UpgradeWatcherCheckConfig{
+						Interval: defaultStatusCheckInterval,
+					},
+					Gates: UpgradeWatcherGatesConfig{
+						Timeout: defaultGatesTimeout,
+					},
+				},
+				Rollback: &UpgradeRollbackConfig{
+					Window: defaultRollbackWindowDuration,
+				},
+				Windows: []UpgradeWindowConfig{
+					{
+						Cron:     "0 2 * * SAT",
+						Duration: 3 * time.Hour,
+						Timezone: "Europe/Paris",
+					},
+					{
+						Days:  []string{"saturday", "sun"},
+						Start: "22:00",
+						End:   "04:00",
+					},
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -97,3 +180,52 @@ func TestParseUpgradeConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestParseUpgradeConfigInvalidGates(t *testing.T) {
+	tests := map[string]map[string]any{
+		"negative min_healthy_components": {"min_healthy_components": -1},
+		"negative unit_failed_timeout":    {"unit_failed_timeout": "-1m"},
+		"invalid condition":               {"condition": "$${state} =="},
+	}
+
+	for name, gates := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the gates are checked by the watcher, an invalid configuration still loads
+			c, err := NewFromConfig(config.MustNewConfigFrom(map[string]any{
+				"agent": map[string]any{"upgrade": map[string]any{"watcher": map[string]any{
+					"grace_period": "3m",
+					"gates":        gates,
+				}}},
+			}))
+			require.NoError(t, err)
+			require.Equal(t, 3*time.Minute, c.Settings.Upgrade.Watcher.GracePeriod)
+			require.Error(t, c.Settings.Upgrade.Watcher.Gates.Check())
+		})
+	}
+}
+
+func TestParseUpgradeConfigInvalidWindows(t *testing.T) {
+	tests := map[string]map[string]any{
+		"empty":                 {},
+		"invalid cron":          {"cron": "0 2 * *", "duration": "1h"},
+		"cron without duration": {"cron": "0 2 * * *"},
+		"cron with time range":  {"cron": "0 2 * * *", "duration": "1h", "start": "02:00", "end": "03:00"},
+		"missing end":           {"start": "02:00"},
+		"invalid start":         {"start": "2am", "end": "03:00"},
+		"start equal to end":    {"start": "02:00", "end": "02:00"},
+		"invalid day":           {"days": []any{"someday"}, "start": "02:00", "end": "03:00"},
+		"invalid timezone":      {"start": "02:00", "end": "03:00", "timezone": "Mars/Olympus_Mons"},
+	}
+
+	for name, window := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the windows are checked when they are created, an invalid window still loads
+			c, err := NewFromConfig(config.MustNewConfigFrom(map[string]any{
+				"agent": map[string]any{"upgrade": map[string]any{"windows": []any{window}}},
+			}))
+			require.NoError(t, err)
+			require.Len(t, c.Settings.Upgrade.Windows, 1)
+			require.Error(t, c.Settings.Upgrade.Windows[0].Check())
+		})
+	}
+}
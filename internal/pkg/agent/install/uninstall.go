@@ -386,12 +386,10 @@ func uninstallComponents(ctx context.Context, cfgFile string, uninstallToken str
 		return fmt.Errorf("error checking capabilities: %w", err)
 	}
 
-	// remove each service component
+	// remove each service component, filtered the same way the coordinator does so conditional
+	// rules are evaluated against the unit configurations
+	comps, _ = capabilities.FilterComponents(caps, comps)
 	for _, comp := range comps {
-		if !caps.AllowInput(comp.InputType) || !caps.AllowOutput(comp.OutputType) {
-			// This component is not active
-			continue
-		}
 		if err = uninstallServiceComponent(ctx, log, comp, uninstallToken, pt); err != nil {
 			os.Stderr.WriteString(fmt.Sprintf("failed to uninstall component %q: %s\n", comp.ID, err))
 			// The decision was made to change the behaviour and leave the Agent installed if Endpoint uninstall fails
@@ -1108,6 +1108,13 @@ func TestVars(t *testing.T) {
 			result:          []string{"custom.var1", "host.var2", "custom.var3", "custom.var1", "host.var5", "host.var6", "custom.var1"},
 			defaultProvider: "custom",
 		},
+		"expression": {
+			input: map[string]interface{}{
+				"hosts": "${kubernetes.labels.env == 'prod' ? host.prod : var1}",
+			},
+			result:          []string{"kubernetes.labels.env", "host.prod", "custom.var1"},
+			defaultProvider: "custom",
+		},
 	}
 
 	for name, test := range tests {
@@ -13,12 +13,18 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/mapstr"
 	"github.com/elastic/elastic-agent/internal/pkg/core/composable"
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
 )
 
 const varsSeparator = "."
 
 var varsRegex = regexp.MustCompile(`\$\$?{([\p{L}\d\s\\\-_|.'":\/]*)}`)
 
+// exprRegex also matches the variables that are EQL expressions, e.g.
+// ${kubernetes.labels.env == 'prod' ? 'prod-es:9200' : 'dev-es:9200'}. Its matches that are not expressions
+// must be matched by varsRegex.
+var exprRegex = regexp.MustCompile(`\$\$?{([\p{L}\d\s\\\-_|.'":\/=!<>?()+*%,\[\]]*)}`)
+
 // ErrNoMatch is return when the replace didn't fail, just that no vars match to perform the replace.
 var ErrNoMatch = errors.New("no matching vars")
 
@@ -109,7 +115,7 @@ func (v *Vars) lookupNode(name string) (Node, bool) {
 
 func replaceVars(value string, replacer func(variable string) (Node, Processors, bool), reqMatch bool, defaultProvider string) (Node, error) {
 	var processors Processors
-	matchIdxs := varsRegex.FindAllSubmatchIndex([]byte(value), -1)
+	matchIdxs := findVars(value)
 	if !validBrackets(value, matchIdxs) {
 		return nil, fmt.Errorf("starting ${ is missing ending }")
 	}
@@ -124,6 +130,37 @@ func replaceVars(value string, replacer func(variable string) (Node, Processors,
 				continue
 			}
 			// match on a non-escaped var
+			expression, err := pipesToExpression(value[r[i+2]:r[i+3]], defaultProvider)
+			if err != nil {
+				return nil, fmt.Errorf(`error parsing variable "%s": %w`, value[r[i]:r[i+1]], err)
+			}
+			if expression == "" && eql.IsInline(value[r[i+2]:r[i+3]]) {
+				expression = value[r[i+2]:r[i+3]]
+			}
+			if expression != "" {
+				node, nodeProcessors, err := replaceExpression(expression, replacer, defaultProvider)
+				if err != nil {
+					return nil, fmt.Errorf(`error evaluating variable "%s": %w`, value[r[i]:r[i+1]], err)
+				}
+				if node == nil {
+					if reqMatch {
+						return NewStrVal(""), fmt.Errorf("%w: %s", ErrNoMatch, value[r[i]:r[i+1]])
+					}
+					lastIndex = r[1]
+					continue
+				}
+				if nodeProcessors != nil {
+					processors = nodeProcessors
+				}
+				if r[i] == 0 && r[i+1] == len(value) {
+					// possible for complete replacement of object, because the variable
+					// is not inside of a string
+					return attachProcessors(node, processors), nil
+				}
+				result += value[lastIndex:r[0]] + node.String()
+				lastIndex = r[1]
+				continue
+			}
 			vars, err := extractVars(value[r[i+2]:r[i+3]], defaultProvider)
 			if err != nil {
 				return nil, fmt.Errorf(`error parsing variable "%s": %w`, value[r[i]:r[i+1]], err)
@@ -163,6 +200,145 @@ func replaceVars(value string, replacer func(variable string) (Node, Processors,
 	return NewStrValWithProcessors(result+value[lastIndex:], processors), nil
 }
 
+// findVars returns the submatch indexes of the variables of the value. The content of a variable is either an
+// EQL expression or a list of variables and constants matched by varsRegex.
+func findVars(value string) [][]int {
+	var matchIdxs [][]int
+	for _, r := range exprRegex.FindAllStringSubmatchIndex(value, -1) {
+		if !eql.IsInline(value[r[2]:r[3]]) {
+			loc := varsRegex.FindStringIndex(value[r[0]:r[1]])
+			if loc == nil || loc[0] != 0 || loc[1] != r[1]-r[0] {
+				continue
+			}
+		}
+		matchIdxs = append(matchIdxs, r)
+	}
+	return matchIdxs
+}
+
+// pipesToExpression returns the EQL expression of a variable that pipes its value to functions with |>, e.g.
+// "(host.name) |> sha256" for "host.name |> sha256", or "(host.id ?? 'none') |> sha256 |> truncate(8)" for
+// "host.id|'none' |> sha256 |> truncate(8)". The variables and constants separated by | before the first pipe
+// are the fallbacks of the piped value. Returns an empty string when the variable has no pipes.
+func pipesToExpression(content string, defaultProvider string) (string, error) {
+	idx := pipeIndex(content)
+	if idx < 0 {
+		return "", nil
+	}
+	base := content[:idx]
+	if eql.IsInline(base) {
+		return content, nil
+	}
+
+	vars, err := extractVars(base, defaultProvider)
+	if err != nil {
+		return "", err
+	}
+	operands := make([]string, 0, len(vars))
+	for _, val := range vars {
+		switch val.(type) {
+		case *constString:
+			quoted, err := quoteConstant(val.Value())
+			if err != nil {
+				return "", err
+			}
+			operands = append(operands, quoted)
+		case *varString:
+			operands = append(operands, val.Value())
+		}
+	}
+	if len(operands) == 0 {
+		return "", errors.New("pipe is missing a value")
+	}
+	return "(" + strings.Join(operands, " ?? ") + ") " + content[idx:], nil
+}
+
+// pipeIndex returns the index of the first |> of the content that is not inside quotes, parentheses or
+// brackets, or -1.
+func pipeIndex(content string) int {
+	const out = rune(0)
+	quote := out
+	escape := false
+	depth := 0
+	for i, r := range content {
+		switch {
+		case escape:
+			escape = false
+		case r == '\\':
+			escape = true
+		case quote != out:
+			if r == quote {
+				quote = out
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+		case r == '|' && depth == 0 && strings.HasPrefix(content[i:], "|>"):
+			return i
+		}
+	}
+	return -1
+}
+
+// quoteConstant quotes a constant for use in an EQL expression.
+func quoteConstant(constant string) (string, error) {
+	if !strings.Contains(constant, "'") {
+		return "'" + constant + "'", nil
+	}
+	if !strings.Contains(constant, `"`) {
+		return `"` + constant + `"`, nil
+	}
+	return "", fmt.Errorf("constant %s cannot be piped, it contains both ' and \"", constant)
+}
+
+// replaceExpression evaluates an EQL expression that references variables by name, resolving them with the
+// replacer. Missing variables evaluate to null, the returned node is nil when the expression evaluates to
+// null.
+func replaceExpression(expression string, replacer func(variable string) (Node, Processors, bool), defaultProvider string) (Node, Processors, error) {
+	e, names, err := eql.NewInline(expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	var processors Processors
+	store := exprVarStore{}
+	for _, name := range names {
+		node, nodeProcessors, ok := replacer(maybeAddDefaultProvider(name, defaultProvider))
+		if !ok {
+			continue
+		}
+		if nodeProcessors != nil {
+			processors = nodeProcessors
+		}
+		m := &MapVisitor{}
+		(&AST{}).dispatch(nodeToValue(node), m)
+		store[name] = m.Content
+	}
+	result, err := e.Value(store, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result == eql.Null {
+		return nil, processors, nil
+	}
+	node, err := loadForNew(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	return node, processors, nil
+}
+
+// exprVarStore is the eql.VarStore of the variables referenced by an expression.
+type exprVarStore map[string]interface{}
+
+// Lookup returns the value of the variable.
+func (s exprVarStore) Lookup(name string) (interface{}, bool) {
+	v, ok := s[name]
+	return v, ok
+}
+
 func toRepresentation(vars []varI) string {
 	var sb strings.Builder
 	sb.WriteString("${")
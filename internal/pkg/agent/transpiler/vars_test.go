@@ -32,7 +32,15 @@ func TestVars_Replace(t *testing.T) {
 			},
 		},
 		"other": map[string]interface{}{
-			"data": "info",
+			"data":   "info",
+			"length": "other-length",
+			"sha256": "other-sha256",
+		},
+		"pipes": map[string]interface{}{
+			"mixed":  "  Mixed Case ",
+			"hosts":  "es1:9200,es2:9200",
+			"host":   "my-host",
+			"secret": "czNjcjN0",
 		},
 		"special": map[string]interface{}{
 			"key1": "$1$$2",
@@ -255,6 +263,216 @@ func TestVars_Replace(t *testing.T) {
 			false,
 			false,
 		},
+		{
+			`${un-der_score.key1 == 'data1' ? 'prod-es:9200' : 'dev-es:9200'}`,
+			NewStrVal("prod-es:9200"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.key1 == 'other' ? 'prod-es:9200' : 'dev-es:9200'}`,
+			NewStrVal("dev-es:9200"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing == 'data1' ? 'prod-es:9200' : 'dev-es:9200'}`,
+			NewStrVal("dev-es:9200"),
+			false,
+			false,
+		},
+		{
+			`http://${un-der_score.key1 == 'data1' ? 'prod-es' : 'dev-es'}:9200`,
+			NewStrVal("http://prod-es:9200"),
+			false,
+			false,
+		},
+		{
+			`${data == 'info' ? 1 : 2}`,
+			NewIntVal(1),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.key1 == 'data1' ? un-der_score.list : []}`,
+			NewList([]Node{NewStrVal("array1"), NewStrVal("array2")}),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing ?? un-der_score.key2}`,
+			NewStrVal("data2"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing ?? un-der_score.missing2}`,
+			NewStrVal(""),
+			false,
+			true,
+		},
+		{
+			`${concat(un-der_score.key1, '-', data)}`,
+			NewStrVal("data1-info"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.key1 == 'data1' ? 'a'}`,
+			NewStrVal(""),
+			true,
+			false,
+		},
+		{
+			`${un-der_score.key1 |> upper}`,
+			NewStrVal("DATA1"),
+			false,
+			false,
+		},
+		{
+			`${pipes.mixed |> lower |> trim}`,
+			NewStrVal("mixed case"),
+			false,
+			false,
+		},
+		{
+			`${pipes.hosts |> split(',')}`,
+			NewList([]Node{NewStrVal("es1:9200"), NewStrVal("es2:9200")}),
+			false,
+			false,
+		},
+		{
+			`${pipes.host |> sha256 |> truncate(8)}`,
+			NewStrVal("e6ad3b2d"),
+			false,
+			false,
+		},
+		{
+			`index-${pipes.host |> sha256 |> truncate(8)}`,
+			NewStrVal("index-e6ad3b2d"),
+			false,
+			false,
+		},
+		{
+			`${pipes.secret |> base64Decode}`,
+			NewStrVal("s3cr3t"),
+			false,
+			false,
+		},
+		{
+			`${pipes.host |> base64Encode}`,
+			NewStrVal("bXktaG9zdA=="),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing | un-der_score.key2 |> upper}`,
+			NewStrVal("DATA2"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing | 'fall|back' |> upper}`,
+			NewStrVal("FALL|BACK"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing |> upper}`,
+			NewStrVal(""),
+			false,
+			true,
+		},
+		{
+			`${un-der_score.key1 == 'data1' ? 'prod' : 'dev' |> upper}`,
+			NewStrVal("PROD"),
+			false,
+			false,
+		},
+		{
+			`${pipes.secret |> base64Decode('too many')}`,
+			NewStrVal(""),
+			true,
+			false,
+		},
+		{
+			`${un-der_score.missing|length}`,
+			NewStrVal("other-length"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.key1|sha256}`,
+			NewStrVal("data1"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing|string|'default'}`,
+			NewStrVal("default"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing|lower}`,
+			NewStrVal(""),
+			false,
+			true,
+		},
+		{
+			`${un-der_score.missing|length |> upper}`,
+			NewStrVal("OTHER-LENGTH"),
+			false,
+			false,
+		},
+		{
+			`${pipes.mixed |> unknownFunction}`,
+			NewStrVal(""),
+			true,
+			false,
+		},
+		{
+			`$${un-der_score.key1 == 'data1' ? 'a' : 'b'}`,
+			NewStrVal("${un-der_score.key1 == 'data1' ? 'a' : 'b'}"),
+			false,
+			false,
+		},
+		{
+			`${10 - 4}`,
+			NewIntVal(6),
+			false,
+			false,
+		},
+		{
+			`${10 / 4 * 2}`,
+			NewIntVal(4),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.with/slash}`,
+			NewStrVal("some/path"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.missing|un-der_score.with-dash}`,
+			NewStrVal("dash-value"),
+			false,
+			false,
+		},
+		{
+			`${un-der_score.key1=data1}`,
+			NewStrVal(""),
+			true,
+			false,
+		},
+		{
+			`${un-der_score.missing|'a,b'}`,
+			NewStrVal(""),
+			true,
+			false,
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.Input, func(t *testing.T) {
@@ -17,29 +17,111 @@ import (
 
 type Capabilities interface {
 	AllowUpgrade(version string, sourceURI string) bool
-	AllowInput(name string) bool
-	AllowOutput(name string) bool
+	// AllowInput reports whether inputs of the given type may run. The input
+	// configuration, if known, is available to rule conditions as ${input.*}.
+	AllowInput(name string, inputCfg map[string]interface{}) bool
+	// AllowOutput reports whether outputs of the given type may be used. The
+	// output configuration, if known, is available to rule conditions as
+	// ${output.*}.
+	AllowOutput(name string, outputCfg map[string]interface{}) bool
+	// AllowRuntime reports whether components may run under the given
+	// runtime manager, e.g. "process" or "otel".
+	AllowRuntime(runtime string) bool
+	// AllowNamespace reports whether data may be sent to the given
+	// data_stream.namespace.
+	AllowNamespace(namespace string) bool
+	// AllowLogLevel reports whether the agent may be set to the given log
+	// level, e.g. "debug".
+	AllowLogLevel(level string) bool
+
+	// ExplainUpgrade is like AllowUpgrade but also reports which rule decided.
+	ExplainUpgrade(version string, sourceURI string) Decision
+	// ExplainInput is like AllowInput but also reports which rule decided.
+	ExplainInput(name string, inputCfg map[string]interface{}) Decision
+	// ExplainOutput is like AllowOutput but also reports which rule decided.
+	ExplainOutput(name string, outputCfg map[string]interface{}) Decision
+	// ExplainRuntime is like AllowRuntime but also reports which rule decided.
+	ExplainRuntime(runtime string) Decision
+	// ExplainNamespace is like AllowNamespace but also reports which rule
+	// decided.
+	ExplainNamespace(namespace string) Decision
+}
+
+// Decision is the outcome of a capability check.
+type Decision struct {
+	Allowed bool `yaml:"allowed"`
+	// Rule is the index of the deciding rule in capabilities.yml, starting at
+	// 0, or -1 when no rule applied and the default allowed it.
+	Rule int `yaml:"rule"`
 }
 
+// defaultDecision is returned when no rule applies.
+var defaultDecision = Decision{Allowed: true, Rule: -1}
+
 type capabilitiesManager struct {
-	log          *logger.Logger
-	inputChecks  []*stringMatcher
-	outputChecks []*stringMatcher
-	upgradeCaps  []*upgradeCapability
+	log             *logger.Logger
+	facts           map[string]interface{}
+	inputChecks     []*stringMatcher
+	outputChecks    []*stringMatcher
+	runtimeChecks   []*stringMatcher
+	namespaceChecks []*stringMatcher
+	logLevelChecks  []*stringMatcher
+	upgradeCaps     []*upgradeCapability
+}
+
+func (cm *capabilitiesManager) AllowInput(inputType string, inputCfg map[string]interface{}) bool {
+	vars := cm.conditionVars(cm.inputChecks, "input", inputCfg)
+	return matchString(cm.log, inputType, vars, cm.inputChecks)
+}
+
+func (cm *capabilitiesManager) AllowOutput(outputType string, outputCfg map[string]interface{}) bool {
+	vars := cm.conditionVars(cm.outputChecks, "output", outputCfg)
+	return matchString(cm.log, outputType, vars, cm.outputChecks)
+}
+
+func (cm *capabilitiesManager) AllowRuntime(runtime string) bool {
+	vars := cm.conditionVars(cm.runtimeChecks, "", nil)
+	return matchString(cm.log, runtime, vars, cm.runtimeChecks)
 }
 
-func (cm *capabilitiesManager) AllowInput(inputType string) bool {
-	return matchString(inputType, cm.inputChecks)
+func (cm *capabilitiesManager) AllowNamespace(namespace string) bool {
+	vars := cm.conditionVars(cm.namespaceChecks, "", nil)
+	return matchString(cm.log, namespace, vars, cm.namespaceChecks)
 }
 
-func (cm *capabilitiesManager) AllowOutput(outputType string) bool {
-	return matchString(outputType, cm.outputChecks)
+func (cm *capabilitiesManager) AllowLogLevel(level string) bool {
+	vars := cm.conditionVars(cm.logLevelChecks, "", nil)
+	return matchString(cm.log, level, vars, cm.logLevelChecks)
 }
 
 func (cm *capabilitiesManager) AllowUpgrade(version string, uri string) bool {
 	return allowUpgrade(cm.log, version, uri, cm.upgradeCaps)
 }
 
+func (cm *capabilitiesManager) ExplainInput(inputType string, inputCfg map[string]interface{}) Decision {
+	vars := cm.conditionVars(cm.inputChecks, "input", inputCfg)
+	return decideString(cm.log, inputType, vars, cm.inputChecks)
+}
+
+func (cm *capabilitiesManager) ExplainOutput(outputType string, outputCfg map[string]interface{}) Decision {
+	vars := cm.conditionVars(cm.outputChecks, "output", outputCfg)
+	return decideString(cm.log, outputType, vars, cm.outputChecks)
+}
+
+func (cm *capabilitiesManager) ExplainRuntime(runtime string) Decision {
+	vars := cm.conditionVars(cm.runtimeChecks, "", nil)
+	return decideString(cm.log, runtime, vars, cm.runtimeChecks)
+}
+
+func (cm *capabilitiesManager) ExplainNamespace(namespace string) Decision {
+	vars := cm.conditionVars(cm.namespaceChecks, "", nil)
+	return decideString(cm.log, namespace, vars, cm.namespaceChecks)
+}
+
+func (cm *capabilitiesManager) ExplainUpgrade(version string, uri string) Decision {
+	return decideUpgrade(cm.log, version, uri, cm.upgradeCaps)
+}
+
 func LoadFile(capsFile string, log *logger.Logger) (Capabilities, error) {
 	// load capabilities from file
 	fd, err := os.Open(capsFile)
@@ -68,8 +150,13 @@ func Load(capsReader io.Reader, log *logger.Logger) (Capabilities, error) {
 	caps := spec.Capabilities
 
 	return &capabilitiesManager{
-		inputChecks:  caps.inputChecks,
-		outputChecks: caps.outputChecks,
-		upgradeCaps:  caps.upgradeChecks,
+		log:             log,
+		facts:           defaultFacts(),
+		inputChecks:     caps.inputChecks,
+		outputChecks:    caps.outputChecks,
+		runtimeChecks:   caps.runtimeChecks,
+		namespaceChecks: caps.namespaceChecks,
+		logLevelChecks:  caps.logLevelChecks,
+		upgradeCaps:     caps.upgradeChecks,
 	}, nil
 }
@@ -24,9 +24,12 @@ capabilities:
 	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
 	require.NoError(t, err, "Loading capabilities should succeed")
 
-	assert.True(t, caps.AllowInput("system/metrics"))
-	assert.True(t, caps.AllowInput("system/logs"))
-	assert.True(t, caps.AllowOutput("elasticsearch"))
+	assert.True(t, caps.AllowInput("system/metrics", nil))
+	assert.True(t, caps.AllowInput("system/logs", nil))
+	assert.True(t, caps.AllowOutput("elasticsearch", nil))
+	assert.True(t, caps.AllowRuntime("otel"))
+	assert.True(t, caps.AllowNamespace("default"))
+	assert.True(t, caps.AllowLogLevel("debug"))
 }
 
 func TestAllowMetrics(t *testing.T) {
@@ -40,9 +43,9 @@ capabilities:
 	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
 	require.NoError(t, err, "Loading capabilities should succeed")
 
-	assert.True(t, caps.AllowInput("system/metrics"))
-	assert.False(t, caps.AllowInput("system/logs"))
-	assert.True(t, caps.AllowOutput("elasticsearch"))
+	assert.True(t, caps.AllowInput("system/metrics", nil))
+	assert.False(t, caps.AllowInput("system/logs", nil))
+	assert.True(t, caps.AllowOutput("elasticsearch", nil))
 }
 
 func TestDenyLogs(t *testing.T) {
@@ -54,9 +57,9 @@ capabilities:
 	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
 	require.NoError(t, err, "Loading capabilities should succeed")
 
-	assert.True(t, caps.AllowInput("system/metrics"))
-	assert.False(t, caps.AllowInput("system/logs"))
-	assert.True(t, caps.AllowOutput("elasticsearch"))
+	assert.True(t, caps.AllowInput("system/metrics", nil))
+	assert.False(t, caps.AllowInput("system/logs", nil))
+	assert.True(t, caps.AllowOutput("elasticsearch", nil))
 }
 
 func TestDenyMetrics(t *testing.T) {
@@ -69,13 +72,13 @@ capabilities:
 	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
 	require.NoError(t, err, "Loading capabilities should succeed")
 
-	assert.False(t, caps.AllowInput("system/metrics"))
-	assert.False(t, caps.AllowInput("linux/metrics"))
-	assert.False(t, caps.AllowInput("statsd/metrics"))
-	assert.False(t, caps.AllowInput("gcp/metrics"))
-	assert.True(t, caps.AllowInput("filestream"))
-	assert.True(t, caps.AllowInput("cloudbeat/cis_aws"))
-	assert.True(t, caps.AllowInput("synthetics/http"))
+	assert.False(t, caps.AllowInput("system/metrics", nil))
+	assert.False(t, caps.AllowInput("linux/metrics", nil))
+	assert.False(t, caps.AllowInput("statsd/metrics", nil))
+	assert.False(t, caps.AllowInput("gcp/metrics", nil))
+	assert.True(t, caps.AllowInput("filestream", nil))
+	assert.True(t, caps.AllowInput("cloudbeat/cis_aws", nil))
+	assert.True(t, caps.AllowInput("synthetics/http", nil))
 }
 
 func TestUpgradeVersion(t *testing.T) {
@@ -101,6 +104,115 @@ capabilities:
 
 }
 
+func TestDenyOtelRuntime(t *testing.T) {
+	yml := `
+capabilities:
+- rule: deny
+  runtime: otel
+`
+	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err, "Loading capabilities should succeed")
+
+	assert.True(t, caps.AllowRuntime("process"))
+	assert.False(t, caps.AllowRuntime("otel"))
+	assert.True(t, caps.AllowInput("system/metrics", nil))
+}
+
+func TestDenyNamespace(t *testing.T) {
+	yml := `
+capabilities:
+- rule: allow
+  namespace: default
+- rule: allow
+  namespace: production
+- rule: deny
+  namespace: "*"
+`
+	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err, "Loading capabilities should succeed")
+
+	assert.True(t, caps.AllowNamespace("default"))
+	assert.True(t, caps.AllowNamespace("production"))
+	assert.False(t, caps.AllowNamespace("testing"))
+}
+
+func TestDenyLogLevel(t *testing.T) {
+	yml := `
+capabilities:
+- rule: deny
+  log_level: debug
+`
+	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err, "Loading capabilities should succeed")
+
+	assert.False(t, caps.AllowLogLevel("debug"))
+	assert.True(t, caps.AllowLogLevel("info"))
+	assert.True(t, caps.AllowLogLevel("warning"))
+	assert.True(t, caps.AllowLogLevel("error"))
+}
+
+func TestInputCondition(t *testing.T) {
+	yml := `
+capabilities:
+- rule: deny
+  input: filestream
+  condition: "${host.platform} == 'windows'"
+- rule: deny
+  input: "*"
+  condition: "${input.id} == 'blocked-id'"
+`
+	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err, "Loading capabilities should succeed")
+
+	manager, ok := caps.(*capabilitiesManager)
+	require.True(t, ok)
+	manager.facts = map[string]interface{}{
+		"host": map[string]interface{}{"platform": "windows"},
+	}
+	assert.False(t, caps.AllowInput("filestream", map[string]interface{}{"id": "logs"}))
+	assert.True(t, caps.AllowInput("system/metrics", map[string]interface{}{"id": "metrics"}))
+	assert.False(t, caps.AllowInput("system/metrics", map[string]interface{}{"id": "blocked-id"}))
+
+	manager.facts = map[string]interface{}{
+		"host": map[string]interface{}{"platform": "linux"},
+	}
+	assert.True(t, caps.AllowInput("filestream", map[string]interface{}{"id": "logs"}))
+	assert.True(t, caps.AllowInput("filestream", nil))
+}
+
+func TestOutputCondition(t *testing.T) {
+	yml := `
+capabilities:
+- rule: allow
+  output: logstash
+  condition: "${output.ssl.enabled} == true"
+- rule: deny
+  output: logstash
+`
+	caps, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err, "Loading capabilities should succeed")
+
+	assert.True(t, caps.AllowOutput("logstash", map[string]interface{}{
+		"hosts": []interface{}{"logstash:5044"},
+		"ssl":   map[string]interface{}{"enabled": true},
+	}))
+	assert.False(t, caps.AllowOutput("logstash", map[string]interface{}{
+		"hosts": []interface{}{"logstash:5044"},
+	}))
+	assert.True(t, caps.AllowOutput("elasticsearch", nil))
+}
+
+func TestInvalidCondition(t *testing.T) {
+	yml := `
+capabilities:
+- rule: deny
+  input: filestream
+  condition: "${host.platform} =="
+`
+	_, err := Load(strings.NewReader(yml), logger.NewWithoutConfig("testing"))
+	assert.Error(t, err)
+}
+
 func TestNoCaps(t *testing.T) {
 	// Make sure capabilities loaded from a nonexistent file don't interfere
 	// with anything
@@ -108,7 +220,10 @@ func TestNoCaps(t *testing.T) {
 	caps, err := LoadFile(filename, logger.NewWithoutConfig("testing"))
 	require.NoError(t, err, "Loading capabilities should succeed")
 
-	assert.True(t, caps.AllowInput("system/metrics"))
-	assert.True(t, caps.AllowInput("system/logs"))
-	assert.True(t, caps.AllowOutput("elasticsearch"))
+	assert.True(t, caps.AllowInput("system/metrics", nil))
+	assert.True(t, caps.AllowInput("system/logs", nil))
+	assert.True(t, caps.AllowOutput("elasticsearch", nil))
+	assert.True(t, caps.AllowRuntime("otel"))
+	assert.True(t, caps.AllowNamespace("default"))
+	assert.True(t, caps.AllowLogLevel("debug"))
 }
@@ -0,0 +1,198 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package capabilities
+
+import (
+	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+
+	"github.com/elastic/elastic-agent/pkg/component"
+)
+
+// defaultNamespace is the data_stream.namespace used by inputs that don't
+// set one explicitly.
+const defaultNamespace = "default"
+
+// Capability checks reported by InputDecision.Check.
+const (
+	CheckRuntime   = "runtime"
+	CheckOutput    = "output"
+	CheckInput     = "input"
+	CheckNamespace = "namespace"
+)
+
+// Report explains the capability decisions for a component model and,
+// optionally, a proposed upgrade.
+type Report struct {
+	Inputs  []InputDecision  `yaml:"inputs"`
+	Outputs []OutputDecision `yaml:"outputs"`
+	Upgrade *UpgradeDecision `yaml:"upgrade,omitempty"`
+}
+
+// InputDecision explains whether an input unit is allowed to run.
+type InputDecision struct {
+	Component string `yaml:"component"`
+	Unit      string `yaml:"unit"`
+	Type      string `yaml:"type"`
+	Runtime   string `yaml:"runtime"`
+	Output    string `yaml:"output,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	// Check is the capability that decided: runtime, output, input or
+	// namespace.
+	Check    string `yaml:"check"`
+	Decision `yaml:",inline"`
+}
+
+// OutputDecision explains whether an output is allowed to be used.
+type OutputDecision struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Decision `yaml:",inline"`
+}
+
+// UpgradeDecision explains whether an upgrade is allowed.
+type UpgradeDecision struct {
+	Version   string `yaml:"version"`
+	SourceURI string `yaml:"source_uri,omitempty"`
+	Decision  `yaml:",inline"`
+}
+
+// ExplainUpgradeTo explains whether caps allows an upgrade to version from
+// sourceURI.
+func ExplainUpgradeTo(caps Capabilities, version string, sourceURI string) *UpgradeDecision {
+	return &UpgradeDecision{
+		Version:   version,
+		SourceURI: sourceURI,
+		Decision:  caps.ExplainUpgrade(version, sourceURI),
+	}
+}
+
+// FilterComponents removes the components and input units denied by caps
+// and returns the remaining components along with a report explaining every
+// decision. A component is removed when its output or runtime is denied, or
+// when all of its input units are denied by their input type or namespace.
+func FilterComponents(caps Capabilities, comps []component.Component) ([]component.Component, Report) {
+	report := Report{
+		Inputs:  []InputDecision{},
+		Outputs: []OutputDecision{},
+	}
+	outputs := map[string]Decision{}
+	result := []component.Component{}
+	for _, comp := range comps {
+		outputDecision, ok := outputs[comp.OutputName]
+		if !ok {
+			outputDecision = caps.ExplainOutput(comp.OutputType, outputUnitConfig(comp))
+			outputs[comp.OutputName] = outputDecision
+			report.Outputs = append(report.Outputs, OutputDecision{
+				Name:     comp.OutputName,
+				Type:     comp.OutputType,
+				Decision: outputDecision,
+			})
+		}
+		runtimeDecision := caps.ExplainRuntime(string(comp.RuntimeManager))
+
+		units := make([]component.Unit, 0, len(comp.Units))
+		inputUnits := 0
+		for _, unit := range comp.Units {
+			if unit.Type != client.UnitTypeInput {
+				units = append(units, unit)
+				continue
+			}
+			d := InputDecision{
+				Component: comp.ID,
+				Unit:      unit.ID,
+				Type:      comp.InputType,
+				Runtime:   string(comp.RuntimeManager),
+				Output:    comp.OutputName,
+			}
+			d.Check, d.Namespace, d.Decision = explainInputUnit(caps, comp, unit, outputDecision, runtimeDecision)
+			report.Inputs = append(report.Inputs, d)
+			if d.Allowed {
+				inputUnits++
+				units = append(units, unit)
+			}
+		}
+		if !outputDecision.Allowed || !runtimeDecision.Allowed {
+			continue
+		}
+		if inputUnits == 0 && len(units) != len(comp.Units) {
+			// every input unit was filtered, drop the component entirely
+			continue
+		}
+		comp.Units = units
+		result = append(result, comp)
+	}
+	return result, report
+}
+
+// explainInputUnit decides whether the input unit may run, checking the
+// output, the runtime, the input and then each namespace the unit sends data
+// to. Returns the deciding check, the namespace for namespace decisions, and
+// the decision itself.
+func explainInputUnit(caps Capabilities, comp component.Component, unit component.Unit, outputDecision, runtimeDecision Decision) (string, string, Decision) {
+	if !outputDecision.Allowed {
+		return CheckOutput, "", outputDecision
+	}
+	if !runtimeDecision.Allowed {
+		return CheckRuntime, "", runtimeDecision
+	}
+	inputDecision := defaultDecision
+	if comp.InputSpec != nil {
+		inputDecision = caps.ExplainInput(comp.InputType, inputUnitConfig(comp, unit))
+		if !inputDecision.Allowed {
+			return CheckInput, "", inputDecision
+		}
+	}
+	for _, ns := range unitNamespaces(unit) {
+		if d := caps.ExplainNamespace(ns); !d.Allowed {
+			return CheckNamespace, ns, d
+		}
+	}
+	return CheckInput, "", inputDecision
+}
+
+// unitNamespaces returns the namespaces referenced by the unit, at the input
+// and the stream level.
+func unitNamespaces(unit component.Unit) []string {
+	if unit.Config == nil {
+		return nil
+	}
+	namespaces := []string{unit.Config.GetDataStream().GetNamespace()}
+	for _, stream := range unit.Config.GetStreams() {
+		if ns := stream.GetDataStream().GetNamespace(); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	for i, ns := range namespaces {
+		if ns == "" {
+			namespaces[i] = defaultNamespace
+		}
+	}
+	return namespaces
+}
+
+// inputUnitConfig returns the configuration of the input unit as exposed to
+// capability conditions, including the use_output that the component model
+// strips from it.
+func inputUnitConfig(comp component.Component, unit component.Unit) map[string]interface{} {
+	cfg := map[string]interface{}{}
+	if unit.Config != nil && unit.Config.GetSource() != nil {
+		cfg = unit.Config.GetSource().AsMap()
+	}
+	if comp.OutputName != "" {
+		cfg["use_output"] = comp.OutputName
+	}
+	return cfg
+}
+
+// outputUnitConfig returns the configuration of the output unit of the
+// component, nil if it has none.
+func outputUnitConfig(comp component.Component) map[string]interface{} {
+	for _, unit := range comp.Units {
+		if unit.Type == client.UnitTypeOutput && unit.Config != nil && unit.Config.GetSource() != nil {
+			return unit.Config.GetSource().AsMap()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package capabilities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+	"github.com/elastic/elastic-agent-client/v7/pkg/proto"
+
+	"github.com/elastic/elastic-agent/pkg/component"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestFilterComponents(t *testing.T) {
+	caps, err := Load(strings.NewReader(`
+capabilities:
+- rule: deny
+  output: kafka
+- rule: deny
+  namespace: testing
+- rule: allow
+  input: system/metrics
+- rule: deny
+  input: "*"
+`), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err)
+
+	inputUnit := func(id string, namespace string) component.Unit {
+		return component.Unit{
+			ID:   id,
+			Type: client.UnitTypeInput,
+			Config: &proto.UnitExpectedConfig{
+				Id:         id,
+				DataStream: &proto.DataStream{Namespace: namespace},
+			},
+		}
+	}
+	outputUnit := component.Unit{ID: "output", Type: client.UnitTypeOutput}
+
+	comps, report := FilterComponents(caps, []component.Component{
+		{
+			ID:             "system-comp",
+			InputType:      "system/metrics",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "elasticsearch",
+			OutputName:     "default",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("system-default", ""), inputUnit("system-testing", "testing"), outputUnit},
+		},
+		{
+			ID:             "log-comp",
+			InputType:      "log",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "elasticsearch",
+			OutputName:     "default",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("log-default", ""), outputUnit},
+		},
+		{
+			ID:             "kafka-comp",
+			InputType:      "system/metrics",
+			InputSpec:      &component.InputRuntimeSpec{},
+			OutputType:     "kafka",
+			OutputName:     "queue",
+			RuntimeManager: component.ProcessRuntimeManager,
+			Units:          []component.Unit{inputUnit("kafka-default", ""), outputUnit},
+		},
+	})
+
+	require.Len(t, comps, 1)
+	assert.Equal(t, "system-comp", comps[0].ID)
+	require.Len(t, comps[0].Units, 2)
+	assert.Equal(t, "system-default", comps[0].Units[0].ID)
+
+	expectedInputs := []InputDecision{
+		{Component: "system-comp", Unit: "system-default", Type: "system/metrics", Runtime: "process", Output: "default", Check: CheckInput, Decision: Decision{Allowed: true, Rule: 2}},
+		{Component: "system-comp", Unit: "system-testing", Type: "system/metrics", Runtime: "process", Output: "default", Namespace: "testing", Check: CheckNamespace, Decision: Decision{Allowed: false, Rule: 1}},
+		{Component: "log-comp", Unit: "log-default", Type: "log", Runtime: "process", Output: "default", Check: CheckInput, Decision: Decision{Allowed: false, Rule: 3}},
+		{Component: "kafka-comp", Unit: "kafka-default", Type: "system/metrics", Runtime: "process", Output: "queue", Check: CheckOutput, Decision: Decision{Allowed: false, Rule: 0}},
+	}
+	assert.Equal(t, expectedInputs, report.Inputs)
+
+	expectedOutputs := []OutputDecision{
+		{Name: "default", Type: "elasticsearch", Decision: Decision{Allowed: true, Rule: -1}},
+		{Name: "queue", Type: "kafka", Decision: Decision{Allowed: false, Rule: 0}},
+	}
+	assert.Equal(t, expectedOutputs, report.Outputs)
+}
+
+func TestExplainUpgradeTo(t *testing.T) {
+	caps, err := Load(strings.NewReader(`
+capabilities:
+- rule: allow
+  input: "*"
+- rule: deny
+  upgrade: "${version} == '9.0.0'"
+`), logger.NewWithoutConfig("testing"))
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		&UpgradeDecision{Version: "9.0.0", Decision: Decision{Allowed: false, Rule: 1}},
+		ExplainUpgradeTo(caps, "9.0.0", ""))
+	assert.Equal(t,
+		&UpgradeDecision{Version: "9.1.0", SourceURI: "https://example.com", Decision: Decision{Allowed: true, Rule: -1}},
+		ExplainUpgradeTo(caps, "9.1.0", "https://example.com"))
+}
@@ -13,9 +13,12 @@ import (
 // capabilitiesList deserializes a YAML list of capabilities into organized
 // arrays based on their type, for easy use by capabilitiesManager.
 type capabilitiesList struct {
-	inputChecks   []*stringMatcher
-	outputChecks  []*stringMatcher
-	upgradeChecks []*upgradeCapability
+	inputChecks     []*stringMatcher
+	outputChecks    []*stringMatcher
+	runtimeChecks   []*stringMatcher
+	namespaceChecks []*stringMatcher
+	logLevelChecks  []*stringMatcher
+	upgradeChecks   []*upgradeCapability
 }
 
 // a type for capability values that must equal "allow" or "deny", enforced
@@ -47,25 +50,40 @@ func (r *capabilitiesList) UnmarshalYAML(unmarshal func(interface{}) error) erro
 			return err
 		}
 		if _, found := mm["input"]; found {
-			spec := struct {
-				Type  allowOrDeny `yaml:"rule"`
-				Input string      `yaml:"input"`
-			}{}
-			if err := yaml.Unmarshal(partialYaml, &spec); err != nil {
+			matcher, err := newStringMatcherFromYAML(partialYaml, "input")
+			if err != nil {
 				return err
 			}
-			r.inputChecks = append(r.inputChecks,
-				&stringMatcher{pattern: spec.Input, rule: spec.Type})
+			matcher.index = i
+			r.inputChecks = append(r.inputChecks, matcher)
 		} else if _, found = mm["output"]; found {
-			spec := struct {
-				Type   allowOrDeny `yaml:"rule"`
-				Output string      `yaml:"output"`
-			}{}
-			if err := yaml.Unmarshal(partialYaml, &spec); err != nil {
+			matcher, err := newStringMatcherFromYAML(partialYaml, "output")
+			if err != nil {
+				return err
+			}
+			matcher.index = i
+			r.outputChecks = append(r.outputChecks, matcher)
+		} else if _, found = mm["runtime"]; found {
+			matcher, err := newStringMatcherFromYAML(partialYaml, "runtime")
+			if err != nil {
 				return err
 			}
-			r.outputChecks = append(r.outputChecks,
-				&stringMatcher{pattern: spec.Output, rule: spec.Type})
+			matcher.index = i
+			r.runtimeChecks = append(r.runtimeChecks, matcher)
+		} else if _, found = mm["namespace"]; found {
+			matcher, err := newStringMatcherFromYAML(partialYaml, "namespace")
+			if err != nil {
+				return err
+			}
+			matcher.index = i
+			r.namespaceChecks = append(r.namespaceChecks, matcher)
+		} else if _, found = mm["log_level"]; found {
+			matcher, err := newStringMatcherFromYAML(partialYaml, "log_level")
+			if err != nil {
+				return err
+			}
+			matcher.index = i
+			r.logLevelChecks = append(r.logLevelChecks, matcher)
 		} else if _, found = mm["upgrade"]; found {
 			// Serialize upgrade constraints to a temporary struct so we can
 			// safely assemble the associated EQL expression
@@ -80,6 +98,7 @@ func (r *capabilitiesList) UnmarshalYAML(unmarshal func(interface{}) error) erro
 			if err != nil {
 				return err
 			}
+			cap.index = i
 			r.upgradeChecks = append(r.upgradeChecks, cap)
 		} else {
 			return fmt.Errorf("unexpected capability type for definition number '%d'", i)
@@ -89,6 +108,28 @@ func (r *capabilitiesList) UnmarshalYAML(unmarshal func(interface{}) error) erro
 	return nil
 }
 
+// newStringMatcherFromYAML deserializes a single capability definition whose
+// pattern is stored under the given key, e.g. `runtime: otel`, along with its
+// optional EQL `condition`.
+func newStringMatcherFromYAML(partialYaml []byte, key string) (*stringMatcher, error) {
+	spec := struct {
+		Type      allowOrDeny `yaml:"rule"`
+		Condition string      `yaml:"condition"`
+	}{}
+	if err := yaml.Unmarshal(partialYaml, &spec); err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(partialYaml, &values); err != nil {
+		return nil, err
+	}
+	pattern, ok := values[key].(string)
+	if !ok {
+		return nil, fmt.Errorf("capability %q must be a string, got %T", key, values[key])
+	}
+	return newStringMatcher(pattern, spec.Type, spec.Condition)
+}
+
 func (ad allowOrDeny) Validate() error {
 	if ad != ruleTypeAllow && ad != ruleTypeDeny {
 		return fmt.Errorf("capability rule was %q, expected 'allow' or 'deny'", ad)
@@ -23,6 +23,9 @@ func TestUnmarshal(t *testing.T) {
 		assert.Equal(t, 1, len(rr.Capabilities.inputChecks))
 		assert.Equal(t, 1, len(rr.Capabilities.outputChecks))
 		assert.Equal(t, 1, len(rr.Capabilities.upgradeChecks))
+		assert.Equal(t, 1, len(rr.Capabilities.runtimeChecks))
+		assert.Equal(t, 1, len(rr.Capabilities.namespaceChecks))
+		assert.Equal(t, 1, len(rr.Capabilities.logLevelChecks))
 	})
 
 	t.Run("invalid yaml", func(t *testing.T) {
@@ -32,6 +35,14 @@ func TestUnmarshal(t *testing.T) {
 
 		assert.Error(t, err, "error is expected")
 	})
+
+	t.Run("non-string pattern", func(t *testing.T) {
+		var rr capabilitiesSpec
+
+		err := yaml.Unmarshal([]byte("capabilities:\n- rule: deny\n  runtime: [otel]\n"), &rr)
+
+		assert.Error(t, err, "error is expected")
+	})
 }
 
 var yamlDefinitionValid = []byte(`capabilities:
@@ -44,6 +55,15 @@ var yamlDefinitionValid = []byte(`capabilities:
 -
   output: "elasticsearch"
   rule: "allow"
+-
+  runtime: "otel"
+  rule: "deny"
+-
+  namespace: "testing"
+  rule: "deny"
+-
+  log_level: "debug"
+  rule: "deny"
 `)
 
 var yamlDefinitionInvalid = []byte(`
@@ -4,6 +4,13 @@
 
 package capabilities
 
+import (
+	"fmt"
+
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
 type stringMatcher struct {
 	// The pattern to match against, a string that can use '*' as a wildcard
 	// by itself or in between slashes, e.g.
@@ -13,15 +20,82 @@ type stringMatcher struct {
 	// Whether matching this pattern results in allowing or denying the
 	// corresponding string.
 	rule allowOrDeny
+
+	// An optional EQL condition that must also succeed for the rule to apply,
+	// nil if the rule only checks the pattern.
+	condition *eql.Expression
+
+	// The original string used to create the EQL condition, preserved to allow
+	// useful error reporting
+	conditionStr string
+
+	// The position of the rule in capabilities.yml, reported by decisions.
+	index int
+}
+
+func newStringMatcher(pattern string, rule allowOrDeny, condition string) (*stringMatcher, error) {
+	matcher := &stringMatcher{
+		pattern:      pattern,
+		rule:         rule,
+		conditionStr: condition,
+	}
+	if condition != "" {
+		eqlExpr, err := eql.New(condition)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load condition %q: %w", condition, err)
+		}
+		matcher.condition = eqlExpr
+	}
+	return matcher, nil
+}
+
+// matchString checks str against the given matchers, giving their EQL
+// conditions access to the variables in vars. Conditions that can't be
+// evaluated, including when vars is nil, apply deny rules and skip allow rules.
+func matchString(log *logger.Logger, str string, vars eql.VarStore, matchers []*stringMatcher) bool {
+	return decideString(log, str, vars, matchers).Allowed
 }
 
-func matchString(str string, matchers []*stringMatcher) bool {
+// decideString is like matchString but also reports which matcher decided.
+func decideString(log *logger.Logger, str string, vars eql.VarStore, matchers []*stringMatcher) Decision {
 	for _, matcher := range matchers {
-		if matchesExpr(matcher.pattern, str) {
-			// The check passed, allow or reject as appropriate
-			return matcher.rule == ruleTypeAllow
+		if !matchesExpr(matcher.pattern, str) {
+			continue
 		}
+		if matcher.condition != nil {
+			// a condition that can't be evaluated fails closed: deny rules
+			// apply and allow rules are skipped
+			if vars == nil {
+				if matcher.rule != ruleTypeDeny {
+					continue
+				}
+				log.Warnf("no variables to evaluate eql formula %q, applying deny rule %d", matcher.conditionStr, matcher.index)
+			} else {
+				result, err := matcher.condition.Eval(vars, true)
+				if err != nil {
+					if matcher.rule != ruleTypeDeny {
+						log.Warnf("failed evaluating eql formula %q, skipping: %v", matcher.conditionStr, err)
+						continue
+					}
+					log.Warnf("failed evaluating eql formula %q, applying deny rule %d: %v", matcher.conditionStr, matcher.index, err)
+				} else if !result {
+					continue
+				}
+			}
+		}
+		// The check passed, allow or reject as appropriate
+		return Decision{Allowed: matcher.rule == ruleTypeAllow, Rule: matcher.index}
 	}
 	// If nothing blocked it, default to allow.
-	return true
+	return defaultDecision
+}
+
+// hasConditions returns true if any of the matchers has an EQL condition.
+func hasConditions(matchers []*stringMatcher) bool {
+	for _, matcher := range matchers {
+		if matcher.condition != nil {
+			return true
+		}
+	}
+	return false
 }
@@ -8,6 +8,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
 )
 
 func TestStringMatcher(t *testing.T) {
@@ -113,10 +117,30 @@ func TestStringMatcher(t *testing.T) {
 
 	for _, tc := range testCases {
 		for _, str := range tc.allowed {
-			assert.True(t, matchString(str, tc.matchers), "%v: string %q should match test patterns", tc.name, str)
+			assert.True(t, matchString(nil, str, nil, tc.matchers), "%v: string %q should match test patterns", tc.name, str)
 		}
 		for _, str := range tc.blocked {
-			assert.False(t, matchString(str, tc.matchers), "%v: string %q should not match test patterns", tc.name, str)
+			assert.False(t, matchString(nil, str, nil, tc.matchers), "%v: string %q should not match test patterns", tc.name, str)
 		}
 	}
 }
+
+func TestStringMatcherConditionEvalError(t *testing.T) {
+	log := logger.NewWithoutConfig("testing")
+	vars, err := transpiler.NewAST(map[string]interface{}{
+		"input": map[string]interface{}{"id": "logs"},
+	})
+	require.NoError(t, err)
+
+	// length(4) always fails to evaluate
+	deny, err := newStringMatcher("filestream", ruleTypeDeny, "length(4) == 2")
+	require.NoError(t, err)
+	allow, err := newStringMatcher("filestream", ruleTypeAllow, "length(4) == 2")
+	require.NoError(t, err)
+
+	assert.False(t, matchString(log, "filestream", vars, []*stringMatcher{deny}), "deny rule with an eval error should apply")
+	assert.False(t, matchString(log, "filestream", nil, []*stringMatcher{deny}), "deny rule without variables should apply")
+	denyAll, err := newStringMatcher("*", ruleTypeDeny, "")
+	require.NoError(t, err)
+	assert.False(t, matchString(log, "filestream", vars, []*stringMatcher{allow, denyAll}), "allow rule with an eval error should be skipped")
+}
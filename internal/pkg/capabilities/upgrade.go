@@ -22,6 +22,9 @@ type upgradeCapability struct {
 	// The original string used to create the EQL condition, preserved to allow
 	// useful error reporting
 	conditionStr string
+
+	// The position of the rule in capabilities.yml, reported by decisions.
+	index int
 }
 
 func newUpgradeCapability(condition string, rule allowOrDeny) (*upgradeCapability, error) {
@@ -49,6 +52,15 @@ func allowUpgrade(
 	version string, sourceURI string,
 	upgradeCaps []*upgradeCapability,
 ) bool {
+	return decideUpgrade(log, version, sourceURI, upgradeCaps).Allowed
+}
+
+// decideUpgrade is like allowUpgrade but also reports which rule decided.
+func decideUpgrade(
+	log *logger.Logger,
+	version string, sourceURI string,
+	upgradeCaps []*upgradeCapability,
+) Decision {
 	// create VarStore out of map
 	varStore, err := transpiler.NewAST(map[string]interface{}{
 		"version":   version,
@@ -59,7 +71,7 @@ func allowUpgrade(
 		// deterministically succeed. But if there is a mysterious encoding bug,
 		// don't block upgrades.
 		log.Errorf("failed creating a varStore for upgrade capability: %v", err)
-		return true
+		return defaultDecision
 	}
 
 	for _, cap := range upgradeCaps {
@@ -70,9 +82,9 @@ func allowUpgrade(
 		}
 		if result {
 			// The check passed, either accept or deny as configured.
-			return cap.rule == ruleTypeAllow
+			return Decision{Allowed: cap.rule == ruleTypeAllow, Rule: cap.index}
 		}
 	}
 	// If nothing blocked the upgrade, allow it.
-	return true
+	return defaultDecision
 }
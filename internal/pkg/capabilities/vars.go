@@ -0,0 +1,55 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package capabilities
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/transpiler"
+	"github.com/elastic/elastic-agent/internal/pkg/eql"
+	"github.com/elastic/elastic-agent/internal/pkg/release"
+)
+
+// defaultFacts returns the host and agent facts that input and output
+// conditions can reference, e.g. ${host.platform} or ${agent.version}.
+func defaultFacts() map[string]interface{} {
+	hostname, _ := os.Hostname()
+	return map[string]interface{}{
+		"host": map[string]interface{}{
+			"name":         strings.ToLower(hostname),
+			"platform":     runtime.GOOS,
+			"architecture": runtime.GOARCH,
+		},
+		"agent": map[string]interface{}{
+			"version":  release.Version(),
+			"snapshot": release.Snapshot(),
+		},
+	}
+}
+
+// conditionVars creates the variables available to the EQL conditions of the
+// given matchers: the facts plus, when cfg is set, the configuration of the
+// input or output under key. Returns nil when none of the matchers has a
+// condition, so the variables are only built when needed.
+func (cm *capabilitiesManager) conditionVars(matchers []*stringMatcher, key string, cfg map[string]interface{}) eql.VarStore {
+	if !hasConditions(matchers) {
+		return nil
+	}
+	vars := make(map[string]interface{}, len(cm.facts)+1)
+	for k, v := range cm.facts {
+		vars[k] = v
+	}
+	if key != "" && cfg != nil {
+		vars[key] = cfg
+	}
+	varStore, err := transpiler.NewAST(vars)
+	if err != nil {
+		cm.log.Warnf("failed creating a varStore for %s capability, skipping conditions: %v", key, err)
+		return nil
+	}
+	return varStore
+}
@@ -0,0 +1,136 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package capabilities
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// watchDebounce is the time the capabilities file must not change for before it is reloaded, so a file being written
+// is not loaded half written.
+const watchDebounce = 500 * time.Millisecond
+
+// Watcher reports new Capabilities whenever the capabilities file changes.
+type Watcher interface {
+	Watch() <-chan Capabilities
+	Run(ctx context.Context) error
+}
+
+type fileWatcher struct {
+	capsFile string
+	logger   *logger.Logger
+	updateCh chan Capabilities
+	debounce time.Duration
+}
+
+// NewFileWatcher creates a Watcher that reloads capsFile every time it is
+// created, written, or removed, once it stopped changing. A removed file
+// results in empty capabilities that allow everything, the same as LoadFile.
+// An empty or invalid file is ignored and the last capabilities keep applying.
+func NewFileWatcher(capsFile string, log *logger.Logger) Watcher {
+	return &fileWatcher{
+		capsFile: filepath.Clean(capsFile),
+		logger:   log.Named("capabilities_watcher"),
+		updateCh: make(chan Capabilities),
+		debounce: watchDebounce,
+	}
+}
+
+func (fw *fileWatcher) Watch() <-chan Capabilities {
+	return fw.updateCh
+}
+
+// Run starts watching the capabilities file in the background, it returns
+// once the watch is set up.
+func (fw *fileWatcher) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create capabilities watcher: %w", err)
+	}
+
+	// Watch the directory, not the file itself, so we notice the file even
+	// if it's deleted and recreated or doesn't exist yet.
+	capsDir := filepath.Dir(fw.capsFile)
+	if err := watcher.Add(capsDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to set watch on capabilities directory [%s]: %w", capsDir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		// the file is reloaded once it has not changed for the debounce time
+		reload := time.NewTimer(fw.debounce)
+		reload.Stop()
+		defer reload.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok { // Channel was closed (i.e. Watcher.Close() was called).
+					fw.logger.Debug("fsnotify.Watcher's error channel was closed")
+					return
+				}
+				fw.logger.Errorf("capabilities watch returned error: %s", err)
+			case e, ok := <-watcher.Events:
+				if !ok { // Channel was closed (i.e. Watcher.Close() was called).
+					fw.logger.Debug("fsnotify.Watcher's events channel was closed")
+					return
+				}
+				if filepath.Clean(e.Name) != fw.capsFile {
+					// other files in the same directory
+					continue
+				}
+				if e.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Remove|fsnotify.Rename) == 0 {
+					continue
+				}
+				reload.Reset(fw.debounce)
+			case <-reload.C:
+				caps, err := fw.load()
+				if err != nil {
+					// keep enforcing the previous capabilities until the file
+					// is fixed
+					fw.logger.Errorf("failed to reload capabilities from %s, keeping the current ones: %s", fw.capsFile, err)
+					continue
+				}
+				fw.logger.Infof("Capabilities reloaded from %s", fw.capsFile)
+				select {
+				case fw.updateCh <- caps:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// load loads the capabilities file like LoadFile, an empty file is rejected as it is most likely being written.
+func (fw *fileWatcher) load() (Capabilities, error) {
+	data, err := os.ReadFile(fw.capsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		fw.logger.Infof("Capabilities file not found in %s", fw.capsFile)
+		return &capabilitiesManager{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("capabilities file is empty")
+	}
+	return Load(bytes.NewReader(data), fw.logger)
+}
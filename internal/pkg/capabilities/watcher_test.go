@@ -0,0 +1,109 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package capabilities
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+)
+
+func TestFileWatcher(t *testing.T) {
+	capsFile := filepath.Join(t.TempDir(), "capabilities.yml")
+	log, _ := loggertest.New(t.Name())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watcher := NewFileWatcher(capsFile, log)
+	require.NoError(t, watcher.Run(ctx))
+
+	// a single write can produce multiple events, wait until the expected
+	// capabilities are reported
+	waitForCaps := func(check func(Capabilities) bool) {
+		for {
+			select {
+			case caps := <-watcher.Watch():
+				if check(caps) {
+					return
+				}
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for capabilities to be reloaded")
+			}
+		}
+	}
+	metricsDenied := func(caps Capabilities) bool {
+		return !caps.AllowInput("system/metrics", nil)
+	}
+
+	// creating the file reports the new capabilities
+	require.NoError(t, os.WriteFile(capsFile, []byte(`
+capabilities:
+- rule: deny
+  input: system/metrics
+`), 0o600))
+	waitForCaps(metricsDenied)
+
+	// invalid content is not reported, the previous capabilities still apply
+	require.NoError(t, os.WriteFile(capsFile, []byte(`capabilities: [{rule: deny, unknown: x}]`), 0o600))
+
+	// removing the file reports empty capabilities
+	require.NoError(t, os.Remove(capsFile))
+	waitForCaps(func(caps Capabilities) bool {
+		return !metricsDenied(caps)
+	})
+}
+
+func TestFileWatcherIgnoresPartialWrites(t *testing.T) {
+	capsFile := filepath.Join(t.TempDir(), "capabilities.yml")
+	log, _ := loggertest.New(t.Name())
+	require.NoError(t, os.WriteFile(capsFile, []byte(`
+capabilities:
+- rule: deny
+  input: system/metrics
+`), 0o600))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watcher := NewFileWatcher(capsFile, log)
+	watcher.(*fileWatcher).debounce = 100 * time.Millisecond
+	require.NoError(t, watcher.Run(ctx))
+
+	// a truncated file is not reported, the previous capabilities still apply
+	require.NoError(t, os.WriteFile(capsFile, nil, 0o600))
+	select {
+	case <-watcher.Watch():
+		require.FailNow(t, "an empty capabilities file should not be reported")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	// the intermediate contents of a file being written are not reported
+	require.NoError(t, os.WriteFile(capsFile, []byte(`capabilities: []`), 0o600))
+	require.NoError(t, os.WriteFile(capsFile, []byte(`
+capabilities:
+- rule: deny
+  input: log
+`), 0o600))
+	select {
+	case caps := <-watcher.Watch():
+		assert.False(t, caps.AllowInput("log", nil), "the final capabilities should be reported")
+		assert.True(t, caps.AllowInput("system/metrics", nil))
+	case <-ctx.Done():
+		require.FailNow(t, "timed out waiting for capabilities to be reloaded")
+	}
+	select {
+	case <-watcher.Watch():
+		require.FailNow(t, "the capabilities should be reported once")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/transport/httpcommon"
+
+	"github.com/elastic/elastic-agent/internal/pkg/composable"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	corecomp "github.com/elastic/elastic-agent/internal/pkg/core/composable"
+	"github.com/elastic/elastic-agent/internal/pkg/scheduler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func init() {
+	// http provider polls the URLs that are defined in the provider configuration and exposes the JSON object
+	// returned by each of them under the name of its source, e.g. ${http.cmdb.team}.
+	//
+	// When polling a source fails the last values fetched from it are kept, until then the source is not
+	// present in the mapping.
+	composable.Providers.MustAddContextProvider("http", ContextProviderBuilder)
+}
+
+const (
+	// DefaultInterval is the default interval between two polls of a source.
+	DefaultInterval = 5 * time.Minute
+	// DefaultJitter is the default maximum jitter added to the interval between two polls of a source.
+	DefaultJitter = 30 * time.Second
+	// DefaultMaxSize is the default maximum size of a response.
+	DefaultMaxSize = 1024 * 1024 // 1MiB
+)
+
+type sourceConfig struct {
+	URL         string            `config:"url"`
+	Headers     map[string]string `config:"headers"`
+	BearerToken string            `config:"bearer_token"`
+	Interval    time.Duration     `config:"interval"`
+	Jitter      time.Duration     `config:"jitter"`
+
+	// Transport is not embedded, its Unpack would be promoted and unpack only the transport settings.
+	Transport httpcommon.HTTPTransportSettings `config:",inline"`
+}
+
+type providerConfig struct {
+	Enabled bool                     `config:"enabled"` // handled by composable manager (but here to show that it is part of the config)
+	Sources map[string]*sourceConfig `config:"sources"`
+	MaxSize int                      `config:"max_size"`
+}
+
+type contextProvider struct {
+	logger *logger.Logger
+
+	cfg     providerConfig
+	clients map[string]*http.Client
+
+	// used by testing
+	newScheduler func(d, variance time.Duration) scheduler.Scheduler
+}
+
+// Run runs the http context provider.
+func (c *contextProvider) Run(ctx context.Context, comm corecomp.ContextProviderComm) error {
+	var mx sync.Mutex
+	current := make(map[string]interface{}, len(c.cfg.Sources))
+
+	var wg sync.WaitGroup
+	for name, cfg := range c.cfg.Sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.poll(ctx, name, cfg, func(values map[string]interface{}) {
+				mx.Lock()
+				defer mx.Unlock()
+				if reflect.DeepEqual(current[name], values) {
+					// nothing to do
+					return
+				}
+				current[name] = values
+				err := comm.Set(maps.Clone(current))
+				if err != nil {
+					c.logger.Errorf("failed to set mapping from source %q: %s", name, err)
+				}
+			})
+		}()
+	}
+	wg.Wait()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// poll fetches the source on each tick of its scheduler and passes the values to set until ctx is done.
+func (c *contextProvider) poll(ctx context.Context, name string, cfg *sourceConfig, set func(map[string]interface{})) {
+	sched := c.newScheduler(cfg.Interval, cfg.Jitter)
+	go func() {
+		// unblocks WaitTick
+		<-ctx.Done()
+		sched.Stop()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sched.WaitTick():
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		values, err := c.fetch(ctx, name, cfg)
+		if err != nil {
+			c.logger.Warnf("failed to fetch source %q, keeping the previous values: %s", name, err)
+			continue
+		}
+		set(values)
+	}
+}
+
+// fetch requests the URL of the source and decodes the JSON object of the response.
+func (c *contextProvider) fetch(ctx context.Context, name string, cfg *sourceConfig) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.BearerToken)
+	}
+
+	resp, err := c.clients[name].Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	maxSize := c.cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxSize)
+	}
+
+	var values map[string]interface{}
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, fmt.Errorf("response is not a JSON object: %w", err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// ContextProviderBuilder builds the context provider.
+func ContextProviderBuilder(log *logger.Logger, c *config.Config, _ bool) (corecomp.ContextProvider, error) {
+	p := &contextProvider{
+		logger:  log,
+		clients: make(map[string]*http.Client),
+		newScheduler: func(d, variance time.Duration) scheduler.Scheduler {
+			return scheduler.NewPeriodicJitter(d, variance)
+		},
+	}
+	if c != nil {
+		err := c.UnpackTo(&p.cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpack config: %w", err)
+		}
+	}
+	for sourceName, sourceCfg := range p.cfg.Sources {
+		if sourceCfg == nil || sourceCfg.URL == "" {
+			return nil, fmt.Errorf("%q is missing a defined url", sourceName)
+		}
+		u, err := url.Parse(sourceCfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%q has an invalid url: %w", sourceName, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("%q has an url with an unsupported scheme %q", sourceName, u.Scheme)
+		}
+		if sourceCfg.Interval < 0 || sourceCfg.Jitter < 0 {
+			return nil, fmt.Errorf("%q cannot have a negative interval or jitter", sourceName)
+		}
+		if sourceCfg.Interval == 0 {
+			sourceCfg.Interval = DefaultInterval
+		}
+		if sourceCfg.Jitter == 0 {
+			sourceCfg.Jitter = DefaultJitter
+		}
+		if sourceCfg.Transport.Timeout == 0 {
+			sourceCfg.Transport.Timeout = httpcommon.DefaultHTTPTransportSettings().Timeout
+		}
+
+		client, err := sourceCfg.Transport.Client(
+			httpcommon.WithAPMHTTPInstrumentation(),
+			httpcommon.WithKeepaliveSettings{Disable: false, IdleConnTimeout: 30 * time.Second},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%q failed to create http client: %w", sourceName, err)
+		}
+		p.clients[sourceName] = client
+	}
+	return p, nil
+}
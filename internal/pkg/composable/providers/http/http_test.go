@@ -0,0 +1,185 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package http
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/elastic-agent/internal/pkg/composable"
+	ctesting "github.com/elastic/elastic-agent/internal/pkg/composable/testing"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/internal/pkg/scheduler"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+func TestContextProvider_Config(t *testing.T) {
+	scenarios := []struct {
+		Name   string
+		Config *config.Config
+		Err    error
+	}{
+		{
+			Name: "no url",
+			Config: config.MustNewConfigFrom(map[string]interface{}{
+				"sources": map[string]interface{}{
+					"one": map[string]interface{}{},
+				},
+			}),
+			Err: errors.New(`"one" is missing a defined url`),
+		},
+		{
+			Name: "unsupported scheme",
+			Config: config.MustNewConfigFrom(map[string]interface{}{
+				"sources": map[string]interface{}{
+					"one": map[string]interface{}{
+						"url": "ftp://cmdb.example.com",
+					},
+				},
+			}),
+			Err: errors.New(`"one" has an url with an unsupported scheme "ftp"`),
+		},
+		{
+			Name: "negative interval",
+			Config: config.MustNewConfigFrom(map[string]interface{}{
+				"sources": map[string]interface{}{
+					"one": map[string]interface{}{
+						"url":      "https://cmdb.example.com",
+						"interval": "-1s",
+					},
+				},
+			}),
+			Err: errors.New(`"one" cannot have a negative interval or jitter`),
+		},
+		{
+			Name: "valid",
+			Config: config.MustNewConfigFrom(map[string]interface{}{
+				"sources": map[string]interface{}{
+					"one": map[string]interface{}{
+						"url": "https://cmdb.example.com/one",
+					},
+					"two": map[string]interface{}{
+						"url":          "http://cmdb.example.com/two",
+						"bearer_token": "token",
+						"interval":     "1m",
+					},
+				},
+			}),
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.Name, func(t *testing.T) {
+			log, err := logger.New("http_test", false)
+			require.NoError(t, err)
+
+			builder, _ := composable.Providers.GetContextProvider("http")
+			_, err = builder(log, s.Config, true)
+			if s.Err != nil {
+				require.Equal(t, s.Err, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestContextProvider(t *testing.T) {
+	const testTimeout = 3 * time.Second
+
+	var calls atomic.Int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch calls.Add(1) {
+		case 1:
+			_, _ = w.Write([]byte(`{"team": "observability", "cost_center": 42}`))
+		case 2:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"team": "security", "cost_center": 42}`))
+		}
+	}))
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	log, err := logger.New("http_test", false)
+	require.NoError(t, err)
+
+	c, err := config.NewConfigFrom(map[string]interface{}{
+		"sources": map[string]interface{}{
+			"cmdb": map[string]interface{}{
+				"url":          srv.URL,
+				"bearer_token": "s3cr3t",
+				"ssl": map[string]interface{}{
+					"certificate_authorities": []string{string(ca)},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	builder, _ := composable.Providers.GetContextProvider("http")
+	provider, err := builder(log, c, true)
+	require.NoError(t, err)
+
+	stepper := scheduler.NewStepper()
+	httpProvider, _ := provider.(*contextProvider)
+	httpProvider.newScheduler = func(_, _ time.Duration) scheduler.Scheduler {
+		return stepper
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	comm := ctesting.NewContextComm(ctx)
+	setChan := make(chan map[string]interface{})
+	comm.CallOnSet(func(value map[string]interface{}) {
+		// Forward Set's input to the test channel
+		setChan <- value
+	})
+
+	go func() {
+		_ = provider.Run(ctx, comm)
+	}()
+
+	waitSet := func() map[string]interface{} {
+		select {
+		case current := <-setChan:
+			return current
+		case <-time.After(testTimeout):
+			require.FailNow(t, "timeout waiting for provider to call Set")
+		}
+		return nil
+	}
+
+	stepper.Next()
+	current := waitSet()
+	require.Equal(t, map[string]interface{}{
+		"cmdb": map[string]interface{}{
+			"team":        "observability",
+			"cost_center": float64(42),
+		},
+	}, current)
+
+	// failed poll keeps the previous values, no Set
+	stepper.Next()
+	stepper.Next()
+	current = waitSet()
+	require.Equal(t, map[string]interface{}{
+		"cmdb": map[string]interface{}{
+			"team":        "security",
+			"cost_center": float64(42),
+		},
+	}, current)
+	require.Equal(t, int32(3), calls.Load())
+}
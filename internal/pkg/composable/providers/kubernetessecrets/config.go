@@ -5,28 +5,88 @@
 package kubernetessecrets
 
 import (
+	"errors"
+	"fmt"
+	"path"
 	"time"
 
 	"github.com/elastic/elastic-agent-autodiscover/kubernetes"
 )
 
+const (
+	// refreshModePoll refreshes the cached secrets from the API every cache_refresh_interval
+	refreshModePoll = "poll"
+	// refreshModeWatch watches the cached secrets and updates them as soon as they change
+	refreshModeWatch = "watch"
+)
+
 // Config for kubernetes_secrets provider
 type Config struct {
 	KubeConfig        string                       `config:"kube_config"`
 	KubeClientOptions kubernetes.KubeClientOptions `config:"kube_client_options"`
 
 	RefreshInterval time.Duration `config:"cache_refresh_interval" validate:"positive,nonzero"`
+	RefreshMode     string        `config:"cache_refresh_mode"`
 	TTLDelete       time.Duration `config:"cache_ttl"`
 	RequestTimeout  time.Duration `config:"cache_request_timeout" validate:"positive,nonzero"`
 	DisableCache    bool          `config:"cache_disable"`
+
+	// AllowedNamespaces are the patterns of the namespaces secrets can be read from, all namespaces when empty
+	AllowedNamespaces []string `config:"allowed_namespaces"`
+	// AllowedSecrets are the patterns of the names of the secrets that can be read, all secrets when empty
+	AllowedSecrets []string `config:"allowed_secrets"`
 }
 
 // defaultConfig returns default configuration for kubernetes_secrets provider
 func defaultConfig() *Config {
 	return &Config{
 		RefreshInterval: 60 * time.Second,
+		RefreshMode:     refreshModePoll,
 		TTLDelete:       1 * time.Hour,
 		RequestTimeout:  5 * time.Second,
 		DisableCache:    false,
 	}
 }
+
+// Validate validates the configuration.
+func (c *Config) Validate() error {
+	switch c.RefreshMode {
+	case "", refreshModePoll:
+	case refreshModeWatch:
+		if c.DisableCache {
+			return errors.New("cache_refresh_mode watch requires the cache to be enabled")
+		}
+	default:
+		return fmt.Errorf("invalid cache_refresh_mode %q, must be one of %q or %q", c.RefreshMode, refreshModePoll, refreshModeWatch)
+	}
+
+	for _, pattern := range c.AllowedNamespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowed_namespaces pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range c.AllowedSecrets {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowed_secrets pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// isAllowed returns true when the secret is allowed by the namespaces and secrets allowlists.
+func (c *Config) isAllowed(namespace string, name string) bool {
+	return matchesAnyPattern(c.AllowedNamespaces, namespace) && matchesAnyPattern(c.AllowedSecrets, name)
+}
+
+// matchesAnyPattern returns true when the value matches one of the patterns, or when there are no patterns.
+func matchesAnyPattern(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
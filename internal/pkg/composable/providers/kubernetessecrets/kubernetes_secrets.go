@@ -72,6 +72,8 @@ type contextProviderK8SSecrets struct {
 	clientMtx sync.RWMutex
 	running   chan struct{}
 	store     store
+	// watcher watches the cached secrets when the cache refresh mode is watch, set before running is closed
+	watcher *secretWatcher
 }
 
 // ContextProviderBuilder builds the kubernetes_secrets context provider. By default, this provider employs a cache
@@ -79,7 +81,10 @@ type contextProviderK8SSecrets struct {
 // every Config.RefreshInterval. To maintain only secrets that are actually needed by the agent, each secret reference
 // expires based on the Config.TTLDelete. During expiration of secret references or actual changes of secret values,
 // the kubernetes_secrets provider calls the ContextProviderComm.Signal() to notify the agent. The cache mechanism
-// can be disabled by setting Config.DisableCache to true.
+// can be disabled by setting Config.DisableCache to true. With Config.RefreshMode set to watch, the referenced secrets
+// are watched instead of being refreshed, and changes are applied as soon as they happen. They are still refreshed
+// every Config.RefreshInterval while a secret cannot be listed or watched. Only the secrets allowed by
+// Config.AllowedNamespaces and Config.AllowedSecrets can be fetched.
 func ContextProviderBuilder(logger *logger.Logger, c *config.Config, _ bool) (corecomp.ContextProvider, error) {
 	cfg := defaultConfig()
 
@@ -114,6 +119,9 @@ func (p *contextProviderK8SSecrets) Run(ctx context.Context, comm corecomp.Conte
 	p.clientMtx.Unlock()
 
 	if !p.config.DisableCache {
+		if p.config.RefreshMode == refreshModeWatch {
+			p.watcher = newSecretWatcher(ctx, p.logger, client, p.store, comm.Signal)
+		}
 		go p.refreshCache(ctx, comm)
 	}
 
@@ -146,6 +154,11 @@ func (p *contextProviderK8SSecrets) Fetch(key string) (string, bool) {
 	secretName := tokens[2]
 	secretKey := tokens[3]
 
+	if !p.config.isAllowed(secretNamespace, secretName) {
+		p.logger.Warnf(`Secret %q at namespace %q is not allowed by the allowed_namespaces and allowed_secrets settings of the %s provider`, secretName, secretNamespace, k8sSecretsProviderName)
+		return "", false
+	}
+
 	// Wait for the provider to be initialized
 	<-p.running
 
@@ -157,6 +170,9 @@ func (p *contextProviderK8SSecrets) Fetch(key string) (string, bool) {
 	}
 
 	// cache enabled
+	if p.watcher != nil {
+		defer p.watcher.watch(secretNamespace, secretName)
+	}
 	sd, exists := p.store.Get(key, true)
 	if exists {
 		// cache hit
@@ -203,6 +219,20 @@ func (p *contextProviderK8SSecrets) refreshCache(ctx context.Context, comm corec
 		case <-ctx.Done():
 			return
 		case <-timer.C:
+			if p.watcher != nil && p.watcher.healthy() {
+				// watched secrets are already up-to-date, only expire them
+				if p.expireSecrets() {
+					p.logger.Info("Cache: secrets expired, agent will be notified")
+					comm.Signal()
+				}
+				p.watcher.prune()
+				timer.Reset(p.config.RefreshInterval)
+				continue
+			}
+			if p.watcher != nil {
+				p.logger.Warn("Watch: secrets are not all watched, falling back to refreshing them")
+			}
+
 			p.logger.Info("Cache: refresh started")
 			hasUpdate := p.updateSecrets(ctx)
 			if hasUpdate {
@@ -211,6 +241,9 @@ func (p *contextProviderK8SSecrets) refreshCache(ctx context.Context, comm corec
 			} else {
 				p.logger.Info("Cache: refresh ended without updates")
 			}
+			if p.watcher != nil {
+				p.watcher.prune()
+			}
 			timer.Reset(p.config.RefreshInterval)
 		}
 	}
@@ -266,6 +299,18 @@ func (p *contextProviderK8SSecrets) updateSecrets(ctx context.Context) bool {
 	return hasUpdates
 }
 
+// expireSecrets removes the expired secrets from the cache and returns true if any of the secrets has expired
+func (p *contextProviderK8SSecrets) expireSecrets() bool {
+	hasUpdates := false
+	for _, key := range p.store.ListKeys() {
+		if _, exists := p.store.Get(key, false); !exists {
+			p.logger.Infof(`Cache: %q expired`, key)
+			hasUpdates = true
+		}
+	}
+	return hasUpdates
+}
+
 // fetchFromAPI fetches the secret value from the API
 func (p *contextProviderK8SSecrets) fetchFromAPI(ctx context.Context, secretName string, secretNamespace string, secretKey string) (string, string, bool) {
 	ctx, cancel := context.WithTimeout(ctx, p.config.RequestTimeout)
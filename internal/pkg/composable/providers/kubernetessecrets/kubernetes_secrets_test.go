@@ -18,9 +18,13 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	k8sclient "k8s.io/client-go/kubernetes"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/elastic/elastic-agent-autodiscover/kubernetes"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -89,6 +93,54 @@ func Test_Fetch(t *testing.T) {
 				buildCacheEntry("default", "secret_name", "wrong", "", false, time.Now(), time.Now()),
 			),
 		},
+		{
+			name: "namespace not allowed",
+			providerCfg: Config{
+				RequestTimeout:    time.Second,
+				AllowedNamespaces: []string{"kube-*"},
+			},
+			k8sClient: k8sfake.NewClientset(
+				testDataBuilder.buildK8SSecret("secret_value"),
+			),
+			storeInit:     func(t *testing.T) store { return newExpirationCache(time.Minute) },
+			keyToFetch:    testDataBuilder.getFetchKey(),
+			expectedValue: "",
+			expectedFound: false,
+			expectedCache: nil,
+		},
+		{
+			name: "secret not allowed",
+			providerCfg: Config{
+				RequestTimeout: time.Second,
+				AllowedSecrets: []string{"db-*"},
+			},
+			k8sClient: k8sfake.NewClientset(
+				testDataBuilder.buildK8SSecret("secret_value"),
+			),
+			storeInit:     func(t *testing.T) store { return newExpirationCache(time.Minute) },
+			keyToFetch:    testDataBuilder.getFetchKey(),
+			expectedValue: "",
+			expectedFound: false,
+			expectedCache: nil,
+		},
+		{
+			name: "namespace and secret allowed",
+			providerCfg: Config{
+				RequestTimeout:    time.Second,
+				AllowedNamespaces: []string{"kube-system", "default"},
+				AllowedSecrets:    []string{"secret_*"},
+			},
+			k8sClient: k8sfake.NewClientset(
+				testDataBuilder.buildK8SSecret("secret_value"),
+			),
+			storeInit:     func(t *testing.T) store { return newExpirationCache(time.Minute) },
+			keyToFetch:    testDataBuilder.getFetchKey(),
+			expectedValue: "secret_value",
+			expectedFound: true,
+			expectedCache: buildCacheMap(
+				testDataBuilder.buildCacheEntry("secret_value", true, time.Now(), time.Now()),
+			),
+		},
 		{
 			name: "k8s client nil",
 			providerCfg: Config{
@@ -871,6 +923,170 @@ func Test_Run(t *testing.T) {
 	}
 }
 
+func Test_RunWatch(t *testing.T) {
+	testDataBuilder := secretTestDataBuilder{
+		namespace: "default",
+		name:      "secret_name",
+		key:       "secret_key",
+	}
+
+	k8sClient := k8sfake.NewClientset(
+		testDataBuilder.buildK8SSecret("secret_value"),
+	)
+	// the fake client does not guarantee events sent before the watch is established are received, the watch
+	// events are sent once it is
+	fakeWatcher := watch.NewFake()
+	watchStarted := make(chan struct{})
+	k8sClient.PrependWatchReactor("secrets", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		close(watchStarted)
+		return true, fakeWatcher, nil
+	})
+	getK8sClientFunc = func(kubeconfig string, opt kubernetes.KubeClientOptions) (k8sclient.Interface, error) {
+		return k8sClient, nil
+	}
+	t.Cleanup(func() {
+		getK8sClientFunc = kubernetes.GetKubernetesClient
+	})
+
+	cfg, err := config.NewConfigFrom(map[string]interface{}{
+		"cache_refresh_mode":     "watch",
+		"cache_refresh_interval": "10m",
+	})
+	require.NoError(t, err)
+	provider, err := ContextProviderBuilder(logp.NewLogger("test_k8s_secrets"), cfg, true)
+	require.NoError(t, err)
+	p, is := provider.(*contextProviderK8SSecrets)
+	require.True(t, is)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	comm := ctesting.NewContextComm(ctx)
+	signal := make(chan struct{}, 10)
+	comm.CallOnSignal(func() {
+		select {
+		case <-comm.Done():
+		case signal <- struct{}{}:
+		}
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = p.Run(ctx, comm)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	value, found := p.Fetch(testDataBuilder.getFetchKey())
+	require.True(t, found)
+	require.Equal(t, "secret_value", value)
+
+	select {
+	case <-watchStarted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("secret was not watched")
+	}
+
+	// the rotated secret is applied without waiting for the refresh interval
+	fakeWatcher.Modify(testDataBuilder.buildK8SSecret("secret_value_rotated"))
+	select {
+	case <-signal:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent was not signaled of the secret update")
+	}
+	value, found = p.Fetch(testDataBuilder.getFetchKey())
+	require.True(t, found)
+	require.Equal(t, "secret_value_rotated", value)
+
+	// the deleted secret is applied as well
+	fakeWatcher.Delete(testDataBuilder.buildK8SSecret("secret_value_rotated"))
+	select {
+	case <-signal:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent was not signaled of the secret deletion")
+	}
+	_, found = p.Fetch(testDataBuilder.getFetchKey())
+	require.False(t, found)
+}
+
+func Test_RunWatchFallback(t *testing.T) {
+	testDataBuilder := secretTestDataBuilder{
+		namespace: "default",
+		name:      "secret_name",
+		key:       "secret_key",
+	}
+
+	k8sClient := k8sfake.NewClientset(
+		testDataBuilder.buildK8SSecret("secret_value"),
+	)
+	// the service account can get the secrets but not list or watch them
+	forbidden := func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		return true, nil, k8serrors.NewForbidden(v1.Resource("secrets"), "", errors.New("list and watch not allowed"))
+	}
+	k8sClient.PrependReactor("list", "secrets", forbidden)
+	k8sClient.PrependWatchReactor("secrets", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		_, _, err := forbidden(action)
+		return true, nil, err
+	})
+	getK8sClientFunc = func(kubeconfig string, opt kubernetes.KubeClientOptions) (k8sclient.Interface, error) {
+		return k8sClient, nil
+	}
+	t.Cleanup(func() {
+		getK8sClientFunc = kubernetes.GetKubernetesClient
+	})
+
+	cfg, err := config.NewConfigFrom(map[string]interface{}{
+		"cache_refresh_mode":     "watch",
+		"cache_refresh_interval": "100ms",
+	})
+	require.NoError(t, err)
+	provider, err := ContextProviderBuilder(logp.NewLogger("test_k8s_secrets"), cfg, true)
+	require.NoError(t, err)
+	p, is := provider.(*contextProviderK8SSecrets)
+	require.True(t, is)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	comm := ctesting.NewContextComm(ctx)
+	signal := make(chan struct{}, 10)
+	comm.CallOnSignal(func() {
+		select {
+		case <-comm.Done():
+		case signal <- struct{}{}:
+		}
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = p.Run(ctx, comm)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	value, found := p.Fetch(testDataBuilder.getFetchKey())
+	require.True(t, found)
+	require.Equal(t, "secret_value", value)
+
+	// the rotated secret is refreshed as the watch fails
+	_, err = k8sClient.CoreV1().Secrets(testDataBuilder.namespace).Update(ctx, testDataBuilder.buildK8SSecret("secret_value_rotated"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	select {
+	case <-signal:
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent was not signaled of the secret update")
+	}
+	value, found = p.Fetch(testDataBuilder.getFetchKey())
+	require.True(t, found)
+	require.Equal(t, "secret_value_rotated", value)
+}
+
 func Test_Config(t *testing.T) {
 	for _, tc := range []struct {
 		name           string
@@ -911,6 +1127,43 @@ func Test_Config(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "watch refresh mode and allowlists",
+			inConfig: map[string]interface{}{
+				"cache_refresh_mode": "watch",
+				"allowed_namespaces": []string{"default"},
+				"allowed_secrets":    []string{"db-*"},
+			},
+			expectedConfig: func() *Config {
+				cfg := defaultConfig()
+				cfg.RefreshMode = refreshModeWatch
+				cfg.AllowedNamespaces = []string{"default"}
+				cfg.AllowedSecrets = []string{"db-*"}
+				return cfg
+			}(),
+		},
+		{
+			name: "invalid config unknown cache_refresh_mode",
+			inConfig: map[string]interface{}{
+				"cache_refresh_mode": "push",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid config watch refresh mode with cache disabled",
+			inConfig: map[string]interface{}{
+				"cache_refresh_mode": "watch",
+				"cache_disable":      true,
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid config allowed_secrets pattern",
+			inConfig: map[string]interface{}{
+				"allowed_secrets": []string{"db-["},
+			},
+			expectErr: true,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			var cfg *config.Config
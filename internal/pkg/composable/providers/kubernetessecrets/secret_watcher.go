@@ -0,0 +1,190 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package kubernetessecrets
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// secretWatcher watches the secrets referenced by the cache and updates their cached values as soon as they change.
+// Every secret is watched on its own, so only the referenced secrets need to be listed and watched.
+type secretWatcher struct {
+	ctx    context.Context
+	logger *logger.Logger
+	client k8sclient.Interface
+	store  store
+	signal func()
+
+	mu sync.Mutex
+	// watches are the watches of the secrets, by secret namespace/name
+	watches map[string]*secretWatch
+}
+
+// secretWatch is the watch of a secret.
+type secretWatch struct {
+	cancel context.CancelFunc
+	synced func() bool
+	// failing is set while listing or watching the secret fails, e.g. when the service account cannot list or
+	// watch secrets
+	failing atomic.Bool
+}
+
+func newSecretWatcher(ctx context.Context, logger *logger.Logger, client k8sclient.Interface, store store, signal func()) *secretWatcher {
+	return &secretWatcher{
+		ctx:     ctx,
+		logger:  logger,
+		client:  client,
+		store:   store,
+		signal:  signal,
+		watches: make(map[string]*secretWatch),
+	}
+}
+
+// watch starts watching the secret unless it is already watched.
+func (w *secretWatcher) watch(namespace string, name string) {
+	id := namespace + "/" + name
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, watched := w.watches[id]; watched {
+		return
+	}
+	ctx, cancel := context.WithCancel(w.ctx)
+	sw := &secretWatch{cancel: cancel}
+	w.watches[id] = sw
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", name).String()
+	secrets := w.client.CoreV1().Secrets(namespace)
+	_, controller := cache.NewInformerWithOptions(cache.InformerOptions{
+		ListerWatcher: &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.FieldSelector = fieldSelector
+				list, err := secrets.List(ctx, options)
+				sw.failing.Store(err != nil)
+				return list, err
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = fieldSelector
+				watcher, err := secrets.Watch(ctx, options)
+				sw.failing.Store(err != nil)
+				return watcher, err
+			},
+		},
+		ObjectType: &v1.Secret{},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				w.onSecret(namespace, name, obj)
+			},
+			UpdateFunc: func(_, obj interface{}) {
+				w.onSecret(namespace, name, obj)
+			},
+			DeleteFunc: func(_ interface{}) {
+				w.update(namespace, name, nil)
+			},
+		},
+	})
+	sw.synced = controller.HasSynced
+	go controller.Run(ctx.Done())
+	w.logger.Infof(`Watch: started watching secret %q at namespace %q`, name, namespace)
+}
+
+// healthy returns true when every referenced secret has been listed and is watched without errors. The cached
+// values of the secrets are only up-to-date then.
+func (w *secretWatcher) healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, sw := range w.watches {
+		if sw.failing.Load() || !sw.synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// prune stops watching the secrets no longer referenced by the cache.
+func (w *secretWatcher) prune() {
+	referenced := make(map[string]struct{})
+	for _, sd := range w.store.List() {
+		referenced[sd.namespace+"/"+sd.name] = struct{}{}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id, sw := range w.watches {
+		if _, ok := referenced[id]; !ok {
+			sw.cancel()
+			delete(w.watches, id)
+			w.logger.Infof(`Watch: stopped watching secret %q`, id)
+		}
+	}
+}
+
+func (w *secretWatcher) onSecret(namespace string, name string, obj interface{}) {
+	s, ok := obj.(*v1.Secret)
+	if !ok || s.Name != name {
+		// not every client applies the field selector
+		return
+	}
+	w.update(namespace, name, s)
+}
+
+// update updates the cached keys of the secret with the values of the watched secret, a nil secret has been deleted.
+// The agent is signaled when a cached value changed.
+func (w *secretWatcher) update(namespace string, name string, s *v1.Secret) {
+	hasUpdates := false
+	now := time.Now()
+	for _, sd := range w.store.List() {
+		if sd.namespace != namespace || sd.name != name {
+			continue
+		}
+
+		value, apiExists := "", false
+		if s != nil {
+			var data []byte
+			data, apiExists = s.Data[sd.key]
+			value = string(data)
+		}
+		key := fmt.Sprintf("%s.%s.%s.%s", k8sSecretsProviderName, namespace, name, sd.key)
+		updated := secret{
+			name:         name,
+			namespace:    namespace,
+			key:          sd.key,
+			value:        value,
+			apiExists:    apiExists,
+			apiFetchTime: now,
+		}
+		w.store.AddConditionally(key, updated, false, func(existing secret, exists bool) bool {
+			if !exists {
+				// the secret has expired in the meantime
+				return false
+			}
+			if (existing.value != value || existing.apiExists != apiExists) && !existing.apiFetchTime.After(now) {
+				hasUpdates = true
+				w.logger.Infof(`Watch: %q updated`, key)
+				return true
+			}
+			return false
+		})
+	}
+
+	if hasUpdates {
+		w.logger.Info("Watch: secret updated, agent will be notified")
+		w.signal()
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package process
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+)
+
+// Config for process provider
+type Config struct {
+	ProcPath      string        `config:"proc_path"`
+	CheckInterval time.Duration `config:"check_interval" validate:"positive,nonzero"`
+	Selectors     []*Selector   `config:"selectors"`
+}
+
+// Selector selects the processes to provide, all the fields that are set must match.
+type Selector struct {
+	// Name identifies the selector in the mapping of the processes it selects.
+	Name string `config:"name"`
+	// Exe is a glob pattern matched against the path of the executable.
+	Exe string `config:"exe"`
+	// Cmdline is a regular expression matched against the command line, the arguments joined by spaces.
+	Cmdline string `config:"cmdline"`
+	// User is the name or the ID of the user running the process.
+	User string `config:"user"`
+
+	cmdline *regexp.Regexp
+}
+
+// InitDefaults initializes the default values for the config.
+func (c *Config) InitDefaults() {
+	c.ProcPath = "/proc"
+	c.CheckInterval = 10 * time.Second
+}
+
+// Validate validates the selectors and compiles their regular expressions.
+func (c *Config) Validate() error {
+	for i, s := range c.Selectors {
+		if s.Exe == "" && s.Cmdline == "" && s.User == "" {
+			return fmt.Errorf("selector %d must define at least one of exe, cmdline or user", i)
+		}
+		if _, err := path.Match(s.Exe, ""); err != nil {
+			return fmt.Errorf("selector %d has an invalid exe pattern %q: %w", i, s.Exe, err)
+		}
+		if s.Cmdline != "" {
+			re, err := regexp.Compile(s.Cmdline)
+			if err != nil {
+				return fmt.Errorf("selector %d has an invalid cmdline regular expression: %w", i, err)
+			}
+			s.cmdline = re
+		}
+	}
+	if c.ProcPath == "" {
+		return errors.New("proc_path cannot be empty")
+	}
+	return nil
+}
+
+// matches returns true if the process is selected.
+func (s *Selector) matches(p *procInfo) bool {
+	if s.Exe != "" {
+		if ok, _ := path.Match(s.Exe, p.exe); !ok {
+			return false
+		}
+	}
+	if s.cmdline != nil && !s.cmdline.MatchString(p.cmdline()) {
+		return false
+	}
+	if s.User != "" && s.User != p.uid && s.User != p.user {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tcpListen is the state of a listening socket in /proc/net/tcp.
+const tcpListen = "0A"
+
+// procInfo is the information read from /proc about a process.
+type procInfo struct {
+	pid  int
+	ppid int
+	name string
+	exe  string
+	args []string
+	uid  string
+	user string
+	// sockets are the inodes of the sockets opened by the process.
+	sockets []string
+}
+
+func (p *procInfo) cmdline() string {
+	return strings.Join(p.args, " ")
+}
+
+// procReader reads the processes from a procfs mount.
+type procReader struct {
+	root string
+	// users caches the user names by ID
+	users map[string]string
+}
+
+func newProcReader(root string) *procReader {
+	return &procReader{
+		root:  root,
+		users: map[string]string{},
+	}
+}
+
+// processes returns the processes that are running, processes that exit while being read are skipped.
+func (r *procReader) processes() ([]*procInfo, error) {
+	entries, err := os.ReadDir(r.root)
+	if err != nil {
+		return nil, err
+	}
+	procs := make([]*procInfo, 0, len(entries))
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || !e.IsDir() {
+			continue
+		}
+		p, err := r.process(pid)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+func (r *procReader) process(pid int) (*procInfo, error) {
+	dir := filepath.Join(r.root, strconv.Itoa(pid))
+	p := &procInfo{pid: pid}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		switch key {
+		case "Name":
+			p.name = fields[0]
+		case "PPid":
+			p.ppid, _ = strconv.Atoi(fields[0])
+		case "Uid":
+			// real, effective, saved and filesystem IDs; use the effective one
+			if len(fields) > 1 {
+				p.uid = fields[1]
+			} else {
+				p.uid = fields[0]
+			}
+		}
+	}
+	p.user = r.userName(p.uid)
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range bytes.Split(bytes.TrimRight(cmdline, "\x00"), []byte{0}) {
+		if len(arg) > 0 {
+			p.args = append(p.args, string(arg))
+		}
+	}
+	if len(p.args) == 0 {
+		// kernel thread
+		return nil, fmt.Errorf("process %d has no command line", pid)
+	}
+
+	// reading the executable and the file descriptors of processes owned by other users requires privileges,
+	// without them the process can still be selected by its command line or its user
+	p.exe, _ = os.Readlink(filepath.Join(dir, "exe"))
+	fds, _ := os.ReadDir(filepath.Join(dir, "fd"))
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(link, "socket:["); ok {
+			p.sockets = append(p.sockets, strings.TrimSuffix(inode, "]"))
+		}
+	}
+	return p, nil
+}
+
+func (r *procReader) userName(uid string) string {
+	if uid == "" {
+		return ""
+	}
+	if name, ok := r.users[uid]; ok {
+		return name
+	}
+	var name string
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	r.users[uid] = name
+	return name
+}
+
+// listeningPorts returns the ports of the listening TCP sockets by inode.
+func (r *procReader) listeningPorts() map[string]int {
+	ports := map[string]int{}
+	for _, file := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(r.root, "net", file))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[3] != tcpListen {
+				continue
+			}
+			_, hexPort, ok := strings.Cut(fields[1], ":")
+			if !ok {
+				continue
+			}
+			port, err := strconv.ParseUint(hexPort, 16, 16)
+			if err != nil {
+				continue
+			}
+			ports[fields[9]] = int(port)
+		}
+		_ = f.Close()
+	}
+	return ports
+}
+
+// ports returns the sorted listening ports of the process.
+func (p *procInfo) ports(listening map[string]int) []int {
+	ports := []int{}
+	for _, inode := range p.sockets {
+		if port, ok := listening[inode]; ok && !slices.Contains(ports, port) {
+			ports = append(ports, port)
+		}
+	}
+	slices.Sort(ports)
+	return ports
+}
@@ -0,0 +1,165 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package process
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/composable"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// ProcessPriority is the priority that process mappings are added to the provider.
+const ProcessPriority = 0
+
+func init() {
+	// process provider scans the processes on an interval and provides a mapping for each process that
+	// matches one of the configured selectors, e.g. ${process.pid} and ${process.ports}. Processes whose
+	// parent matches the same selector are skipped, so a server forking workers results in a single mapping.
+	composable.Providers.MustAddDynamicProvider("process", DynamicProviderBuilder)
+}
+
+type processData struct {
+	mapping    map[string]interface{}
+	processors []map[string]interface{}
+}
+
+type dynamicProvider struct {
+	logger *logger.Logger
+	config *Config
+}
+
+// Run runs the process dynamic provider.
+func (c *dynamicProvider) Run(comm composable.DynamicProviderComm) error {
+	if len(c.config.Selectors) == 0 {
+		// nothing selected, nothing to scan
+		<-comm.Done()
+		return comm.Err()
+	}
+
+	reader := newProcReader(c.config.ProcPath)
+	if _, err := reader.processes(); err != nil {
+		// info only; return nil (do nothing)
+		c.logger.Infof("Process provider skipped, unable to read %s: %s", c.config.ProcPath, err)
+		return nil
+	}
+
+	current := map[string]*processData{}
+	for {
+		c.sync(comm, reader, current)
+
+		t := time.NewTimer(c.config.CheckInterval)
+		select {
+		case <-comm.Done():
+			t.Stop()
+			return comm.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// sync scans the processes, adding or updating the mappings of the selected processes and removing the
+// mappings of the processes that exited.
+func (c *dynamicProvider) sync(comm composable.DynamicProviderComm, reader *procReader, current map[string]*processData) {
+	procs, err := reader.processes()
+	if err != nil {
+		c.logger.Warnf("Failed scanning processes: %s", err)
+		return
+	}
+	listening := reader.listeningPorts()
+
+	selected := make(map[int]*Selector, len(procs))
+	for _, p := range procs {
+		for _, s := range c.config.Selectors {
+			if s.matches(p) {
+				selected[p.pid] = s
+				break
+			}
+		}
+	}
+
+	found := make(map[string]bool, len(selected))
+	for _, p := range procs {
+		s, ok := selected[p.pid]
+		if !ok || selected[p.ppid] == s {
+			continue
+		}
+		id := strconv.Itoa(p.pid)
+		found[id] = true
+		data := generateData(p, s, listening)
+		if prev, ok := current[id]; ok && reflect.DeepEqual(prev, data) {
+			// nothing to do
+			continue
+		}
+		current[id] = data
+		err := comm.AddOrUpdate(id, ProcessPriority, data.mapping, data.processors)
+		if err != nil {
+			c.logger.Errorf("%s", err)
+		}
+	}
+	for id := range current {
+		if !found[id] {
+			delete(current, id)
+			comm.Remove(id)
+		}
+	}
+}
+
+// DynamicProviderBuilder builds the dynamic provider.
+func DynamicProviderBuilder(logger *logger.Logger, c *config.Config, managed bool) (composable.DynamicProvider, error) {
+	var cfg Config
+	if c == nil {
+		c = config.New()
+	}
+	err := c.UnpackTo(&cfg)
+	if err != nil {
+		return nil, errors.New(err, "failed to unpack configuration")
+	}
+	return &dynamicProvider{logger, &cfg}, nil
+}
+
+func generateData(p *procInfo, s *Selector, listening map[string]int) *processData {
+	process := map[string]interface{}{
+		"pid":   p.pid,
+		"ppid":  p.ppid,
+		"name":  p.name,
+		"args":  p.args,
+		"ports": p.ports(listening),
+	}
+	if s.Name != "" {
+		process["selector"] = s.Name
+	}
+	if p.exe != "" {
+		process["exe"] = p.exe
+	}
+	if p.user != "" {
+		process["user"] = p.user
+	} else {
+		process["user"] = p.uid
+	}
+
+	fields := map[string]interface{}{
+		"pid":  p.pid,
+		"name": p.name,
+	}
+	if p.exe != "" {
+		fields["executable"] = p.exe
+	}
+	return &processData{
+		mapping: process,
+		processors: []map[string]interface{}{
+			{
+				"add_fields": map[string]interface{}{
+					"fields": fields,
+					"target": "process",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctesting "github.com/elastic/elastic-agent/internal/pkg/composable/testing"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+const (
+	postgresExe = "/usr/lib/postgresql/16/bin/postgres"
+	tcpHeader   = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+)
+
+// writeProc writes a process to the fake procfs at root.
+func writeProc(t *testing.T, root string, pid int, ppid int, uid string, exe string, args []string, sockets ...string) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0o755))
+	name := ""
+	if exe != "" {
+		name = filepath.Base(exe)
+		require.NoError(t, os.Symlink(exe, filepath.Join(dir, "exe")))
+	}
+	status := "Name:\t" + name + "\nPPid:\t" + strconv.Itoa(ppid) + "\nUid:\t" + strings.Repeat(uid+"\t", 4) + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644))
+	cmdline := ""
+	for _, arg := range args {
+		cmdline += arg + "\x00"
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0o644))
+	for i, inode := range sockets {
+		require.NoError(t, os.Symlink("socket:["+inode+"]", filepath.Join(dir, "fd", strconv.Itoa(i+3))))
+	}
+}
+
+func fakeProc(t *testing.T) string {
+	root := t.TempDir()
+	// kernel thread
+	writeProc(t, root, 2, 0, "0", "", nil)
+	// postgres and one of its workers
+	writeProc(t, root, 100, 1, "4242", postgresExe, []string{postgresExe, "-D", "/var/lib/postgresql/16/main"}, "555", "557")
+	writeProc(t, root, 101, 100, "4242", postgresExe, []string{"postgres: checkpointer"})
+	// unrelated process
+	writeProc(t, root, 200, 1, "0", "/usr/sbin/nginx", []string{"/usr/sbin/nginx", "-g", "daemon on;"}, "556")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net"), 0o755))
+	tcp := tcpHeader +
+		"   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000  4242        0 555 1 0000000000000000 100 0 0 10 0\n" +
+		"   1: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 556 1 0000000000000000 100 0 0 10 0\n" +
+		"   2: 0100007F:1538 0100007F:9C40 01 00000000:00000000 00:00000000 00000000  4242        0 557 1 0000000000000000 100 0 0 10 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "tcp"), []byte(tcp), 0o644))
+	tcp6 := tcpHeader +
+		"   0: 00000000000000000000000000000000:1538 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  4242        0 557 1 0000000000000000 100 0 0 10 0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "tcp6"), []byte(tcp6), 0o644))
+	return root
+}
+
+func TestConfig(t *testing.T) {
+	scenarios := []struct {
+		name string
+		cfg  map[string]interface{}
+		err  string
+	}{
+		{
+			name: "empty selector",
+			cfg:  map[string]interface{}{"selectors": []interface{}{map[string]interface{}{"name": "postgres"}}},
+			err:  "selector 0 must define at least one of exe, cmdline or user",
+		},
+		{
+			name: "invalid exe",
+			cfg:  map[string]interface{}{"selectors": []interface{}{map[string]interface{}{"exe": "[a-"}}},
+			err:  "selector 0 has an invalid exe pattern",
+		},
+		{
+			name: "invalid cmdline",
+			cfg:  map[string]interface{}{"selectors": []interface{}{map[string]interface{}{"cmdline": "(postgres"}}},
+			err:  "selector 0 has an invalid cmdline regular expression",
+		},
+		{
+			name: "valid",
+			cfg:  map[string]interface{}{"selectors": []interface{}{map[string]interface{}{"exe": "*/postgres", "user": "postgres"}}},
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			log, err := logger.New("process_test", false)
+			require.NoError(t, err)
+			_, err = DynamicProviderBuilder(log, config.MustNewConfigFrom(s.cfg), true)
+			if s.err != "" {
+				require.ErrorContains(t, err, s.err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	p := &procInfo{
+		exe:  postgresExe,
+		args: []string{postgresExe, "-D", "/var/lib/postgresql/16/main"},
+		uid:  "4242",
+		user: "postgres",
+	}
+	cmdline := func(re string) *Selector {
+		c := &Config{ProcPath: "/proc", Selectors: []*Selector{{Cmdline: re}}}
+		require.NoError(t, c.Validate())
+		return c.Selectors[0]
+	}
+
+	assert.True(t, (&Selector{Exe: "/usr/lib/postgresql/*/bin/postgres"}).matches(p))
+	assert.False(t, (&Selector{Exe: "/usr/sbin/*"}).matches(p))
+	assert.True(t, (&Selector{User: "postgres"}).matches(p))
+	assert.True(t, (&Selector{User: "4242"}).matches(p))
+	assert.False(t, (&Selector{User: "root"}).matches(p))
+	assert.True(t, cmdline(`-D /var/lib/postgresql/\d+/main`).matches(p))
+	assert.False(t, cmdline(`^nginx`).matches(p))
+	assert.False(t, (&Selector{Exe: "/usr/lib/postgresql/*/bin/postgres", User: "root"}).matches(p))
+}
+
+func TestSync(t *testing.T) {
+	root := fakeProc(t)
+
+	log, err := logger.New("process_test", false)
+	require.NoError(t, err)
+	builder, err := DynamicProviderBuilder(log, config.MustNewConfigFrom(map[string]interface{}{
+		"proc_path": root,
+		"selectors": []interface{}{
+			map[string]interface{}{
+				"name": "postgres",
+				"exe":  "/usr/lib/postgresql/*/bin/postgres",
+			},
+		},
+	}), true)
+	require.NoError(t, err)
+	provider, _ := builder.(*dynamicProvider)
+
+	comm := ctesting.NewDynamicComm(context.Background())
+	reader := newProcReader(root)
+	current := map[string]*processData{}
+	provider.sync(comm, reader, current)
+
+	assert.ElementsMatch(t, []string{"100"}, comm.CurrentIDs())
+	state, _ := comm.Current("100")
+	assert.Equal(t, map[string]interface{}{
+		"pid":      float64(100),
+		"ppid":     float64(1),
+		"name":     "postgres",
+		"exe":      postgresExe,
+		"args":     []interface{}{postgresExe, "-D", "/var/lib/postgresql/16/main"},
+		"user":     "4242",
+		"ports":    []interface{}{float64(5432)},
+		"selector": "postgres",
+	}, state.Mapping)
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"add_fields": map[string]interface{}{
+				"fields": map[string]interface{}{
+					"pid":        float64(100),
+					"name":       "postgres",
+					"executable": postgresExe,
+				},
+				"target": "process",
+			},
+		},
+	}, state.Processors)
+
+	// postgres exits, its worker is orphaned and now selected
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "100")))
+	provider.sync(comm, reader, current)
+	assert.ElementsMatch(t, []string{"101"}, comm.CurrentIDs())
+	assert.True(t, comm.Deleted("100"))
+}
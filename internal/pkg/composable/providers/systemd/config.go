@@ -0,0 +1,24 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package systemd
+
+import (
+	"time"
+)
+
+// Config for systemd provider
+type Config struct {
+	// Patterns are the unit name patterns of the units to provide, e.g. "*.service".
+	Patterns []string `config:"patterns"`
+	// CheckInterval is the interval between two listings of the units. The changes of the units are
+	// received from systemd as they happen, the listing resyncs the changes that were missed.
+	CheckInterval time.Duration `config:"check_interval" validate:"positive,nonzero"`
+}
+
+// InitDefaults initializes the default values for the config.
+func (c *Config) InitDefaults() {
+	c.Patterns = []string{"*.service"}
+	c.CheckInterval = time.Minute
+}
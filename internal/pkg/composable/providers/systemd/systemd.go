@@ -0,0 +1,268 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package systemd
+
+import (
+	"context"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+
+	"github.com/elastic/elastic-agent/internal/pkg/agent/errors"
+	"github.com/elastic/elastic-agent/internal/pkg/composable"
+	"github.com/elastic/elastic-agent/internal/pkg/config"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// UnitPriority is the priority that unit mappings are added to the provider.
+const UnitPriority = 0
+
+// subscriptionBuffer is the number of unit changes buffered before they are dropped and the units resynced.
+const subscriptionBuffer = 64
+
+func init() {
+	composable.Providers.MustAddDynamicProvider("systemd", DynamicProviderBuilder)
+}
+
+// conn is the part of the systemd D-Bus API used by the provider.
+type conn interface {
+	Subscribe() error
+	SetSubStateSubscriber(updateCh chan<- *dbus.SubStateUpdate, errCh chan<- error)
+	ListUnitsByPatternsContext(ctx context.Context, states []string, patterns []string) ([]dbus.UnitStatus, error)
+	ListUnitsByNamesContext(ctx context.Context, units []string) ([]dbus.UnitStatus, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+type unitData struct {
+	status     dbus.UnitStatus
+	mapping    map[string]interface{}
+	processors []map[string]interface{}
+}
+
+type dynamicProvider struct {
+	logger *logger.Logger
+	config *Config
+
+	// used by testing
+	connect func(ctx context.Context) (conn, error)
+}
+
+// Run runs the systemd dynamic provider.
+func (c *dynamicProvider) Run(comm composable.DynamicProviderComm) error {
+	sd, err := c.connect(comm)
+	if err != nil {
+		// info only; return nil (do nothing)
+		c.logger.Infof("Systemd provider skipped, unable to connect to D-Bus: %s", err)
+		return nil
+	}
+	defer sd.Close()
+
+	// changes of the units are received from the D-Bus signals, the units are also listed on each check
+	// interval to resync the mappings with the signals that were missed
+	updates := make(chan *dbus.SubStateUpdate, subscriptionBuffer)
+	errs := make(chan error, 1)
+	err = sd.Subscribe()
+	if err != nil {
+		c.logger.Warnf("Failed subscribing to systemd unit changes, listing the units every %s: %s", c.config.CheckInterval, err)
+	} else {
+		sd.SetSubStateSubscriber(updates, errs)
+	}
+
+	units := map[string]*unitData{}
+	c.sync(comm, sd, units)
+	t := time.NewTicker(c.config.CheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-comm.Done():
+			return comm.Err()
+		case <-t.C:
+			c.sync(comm, sd, units)
+		case update := <-updates:
+			if c.matches(update.UnitName) {
+				c.syncUnit(comm, sd, units, update.UnitName)
+			}
+		case err := <-errs:
+			// an update was dropped or could not be read
+			c.logger.Debugf("Resyncing systemd units after a subscription error: %s", err)
+			c.sync(comm, sd, units)
+		}
+	}
+}
+
+// sync lists the active units, adding or updating the mappings of the units that started or changed and
+// removing the mappings of the units that stopped.
+func (c *dynamicProvider) sync(comm composable.DynamicProviderComm, sd conn, units map[string]*unitData) {
+	statuses, err := sd.ListUnitsByPatternsContext(comm, []string{"active"}, c.config.Patterns)
+	if err != nil {
+		c.logger.Warnf("Failed listing systemd units: %s", err)
+		return
+	}
+
+	active := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		active[status.Name] = true
+		c.update(comm, sd, units, status)
+	}
+	for name := range units {
+		if !active[name] {
+			delete(units, name)
+			comm.Remove(name)
+		}
+	}
+}
+
+// syncUnit adds, updates or removes the mapping of a unit whose state changed.
+func (c *dynamicProvider) syncUnit(comm composable.DynamicProviderComm, sd conn, units map[string]*unitData, name string) {
+	statuses, err := sd.ListUnitsByNamesContext(comm, []string{name})
+	if err != nil {
+		c.logger.Warnf("Failed getting status of systemd unit %s: %s", name, err)
+		return
+	}
+	if len(statuses) == 1 && statuses[0].ActiveState == "active" {
+		c.update(comm, sd, units, statuses[0])
+		return
+	}
+	if _, ok := units[name]; ok {
+		delete(units, name)
+		comm.Remove(name)
+	}
+}
+
+// update adds or updates the mapping of an active unit when its status changed.
+func (c *dynamicProvider) update(comm composable.DynamicProviderComm, sd conn, units map[string]*unitData, status dbus.UnitStatus) {
+	prev, ok := units[status.Name]
+	if ok && reflect.DeepEqual(prev.status, status) {
+		// nothing to do
+		return
+	}
+	props, err := sd.GetUnitTypePropertiesContext(comm, status.Name, unitType(status.Name))
+	if err != nil {
+		c.logger.Warnf("Failed getting properties of systemd unit %s: %s", status.Name, err)
+		return
+	}
+	data := generateData(status, props)
+	units[status.Name] = data
+	err = comm.AddOrUpdate(status.Name, UnitPriority, data.mapping, data.processors)
+	if err != nil {
+		c.logger.Errorf("%s", err)
+	}
+}
+
+// matches returns true when the unit name matches one of the configured patterns.
+func (c *dynamicProvider) matches(name string) bool {
+	if len(c.config.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range c.config.Patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DynamicProviderBuilder builds the dynamic provider.
+func DynamicProviderBuilder(logger *logger.Logger, c *config.Config, managed bool) (composable.DynamicProvider, error) {
+	var cfg Config
+	if c == nil {
+		c = config.New()
+	}
+	err := c.UnpackTo(&cfg)
+	if err != nil {
+		return nil, errors.New(err, "failed to unpack configuration")
+	}
+	return &dynamicProvider{
+		logger: logger,
+		config: &cfg,
+		connect: func(ctx context.Context) (conn, error) {
+			return dbus.NewSystemConnectionContext(ctx)
+		},
+	}, nil
+}
+
+// unitType returns the D-Bus interface name of the unit type, e.g. Service for nginx.service.
+func unitType(name string) string {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if ext == "" {
+		return ""
+	}
+	return strings.ToUpper(ext[:1]) + ext[1:]
+}
+
+func generateData(status dbus.UnitStatus, props map[string]interface{}) *unitData {
+	unit := map[string]interface{}{
+		"name":              status.Name,
+		"description":       status.Description,
+		"load_state":        status.LoadState,
+		"state":             status.ActiveState,
+		"sub_state":         status.SubState,
+		"cgroup":            stringProperty(props, "ControlGroup"),
+		"exec_start":        execStart(props),
+		"syslog_identifier": journalIdentifier(props),
+	}
+	for k, v := range unit {
+		if v == "" {
+			delete(unit, k)
+		}
+	}
+	if pid, ok := props["MainPID"].(uint32); ok && pid != 0 {
+		unit["main_pid"] = int(pid)
+	}
+
+	return &unitData{
+		status: status,
+		mapping: map[string]interface{}{
+			"unit": unit,
+		},
+		processors: []map[string]interface{}{
+			{
+				"add_fields": map[string]interface{}{
+					"fields": map[string]interface{}{
+						"name": status.Name,
+					},
+					"target": "systemd.unit",
+				},
+			},
+		},
+	}
+}
+
+func stringProperty(props map[string]interface{}, name string) string {
+	s, _ := props[name].(string)
+	return s
+}
+
+// execStart returns the command line of the first ExecStart of the unit. The property has the D-Bus
+// signature a(sasbttttuii) where the second field holds the arguments.
+func execStart(props map[string]interface{}) string {
+	execs, ok := props["ExecStart"].([][]interface{})
+	if !ok || len(execs) == 0 || len(execs[0]) < 2 {
+		return ""
+	}
+	args, _ := execs[0][1].([]string)
+	return strings.Join(args, " ")
+}
+
+// journalIdentifier returns the SYSLOG_IDENTIFIER the unit logs to the journal with, which defaults to the
+// name of the executable.
+func journalIdentifier(props map[string]interface{}) string {
+	if id := stringProperty(props, "SyslogIdentifier"); id != "" {
+		return id
+	}
+	execs, ok := props["ExecStart"].([][]interface{})
+	if !ok || len(execs) == 0 || len(execs[0]) < 1 {
+		return ""
+	}
+	exe, _ := execs[0][0].(string)
+	if exe == "" {
+		return ""
+	}
+	return path.Base(exe)
+}
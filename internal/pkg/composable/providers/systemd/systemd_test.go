@@ -0,0 +1,330 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ctesting "github.com/elastic/elastic-agent/internal/pkg/composable/testing"
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+)
+
+// fakeBus implements conn with units that can be started and stopped by the test.
+type fakeBus struct {
+	mx      sync.Mutex
+	units   map[string]dbus.UnitStatus
+	props   map[string]map[string]interface{}
+	listErr error
+	closed  bool
+
+	// signals are sent when subscribed
+	mute    bool
+	updates chan<- *dbus.SubStateUpdate
+	errs    chan<- error
+}
+
+func newFakeBus() *fakeBus {
+	return &fakeBus{
+		units: map[string]dbus.UnitStatus{},
+		props: map[string]map[string]interface{}{},
+	}
+}
+
+func (b *fakeBus) start(name string, props map[string]interface{}) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.units[name] = dbus.UnitStatus{
+		Name:        name,
+		Description: name + " daemon",
+		LoadState:   "loaded",
+		ActiveState: "active",
+		SubState:    "running",
+	}
+	b.props[name] = props
+	b.signal(name, "running")
+}
+
+func (b *fakeBus) stop(name string) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	delete(b.units, name)
+	b.signal(name, "dead")
+}
+
+func (b *fakeBus) signal(name string, subState string) {
+	if b.updates == nil || b.mute {
+		return
+	}
+	b.updates <- &dbus.SubStateUpdate{UnitName: name, SubState: subState}
+}
+
+func (b *fakeBus) Subscribe() error {
+	return nil
+}
+
+func (b *fakeBus) SetSubStateSubscriber(updateCh chan<- *dbus.SubStateUpdate, errCh chan<- error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.updates = updateCh
+	b.errs = errCh
+}
+
+func (b *fakeBus) subscribed() bool {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.updates != nil
+}
+
+func (b *fakeBus) ListUnitsByPatternsContext(_ context.Context, states []string, patterns []string) ([]dbus.UnitStatus, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if b.listErr != nil {
+		return nil, b.listErr
+	}
+	if len(states) != 1 || states[0] != "active" || len(patterns) != 1 || patterns[0] != "*.service" {
+		return nil, errors.New("unexpected filter")
+	}
+	units := make([]dbus.UnitStatus, 0, len(b.units))
+	for _, u := range b.units {
+		units = append(units, u)
+	}
+	return units, nil
+}
+
+func (b *fakeBus) ListUnitsByNamesContext(_ context.Context, units []string) ([]dbus.UnitStatus, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	statuses := make([]dbus.UnitStatus, 0, len(units))
+	for _, name := range units {
+		u, ok := b.units[name]
+		if !ok {
+			u = dbus.UnitStatus{Name: name, LoadState: "loaded", ActiveState: "inactive", SubState: "dead"}
+		}
+		statuses = append(statuses, u)
+	}
+	return statuses, nil
+}
+
+func (b *fakeBus) GetUnitTypePropertiesContext(_ context.Context, unit string, unitType string) (map[string]interface{}, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	if unitType != "Service" {
+		return nil, errors.New("unexpected unit type")
+	}
+	props, ok := b.props[unit]
+	if !ok {
+		return nil, errors.New("unknown unit")
+	}
+	return props, nil
+}
+
+func (b *fakeBus) Close() {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.closed = true
+}
+
+func nginxProps() map[string]interface{} {
+	return map[string]interface{}{
+		"ControlGroup": "/system.slice/nginx.service",
+		"MainPID":      uint32(1234),
+		"ExecStart": [][]interface{}{
+			{"/usr/sbin/nginx", []string{"/usr/sbin/nginx", "-g", "daemon on;"}, false, uint64(0), uint64(0), uint64(0), uint64(0), uint32(0), int32(0), int32(0)},
+		},
+	}
+}
+
+func TestGenerateData(t *testing.T) {
+	status := dbus.UnitStatus{
+		Name:        "nginx.service",
+		Description: "nginx daemon",
+		LoadState:   "loaded",
+		ActiveState: "active",
+		SubState:    "running",
+	}
+
+	data := generateData(status, nginxProps())
+	assert.Equal(t, map[string]interface{}{
+		"unit": map[string]interface{}{
+			"name":              "nginx.service",
+			"description":       "nginx daemon",
+			"load_state":        "loaded",
+			"state":             "active",
+			"sub_state":         "running",
+			"cgroup":            "/system.slice/nginx.service",
+			"exec_start":        "/usr/sbin/nginx -g daemon on;",
+			"main_pid":          1234,
+			"syslog_identifier": "nginx",
+		},
+	}, data.mapping)
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"add_fields": map[string]interface{}{
+				"fields": map[string]interface{}{
+					"name": "nginx.service",
+				},
+				"target": "systemd.unit",
+			},
+		},
+	}, data.processors)
+
+	props := nginxProps()
+	props["SyslogIdentifier"] = "web"
+	data = generateData(status, props)
+	assert.Equal(t, "web", data.mapping["unit"].(map[string]interface{})["syslog_identifier"])
+}
+
+func TestUnitType(t *testing.T) {
+	assert.Equal(t, "Service", unitType("nginx.service"))
+	assert.Equal(t, "Socket", unitType("sshd.socket"))
+	assert.Equal(t, "", unitType("nginx"))
+}
+
+func TestDynamicProvider(t *testing.T) {
+	bus := newFakeBus()
+	bus.start("nginx.service", nginxProps())
+
+	log, err := logger.New("systemd_test", false)
+	require.NoError(t, err)
+	builder, err := DynamicProviderBuilder(log, nil, true)
+	require.NoError(t, err)
+	provider, _ := builder.(*dynamicProvider)
+	provider.config.CheckInterval = 10 * time.Millisecond
+	provider.connect = func(ctx context.Context) (conn, error) {
+		return bus, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	comm := ctesting.NewDynamicComm(ctx)
+	done := make(chan error)
+	go func() {
+		done <- provider.Run(comm)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("nginx.service")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+	state, _ := comm.Current("nginx.service")
+	assert.Equal(t, "nginx.service", state.Mapping["unit"].(map[string]interface{})["name"])
+
+	// errors listing the units keep the current mappings
+	bus.mx.Lock()
+	bus.listErr = errors.New("bus disconnected")
+	bus.mx.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	_, ok := comm.Current("nginx.service")
+	assert.True(t, ok)
+	bus.mx.Lock()
+	bus.listErr = nil
+	bus.mx.Unlock()
+
+	bus.start("postgresql.service", map[string]interface{}{"SyslogIdentifier": "postgres"})
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("postgresql.service")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	bus.stop("nginx.service")
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("nginx.service")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+	_, ok = comm.Current("postgresql.service")
+	assert.True(t, ok)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for provider to stop")
+	}
+	bus.mx.Lock()
+	defer bus.mx.Unlock()
+	assert.True(t, bus.closed)
+}
+
+func TestDynamicProvider_Subscription(t *testing.T) {
+	bus := newFakeBus()
+
+	log, err := logger.New("systemd_test", false)
+	require.NoError(t, err)
+	builder, err := DynamicProviderBuilder(log, nil, true)
+	require.NoError(t, err)
+	provider, _ := builder.(*dynamicProvider)
+	// only the signals and the subscription errors update the mappings
+	provider.config.CheckInterval = time.Hour
+	provider.connect = func(ctx context.Context) (conn, error) {
+		return bus, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	comm := ctesting.NewDynamicComm(ctx)
+	done := make(chan error)
+	go func() {
+		done <- provider.Run(comm)
+	}()
+	require.Eventually(t, bus.subscribed, time.Second, 10*time.Millisecond)
+
+	bus.start("nginx.service", nginxProps())
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("nginx.service")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	// units that don't match the patterns are ignored
+	bus.start("sshd.socket", map[string]interface{}{})
+	bus.stop("nginx.service")
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("nginx.service")
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+	_, ok := comm.Current("sshd.socket")
+	assert.False(t, ok)
+
+	// a dropped signal is resynced on the subscription error
+	bus.mx.Lock()
+	bus.mute = true
+	bus.mx.Unlock()
+	bus.start("postgresql.service", map[string]interface{}{"SyslogIdentifier": "postgres"})
+	bus.errs <- errors.New("update channel is full")
+	require.Eventually(t, func() bool {
+		_, ok := comm.Current("postgresql.service")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		require.FailNow(t, "timeout waiting for provider to stop")
+	}
+}
+
+func TestDynamicProvider_NoBus(t *testing.T) {
+	log, err := logger.New("systemd_test", false)
+	require.NoError(t, err)
+	builder, err := DynamicProviderBuilder(log, nil, true)
+	require.NoError(t, err)
+	provider, _ := builder.(*dynamicProvider)
+	provider.connect = func(ctx context.Context) (conn, error) {
+		return nil, errors.New("no such file or directory")
+	}
+
+	comm := ctesting.NewDynamicComm(context.Background())
+	assert.NoError(t, provider.Run(comm))
+}
@@ -33,6 +33,9 @@ LDICT: '{';
 RDICT: '}';
 BEGIN_EVARIABLE: '$${';
 BEGIN_VARIABLE: '${';
+QUESTION: '?';
+COALESCE: '??';
+PIPE: '|>';
 
 expList: exp EOF;
 
@@ -71,6 +74,9 @@ exp
 | left=exp GT right=exp # ExpArithmeticGT
 | left=exp AND right=exp # ExpLogicalAnd
 | left=exp OR right=exp # ExpLogicalOR
+| <assoc=right> left=exp COALESCE right=exp # ExpCoalesce
+| <assoc=right> cond=exp QUESTION left=exp ':' right=exp # ExpConditional
+| left=exp PIPE NAME (LPAR arguments? RPAR)? # ExpPipe
 | boolean # ExpBoolean
 | BEGIN_EVARIABLE variableExp RDICT # ExpEVariable
 | BEGIN_VARIABLE variableExp RDICT # ExpVariable
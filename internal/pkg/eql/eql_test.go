@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/antlr4-go/antlr/v4"
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,9 @@ func TestEqlNewReportsBadSyntax(t *testing.T) {
 }
 
 func TestEql(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
 	testcases := []struct {
 		expression       string
 		allowMissingVars bool
@@ -266,6 +270,12 @@ func TestEql(t *testing.T) {
 		{expression: "arrayContains(${null.data}, 'str2', 3.5)", err: true},
 		{expression: "arrayContains(${data.array}, 'array5', 'array2')", result: true},
 		{expression: "arrayContains('not array', 'str2')", err: true},
+		{expression: "join(['a', 'b', 'c'], ',') == 'a,b,c'", result: true},
+		{expression: "join(${data.array}, ' ') == 'array1 array2 array3'", result: true},
+		{expression: "join(split('a.b.c', '.'), '/') == 'a/b/c'", result: true},
+		{expression: "join(${null.data}, ',') == ''", allowMissingVars: true, result: true},
+		{expression: "join('not array', ',')", err: true},
+		{expression: "join(['a'])", err: true},
 
 		// methods dict
 		{expression: "hasKey({key1: 'val1', key2: 'val2'}, 'key2')", result: true},
@@ -353,6 +363,121 @@ func TestEql(t *testing.T) {
 		{expression: "stringContains('hello world', 'o w', 'too many')", err: true},
 		{expression: "stringContains(0, 'o w', 'too many')", err: true},
 		{expression: "stringContains('hello world', 0)", result: false},
+		{expression: "lower('Hello World') == 'hello world'", result: true},
+		{expression: "lower('Hello', 'too many') == 'hello'", err: true},
+		{expression: "upper('Hello World') == 'HELLO WORLD'", result: true},
+		{expression: "upper() == ''", err: true},
+		{expression: "trim('  hello world\t') == 'hello world'", result: true},
+		{expression: "trim('hello', 'too many') == 'hello'", err: true},
+		{expression: "split('a,b,c', ',') == ['a', 'b', 'c']", result: true},
+		{expression: "split('abc', ',') == ['abc']", result: true},
+		{expression: "split('a,b,c') == ['a']", err: true},
+		{expression: "regexExtract('kube-system/coredns-5d78c9869d', '^([^/]+)/') == 'kube-system'", result: true},
+		{expression: "regexExtract('kube-system/coredns-5d78c9869d', '^([^/]+)/(.+)-[a-z0-9]+$', 2) == 'coredns'", result: true},
+		{expression: "regexExtract('kube-system/coredns-5d78c9869d', '[0-9]+') == '5'", result: true},
+		{expression: "regexExtract('kube-system/coredns-5d78c9869d', '^([^/]+)/', 0) == 'kube-system/'", result: true},
+		{expression: "regexExtract('no match here', '^([0-9]+)$') == ${missing}", allowMissingVars: true, result: true},
+		{expression: "regexExtract('kube-system', '^([^/]+)', 2) == 'kube-system'", err: true},
+		{expression: "regexExtract('kube-system', '^([^/]+)', 'one') == 'kube-system'", err: true},
+		{expression: "regexExtract('kube-system', '[a-z') == 'kube-system'", err: true},
+		{expression: "regexExtract('kube-system') == 'kube-system'", err: true},
+
+		{expression: "truncate('abcdefgh', 4) == 'abcd'", result: true},
+		{expression: "truncate('abc', 4) == 'abc'", result: true},
+		{expression: "truncate('abc', -1) == 'abc'", err: true},
+		{expression: "truncate('abc', 'one') == 'abc'", err: true},
+		{expression: "truncate('abc') == 'abc'", err: true},
+
+		// encoding
+		{expression: "base64Encode('hello world') == 'aGVsbG8gd29ybGQ='", result: true},
+		{expression: "base64Decode('aGVsbG8gd29ybGQ=') == 'hello world'", result: true},
+		{expression: "base64Decode('not base64!') == ''", err: true},
+		{expression: "base64Encode('a', 'b') == ''", err: true},
+		{expression: "sha256('hello') == '2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824'", result: true},
+		{expression: "sha256() == ''", err: true},
+
+		// pipes
+		{expression: "'ABC' |> lower == 'abc'", result: true},
+		{expression: "'  abc ' |> trim |> upper == 'ABC'", result: true},
+		{expression: "'a,b,c' |> split(',') |> length == 3", result: true},
+		{expression: "'abcdefgh' |> truncate(4) == 'abcd'", result: true},
+		{expression: "'abc' |> unknownFunction == 'abc'", err: true},
+
+		// net
+		{expression: "cidrContains('10.1.2.3', '10.0.0.0/8')", result: true},
+		{expression: "cidrContains('192.168.1.10', '10.0.0.0/8')", result: false},
+		{expression: "cidrContains('192.168.1.10', '10.0.0.0/8', '192.168.0.0/16')", result: true},
+		{expression: "cidrContains('::ffff:10.1.2.3', '10.0.0.0/8')", result: true},
+		{expression: "cidrContains('fd00::1', 'fd00::/8')", result: true},
+		{expression: "cidrContains(${host.ip}, '10.0.0.0/8')", result: true},
+		{expression: "cidrContains(${host.ip}, 'fe80::/10')", result: true},
+		{expression: "cidrContains(${host.ip}, '192.168.0.0/16')", result: false},
+		{expression: "cidrContains(${host.single_ip}, '10.0.0.0/8')", result: true},
+		{expression: "cidrContains(${host.single_ip}, '192.168.0.0/16')", result: false},
+		{expression: "cidrContains('10.0.12.7/24', '10.0.12.0/24')", result: true},
+		{expression: "cidrContains(${data.array}, '10.0.0.0/8')", result: false},
+		{expression: "cidrContains('not an ip', '10.0.0.0/8')", result: false},
+		{expression: "cidrContains(${null.data}, '10.0.0.0/8')", allowMissingVars: true, result: false},
+		{expression: "cidrContains('10.1.2.3', '10.0.0.0')", err: true},
+		{expression: "cidrContains('10.1.2.3', 8)", err: true},
+		{expression: "cidrContains('10.1.2.3')", err: true},
+
+		// time
+		{expression: "now() == 1704067200", result: true},
+		{expression: "now('too many') == 1704067200", err: true},
+		{expression: "duration('1h30m') == 5400", result: true},
+		{expression: "duration('1h') == 3600 and duration('90s') == 90", result: true},
+		{expression: "duration('not a duration') == 0", err: true},
+		{expression: "duration(3600) == 3600", err: true},
+		{expression: "duration() == 0", err: true},
+		{expression: "timestamp('2023-12-31T23:00:00Z') == 1704063600", result: true},
+		{expression: "subtract(now(), timestamp('2023-12-31T23:00:00Z')) > duration('30m')", result: true},
+		{expression: "subtract(now(), timestamp('2023-12-31T23:00:00Z')) > duration('2h')", result: false},
+		{expression: "timestamp('yesterday') == 0", err: true},
+
+		// version
+		{expression: "semverCompare('9.1.0', '>=9.1.0')", result: true},
+		{expression: "semverCompare('9.0.5', '>=9.1.0')", result: false},
+		{expression: "semverCompare('9.1.0', '>9.1.0')", result: false},
+		{expression: "semverCompare('9.1.0-SNAPSHOT', '<9.1.0')", result: true},
+		{expression: "semverCompare('9.1.2', '>=9.1.0, <10.0.0')", result: true},
+		{expression: "semverCompare('10.0.0', '>=9.1.0, <10.0.0')", result: false},
+		{expression: "semverCompare('9.1.0', '9.1.0')", result: true},
+		{expression: "semverCompare('9.1.0', '!=9.1.0')", result: false},
+		{expression: "semverCompare('9.1.0', '<=9.1.0')", result: true},
+		{expression: "semverCompare(${agent.version}, '>=8.0.0')", result: true},
+		{expression: "semverCompare(${null.data}, '>=8.0.0')", allowMissingVars: true, result: false},
+		{expression: "semverCompare('not a version', '>=9.1.0')", err: true},
+		{expression: "semverCompare('9.1.0', '>=nine')", err: true},
+		{expression: "semverCompare('9.1.0')", err: true},
+
+		// conditional
+		{expression: "(true ? 'yes' : 'no') == 'yes'", result: true},
+		{expression: "(false ? 'yes' : 'no') == 'no'", result: true},
+		{expression: "${host.name} == 'host-name' ? true : false", result: true},
+		{expression: "${host.name} == 'other' ? true : ${missing} == 'x'", allowMissingVars: true, result: false},
+		{expression: "${host.name} == 'host-name' ? true : ${missing}", result: true},
+		{expression: "${host.name} == 'other' ? true : ${missing}", err: true},
+		{expression: "false ? false : true ? true : false", result: true},
+		{expression: "true ? false ? false : true : false", result: true},
+		{expression: "length(true ? ${data.array} : []) == 3", result: true},
+		{expression: "concat(true ? 'a' : 'b', false ? 'c' : 'd') == 'ad'", result: true},
+		{expression: "(1 == 2 ? 1 : 2) + 1 == 3", result: true},
+		{expression: "({key: 'value'} == {key: 'value'}) ? true : false", result: true},
+		{expression: "'a?b' == 'a?b' ? true : false", result: true},
+		{expression: "1 ? true : false", err: true},
+		{expression: "true ? true", err: true},
+		{expression: "? true : false", err: true},
+		{expression: "true ? 'not a boolean' : false", err: true},
+
+		// null-coalescing
+		{expression: "(${missing} ?? 'fallback') == 'fallback'", result: true},
+		{expression: "(${host.name} ?? 'fallback') == 'host-name'", result: true},
+		{expression: "(${missing} ?? ${other.missing} ?? 2) == 2", result: true},
+		{expression: "${missing} ?? ${other.missing}", err: true},
+		{expression: "(${missing} ?? 'a') == 'a' ? true : false", result: true},
+		{expression: "true ?? false", result: true},
+		{expression: "?? true", err: true},
 
 		// Bad expression and malformed expression
 		{expression: "length('hello')", err: true},
@@ -368,6 +493,9 @@ func TestEql(t *testing.T) {
 			"env.HOSTNAME":    "my-hostname",
 			"env.HOSTSAME":    "my-hostname",
 			"host.name":       "host-name",
+			"host.ip":         []interface{}{"10.0.12.7/24", "fe80::1c2d:3eff:fe4f:5a6b/64"},
+			"host.single_ip":  "10.0.12.7/24",
+			"agent.version":   "9.1.0",
 			"data.array":      []interface{}{"array1", "array2", "array3"},
 			"data.with-dash":  "dash-value",
 			"data.with/slash": "some/path",
@@ -404,6 +532,79 @@ func TestEql(t *testing.T) {
 	}
 }
 
+func TestEqlValue(t *testing.T) {
+	store := &testVarStore{
+		vars: map[string]interface{}{
+			"kubernetes.labels.env": "prod",
+		},
+	}
+	testcases := []struct {
+		expression string
+		result     interface{}
+	}{
+		{expression: "${kubernetes.labels.env} == 'prod' ? 'prod-es:9200' : 'dev-es:9200'", result: "prod-es:9200"},
+		{expression: "${kubernetes.labels.env} == 'dev' ? 'prod-es:9200' : 'dev-es:9200'", result: "dev-es:9200"},
+		{expression: "${kubernetes.labels.missing} ?? 'none'", result: "none"},
+		{expression: "${kubernetes.labels.env} == 'prod' ? 3 : 1", result: 3},
+		{expression: "concat('a', 'b')", result: "ab"},
+	}
+	for _, test := range testcases {
+		t.Run(test.expression, func(t *testing.T) {
+			e, err := New(test.expression)
+			require.NoError(t, err)
+			r, err := e.Value(store, false)
+			require.NoError(t, err)
+			assert.Equal(t, test.result, r)
+		})
+	}
+}
+
+func TestEqlEvalNotBoolean(t *testing.T) {
+	_, err := Eval("concat('a', 'b')", &testVarStore{}, false)
+	assert.Error(t, err)
+}
+
+func TestNewInline(t *testing.T) {
+	store := &testVarStore{
+		vars: map[string]interface{}{
+			"kubernetes.labels.env": "prod",
+			"host.name":             "my-host",
+		},
+	}
+	testcases := []struct {
+		expression string
+		names      []string
+		result     interface{}
+		err        string
+	}{
+		{expression: "kubernetes.labels.env == 'prod' ? 'prod-es:9200' : 'dev-es:9200'", names: []string{"kubernetes.labels.env"}, result: "prod-es:9200"},
+		{expression: "kubernetes.labels.team ?? host.name", names: []string{"kubernetes.labels.team", "host.name"}, result: "my-host"},
+		{expression: "concat(host.name, '-', kubernetes.labels.env)", names: []string{"host.name", "kubernetes.labels.env"}, result: "my-host-prod"},
+		{expression: "host.name == 'my-host' and host.name != 'host.name'", names: []string{"host.name"}, result: true},
+		{expression: "${host.name} == 'my-host'", result: true},
+		{expression: "host.name |> upper", names: []string{"host.name"}, result: "MY-HOST"},
+		{expression: "kubernetes.labels.team ?? host.name |> split('-') |> length", names: []string{"kubernetes.labels.team", "host.name"}, result: 2},
+		{expression: "kubernetes.labels.team |> lower", names: []string{"kubernetes.labels.team"}, result: Null},
+		{expression: "host.name == ", err: "condition line 1 column 13: mismatched input '<EOF>'"},
+		{expression: "host.name ? 'a'", err: "condition line 1 column 15: mismatched input '<EOF>' expecting ':'"},
+		{expression: "host.name == ) ", err: "condition line 1 column 13: mismatched input ')'"},
+	}
+	for _, test := range testcases {
+		t.Run(test.expression, func(t *testing.T) {
+			e, names, err := NewInline(test.expression)
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.names, names)
+			r, err := e.Value(store, true)
+			require.NoError(t, err)
+			assert.Equal(t, test.result, r)
+		})
+	}
+}
+
 func debug(t *testing.T, expression string) {
 	raw := antlr.NewInputStream(expression)
 
@@ -7,6 +7,7 @@ package eql
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/antlr4-go/antlr/v4"
 
@@ -43,7 +44,21 @@ type Expression struct {
 // Evaluation does not use logical short circuiting: for example,
 // the expression "${validVariable} or ${invalidVariable}" will generate
 // an error even if ${validVariable} is true.
-func (e *Expression) Eval(store VarStore, allowMissingVars bool) (result bool, err error) {
+func (e *Expression) Eval(store VarStore, allowMissingVars bool) (bool, error) {
+	r, err := e.Value(store, allowMissingVars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := r.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %s must evaluate to a boolean; received %T", e.expression, r)
+	}
+	return b, nil
+}
+
+// Value evaluates the expression like Eval but returns its result whatever its type, e.g. the string
+// selected by "${env} == 'prod' ? 'prod-es:9200' : 'dev-es:9200'".
+func (e *Expression) Value(store VarStore, allowMissingVars bool) (result interface{}, err error) {
 	// Antlr can panic on errors so we have to recover somehow.
 	defer func() {
 		r := recover()
@@ -56,10 +71,10 @@ func (e *Expression) Eval(store VarStore, allowMissingVars bool) (result bool, e
 	r := visitor.Visit(e.tree)
 
 	if visitor.err != nil {
-		return false, visitor.err
+		return nil, visitor.err
 	}
 
-	return r.(bool), nil
+	return r, nil
 }
 
 // New create a new boolean expression parser will return an error if the expression if invalid.
@@ -67,9 +82,15 @@ func New(expression string) (*Expression, error) {
 	if len(expression) == 0 {
 		return nil, ErrEmptyExpression
 	}
+	return parse(expression, expression, nil)
+}
 
+// parse parses source, expression is the text used in the evaluation errors. The insertions made to the
+// expression to produce source are removed from the columns of the syntax errors.
+func parse(expression, source string, insertions []insertion) (*Expression, error) {
 	errorListener := newErrorListener()
-	input := antlr.NewInputStream(expression)
+	errorListener.insertions = insertions
+	input := antlr.NewInputStream(source)
 	lexer := parser.NewEqlLexer(input)
 	lexer.RemoveErrorListeners()
 	lexer.AddErrorListener(errorListener)
@@ -94,10 +115,20 @@ type errorListener struct {
 	// "errors" uses multierror to store all parse errors in a single
 	// error object.
 	errors error
+
+	// insertions made to the parsed text, see NewInline.
+	insertions []insertion
+}
+
+// insertion is text inserted into an expression before parsing it.
+type insertion struct {
+	line   int
+	column int
+	length int
 }
 
 func newErrorListener() *errorListener {
-	return &errorListener{antlr.NewDefaultErrorListener(), nil}
+	return &errorListener{antlr.NewDefaultErrorListener(), nil, nil}
 }
 
 func (el *errorListener) SyntaxError(
@@ -107,6 +138,121 @@ func (el *errorListener) SyntaxError(
 	msg string,
 	e antlr.RecognitionException,
 ) {
+	// report the column in the expression as it was written
+	offset := 0
+	for _, i := range el.insertions {
+		if i.line == line && i.column < column {
+			offset += min(i.length, column-i.column)
+		}
+	}
 	el.errors = errors.Join(el.errors,
-		fmt.Errorf("condition line %d column %d: %v", line, column, msg))
+		fmt.Errorf("condition line %d column %d: %v", line, column-offset, msg))
+}
+
+// inlineOperators are the tokens that make the text of a variable substitution an expression.
+var inlineOperators = map[int]bool{
+	parser.EqlLexerEQ:       true,
+	parser.EqlLexerNEQ:      true,
+	parser.EqlLexerGT:       true,
+	parser.EqlLexerLT:       true,
+	parser.EqlLexerGTE:      true,
+	parser.EqlLexerLTE:      true,
+	parser.EqlLexerADD:      true,
+	parser.EqlLexerSUB:      true,
+	parser.EqlLexerMUL:      true,
+	parser.EqlLexerDIV:      true,
+	parser.EqlLexerMOD:      true,
+	parser.EqlLexerAND:      true,
+	parser.EqlLexerOR:       true,
+	parser.EqlLexerNOT:      true,
+	parser.EqlLexerLPAR:     true,
+	parser.EqlLexerRPAR:     true,
+	parser.EqlLexerLARR:     true,
+	parser.EqlLexerRARR:     true,
+	parser.EqlLexerT__1:     true,
+	parser.EqlLexerQUESTION: true,
+	parser.EqlLexerCOALESCE: true,
+	parser.EqlLexerPIPE:     true,
+}
+
+// IsInline returns true if the text of a variable substitution is an expression that must be created with
+// NewInline, e.g. "host.name == 'a' ? 'b' : 'c'", "a - b" or "host.name |> lower", instead of a variable name or a list of
+// variables and constants separated by |, e.g. "host.name|'default'" or "data.some-key". It is an
+// expression when an operator, a parenthesis, a bracket or a comma is used outside of quotes.
+func IsInline(text string) bool {
+	lexer := parser.NewEqlLexer(antlr.NewInputStream(text))
+	lexer.RemoveErrorListeners()
+	tokens := 0
+	operator := false
+	for token := lexer.NextToken(); token.GetTokenType() != antlr.TokenEOF; token = lexer.NextToken() {
+		tokens++
+		operator = operator || inlineOperators[token.GetTokenType()]
+	}
+	// a single token is a variable, e.g. "and" or "not"
+	return operator && tokens > 1
+}
+
+// NewInline creates an expression written inside a variable substitution, where variables are referenced
+// by their name without ${}, e.g. "host.name == 'a' ? 'b' : 'c'". It also returns the names of the
+// variables referenced by the expression.
+func NewInline(expression string) (*Expression, []string, error) {
+	if len(expression) == 0 {
+		return nil, nil, ErrEmptyExpression
+	}
+
+	// Lexing errors are reported when the rewritten expression is parsed, the text between tokens is
+	// kept as is.
+	runes := []rune(expression)
+	lexer := parser.NewEqlLexer(antlr.NewInputStream(expression))
+	lexer.RemoveErrorListeners()
+	var tokens []antlr.Token
+	for token := lexer.NextToken(); token.GetTokenType() != antlr.TokenEOF; token = lexer.NextToken() {
+		tokens = append(tokens, token)
+	}
+
+	var sb strings.Builder
+	var names []string
+	var insertions []insertion
+	shifts := map[int]int{}
+	seen := map[string]bool{}
+	last := 0
+	depth := 0
+	for i, token := range tokens {
+		switch token.GetTokenType() {
+		case parser.EqlLexerBEGIN_VARIABLE, parser.EqlLexerBEGIN_EVARIABLE, parser.EqlLexerLDICT:
+			depth++
+			continue
+		case parser.EqlLexerRDICT:
+			depth--
+			continue
+		case parser.EqlLexerNAME, parser.EqlLexerVNAME:
+		default:
+			continue
+		}
+		if depth > 0 || (i+1 < len(tokens) && tokens[i+1].GetTokenType() == parser.EqlLexerLPAR) ||
+			(i > 0 && tokens[i-1].GetTokenType() == parser.EqlLexerPIPE) {
+			// already a variable, a dict key or a function name
+			continue
+		}
+		name := token.GetText()
+		sb.WriteString(string(runes[last:token.GetStart()]))
+		sb.WriteString("${" + name + "}")
+		last = token.GetStop() + 1
+		line, column := token.GetLine(), token.GetColumn()+shifts[token.GetLine()]
+		insertions = append(insertions,
+			insertion{line: line, column: column, length: 2},
+			insertion{line: line, column: column + 2 + last - token.GetStart(), length: 1})
+		shifts[line] += 3
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sb.WriteString(string(runes[last:]))
+
+	e, err := parse(expression, sb.String(), insertions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, names, nil
 }
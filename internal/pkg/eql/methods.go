@@ -12,10 +12,16 @@ type callFunc func(args []interface{}) (interface{}, error)
 var methods = map[string]callFunc{
 	// array
 	"arrayContains": arrayContains,
+	"join":          join,
 
 	// dict
 	"hasKey": hasKey,
 
+	// encoding
+	"base64Decode": base64Decode,
+	"base64Encode": base64Encode,
+	"sha256":       sha256Hex,
+
 	// length:
 	"length": length,
 
@@ -26,13 +32,30 @@ var methods = map[string]callFunc{
 	"divide":   divide,
 	"modulo":   modulo,
 
+	// net
+	"cidrContains": cidrContains,
+
 	// str
 	"concat":         concat,
 	"endsWith":       endsWith,
 	"indexOf":        indexOf,
+	"lower":          lower,
 	"match":          match,
 	"number":         number,
+	"regexExtract":   regexExtract,
+	"split":          split,
 	"startsWith":     startsWith,
 	"string":         str,
 	"stringContains": stringContains,
+	"trim":           trim,
+	"truncate":       truncate,
+	"upper":          upper,
+
+	// time
+	"duration":  duration,
+	"now":       now,
+	"timestamp": timestamp,
+
+	// version
+	"semverCompare": semverCompare,
 }
@@ -7,6 +7,7 @@ package eql
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // arrayContains check if value is a member of the array.
@@ -29,3 +30,21 @@ func arrayContains(args []interface{}) (interface{}, error) {
 	}
 	return nil, fmt.Errorf("arrayContains: first argument must be an array; received %T", args[0])
 }
+
+// join concatenates the members of the array into a string, placing the separator between them.
+func join(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("join: accepts exactly 2 arguments; received %d", len(args))
+	}
+	switch a := args[0].(type) {
+	case *null:
+		return "", nil
+	case []interface{}:
+		items := make([]string, 0, len(a))
+		for _, i := range a {
+			items = append(items, toString(i))
+		}
+		return strings.Join(items, toString(args[1])), nil
+	}
+	return nil, fmt.Errorf("join: first argument must be an array; received %T", args[0])
+}
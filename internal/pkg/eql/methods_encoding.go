@@ -0,0 +1,41 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package eql
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// base64Decode decodes the standard base64 encoded string
+func base64Decode(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("base64Decode: accepts exactly 1 argument; received %d", len(args))
+	}
+	decoded, err := base64.StdEncoding.DecodeString(toString(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("base64Decode: failed to decode: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// base64Encode encodes the string with standard base64 encoding
+func base64Encode(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("base64Encode: accepts exactly 1 argument; received %d", len(args))
+	}
+	return base64.StdEncoding.EncodeToString([]byte(toString(args[0]))), nil
+}
+
+// sha256Hex returns the hex encoded SHA-256 hash of the string
+func sha256Hex(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("sha256: accepts exactly 1 argument; received %d", len(args))
+	}
+	sum := sha256.Sum256([]byte(toString(args[0])))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,66 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package eql
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// cidrContains returns true if the IP address is contained in any of the provided CIDR ranges.
+//
+// The first argument is either a single address or an array of addresses, in which case the
+// result is true when any of them is contained. Addresses may carry a prefix length
+// (e.g. 10.0.12.7/24), as published by the host provider for host.ip.
+func cidrContains(args []interface{}) (interface{}, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("cidrContains: accepts minimum of 2 arguments; received %d", len(args))
+	}
+	var ips []netip.Addr
+	switch a := args[0].(type) {
+	case *null:
+		return false, nil
+	case string:
+		ips = appendAddr(ips, a)
+	case []interface{}:
+		for i, v := range a {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("cidrContains: first argument must be an array of strings; element %d is %T", i, v)
+			}
+			ips = appendAddr(ips, s)
+		}
+	default:
+		return nil, fmt.Errorf("cidrContains: first argument must be a string or an array; received %T", args[0])
+	}
+	for i, arg := range args[1:] {
+		cidr, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("cidrContains: argument %d must be a string; received %T", i+1, arg)
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("cidrContains: failed to parse CIDR: %w", err)
+		}
+		for _, ip := range ips {
+			if prefix.Contains(ip) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// appendAddr parses s as an address or as an address with a prefix length and appends the
+// address to ips. Values that are not IP addresses cannot be in any range and are skipped.
+func appendAddr(ips []netip.Addr, s string) []netip.Addr {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		return append(ips, addr.Unmap())
+	}
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return append(ips, prefix.Addr().Unmap())
+	}
+	return ips
+}
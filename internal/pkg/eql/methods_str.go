@@ -46,6 +46,14 @@ func indexOf(args []interface{}) (interface{}, error) {
 	return start + strings.Index(input[start:], substring), nil
 }
 
+// lower returns the string in lower case
+func lower(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("lower: accepts exactly 1 argument; received %d", len(args))
+	}
+	return strings.ToLower(toString(args[0])), nil
+}
+
 // match returns true if the string matches any of the provided regular expressions
 func match(args []interface{}) (interface{}, error) {
 	if len(args) < 2 {
@@ -93,6 +101,55 @@ func number(args []interface{}) (interface{}, error) {
 	return int(n), nil
 }
 
+// regexExtract returns the text of the capture group in the first match of the regular expression, the first
+// capture group is used when none is provided and the whole match when the expression has no groups
+func regexExtract(args []interface{}) (interface{}, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("regexExtract: accepts 2-3 arguments; received %d", len(args))
+	}
+	input := toString(args[0])
+	r, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexExtract: argument 1 must be a string; received %T", args[1])
+	}
+	exp, err := regexp.Compile(r)
+	if err != nil {
+		return nil, fmt.Errorf("regexExtract: failed to compile regexp: %w", err)
+	}
+	group := 0
+	if exp.NumSubexp() > 0 {
+		group = 1
+	}
+	if len(args) > 2 {
+		g, gOk := args[2].(int)
+		if !gOk {
+			return nil, fmt.Errorf("regexExtract: argument 2 must be an integer; received %T", args[2])
+		}
+		if g < 0 || g > exp.NumSubexp() {
+			return nil, fmt.Errorf("regexExtract: capture group %d out of range; expression has %d", g, exp.NumSubexp())
+		}
+		group = g
+	}
+	matches := exp.FindStringSubmatch(input)
+	if matches == nil {
+		return Null, nil
+	}
+	return matches[group], nil
+}
+
+// split splits the string into an array of strings around each instance of the separator
+func split(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("split: accepts exactly 2 arguments; received %d", len(args))
+	}
+	parts := strings.Split(toString(args[0]), toString(args[1]))
+	result := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		result = append(result, part)
+	}
+	return result, nil
+}
+
 // startsWith returns true if the string starts with given prefix
 func startsWith(args []interface{}) (interface{}, error) {
 	if len(args) != 2 {
@@ -117,6 +174,38 @@ func stringContains(args []interface{}) (interface{}, error) {
 	return strings.Contains(toString(args[0]), toString(args[1])), nil
 }
 
+// trim returns the string without leading and trailing white space
+func trim(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("trim: accepts exactly 1 argument; received %d", len(args))
+	}
+	return strings.TrimSpace(toString(args[0])), nil
+}
+
+// upper returns the string in upper case
+func upper(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("upper: accepts exactly 1 argument; received %d", len(args))
+	}
+	return strings.ToUpper(toString(args[0])), nil
+}
+
+// truncate returns the first n characters of the string
+func truncate(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("truncate: accepts exactly 2 arguments; received %d", len(args))
+	}
+	n, ok := args[1].(int)
+	if !ok || n < 0 {
+		return nil, fmt.Errorf("truncate: argument 1 must be a positive integer; received %v", args[1])
+	}
+	input := []rune(toString(args[0]))
+	if len(input) <= n {
+		return string(input), nil
+	}
+	return string(input[:n]), nil
+}
+
 func toString(arg interface{}) string {
 	switch a := arg.(type) {
 	case *null:
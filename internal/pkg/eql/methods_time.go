@@ -0,0 +1,54 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package eql
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeNow returns the current time, replaced in tests.
+var timeNow = time.Now
+
+// now returns the current time as seconds since the Unix epoch
+func now(args []interface{}) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("now: accepts no arguments; received %d", len(args))
+	}
+	return int(timeNow().Unix()), nil
+}
+
+// duration converts a duration string, e.g. '1h30m', into seconds, so it can be compared with or added to now()
+func duration(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("duration: accepts exactly 1 argument; received %d", len(args))
+	}
+	input, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("duration: argument 0 must be a string; received %T", args[0])
+	}
+	d, err := time.ParseDuration(input)
+	if err != nil {
+		return nil, fmt.Errorf("duration: failed to parse '%s': %w", input, err)
+	}
+	return int(d / time.Second), nil
+}
+
+// timestamp converts an RFC 3339 time, e.g. '2024-01-01T00:00:00Z', into seconds since the Unix epoch, so it
+// can be compared with now()
+func timestamp(args []interface{}) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("timestamp: accepts exactly 1 argument; received %d", len(args))
+	}
+	input, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("timestamp: argument 0 must be a string; received %T", args[0])
+	}
+	t, err := time.Parse(time.RFC3339, input)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: failed to parse '%s': %w", input, err)
+	}
+	return int(t.Unix()), nil
+}
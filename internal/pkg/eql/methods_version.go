@@ -0,0 +1,75 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package eql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/elastic-agent/pkg/version"
+)
+
+// semverOperators are the comparison operators accepted in a semverCompare constraint, longest first so
+// that ">=" is not read as ">".
+var semverOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// semverCompare returns true if the version satisfies every comma separated constraint, e.g. ">=9.1.0, <10.0.0"
+func semverCompare(args []interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("semverCompare: accepts exactly 2 arguments; received %d", len(args))
+	}
+	if _, ok := args[0].(*null); ok {
+		return false, nil
+	}
+	v, err := version.ParseVersion(toString(args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("semverCompare: failed to parse version: %w", err)
+	}
+	constraints, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("semverCompare: argument 1 must be a string; received %T", args[1])
+	}
+	for _, constraint := range strings.Split(constraints, ",") {
+		satisfied, err := semverSatisfies(*v, strings.TrimSpace(constraint))
+		if err != nil {
+			return nil, fmt.Errorf("semverCompare: %w", err)
+		}
+		if !satisfied {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// semverSatisfies returns true if the version satisfies a single constraint, a version without an operator
+// must be equal.
+func semverSatisfies(v version.ParsedSemVer, constraint string) (bool, error) {
+	op := "=="
+	for _, o := range semverOperators {
+		if strings.HasPrefix(constraint, o) {
+			op = o
+			constraint = strings.TrimSpace(strings.TrimPrefix(constraint, o))
+			break
+		}
+	}
+	other, err := version.ParseVersion(constraint)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse constraint: %w", err)
+	}
+	switch op {
+	case ">=":
+		return !v.Less(*other), nil
+	case "<=":
+		return v.Less(*other) || v.Equal(*other), nil
+	case ">":
+		return other.Less(v), nil
+	case "<":
+		return v.Less(*other), nil
+	case "!=":
+		return !v.Equal(*other), nil
+	default:
+		return v.Equal(*other), nil
+	}
+}
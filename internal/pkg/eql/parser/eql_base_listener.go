@@ -97,6 +97,12 @@ func (s *BaseEqlListener) EnterExpArithmeticMulDivMod(ctx *ExpArithmeticMulDivMo
 // ExitExpArithmeticMulDivMod is called when production ExpArithmeticMulDivMod is exited.
 func (s *BaseEqlListener) ExitExpArithmeticMulDivMod(ctx *ExpArithmeticMulDivModContext) {}
 
+// EnterExpPipe is called when production ExpPipe is entered.
+func (s *BaseEqlListener) EnterExpPipe(ctx *ExpPipeContext) {}
+
+// ExitExpPipe is called when production ExpPipe is exited.
+func (s *BaseEqlListener) ExitExpPipe(ctx *ExpPipeContext) {}
+
 // EnterExpDict is called when production ExpDict is entered.
 func (s *BaseEqlListener) EnterExpDict(ctx *ExpDictContext) {}
 
@@ -109,6 +115,12 @@ func (s *BaseEqlListener) EnterExpText(ctx *ExpTextContext) {}
 // ExitExpText is called when production ExpText is exited.
 func (s *BaseEqlListener) ExitExpText(ctx *ExpTextContext) {}
 
+// EnterExpCoalesce is called when production ExpCoalesce is entered.
+func (s *BaseEqlListener) EnterExpCoalesce(ctx *ExpCoalesceContext) {}
+
+// ExitExpCoalesce is called when production ExpCoalesce is exited.
+func (s *BaseEqlListener) ExitExpCoalesce(ctx *ExpCoalesceContext) {}
+
 // EnterExpNumber is called when production ExpNumber is entered.
 func (s *BaseEqlListener) EnterExpNumber(ctx *ExpNumberContext) {}
 
@@ -175,6 +187,12 @@ func (s *BaseEqlListener) EnterExpFunction(ctx *ExpFunctionContext) {}
 // ExitExpFunction is called when production ExpFunction is exited.
 func (s *BaseEqlListener) ExitExpFunction(ctx *ExpFunctionContext) {}
 
+// EnterExpConditional is called when production ExpConditional is entered.
+func (s *BaseEqlListener) EnterExpConditional(ctx *ExpConditionalContext) {}
+
+// ExitExpConditional is called when production ExpConditional is exited.
+func (s *BaseEqlListener) ExitExpConditional(ctx *ExpConditionalContext) {}
+
 // EnterExpArithmeticLT is called when production ExpArithmeticLT is entered.
 func (s *BaseEqlListener) EnterExpArithmeticLT(ctx *ExpArithmeticLTContext) {}
 
@@ -60,6 +60,10 @@ func (v *BaseEqlVisitor) VisitExpArithmeticMulDivMod(ctx *ExpArithmeticMulDivMod
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseEqlVisitor) VisitExpPipe(ctx *ExpPipeContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseEqlVisitor) VisitExpDict(ctx *ExpDictContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -68,6 +72,10 @@ func (v *BaseEqlVisitor) VisitExpText(ctx *ExpTextContext) interface{} {
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseEqlVisitor) VisitExpCoalesce(ctx *ExpCoalesceContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseEqlVisitor) VisitExpNumber(ctx *ExpNumberContext) interface{} {
 	return v.VisitChildren(ctx)
 }
@@ -112,6 +120,10 @@ func (v *BaseEqlVisitor) VisitExpFunction(ctx *ExpFunctionContext) interface{} {
 	return v.VisitChildren(ctx)
 }
 
+func (v *BaseEqlVisitor) VisitExpConditional(ctx *ExpConditionalContext) interface{} {
+	return v.VisitChildren(ctx)
+}
+
 func (v *BaseEqlVisitor) VisitExpArithmeticLT(ctx *ExpArithmeticLTContext) interface{} {
 	return v.VisitChildren(ctx)
 }
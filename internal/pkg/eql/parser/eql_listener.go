@@ -48,12 +48,18 @@ type EqlListener interface {
 	// EnterExpArithmeticMulDivMod is called when entering the ExpArithmeticMulDivMod production.
 	EnterExpArithmeticMulDivMod(c *ExpArithmeticMulDivModContext)
 
+	// EnterExpPipe is called when entering the ExpPipe production.
+	EnterExpPipe(c *ExpPipeContext)
+
 	// EnterExpDict is called when entering the ExpDict production.
 	EnterExpDict(c *ExpDictContext)
 
 	// EnterExpText is called when entering the ExpText production.
 	EnterExpText(c *ExpTextContext)
 
+	// EnterExpCoalesce is called when entering the ExpCoalesce production.
+	EnterExpCoalesce(c *ExpCoalesceContext)
+
 	// EnterExpNumber is called when entering the ExpNumber production.
 	EnterExpNumber(c *ExpNumberContext)
 
@@ -87,6 +93,9 @@ type EqlListener interface {
 	// EnterExpFunction is called when entering the ExpFunction production.
 	EnterExpFunction(c *ExpFunctionContext)
 
+	// EnterExpConditional is called when entering the ExpConditional production.
+	EnterExpConditional(c *ExpConditionalContext)
+
 	// EnterExpArithmeticLT is called when entering the ExpArithmeticLT production.
 	EnterExpArithmeticLT(c *ExpArithmeticLTContext)
 
@@ -138,12 +147,18 @@ type EqlListener interface {
 	// ExitExpArithmeticMulDivMod is called when exiting the ExpArithmeticMulDivMod production.
 	ExitExpArithmeticMulDivMod(c *ExpArithmeticMulDivModContext)
 
+	// ExitExpPipe is called when exiting the ExpPipe production.
+	ExitExpPipe(c *ExpPipeContext)
+
 	// ExitExpDict is called when exiting the ExpDict production.
 	ExitExpDict(c *ExpDictContext)
 
 	// ExitExpText is called when exiting the ExpText production.
 	ExitExpText(c *ExpTextContext)
 
+	// ExitExpCoalesce is called when exiting the ExpCoalesce production.
+	ExitExpCoalesce(c *ExpCoalesceContext)
+
 	// ExitExpNumber is called when exiting the ExpNumber production.
 	ExitExpNumber(c *ExpNumberContext)
 
@@ -177,6 +192,9 @@ type EqlListener interface {
 	// ExitExpFunction is called when exiting the ExpFunction production.
 	ExitExpFunction(c *ExpFunctionContext)
 
+	// ExitExpConditional is called when exiting the ExpConditional production.
+	ExitExpConditional(c *ExpConditionalContext)
+
 	// ExitExpArithmeticLT is called when exiting the ExpArithmeticLT production.
 	ExitExpArithmeticLT(c *ExpArithmeticLTContext)
 
@@ -40,12 +40,14 @@ func eqlParserInit() {
 		"", "'|'", "','", "':'", "'=='", "'!='", "'>'", "'<'", "'>='", "'<='",
 		"'+'", "'-'", "'*'", "'/'", "'%'", "", "", "", "", "", "", "", "", "",
 		"", "", "", "'('", "')'", "'['", "']'", "'{'", "'}'", "'$${'", "'${'",
+		"'?'", "'??'", "'|>'",
 	}
 	staticData.SymbolicNames = []string{
 		"", "", "", "", "EQ", "NEQ", "GT", "LT", "GTE", "LTE", "ADD", "SUB",
 		"MUL", "DIV", "MOD", "AND", "OR", "TRUE", "FALSE", "FLOAT", "NUMBER",
 		"WHITESPACE", "NOT", "NAME", "VNAME", "STEXT", "DTEXT", "LPAR", "RPAR",
 		"LARR", "RARR", "LDICT", "RDICT", "BEGIN_EVARIABLE", "BEGIN_VARIABLE",
+		"QUESTION", "COALESCE", "PIPE",
 	}
 	staticData.RuleNames = []string{
 		"expList", "boolean", "constant", "variable", "variableExp", "exp",
@@ -53,7 +55,7 @@ func eqlParserInit() {
 	}
 	staticData.PredictionContextCache = antlr.NewPredictionContextCache()
 	staticData.serializedATN = []int32{
-		4, 1, 34, 146, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7,
+		4, 1, 37, 165, 2, 0, 7, 0, 2, 1, 7, 1, 2, 2, 7, 2, 2, 3, 7, 3, 2, 4, 7,
 		4, 2, 5, 7, 5, 2, 6, 7, 6, 2, 7, 7, 7, 2, 8, 7, 8, 2, 9, 7, 9, 1, 0, 1,
 		0, 1, 0, 1, 1, 1, 1, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 3, 2, 31, 8, 2, 1, 3,
 		1, 3, 1, 3, 3, 3, 36, 8, 3, 1, 4, 1, 4, 1, 4, 5, 4, 41, 8, 4, 10, 4, 12,
@@ -62,63 +64,72 @@ func eqlParserInit() {
 		5, 1, 5, 1, 5, 3, 5, 70, 8, 5, 1, 5, 1, 5, 1, 5, 3, 5, 75, 8, 5, 1, 5,
 		1, 5, 1, 5, 1, 5, 3, 5, 81, 8, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1,
 		5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1,
-		5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 5,
-		5, 113, 8, 5, 10, 5, 12, 5, 116, 9, 5, 1, 6, 1, 6, 1, 6, 5, 6, 121, 8,
-		6, 10, 6, 12, 6, 124, 9, 6, 1, 7, 1, 7, 1, 7, 5, 7, 129, 8, 7, 10, 7, 12,
-		7, 132, 9, 7, 1, 8, 1, 8, 1, 8, 1, 8, 1, 9, 1, 9, 1, 9, 5, 9, 141, 8, 9,
-		10, 9, 12, 9, 144, 9, 9, 1, 9, 0, 1, 10, 10, 0, 2, 4, 6, 8, 10, 12, 14,
-		16, 18, 0, 5, 1, 0, 17, 18, 1, 0, 25, 26, 1, 0, 12, 14, 1, 0, 10, 11, 2,
-		0, 23, 23, 25, 26, 168, 0, 20, 1, 0, 0, 0, 2, 23, 1, 0, 0, 0, 4, 30, 1,
-		0, 0, 0, 6, 35, 1, 0, 0, 0, 8, 37, 1, 0, 0, 0, 10, 80, 1, 0, 0, 0, 12,
-		117, 1, 0, 0, 0, 14, 125, 1, 0, 0, 0, 16, 133, 1, 0, 0, 0, 18, 137, 1,
-		0, 0, 0, 20, 21, 3, 10, 5, 0, 21, 22, 5, 0, 0, 1, 22, 1, 1, 0, 0, 0, 23,
-		24, 7, 0, 0, 0, 24, 3, 1, 0, 0, 0, 25, 31, 5, 25, 0, 0, 26, 31, 5, 26,
-		0, 0, 27, 31, 5, 19, 0, 0, 28, 31, 5, 20, 0, 0, 29, 31, 3, 2, 1, 0, 30,
-		25, 1, 0, 0, 0, 30, 26, 1, 0, 0, 0, 30, 27, 1, 0, 0, 0, 30, 28, 1, 0, 0,
-		0, 30, 29, 1, 0, 0, 0, 31, 5, 1, 0, 0, 0, 32, 36, 5, 23, 0, 0, 33, 36,
-		5, 24, 0, 0, 34, 36, 3, 4, 2, 0, 35, 32, 1, 0, 0, 0, 35, 33, 1, 0, 0, 0,
-		35, 34, 1, 0, 0, 0, 36, 7, 1, 0, 0, 0, 37, 42, 3, 6, 3, 0, 38, 39, 5, 1,
-		0, 0, 39, 41, 3, 6, 3, 0, 40, 38, 1, 0, 0, 0, 41, 44, 1, 0, 0, 0, 42, 40,
-		1, 0, 0, 0, 42, 43, 1, 0, 0, 0, 43, 9, 1, 0, 0, 0, 44, 42, 1, 0, 0, 0,
-		45, 46, 6, 5, -1, 0, 46, 47, 5, 27, 0, 0, 47, 48, 3, 10, 5, 0, 48, 49,
-		5, 28, 0, 0, 49, 81, 1, 0, 0, 0, 50, 51, 5, 22, 0, 0, 51, 81, 3, 10, 5,
-		18, 52, 81, 3, 2, 1, 0, 53, 54, 5, 33, 0, 0, 54, 55, 3, 8, 4, 0, 55, 56,
-		5, 32, 0, 0, 56, 81, 1, 0, 0, 0, 57, 58, 5, 34, 0, 0, 58, 59, 3, 8, 4,
-		0, 59, 60, 5, 32, 0, 0, 60, 81, 1, 0, 0, 0, 61, 62, 5, 23, 0, 0, 62, 64,
-		5, 27, 0, 0, 63, 65, 3, 12, 6, 0, 64, 63, 1, 0, 0, 0, 64, 65, 1, 0, 0,
-		0, 65, 66, 1, 0, 0, 0, 66, 81, 5, 28, 0, 0, 67, 69, 5, 29, 0, 0, 68, 70,
-		3, 14, 7, 0, 69, 68, 1, 0, 0, 0, 69, 70, 1, 0, 0, 0, 70, 71, 1, 0, 0, 0,
-		71, 81, 5, 30, 0, 0, 72, 74, 5, 31, 0, 0, 73, 75, 3, 18, 9, 0, 74, 73,
-		1, 0, 0, 0, 74, 75, 1, 0, 0, 0, 75, 76, 1, 0, 0, 0, 76, 81, 5, 32, 0, 0,
-		77, 81, 7, 1, 0, 0, 78, 81, 5, 19, 0, 0, 79, 81, 5, 20, 0, 0, 80, 45, 1,
-		0, 0, 0, 80, 50, 1, 0, 0, 0, 80, 52, 1, 0, 0, 0, 80, 53, 1, 0, 0, 0, 80,
-		57, 1, 0, 0, 0, 80, 61, 1, 0, 0, 0, 80, 67, 1, 0, 0, 0, 80, 72, 1, 0, 0,
-		0, 80, 77, 1, 0, 0, 0, 80, 78, 1, 0, 0, 0, 80, 79, 1, 0, 0, 0, 81, 114,
-		1, 0, 0, 0, 82, 83, 10, 20, 0, 0, 83, 84, 7, 2, 0, 0, 84, 113, 3, 10, 5,
-		21, 85, 86, 10, 19, 0, 0, 86, 87, 7, 3, 0, 0, 87, 113, 3, 10, 5, 20, 88,
-		89, 10, 17, 0, 0, 89, 90, 5, 4, 0, 0, 90, 113, 3, 10, 5, 18, 91, 92, 10,
-		16, 0, 0, 92, 93, 5, 5, 0, 0, 93, 113, 3, 10, 5, 17, 94, 95, 10, 15, 0,
-		0, 95, 96, 5, 9, 0, 0, 96, 113, 3, 10, 5, 16, 97, 98, 10, 14, 0, 0, 98,
-		99, 5, 8, 0, 0, 99, 113, 3, 10, 5, 15, 100, 101, 10, 13, 0, 0, 101, 102,
-		5, 7, 0, 0, 102, 113, 3, 10, 5, 14, 103, 104, 10, 12, 0, 0, 104, 105, 5,
-		6, 0, 0, 105, 113, 3, 10, 5, 13, 106, 107, 10, 11, 0, 0, 107, 108, 5, 15,
-		0, 0, 108, 113, 3, 10, 5, 12, 109, 110, 10, 10, 0, 0, 110, 111, 5, 16,
-		0, 0, 111, 113, 3, 10, 5, 11, 112, 82, 1, 0, 0, 0, 112, 85, 1, 0, 0, 0,
-		112, 88, 1, 0, 0, 0, 112, 91, 1, 0, 0, 0, 112, 94, 1, 0, 0, 0, 112, 97,
-		1, 0, 0, 0, 112, 100, 1, 0, 0, 0, 112, 103, 1, 0, 0, 0, 112, 106, 1, 0,
-		0, 0, 112, 109, 1, 0, 0, 0, 113, 116, 1, 0, 0, 0, 114, 112, 1, 0, 0, 0,
-		114, 115, 1, 0, 0, 0, 115, 11, 1, 0, 0, 0, 116, 114, 1, 0, 0, 0, 117, 122,
-		3, 10, 5, 0, 118, 119, 5, 2, 0, 0, 119, 121, 3, 10, 5, 0, 120, 118, 1,
-		0, 0, 0, 121, 124, 1, 0, 0, 0, 122, 120, 1, 0, 0, 0, 122, 123, 1, 0, 0,
-		0, 123, 13, 1, 0, 0, 0, 124, 122, 1, 0, 0, 0, 125, 130, 3, 4, 2, 0, 126,
-		127, 5, 2, 0, 0, 127, 129, 3, 4, 2, 0, 128, 126, 1, 0, 0, 0, 129, 132,
-		1, 0, 0, 0, 130, 128, 1, 0, 0, 0, 130, 131, 1, 0, 0, 0, 131, 15, 1, 0,
-		0, 0, 132, 130, 1, 0, 0, 0, 133, 134, 7, 4, 0, 0, 134, 135, 5, 3, 0, 0,
-		135, 136, 3, 4, 2, 0, 136, 17, 1, 0, 0, 0, 137, 142, 3, 16, 8, 0, 138,
-		139, 5, 2, 0, 0, 139, 141, 3, 16, 8, 0, 140, 138, 1, 0, 0, 0, 141, 144,
-		1, 0, 0, 0, 142, 140, 1, 0, 0, 0, 142, 143, 1, 0, 0, 0, 143, 19, 1, 0,
-		0, 0, 144, 142, 1, 0, 0, 0, 12, 30, 35, 42, 64, 69, 74, 80, 112, 114, 122,
-		130, 142,
+		5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1,
+		5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1, 5, 1,
+		5, 1, 5, 3, 5, 127, 8, 5, 1, 5, 3, 5, 130, 8, 5, 5, 5, 132, 8, 5, 10, 5,
+		12, 5, 135, 9, 5, 1, 6, 1, 6, 1, 6, 5, 6, 140, 8, 6, 10, 6, 12, 6, 143,
+		9, 6, 1, 7, 1, 7, 1, 7, 5, 7, 148, 8, 7, 10, 7, 12, 7, 151, 9, 7, 1, 8,
+		1, 8, 1, 8, 1, 8, 1, 9, 1, 9, 1, 9, 5, 9, 160, 8, 9, 10, 9, 12, 9, 163,
+		9, 9, 1, 9, 0, 1, 10, 10, 0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 0, 5, 1, 0,
+		17, 18, 1, 0, 25, 26, 1, 0, 12, 14, 1, 0, 10, 11, 2, 0, 23, 23, 25, 26,
+		192, 0, 20, 1, 0, 0, 0, 2, 23, 1, 0, 0, 0, 4, 30, 1, 0, 0, 0, 6, 35, 1,
+		0, 0, 0, 8, 37, 1, 0, 0, 0, 10, 80, 1, 0, 0, 0, 12, 136, 1, 0, 0, 0, 14,
+		144, 1, 0, 0, 0, 16, 152, 1, 0, 0, 0, 18, 156, 1, 0, 0, 0, 20, 21, 3, 10,
+		5, 0, 21, 22, 5, 0, 0, 1, 22, 1, 1, 0, 0, 0, 23, 24, 7, 0, 0, 0, 24, 3,
+		1, 0, 0, 0, 25, 31, 5, 25, 0, 0, 26, 31, 5, 26, 0, 0, 27, 31, 5, 19, 0,
+		0, 28, 31, 5, 20, 0, 0, 29, 31, 3, 2, 1, 0, 30, 25, 1, 0, 0, 0, 30, 26,
+		1, 0, 0, 0, 30, 27, 1, 0, 0, 0, 30, 28, 1, 0, 0, 0, 30, 29, 1, 0, 0, 0,
+		31, 5, 1, 0, 0, 0, 32, 36, 5, 23, 0, 0, 33, 36, 5, 24, 0, 0, 34, 36, 3,
+		4, 2, 0, 35, 32, 1, 0, 0, 0, 35, 33, 1, 0, 0, 0, 35, 34, 1, 0, 0, 0, 36,
+		7, 1, 0, 0, 0, 37, 42, 3, 6, 3, 0, 38, 39, 5, 1, 0, 0, 39, 41, 3, 6, 3,
+		0, 40, 38, 1, 0, 0, 0, 41, 44, 1, 0, 0, 0, 42, 40, 1, 0, 0, 0, 42, 43,
+		1, 0, 0, 0, 43, 9, 1, 0, 0, 0, 44, 42, 1, 0, 0, 0, 45, 46, 6, 5, -1, 0,
+		46, 47, 5, 27, 0, 0, 47, 48, 3, 10, 5, 0, 48, 49, 5, 28, 0, 0, 49, 81,
+		1, 0, 0, 0, 50, 51, 5, 22, 0, 0, 51, 81, 3, 10, 5, 21, 52, 81, 3, 2, 1,
+		0, 53, 54, 5, 33, 0, 0, 54, 55, 3, 8, 4, 0, 55, 56, 5, 32, 0, 0, 56, 81,
+		1, 0, 0, 0, 57, 58, 5, 34, 0, 0, 58, 59, 3, 8, 4, 0, 59, 60, 5, 32, 0,
+		0, 60, 81, 1, 0, 0, 0, 61, 62, 5, 23, 0, 0, 62, 64, 5, 27, 0, 0, 63, 65,
+		3, 12, 6, 0, 64, 63, 1, 0, 0, 0, 64, 65, 1, 0, 0, 0, 65, 66, 1, 0, 0, 0,
+		66, 81, 5, 28, 0, 0, 67, 69, 5, 29, 0, 0, 68, 70, 3, 14, 7, 0, 69, 68,
+		1, 0, 0, 0, 69, 70, 1, 0, 0, 0, 70, 71, 1, 0, 0, 0, 71, 81, 5, 30, 0, 0,
+		72, 74, 5, 31, 0, 0, 73, 75, 3, 18, 9, 0, 74, 73, 1, 0, 0, 0, 74, 75, 1,
+		0, 0, 0, 75, 76, 1, 0, 0, 0, 76, 81, 5, 32, 0, 0, 77, 81, 7, 1, 0, 0, 78,
+		81, 5, 19, 0, 0, 79, 81, 5, 20, 0, 0, 80, 45, 1, 0, 0, 0, 80, 50, 1, 0,
+		0, 0, 80, 52, 1, 0, 0, 0, 80, 53, 1, 0, 0, 0, 80, 57, 1, 0, 0, 0, 80, 61,
+		1, 0, 0, 0, 80, 67, 1, 0, 0, 0, 80, 72, 1, 0, 0, 0, 80, 77, 1, 0, 0, 0,
+		80, 78, 1, 0, 0, 0, 80, 79, 1, 0, 0, 0, 81, 133, 1, 0, 0, 0, 82, 83, 10,
+		23, 0, 0, 83, 84, 7, 2, 0, 0, 84, 132, 3, 10, 5, 24, 85, 86, 10, 22, 0,
+		0, 86, 87, 7, 3, 0, 0, 87, 132, 3, 10, 5, 23, 88, 89, 10, 20, 0, 0, 89,
+		90, 5, 4, 0, 0, 90, 132, 3, 10, 5, 21, 91, 92, 10, 19, 0, 0, 92, 93, 5,
+		5, 0, 0, 93, 132, 3, 10, 5, 20, 94, 95, 10, 18, 0, 0, 95, 96, 5, 9, 0,
+		0, 96, 132, 3, 10, 5, 19, 97, 98, 10, 17, 0, 0, 98, 99, 5, 8, 0, 0, 99,
+		132, 3, 10, 5, 18, 100, 101, 10, 16, 0, 0, 101, 102, 5, 7, 0, 0, 102, 132,
+		3, 10, 5, 17, 103, 104, 10, 15, 0, 0, 104, 105, 5, 6, 0, 0, 105, 132, 3,
+		10, 5, 16, 106, 107, 10, 14, 0, 0, 107, 108, 5, 15, 0, 0, 108, 132, 3,
+		10, 5, 15, 109, 110, 10, 13, 0, 0, 110, 111, 5, 16, 0, 0, 111, 132, 3,
+		10, 5, 14, 112, 113, 10, 12, 0, 0, 113, 114, 5, 36, 0, 0, 114, 132, 3,
+		10, 5, 12, 115, 116, 10, 11, 0, 0, 116, 117, 5, 35, 0, 0, 117, 118, 3,
+		10, 5, 0, 118, 119, 5, 3, 0, 0, 119, 120, 3, 10, 5, 11, 120, 132, 1, 0,
+		0, 0, 121, 122, 10, 10, 0, 0, 122, 123, 5, 37, 0, 0, 123, 129, 5, 23, 0,
+		0, 124, 126, 5, 27, 0, 0, 125, 127, 3, 12, 6, 0, 126, 125, 1, 0, 0, 0,
+		126, 127, 1, 0, 0, 0, 127, 128, 1, 0, 0, 0, 128, 130, 5, 28, 0, 0, 129,
+		124, 1, 0, 0, 0, 129, 130, 1, 0, 0, 0, 130, 132, 1, 0, 0, 0, 131, 82, 1,
+		0, 0, 0, 131, 85, 1, 0, 0, 0, 131, 88, 1, 0, 0, 0, 131, 91, 1, 0, 0, 0,
+		131, 94, 1, 0, 0, 0, 131, 97, 1, 0, 0, 0, 131, 100, 1, 0, 0, 0, 131, 103,
+		1, 0, 0, 0, 131, 106, 1, 0, 0, 0, 131, 109, 1, 0, 0, 0, 131, 112, 1, 0,
+		0, 0, 131, 115, 1, 0, 0, 0, 131, 121, 1, 0, 0, 0, 132, 135, 1, 0, 0, 0,
+		133, 131, 1, 0, 0, 0, 133, 134, 1, 0, 0, 0, 134, 11, 1, 0, 0, 0, 135, 133,
+		1, 0, 0, 0, 136, 141, 3, 10, 5, 0, 137, 138, 5, 2, 0, 0, 138, 140, 3, 10,
+		5, 0, 139, 137, 1, 0, 0, 0, 140, 143, 1, 0, 0, 0, 141, 139, 1, 0, 0, 0,
+		141, 142, 1, 0, 0, 0, 142, 13, 1, 0, 0, 0, 143, 141, 1, 0, 0, 0, 144, 149,
+		3, 4, 2, 0, 145, 146, 5, 2, 0, 0, 146, 148, 3, 4, 2, 0, 147, 145, 1, 0,
+		0, 0, 148, 151, 1, 0, 0, 0, 149, 147, 1, 0, 0, 0, 149, 150, 1, 0, 0, 0,
+		150, 15, 1, 0, 0, 0, 151, 149, 1, 0, 0, 0, 152, 153, 7, 4, 0, 0, 153, 154,
+		5, 3, 0, 0, 154, 155, 3, 4, 2, 0, 155, 17, 1, 0, 0, 0, 156, 161, 3, 16,
+		8, 0, 157, 158, 5, 2, 0, 0, 158, 160, 3, 16, 8, 0, 159, 157, 1, 0, 0, 0,
+		160, 163, 1, 0, 0, 0, 161, 159, 1, 0, 0, 0, 161, 162, 1, 0, 0, 0, 162,
+		19, 1, 0, 0, 0, 163, 161, 1, 0, 0, 0, 14, 30, 35, 42, 64, 69, 74, 80, 126,
+		129, 131, 133, 141, 149, 161,
 	}
 	deserializer := antlr.NewATNDeserializer(nil)
 	staticData.atn = deserializer.Deserialize(staticData.serializedATN)
@@ -191,6 +202,9 @@ const (
 	EqlParserRDICT           = 32
 	EqlParserBEGIN_EVARIABLE = 33
 	EqlParserBEGIN_VARIABLE  = 34
+	EqlParserQUESTION        = 35
+	EqlParserCOALESCE        = 36
+	EqlParserPIPE            = 37
 )
 
 // EqlParser rules.
@@ -1676,6 +1690,99 @@ func (s *ExpArithmeticMulDivModContext) Accept(visitor antlr.ParseTreeVisitor) i
 	}
 }
 
+type ExpPipeContext struct {
+	ExpContext
+	left IExpContext
+}
+
+func NewExpPipeContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *ExpPipeContext {
+	var p = new(ExpPipeContext)
+
+	InitEmptyExpContext(&p.ExpContext)
+	p.parser = parser
+	p.CopyAll(ctx.(*ExpContext))
+
+	return p
+}
+
+func (s *ExpPipeContext) GetLeft() IExpContext { return s.left }
+
+func (s *ExpPipeContext) SetLeft(v IExpContext) { s.left = v }
+
+func (s *ExpPipeContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ExpPipeContext) PIPE() antlr.TerminalNode {
+	return s.GetToken(EqlParserPIPE, 0)
+}
+
+func (s *ExpPipeContext) NAME() antlr.TerminalNode {
+	return s.GetToken(EqlParserNAME, 0)
+}
+
+func (s *ExpPipeContext) Exp() IExpContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExpContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpContext)
+}
+
+func (s *ExpPipeContext) LPAR() antlr.TerminalNode {
+	return s.GetToken(EqlParserLPAR, 0)
+}
+
+func (s *ExpPipeContext) RPAR() antlr.TerminalNode {
+	return s.GetToken(EqlParserRPAR, 0)
+}
+
+func (s *ExpPipeContext) Arguments() IArgumentsContext {
+	var t antlr.RuleContext
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IArgumentsContext); ok {
+			t = ctx.(antlr.RuleContext)
+			break
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IArgumentsContext)
+}
+
+func (s *ExpPipeContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.EnterExpPipe(s)
+	}
+}
+
+func (s *ExpPipeContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.ExitExpPipe(s)
+	}
+}
+
+func (s *ExpPipeContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case EqlVisitor:
+		return t.VisitExpPipe(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
 type ExpDictContext struct {
 	ExpContext
 }
@@ -1788,6 +1895,101 @@ func (s *ExpTextContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
 	}
 }
 
+type ExpCoalesceContext struct {
+	ExpContext
+	left  IExpContext
+	right IExpContext
+}
+
+func NewExpCoalesceContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *ExpCoalesceContext {
+	var p = new(ExpCoalesceContext)
+
+	InitEmptyExpContext(&p.ExpContext)
+	p.parser = parser
+	p.CopyAll(ctx.(*ExpContext))
+
+	return p
+}
+
+func (s *ExpCoalesceContext) GetLeft() IExpContext { return s.left }
+
+func (s *ExpCoalesceContext) GetRight() IExpContext { return s.right }
+
+func (s *ExpCoalesceContext) SetLeft(v IExpContext) { s.left = v }
+
+func (s *ExpCoalesceContext) SetRight(v IExpContext) { s.right = v }
+
+func (s *ExpCoalesceContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ExpCoalesceContext) COALESCE() antlr.TerminalNode {
+	return s.GetToken(EqlParserCOALESCE, 0)
+}
+
+func (s *ExpCoalesceContext) AllExp() []IExpContext {
+	children := s.GetChildren()
+	len := 0
+	for _, ctx := range children {
+		if _, ok := ctx.(IExpContext); ok {
+			len++
+		}
+	}
+
+	tst := make([]IExpContext, len)
+	i := 0
+	for _, ctx := range children {
+		if t, ok := ctx.(IExpContext); ok {
+			tst[i] = t.(IExpContext)
+			i++
+		}
+	}
+
+	return tst
+}
+
+func (s *ExpCoalesceContext) Exp(i int) IExpContext {
+	var t antlr.RuleContext
+	j := 0
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExpContext); ok {
+			if j == i {
+				t = ctx.(antlr.RuleContext)
+				break
+			}
+			j++
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpContext)
+}
+
+func (s *ExpCoalesceContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.EnterExpCoalesce(s)
+	}
+}
+
+func (s *ExpCoalesceContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.ExitExpCoalesce(s)
+	}
+}
+
+func (s *ExpCoalesceContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case EqlVisitor:
+		return t.VisitExpCoalesce(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
 type ExpNumberContext struct {
 	ExpContext
 }
@@ -2541,6 +2743,106 @@ func (s *ExpFunctionContext) Accept(visitor antlr.ParseTreeVisitor) interface{}
 	}
 }
 
+type ExpConditionalContext struct {
+	ExpContext
+	cond  IExpContext
+	left  IExpContext
+	right IExpContext
+}
+
+func NewExpConditionalContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *ExpConditionalContext {
+	var p = new(ExpConditionalContext)
+
+	InitEmptyExpContext(&p.ExpContext)
+	p.parser = parser
+	p.CopyAll(ctx.(*ExpContext))
+
+	return p
+}
+
+func (s *ExpConditionalContext) GetCond() IExpContext { return s.cond }
+
+func (s *ExpConditionalContext) GetLeft() IExpContext { return s.left }
+
+func (s *ExpConditionalContext) GetRight() IExpContext { return s.right }
+
+func (s *ExpConditionalContext) SetCond(v IExpContext) { s.cond = v }
+
+func (s *ExpConditionalContext) SetLeft(v IExpContext) { s.left = v }
+
+func (s *ExpConditionalContext) SetRight(v IExpContext) { s.right = v }
+
+func (s *ExpConditionalContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ExpConditionalContext) QUESTION() antlr.TerminalNode {
+	return s.GetToken(EqlParserQUESTION, 0)
+}
+
+func (s *ExpConditionalContext) AllExp() []IExpContext {
+	children := s.GetChildren()
+	len := 0
+	for _, ctx := range children {
+		if _, ok := ctx.(IExpContext); ok {
+			len++
+		}
+	}
+
+	tst := make([]IExpContext, len)
+	i := 0
+	for _, ctx := range children {
+		if t, ok := ctx.(IExpContext); ok {
+			tst[i] = t.(IExpContext)
+			i++
+		}
+	}
+
+	return tst
+}
+
+func (s *ExpConditionalContext) Exp(i int) IExpContext {
+	var t antlr.RuleContext
+	j := 0
+	for _, ctx := range s.GetChildren() {
+		if _, ok := ctx.(IExpContext); ok {
+			if j == i {
+				t = ctx.(antlr.RuleContext)
+				break
+			}
+			j++
+		}
+	}
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IExpContext)
+}
+
+func (s *ExpConditionalContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.EnterExpConditional(s)
+	}
+}
+
+func (s *ExpConditionalContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(EqlListener); ok {
+		listenerT.ExitExpConditional(s)
+	}
+}
+
+func (s *ExpConditionalContext) Accept(visitor antlr.ParseTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case EqlVisitor:
+		return t.VisitExpConditional(s)
+
+	default:
+		return t.VisitChildren(s)
+	}
+}
+
 type ExpArithmeticLTContext struct {
 	ExpContext
 	left  IExpContext
@@ -2701,7 +3003,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 		}
 		{
 			p.SetState(51)
-			p.exp(18)
+			p.exp(21)
 		}
 
 	case EqlParserTRUE, EqlParserFALSE:
@@ -2923,12 +3225,12 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 		goto errorExit
 	}
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
-	p.SetState(114)
+	p.SetState(133)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
 	}
-	_alt = p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 8, p.GetParserRuleContext())
+	_alt = p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 10, p.GetParserRuleContext())
 	if p.HasError() {
 		goto errorExit
 	}
@@ -2938,13 +3240,13 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.TriggerExitRuleEvent()
 			}
 			_prevctx = localctx
-			p.SetState(112)
+			p.SetState(131)
 			p.GetErrorHandler().Sync(p)
 			if p.HasError() {
 				goto errorExit
 			}
 
-			switch p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 7, p.GetParserRuleContext()) {
+			switch p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 9, p.GetParserRuleContext()) {
 			case 1:
 				localctx = NewExpArithmeticMulDivModContext(p, NewExpContext(p, _parentctx, _parentState))
 				localctx.(*ExpArithmeticMulDivModContext).left = _prevctx
@@ -2952,8 +3254,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(82)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 20)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 20)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 23)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 23)", ""))
 					goto errorExit
 				}
 				{
@@ -2970,7 +3272,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(84)
 
-					var _x = p.exp(21)
+					var _x = p.exp(24)
 
 					localctx.(*ExpArithmeticMulDivModContext).right = _x
 				}
@@ -2982,8 +3284,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(85)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 19)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 19)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 22)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 22)", ""))
 					goto errorExit
 				}
 				{
@@ -3000,7 +3302,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(87)
 
-					var _x = p.exp(20)
+					var _x = p.exp(23)
 
 					localctx.(*ExpArithmeticAddSubContext).right = _x
 				}
@@ -3012,8 +3314,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(88)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 17)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 17)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 20)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 20)", ""))
 					goto errorExit
 				}
 				{
@@ -3027,7 +3329,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(90)
 
-					var _x = p.exp(18)
+					var _x = p.exp(21)
 
 					localctx.(*ExpArithmeticEQContext).right = _x
 				}
@@ -3039,8 +3341,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(91)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 16)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 16)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 19)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 19)", ""))
 					goto errorExit
 				}
 				{
@@ -3054,7 +3356,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(93)
 
-					var _x = p.exp(17)
+					var _x = p.exp(20)
 
 					localctx.(*ExpArithmeticNEQContext).right = _x
 				}
@@ -3066,8 +3368,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(94)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 15)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 15)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 18)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 18)", ""))
 					goto errorExit
 				}
 				{
@@ -3081,7 +3383,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(96)
 
-					var _x = p.exp(16)
+					var _x = p.exp(19)
 
 					localctx.(*ExpArithmeticLTEContext).right = _x
 				}
@@ -3093,8 +3395,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(97)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 14)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 14)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 17)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 17)", ""))
 					goto errorExit
 				}
 				{
@@ -3108,7 +3410,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(99)
 
-					var _x = p.exp(15)
+					var _x = p.exp(18)
 
 					localctx.(*ExpArithmeticGTEContext).right = _x
 				}
@@ -3120,8 +3422,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(100)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 13)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 13)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 16)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 16)", ""))
 					goto errorExit
 				}
 				{
@@ -3135,7 +3437,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(102)
 
-					var _x = p.exp(14)
+					var _x = p.exp(17)
 
 					localctx.(*ExpArithmeticLTContext).right = _x
 				}
@@ -3147,8 +3449,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(103)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 12)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 12)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 15)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 15)", ""))
 					goto errorExit
 				}
 				{
@@ -3162,7 +3464,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(105)
 
-					var _x = p.exp(13)
+					var _x = p.exp(16)
 
 					localctx.(*ExpArithmeticGTContext).right = _x
 				}
@@ -3174,8 +3476,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(106)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 11)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 11)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 14)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 14)", ""))
 					goto errorExit
 				}
 				{
@@ -3189,7 +3491,7 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(108)
 
-					var _x = p.exp(12)
+					var _x = p.exp(15)
 
 					localctx.(*ExpLogicalAndContext).right = _x
 				}
@@ -3201,8 +3503,8 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
 				p.SetState(109)
 
-				if !(p.Precpred(p.GetParserRuleContext(), 10)) {
-					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 10)", ""))
+				if !(p.Precpred(p.GetParserRuleContext(), 13)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 13)", ""))
 					goto errorExit
 				}
 				{
@@ -3216,22 +3518,157 @@ func (p *EqlParser) exp(_p int) (localctx IExpContext) {
 				{
 					p.SetState(111)
 
-					var _x = p.exp(11)
+					var _x = p.exp(14)
 
 					localctx.(*ExpLogicalORContext).right = _x
 				}
 
+			case 11:
+				localctx = NewExpCoalesceContext(p, NewExpContext(p, _parentctx, _parentState))
+				localctx.(*ExpCoalesceContext).left = _prevctx
+
+				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
+				p.SetState(112)
+
+				if !(p.Precpred(p.GetParserRuleContext(), 12)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 12)", ""))
+					goto errorExit
+				}
+				{
+					p.SetState(113)
+					p.Match(EqlParserCOALESCE)
+					if p.HasError() {
+						// Recognition error - abort rule
+						goto errorExit
+					}
+				}
+				{
+					p.SetState(114)
+
+					var _x = p.exp(12)
+
+					localctx.(*ExpCoalesceContext).right = _x
+				}
+
+			case 12:
+				localctx = NewExpConditionalContext(p, NewExpContext(p, _parentctx, _parentState))
+				localctx.(*ExpConditionalContext).cond = _prevctx
+
+				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
+				p.SetState(115)
+
+				if !(p.Precpred(p.GetParserRuleContext(), 11)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 11)", ""))
+					goto errorExit
+				}
+				{
+					p.SetState(116)
+					p.Match(EqlParserQUESTION)
+					if p.HasError() {
+						// Recognition error - abort rule
+						goto errorExit
+					}
+				}
+				{
+					p.SetState(117)
+
+					var _x = p.exp(0)
+
+					localctx.(*ExpConditionalContext).left = _x
+				}
+				{
+					p.SetState(118)
+					p.Match(EqlParserT__2)
+					if p.HasError() {
+						// Recognition error - abort rule
+						goto errorExit
+					}
+				}
+				{
+					p.SetState(119)
+
+					var _x = p.exp(11)
+
+					localctx.(*ExpConditionalContext).right = _x
+				}
+
+			case 13:
+				localctx = NewExpPipeContext(p, NewExpContext(p, _parentctx, _parentState))
+				localctx.(*ExpPipeContext).left = _prevctx
+
+				p.PushNewRecursionContext(localctx, _startState, EqlParserRULE_exp)
+				p.SetState(121)
+
+				if !(p.Precpred(p.GetParserRuleContext(), 10)) {
+					p.SetError(antlr.NewFailedPredicateException(p, "p.Precpred(p.GetParserRuleContext(), 10)", ""))
+					goto errorExit
+				}
+				{
+					p.SetState(122)
+					p.Match(EqlParserPIPE)
+					if p.HasError() {
+						// Recognition error - abort rule
+						goto errorExit
+					}
+				}
+				{
+					p.SetState(123)
+					p.Match(EqlParserNAME)
+					if p.HasError() {
+						// Recognition error - abort rule
+						goto errorExit
+					}
+				}
+				p.SetState(129)
+				p.GetErrorHandler().Sync(p)
+
+				if p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 8, p.GetParserRuleContext()) == 1 {
+					{
+						p.SetState(124)
+						p.Match(EqlParserLPAR)
+						if p.HasError() {
+							// Recognition error - abort rule
+							goto errorExit
+						}
+					}
+					p.SetState(126)
+					p.GetErrorHandler().Sync(p)
+					if p.HasError() {
+						goto errorExit
+					}
+					_la = p.GetTokenStream().LA(1)
+
+					if (int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&28703588352) != 0 {
+						{
+							p.SetState(125)
+							p.Arguments()
+						}
+
+					}
+					{
+						p.SetState(128)
+						p.Match(EqlParserRPAR)
+						if p.HasError() {
+							// Recognition error - abort rule
+							goto errorExit
+						}
+					}
+
+				} else if p.HasError() { // JIM
+					goto errorExit
+				}
+
 			case antlr.ATNInvalidAltNumber:
 				goto errorExit
 			}
 
 		}
-		p.SetState(116)
+		p.SetState(135)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
 		}
-		_alt = p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 8, p.GetParserRuleContext())
+		_alt = p.GetInterpreter().AdaptivePredict(p.BaseParser, p.GetTokenStream(), 10, p.GetParserRuleContext())
 		if p.HasError() {
 			goto errorExit
 		}
@@ -3377,10 +3814,10 @@ func (p *EqlParser) Arguments() (localctx IArgumentsContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(117)
+		p.SetState(136)
 		p.exp(0)
 	}
-	p.SetState(122)
+	p.SetState(141)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -3389,7 +3826,7 @@ func (p *EqlParser) Arguments() (localctx IArgumentsContext) {
 
 	for _la == EqlParserT__1 {
 		{
-			p.SetState(118)
+			p.SetState(137)
 			p.Match(EqlParserT__1)
 			if p.HasError() {
 				// Recognition error - abort rule
@@ -3397,11 +3834,11 @@ func (p *EqlParser) Arguments() (localctx IArgumentsContext) {
 			}
 		}
 		{
-			p.SetState(119)
+			p.SetState(138)
 			p.exp(0)
 		}
 
-		p.SetState(124)
+		p.SetState(143)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -3549,10 +3986,10 @@ func (p *EqlParser) Array() (localctx IArrayContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(125)
+		p.SetState(144)
 		p.Constant()
 	}
-	p.SetState(130)
+	p.SetState(149)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -3561,7 +3998,7 @@ func (p *EqlParser) Array() (localctx IArrayContext) {
 
 	for _la == EqlParserT__1 {
 		{
-			p.SetState(126)
+			p.SetState(145)
 			p.Match(EqlParserT__1)
 			if p.HasError() {
 				// Recognition error - abort rule
@@ -3569,11 +4006,11 @@ func (p *EqlParser) Array() (localctx IArrayContext) {
 			}
 		}
 		{
-			p.SetState(127)
+			p.SetState(146)
 			p.Constant()
 		}
 
-		p.SetState(132)
+		p.SetState(151)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -3710,7 +4147,7 @@ func (p *EqlParser) Key() (localctx IKeyContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(133)
+		p.SetState(152)
 		_la = p.GetTokenStream().LA(1)
 
 		if !((int64(_la) & ^0x3f) == 0 && ((int64(1)<<_la)&109051904) != 0) {
@@ -3721,7 +4158,7 @@ func (p *EqlParser) Key() (localctx IKeyContext) {
 		}
 	}
 	{
-		p.SetState(134)
+		p.SetState(153)
 		p.Match(EqlParserT__2)
 		if p.HasError() {
 			// Recognition error - abort rule
@@ -3729,7 +4166,7 @@ func (p *EqlParser) Key() (localctx IKeyContext) {
 		}
 	}
 	{
-		p.SetState(135)
+		p.SetState(154)
 		p.Constant()
 	}
 
@@ -3873,10 +4310,10 @@ func (p *EqlParser) Dict() (localctx IDictContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(137)
+		p.SetState(156)
 		p.Key()
 	}
-	p.SetState(142)
+	p.SetState(161)
 	p.GetErrorHandler().Sync(p)
 	if p.HasError() {
 		goto errorExit
@@ -3885,7 +4322,7 @@ func (p *EqlParser) Dict() (localctx IDictContext) {
 
 	for _la == EqlParserT__1 {
 		{
-			p.SetState(138)
+			p.SetState(157)
 			p.Match(EqlParserT__1)
 			if p.HasError() {
 				// Recognition error - abort rule
@@ -3893,11 +4330,11 @@ func (p *EqlParser) Dict() (localctx IDictContext) {
 			}
 		}
 		{
-			p.SetState(139)
+			p.SetState(158)
 			p.Key()
 		}
 
-		p.SetState(144)
+		p.SetState(163)
 		p.GetErrorHandler().Sync(p)
 		if p.HasError() {
 			goto errorExit
@@ -3937,33 +4374,42 @@ func (p *EqlParser) Sempred(localctx antlr.RuleContext, ruleIndex, predIndex int
 func (p *EqlParser) Exp_Sempred(localctx antlr.RuleContext, predIndex int) bool {
 	switch predIndex {
 	case 0:
-		return p.Precpred(p.GetParserRuleContext(), 20)
+		return p.Precpred(p.GetParserRuleContext(), 23)
 
 	case 1:
-		return p.Precpred(p.GetParserRuleContext(), 19)
+		return p.Precpred(p.GetParserRuleContext(), 22)
 
 	case 2:
-		return p.Precpred(p.GetParserRuleContext(), 17)
+		return p.Precpred(p.GetParserRuleContext(), 20)
 
 	case 3:
-		return p.Precpred(p.GetParserRuleContext(), 16)
+		return p.Precpred(p.GetParserRuleContext(), 19)
 
 	case 4:
-		return p.Precpred(p.GetParserRuleContext(), 15)
+		return p.Precpred(p.GetParserRuleContext(), 18)
 
 	case 5:
-		return p.Precpred(p.GetParserRuleContext(), 14)
+		return p.Precpred(p.GetParserRuleContext(), 17)
 
 	case 6:
-		return p.Precpred(p.GetParserRuleContext(), 13)
+		return p.Precpred(p.GetParserRuleContext(), 16)
 
 	case 7:
-		return p.Precpred(p.GetParserRuleContext(), 12)
+		return p.Precpred(p.GetParserRuleContext(), 15)
 
 	case 8:
-		return p.Precpred(p.GetParserRuleContext(), 11)
+		return p.Precpred(p.GetParserRuleContext(), 14)
 
 	case 9:
+		return p.Precpred(p.GetParserRuleContext(), 13)
+
+	case 10:
+		return p.Precpred(p.GetParserRuleContext(), 12)
+
+	case 11:
+		return p.Precpred(p.GetParserRuleContext(), 11)
+
+	case 12:
 		return p.Precpred(p.GetParserRuleContext(), 10)
 
 	default:
@@ -48,12 +48,18 @@ type EqlVisitor interface {
 	// Visit a parse tree produced by EqlParser#ExpArithmeticMulDivMod.
 	VisitExpArithmeticMulDivMod(ctx *ExpArithmeticMulDivModContext) interface{}
 
+	// Visit a parse tree produced by EqlParser#ExpPipe.
+	VisitExpPipe(ctx *ExpPipeContext) interface{}
+
 	// Visit a parse tree produced by EqlParser#ExpDict.
 	VisitExpDict(ctx *ExpDictContext) interface{}
 
 	// Visit a parse tree produced by EqlParser#ExpText.
 	VisitExpText(ctx *ExpTextContext) interface{}
 
+	// Visit a parse tree produced by EqlParser#ExpCoalesce.
+	VisitExpCoalesce(ctx *ExpCoalesceContext) interface{}
+
 	// Visit a parse tree produced by EqlParser#ExpNumber.
 	VisitExpNumber(ctx *ExpNumberContext) interface{}
 
@@ -87,6 +93,9 @@ type EqlVisitor interface {
 	// Visit a parse tree produced by EqlParser#ExpFunction.
 	VisitExpFunction(ctx *ExpFunctionContext) interface{}
 
+	// Visit a parse tree produced by EqlParser#ExpConditional.
+	VisitExpConditional(ctx *ExpConditionalContext) interface{}
+
 	// Visit a parse tree produced by EqlParser#ExpArithmeticLT.
 	VisitExpArithmeticLT(ctx *ExpArithmeticLTContext) interface{}
 
@@ -45,7 +45,7 @@ func (v *expVisitor) Visit(tree antlr.ParseTree) interface{} {
 		if v.hasErr() {
 			return nil
 		}
-		return r.(bool)
+		return r
 	default:
 		v.err = fmt.Errorf("unknown operation %T", tree.GetText())
 		return false
@@ -60,8 +60,7 @@ func (v *expVisitor) VisitExpList(ctx *parser.ExpListContext) interface{} {
 	if v.hasErr() {
 		return nil
 	}
-	r := ctx.Exp().Accept(v)
-	return r.(bool)
+	return ctx.Exp().Accept(v)
 }
 
 func (v *expVisitor) VisitExpArithmeticNEQ(ctx *parser.ExpArithmeticNEQContext) interface{} {
@@ -225,6 +224,69 @@ func (v *expVisitor) VisitBoolean(ctx *parser.BooleanContext) interface{} {
 	return ctx.TRUE() != nil
 }
 
+// VisitExpConditional evaluates the condition and then only the selected operand.
+func (v *expVisitor) VisitExpConditional(ctx *parser.ExpConditionalContext) interface{} {
+	cond := ctx.GetCond().Accept(v)
+	if v.hasErr() {
+		return nil
+	}
+	b, ok := cond.(bool)
+	if !ok {
+		v.err = fmt.Errorf("conditional: condition must evaluate to a boolean; received %T", cond)
+		return nil
+	}
+	if b {
+		return ctx.GetLeft().Accept(v)
+	}
+	return ctx.GetRight().Accept(v)
+}
+
+// VisitExpCoalesce returns the left operand when it is not Null, otherwise it evaluates the right operand.
+// Missing variables in the left operand evaluate to Null even when allowMissingVars is false.
+func (v *expVisitor) VisitExpCoalesce(ctx *parser.ExpCoalesceContext) interface{} {
+	allowMissingVars := v.allowMissingVars
+	v.allowMissingVars = true
+	r := ctx.GetLeft().Accept(v)
+	v.allowMissingVars = allowMissingVars
+	if v.hasErr() {
+		return nil
+	}
+	if r != Null {
+		return r
+	}
+	return ctx.GetRight().Accept(v)
+}
+
+// VisitExpPipe calls the function with the left operand as its first argument, followed by the
+// arguments of the pipe. The left operand is evaluated once and a Null operand is returned as is.
+func (v *expVisitor) VisitExpPipe(ctx *parser.ExpPipeContext) interface{} {
+	name := ctx.NAME().GetText()
+	method, ok := methods[name]
+	if !ok {
+		v.err = fmt.Errorf("call to unknown function %s", name)
+		return nil
+	}
+
+	left := ctx.GetLeft().Accept(v)
+	if v.hasErr() {
+		return nil
+	}
+	if left == Null {
+		return Null
+	}
+
+	args := []interface{}{left}
+	if ctx.Arguments() != nil {
+		args = append(args, ctx.Arguments().Accept(v).([]interface{})...)
+	}
+	val, err := method(args)
+	if err != nil {
+		v.err = err
+		return nil
+	}
+	return val
+}
+
 func (v *expVisitor) VisitArguments(ctx *parser.ArgumentsContext) interface{} {
 	var args []interface{}
 
@@ -26,6 +26,8 @@ const (
 	ActionTypePolicyChange = "POLICY_CHANGE"
 	// ActionTypePolicyReassign specifies policy reassign action.
 	ActionTypePolicyReassign = "POLICY_REASSIGN"
+	// ActionTypeRollback specifies rollback action.
+	ActionTypeRollback = "ROLLBACK"
 	// ActionTypeSettings specifies change of agent settings.
 	ActionTypeSettings = "SETTINGS"
 	// ActionTypeInputAction specifies agent action.
@@ -109,6 +111,8 @@ func NewAction(actionType string) Action {
 		action = &ActionPolicyChange{}
 	case ActionTypePolicyReassign:
 		action = &ActionPolicyReassign{}
+	case ActionTypeRollback:
+		action = &ActionRollback{}
 	case ActionTypeSettings:
 		action = &ActionSettings{}
 	case ActionTypeUnenroll:
@@ -253,6 +257,8 @@ type ActionUpgrade struct {
 	Data   ActionUpgradeData `json:"data,omitempty" mapstructure:"-"`
 	Signed *Signed           `json:"signed,omitempty" yaml:"signed,omitempty" mapstructure:"signed,omitempty"`
 	Err    error             `json:"-" yaml:"-" mapstructure:"-"`
+	// Response is the report of a dry-run, it is sent with the ack.
+	Response map[string]interface{} `json:"-" yaml:"-" mapstructure:"-"`
 }
 
 type ActionUpgradeData struct {
@@ -260,6 +266,8 @@ type ActionUpgradeData struct {
 	SourceURI string `json:"source_uri,omitempty" yaml:"source_uri,omitempty" mapstructure:"-"`
 	// TODO: update fleet open api schema
 	Retry int `json:"retry_attempt,omitempty" yaml:"retry_attempt,omitempty" mapstructure:"-"`
+	// DryRun runs the upgrade preflight checks without switching versions.
+	DryRun bool `json:"dry_run,omitempty" yaml:"dry_run,omitempty" mapstructure:"-"`
 }
 
 func (a *ActionUpgrade) String() string {
@@ -288,6 +296,9 @@ func (a *ActionUpgrade) AckEvent() AckEvent {
 		p, _ := json.Marshal(payload)
 		event.Payload = p
 	}
+	if a.Response != nil {
+		event.ActionResponse = a.Response
+	}
 	return event
 }
 
@@ -357,6 +368,41 @@ func (a *ActionUpgrade) MarshalMap() (map[string]interface{}, error) {
 	return res, err
 }
 
+// ActionRollback is a request for agent to rollback to the previous version kept on disk after an upgrade.
+type ActionRollback struct {
+	ActionID   string  `json:"id" yaml:"id" mapstructure:"id"`
+	ActionType string  `json:"type" yaml:"type" mapstructure:"type"`
+	Signed     *Signed `json:"signed,omitempty" yaml:"signed,omitempty" mapstructure:"signed,omitempty"`
+	Err        error   `json:"-" yaml:"-" mapstructure:"-"`
+}
+
+func (a *ActionRollback) String() string {
+	var s strings.Builder
+	s.WriteString("id: ")
+	s.WriteString(a.ActionID)
+	s.WriteString(", type: ")
+	s.WriteString(a.ActionType)
+	return s.String()
+}
+
+// Type returns the type of the Action.
+func (a *ActionRollback) Type() string {
+	return a.ActionType
+}
+
+// ID returns the ID of the Action.
+func (a *ActionRollback) ID() string {
+	return a.ActionID
+}
+
+func (a *ActionRollback) AckEvent() AckEvent {
+	event := newAckEvent(a.ActionID, a.ActionType)
+	if a.Err != nil {
+		event.Error = a.Err.Error()
+	}
+	return event
+}
+
 // ActionUnenroll is a request for agent to unhook from fleet.
 type ActionUnenroll struct {
 	ActionID   string  `json:"id" yaml:"id" mapstructure:"id"`
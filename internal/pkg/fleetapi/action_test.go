@@ -6,6 +6,7 @@ package fleetapi
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -114,6 +115,16 @@ func TestActionsUnmarshalJSON(t *testing.T) {
 		assert.Equal(t, "http://example.com", action.Data.SourceURI)
 		assert.Equal(t, 0, action.Data.Retry)
 	})
+	t.Run("ActionUpgrade dry run", func(t *testing.T) {
+		p := []byte(`[{"id":"testid","type":"UPGRADE","data":{"version":"1.2.3","dry_run":true}}]`)
+		a := &Actions{}
+		err := a.UnmarshalJSON(p)
+		require.Nil(t, err)
+		action, ok := (*a)[0].(*ActionUpgrade)
+		require.True(t, ok, "unable to cast action to specific type")
+		assert.Equal(t, "1.2.3", action.Data.Version)
+		assert.True(t, action.Data.DryRun)
+	})
 	t.Run("ActionPolicyChange no start time", func(t *testing.T) {
 		p := []byte(`[{"id":"testid","type":"POLICY_CHANGE","data":{"policy":{"key":"value"}}}]`)
 		a := &Actions{}
@@ -174,6 +185,16 @@ func TestActionsUnmarshalJSON(t *testing.T) {
 		require.Len(t, action.Data.AdditionalMetrics, 1)
 		assert.Equal(t, "CPU", action.Data.AdditionalMetrics[0])
 	})
+	t.Run("ActionRollback", func(t *testing.T) {
+		p := []byte(`[{"id":"testid","type":"ROLLBACK"}]`)
+		a := &Actions{}
+		err := a.UnmarshalJSON(p)
+		require.Nil(t, err)
+		action, ok := (*a)[0].(*ActionRollback)
+		require.True(t, ok, "unable to cast action to specific type")
+		assert.Equal(t, "testid", action.ActionID)
+		assert.Equal(t, ActionTypeRollback, action.ActionType)
+	})
 }
 
 func TestActionUnenrollMarshalMap(t *testing.T) {
@@ -205,6 +226,20 @@ func TestActionUnenrollMarshalMap(t *testing.T) {
 	}
 }
 
+func TestActionUpgradeAckEventResponse(t *testing.T) {
+	action := ActionUpgrade{
+		ActionID:   "164a6819-5c58-40f7-a33c-821c98ab0a8c",
+		ActionType: "UPGRADE",
+		Data:       ActionUpgradeData{Version: "9.1.0", DryRun: true},
+		Err:        errors.New("upgrade preflight checks failed"),
+		Response:   map[string]interface{}{"version": "9.1.0"},
+	}
+
+	event := action.AckEvent()
+	assert.Equal(t, "upgrade preflight checks failed", event.Error)
+	assert.Equal(t, map[string]interface{}{"version": "9.1.0"}, event.ActionResponse)
+}
+
 func TestActionUpgradeMarshalMap(t *testing.T) {
 	action := ActionUpgrade{
 		ActionID:   "164a6819-5c58-40f7-a33c-821c98ab0a8c",
@@ -0,0 +1,196 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package agentprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/elastic/elastic-agent/pkg/core/logger"
+	"github.com/elastic/elastic-agent/pkg/ipc"
+)
+
+// configAckTimeout is the time the collector has to acknowledge a configuration.
+const configAckTimeout = 30 * time.Second
+
+// configMessage delivers a configuration to the collector, the configuration is YAML encoded.
+type configMessage struct {
+	Config string `json:"config"`
+}
+
+// configAckMessage acknowledges a configuration, Error is set when the collector rejected it.
+type configAckMessage struct {
+	Error string `json:"error,omitempty"`
+}
+
+// ConfigServer serves the configuration of a supervised collector over a local socket. The collector connects to it
+// with a SocketProvider, it gets the current configuration when it connects and every configuration update after
+// that, which it applies without restarting.
+type ConfigServer struct {
+	log      *logger.Logger
+	address  string
+	listener net.Listener
+	done     chan struct{}
+
+	mu   sync.Mutex
+	cfg  []byte
+	conn *configConn
+}
+
+// configConn is the connection of the collector to the config server.
+type configConn struct {
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// NewConfigServer starts serving the configuration on the address, either a unix socket or a windows named pipe.
+func NewConfigServer(log *logger.Logger, address string, cfg *confmap.Conf) (*ConfigServer, error) {
+	cfgBytes, err := marshalConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := ipc.CreateListener(log, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collector config listener: %w", err)
+	}
+
+	s := &ConfigServer{
+		log:      log,
+		address:  address,
+		listener: listener,
+		done:     make(chan struct{}),
+		cfg:      cfgBytes,
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Address returns the address the configuration is served on.
+func (s *ConfigServer) Address() string {
+	return s.address
+}
+
+// Update sends the configuration to the collector and waits for the collector to acknowledge it. When the collector
+// is not connected yet the configuration is sent once it connects.
+func (s *ConfigServer) Update(ctx context.Context, cfg *confmap.Conf) error {
+	cfgBytes, err := marshalConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg = cfgBytes
+	if s.conn == nil {
+		return nil
+	}
+	return s.send(ctx, cfgBytes)
+}
+
+// Close stops serving the configuration and closes the connection of the collector.
+func (s *ConfigServer) Close() error {
+	err := s.listener.Close()
+	<-s.done
+
+	s.mu.Lock()
+	s.closeConn()
+	s.mu.Unlock()
+
+	ipc.CleanupListener(s.log, s.address)
+	return err
+}
+
+func (s *ConfigServer) serve() {
+	defer close(s.done)
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Errorf("failed to accept collector config connection: %v", err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		// only the latest collector connection gets configurations
+		s.closeConn()
+		s.conn = &configConn{
+			conn: conn,
+			enc:  json.NewEncoder(conn),
+			dec:  json.NewDecoder(conn),
+		}
+		if err := s.send(context.Background(), s.cfg); err != nil {
+			s.log.Errorf("failed to send configuration to collector: %v", err)
+			s.closeConn()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// send sends the configuration to the collector and waits for its acknowledgement. The connection is closed when the
+// exchange fails, a rejected configuration leaves it open. Must be called with mu held.
+func (s *ConfigServer) send(ctx context.Context, cfg []byte) error {
+	deadline := time.Now().Add(configAckTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	c := s.conn
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		s.closeConn()
+		return fmt.Errorf("failed to set collector config connection deadline: %w", err)
+	}
+	if err := c.enc.Encode(configMessage{Config: string(cfg)}); err != nil {
+		s.closeConn()
+		return fmt.Errorf("failed to send configuration to collector: %w", err)
+	}
+	var ack configAckMessage
+	if err := c.dec.Decode(&ack); err != nil {
+		s.closeConn()
+		return fmt.Errorf("failed to receive configuration acknowledgement from collector: %w", err)
+	}
+	if ack.Error != "" {
+		return fmt.Errorf("collector rejected configuration: %s", ack.Error)
+	}
+	return nil
+}
+
+// closeConn closes the connection of the collector. Must be called with mu held.
+func (s *ConfigServer) closeConn() {
+	if s.conn == nil {
+		return
+	}
+	_ = s.conn.conn.Close()
+	s.conn = nil
+}
+
+func marshalConfig(cfg *confmap.Conf) ([]byte, error) {
+	cfgBytes, err := yaml.Marshal(cfg.ToStringMap())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config to yaml: %w", err)
+	}
+	return cfgBytes, nil
+}
+
+func unmarshalConfig(cfgBytes []byte) (*confmap.Conf, error) {
+	retrieved, err := confmap.NewRetrievedFromYAML(cfgBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	conf, err := retrieved.AsConf()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert config to confmap: %w", err)
+	}
+	return conf, nil
+}
@@ -0,0 +1,244 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package agentprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/exporter/nopexporter"
+	"go.opentelemetry.io/collector/otelcol"
+	"go.opentelemetry.io/collector/receiver/nopreceiver"
+
+	"github.com/elastic/elastic-agent/pkg/core/logger/loggertest"
+	"github.com/elastic/elastic-agent/pkg/ipc"
+)
+
+func testConfigServerAddress(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("npipe:///elastic-agent-otel-config-%s", uuid.Must(uuid.NewV4()).String())
+	}
+	return "unix://" + filepath.Join(t.TempDir(), "otel-config.sock")
+}
+
+func startConfigServer(t *testing.T, cfg *confmap.Conf) *ConfigServer {
+	log, _ := loggertest.New("config-server")
+	s, err := NewConfigServer(log, testConfigServerAddress(t), cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.Close()
+	})
+	return s
+}
+
+func connectSocketProvider(t *testing.T, address string) *SocketProvider {
+	p := NewSocketProvider(address)
+	require.NoError(t, p.Connect(t.Context()))
+	t.Cleanup(func() {
+		_ = p.Shutdown(context.Background())
+	})
+	return p
+}
+
+func TestSocketProvider_Schema(t *testing.T) {
+	p := NewSocketProvider("")
+	assert.Equal(t, AgentConfigProviderSchemeName, p.Scheme())
+	assert.Equal(t, p.provider.URI(), p.URI())
+}
+
+func TestSocketProvider_Retrieve(t *testing.T) {
+	cfg := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"nop": map[string]any{}},
+	})
+	s := startConfigServer(t, cfg)
+	p := connectSocketProvider(t, s.Address())
+
+	ret, err := p.Retrieve(t.Context(), p.URI(), func(event *confmap.ChangeEvent) {})
+	require.NoError(t, err)
+	retCfg, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, cfg.ToStringMap(), retCfg.ToStringMap())
+}
+
+func TestSocketProvider_Retrieve_Update(t *testing.T) {
+	cfg := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"nop": map[string]any{}},
+	})
+	cfg2 := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"nop/2": map[string]any{}},
+	})
+	s := startConfigServer(t, cfg)
+	p := connectSocketProvider(t, s.Address())
+
+	// like the collector, retrieve the configuration when the watcher is notified
+	retCh := make(chan *confmap.Retrieved, 1)
+	_, err := p.Retrieve(t.Context(), p.URI(), func(event *confmap.ChangeEvent) {
+		ret, err := p.Retrieve(context.Background(), p.URI(), func(event *confmap.ChangeEvent) {})
+		assert.NoError(t, err)
+		retCh <- ret
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Update(t.Context(), cfg2))
+	select {
+	case ret := <-retCh:
+		retCfg, err := ret.AsConf()
+		require.NoError(t, err)
+		assert.Equal(t, cfg2.ToStringMap(), retCfg.ToStringMap())
+	default:
+		t.Fatal("the configuration was acknowledged before it was retrieved")
+	}
+}
+
+func TestSocketProvider_Update_CollectorStopped(t *testing.T) {
+	s := startConfigServer(t, confmap.New())
+	p := connectSocketProvider(t, s.Address())
+	_, err := p.Retrieve(t.Context(), p.URI(), func(event *confmap.ChangeEvent) {
+		// the collector fails to apply the configuration and stops
+		go p.CollectorStopped(errors.New("failed to build pipelines"))
+	})
+	require.NoError(t, err)
+
+	err = s.Update(t.Context(), confmap.New())
+	assert.EqualError(t, err, "collector rejected configuration: collector failed to apply the configuration: failed to build pipelines")
+}
+
+func TestSocketProvider_Collector(t *testing.T) {
+	newConfig := func(exporter string) *confmap.Conf {
+		return confmap.NewFromStringMap(map[string]any{
+			"receivers": map[string]any{"nop": map[string]any{}},
+			"exporters": map[string]any{"nop": map[string]any{}},
+			"service": map[string]any{
+				"telemetry": map[string]any{"metrics": map[string]any{"level": "none"}},
+				"pipelines": map[string]any{
+					"logs": map[string]any{
+						"receivers": []any{"nop"},
+						"exporters": []any{exporter},
+					},
+				},
+			},
+		})
+	}
+	s := startConfigServer(t, newConfig("nop"))
+	p := connectSocketProvider(t, s.Address())
+
+	col, err := otelcol.NewCollector(otelcol.CollectorSettings{
+		BuildInfo: component.NewDefaultBuildInfo(),
+		Factories: func() (otelcol.Factories, error) {
+			receivers, err := otelcol.MakeFactoryMap(nopreceiver.NewFactory())
+			if err != nil {
+				return otelcol.Factories{}, err
+			}
+			exporters, err := otelcol.MakeFactoryMap(nopexporter.NewFactory())
+			if err != nil {
+				return otelcol.Factories{}, err
+			}
+			return otelcol.Factories{Receivers: receivers, Exporters: exporters}, nil
+		},
+		ConfigProviderSettings: otelcol.ConfigProviderSettings{
+			ResolverSettings: confmap.ResolverSettings{
+				URIs:              []string{p.URI()},
+				ProviderFactories: []confmap.ProviderFactory{p.NewFactory()},
+			},
+		},
+	})
+	require.NoError(t, err)
+	p.CollectorStarted(col)
+	runErrCh := make(chan error, 1)
+	go func() {
+		err := col.Run(t.Context())
+		p.CollectorStopped(err)
+		runErrCh <- err
+	}()
+	require.Eventually(t, func() bool {
+		return col.GetState() == otelcol.StateRunning
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// a valid configuration is acknowledged once the collector runs with it
+	require.NoError(t, s.Update(t.Context(), newConfig("nop")))
+	assert.Equal(t, otelcol.StateRunning, col.GetState())
+
+	// the error of an invalid configuration is returned
+	err = s.Update(t.Context(), newConfig("missing"))
+	require.ErrorContains(t, err, "collector rejected configuration: collector failed to apply the configuration")
+	require.ErrorContains(t, err, "missing")
+	select {
+	case err := <-runErrCh:
+		assert.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("collector did not stop")
+	}
+}
+
+func TestSocketProvider_Retrieve_ConnectionClosed(t *testing.T) {
+	log, _ := loggertest.New("config-server")
+	address := testConfigServerAddress(t)
+	listener, err := ipc.CreateListener(log, address)
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		// close the connection without sending a configuration
+		conn, err := listener.Accept()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	p := connectSocketProvider(t, address)
+	_, err = p.Retrieve(t.Context(), p.URI(), func(event *confmap.ChangeEvent) {})
+	require.EqualError(t, err, "connection to agent config server closed before receiving a configuration")
+}
+
+func TestConfigServer_Update_NotConnected(t *testing.T) {
+	cfg := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"nop": map[string]any{}},
+	})
+	cfg2 := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{"nop/2": map[string]any{}},
+	})
+	s := startConfigServer(t, cfg)
+
+	// the collector gets the latest configuration when it connects
+	require.NoError(t, s.Update(t.Context(), cfg2))
+	p := connectSocketProvider(t, s.Address())
+	ret, err := p.Retrieve(t.Context(), p.URI(), func(event *confmap.ChangeEvent) {})
+	require.NoError(t, err)
+	retCfg, err := ret.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, cfg2.ToStringMap(), retCfg.ToStringMap())
+}
+
+func TestConfigServer_Update_Rejected(t *testing.T) {
+	s := startConfigServer(t, confmap.New())
+	conn, err := dialContext(t.Context(), s.Address())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// acknowledge the initial configuration, reject the following one
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	var msg configMessage
+	require.NoError(t, dec.Decode(&msg))
+	require.NoError(t, enc.Encode(configAckMessage{}))
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Update(context.Background(), confmap.New())
+	}()
+	require.NoError(t, dec.Decode(&msg))
+	require.NoError(t, enc.Encode(configAckMessage{Error: "invalid configuration"}))
+	assert.EqualError(t, <-errCh, "collector rejected configuration: invalid configuration")
+}
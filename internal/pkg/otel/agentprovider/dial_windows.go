@@ -0,0 +1,18 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+//go:build windows
+
+package agentprovider
+
+import (
+	"context"
+	"net"
+
+	"github.com/elastic/elastic-agent-libs/api/npipe"
+)
+
+func dialContext(ctx context.Context, address string) (net.Conn, error) {
+	return npipe.DialContext(npipe.TransformString(address))(ctx, "", "")
+}
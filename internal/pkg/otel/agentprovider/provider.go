@@ -49,9 +49,7 @@ func (p *Provider) NewFactory() confmap.ProviderFactory {
 
 // Update updates the latest configuration in the provider.
 func (p *Provider) Update(cfg *confmap.Conf) {
-	p.cfgMu.Lock()
-	p.cfg = cfg
-	p.cfgMu.Unlock()
+	p.setConfig(cfg)
 	select {
 	case p.updated <- struct{}{}:
 	default:
@@ -59,6 +57,13 @@ func (p *Provider) Update(cfg *confmap.Conf) {
 	}
 }
 
+// setConfig sets the configuration without notifying the watcher.
+func (p *Provider) setConfig(cfg *confmap.Conf) {
+	p.cfgMu.Lock()
+	p.cfg = cfg
+	p.cfgMu.Unlock()
+}
+
 // Retrieve returns the latest configuration.
 func (p *Provider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
 	if uri != p.uri {
@@ -0,0 +1,242 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package agentprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/otelcol"
+)
+
+const (
+	// reloadTimeout is the time the collector has to apply a configuration, it is shorter than configAckTimeout so
+	// the agent gets the reload error rather than an acknowledgement timeout.
+	reloadTimeout = 20 * time.Second
+
+	// reloadStatePollInterval is the interval the collector state is checked at while it reloads a configuration.
+	reloadStatePollInterval = 100 * time.Millisecond
+)
+
+var _ confmap.Provider = (*SocketProvider)(nil)
+
+// SocketProvider provides the configuration served by the agent ConfigServer over a local socket. The first
+// configuration is the one the collector starts with, the following ones notify the collector watcher so the
+// collector reloads them in place. A configuration is acknowledged once the collector applied it, or with the error
+// of the collector when the reload failed.
+type SocketProvider struct {
+	address  string
+	provider *Provider
+
+	connMu sync.Mutex
+	conn   net.Conn
+
+	// received is closed once the first configuration is received, or the connection is closed before that
+	received   chan struct{}
+	receiveErr error
+
+	// retrieved is notified every time the collector retrieves the configuration
+	retrieved chan struct{}
+
+	collectorMu sync.Mutex
+	collector   *otelcol.Collector
+
+	// stopped is closed once the collector stopped with stopErr
+	stopped chan struct{}
+	stopErr error
+
+	// reloadMu is held while a configuration is applied until it is acknowledged
+	reloadMu sync.Mutex
+}
+
+func NewSocketProvider(address string) *SocketProvider {
+	return &SocketProvider{
+		address:   address,
+		provider:  NewProvider(nil),
+		received:  make(chan struct{}),
+		retrieved: make(chan struct{}, 1),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// Connect connects to the agent config server and starts receiving configurations.
+func (p *SocketProvider) Connect(ctx context.Context) error {
+	conn, err := dialContext(ctx, p.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to agent config server %s: %w", p.address, err)
+	}
+	p.connMu.Lock()
+	p.conn = conn
+	p.connMu.Unlock()
+
+	go p.receive(conn)
+	return nil
+}
+
+func (p *SocketProvider) NewFactory() confmap.ProviderFactory {
+	return confmap.NewProviderFactory(func(_ confmap.ProviderSettings) confmap.Provider {
+		return p
+	})
+}
+
+func (p *SocketProvider) Retrieve(ctx context.Context, uri string, watcher confmap.WatcherFunc) (*confmap.Retrieved, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.received:
+	}
+	if p.receiveErr != nil {
+		return nil, p.receiveErr
+	}
+	ret, err := p.provider.Retrieve(ctx, uri, watcher)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case p.retrieved <- struct{}{}:
+	default:
+	}
+	return ret, nil
+}
+
+func (p *SocketProvider) Scheme() string {
+	return AgentConfigProviderSchemeName
+}
+
+func (p *SocketProvider) Shutdown(ctx context.Context) error {
+	p.connMu.Lock()
+	if p.conn != nil {
+		_ = p.conn.Close()
+		p.conn = nil
+	}
+	p.connMu.Unlock()
+	return p.provider.Shutdown(ctx)
+}
+
+func (p *SocketProvider) URI() string {
+	return p.provider.URI()
+}
+
+// CollectorStarted sets the collector the configurations are applied to, its state tells when a reload succeeded.
+// Without collector a configuration is acknowledged once it is retrieved.
+func (p *SocketProvider) CollectorStarted(col *otelcol.Collector) {
+	p.collectorMu.Lock()
+	p.collector = col
+	p.collectorMu.Unlock()
+}
+
+// CollectorStopped fails the configuration being applied with the error the collector stopped with, and waits for
+// it to be acknowledged so the agent gets the error before the collector exits.
+func (p *SocketProvider) CollectorStopped(err error) {
+	p.stopErr = err
+	close(p.stopped)
+
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+}
+
+// receive receives the configurations sent by the agent until the connection is closed, and acknowledges them.
+func (p *SocketProvider) receive(conn net.Conn) {
+	enc := json.NewEncoder(conn)
+	dec := json.NewDecoder(conn)
+	first := true
+	defer func() {
+		if first {
+			p.receiveErr = errors.New("connection to agent config server closed before receiving a configuration")
+			close(p.received)
+		}
+	}()
+
+	for {
+		var msg configMessage
+		if err := dec.Decode(&msg); err != nil {
+			return
+		}
+
+		cfg, err := unmarshalConfig([]byte(msg.Config))
+		switch {
+		case err != nil:
+			err = enc.Encode(configAckMessage{Error: err.Error()})
+		case first:
+			// the collector starts with the first configuration
+			p.provider.setConfig(cfg)
+			first = false
+			close(p.received)
+			err = enc.Encode(configAckMessage{})
+		default:
+			err = p.reloadAndAck(enc, cfg)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// reloadAndAck applies the configuration to the collector and acknowledges it with the result.
+func (p *SocketProvider) reloadAndAck(enc *json.Encoder, cfg *confmap.Conf) error {
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	var ack configAckMessage
+	if err := p.reload(cfg); err != nil {
+		ack.Error = err.Error()
+	}
+	return enc.Encode(ack)
+}
+
+// reload notifies the collector watcher of the configuration and waits for the collector to retrieve it and to run
+// with it. It returns the error the collector stopped with when it failed to apply it.
+func (p *SocketProvider) reload(cfg *confmap.Conf) error {
+	// forget a retrieval of a previous configuration
+	select {
+	case <-p.retrieved:
+	default:
+	}
+	p.provider.Update(cfg)
+
+	timeout := time.NewTimer(reloadTimeout)
+	defer timeout.Stop()
+	select {
+	case <-p.retrieved:
+	case <-p.stopped:
+		return p.stoppedError()
+	case <-timeout.C:
+		return fmt.Errorf("collector did not retrieve the configuration within %s", reloadTimeout)
+	}
+
+	p.collectorMu.Lock()
+	col := p.collector
+	p.collectorMu.Unlock()
+	if col == nil {
+		return nil
+	}
+
+	// the collector is starting from the retrieval of the configuration until its pipelines run with it
+	ticker := time.NewTicker(reloadStatePollInterval)
+	defer ticker.Stop()
+	for col.GetState() != otelcol.StateRunning {
+		select {
+		case <-p.stopped:
+			return p.stoppedError()
+		case <-timeout.C:
+			return fmt.Errorf("collector did not apply the configuration within %s", reloadTimeout)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (p *SocketProvider) stoppedError() error {
+	if p.stopErr != nil {
+		return fmt.Errorf("collector failed to apply the configuration: %w", p.stopErr)
+	}
+	return errors.New("collector stopped before applying the configuration")
+}
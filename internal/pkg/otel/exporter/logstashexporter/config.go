@@ -0,0 +1,63 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstashexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+const defaultPort = "5044"
+
+// Config is the configuration of the logstash exporter, it mirrors the settings of the logstash output.
+type Config struct {
+	// Hosts are the logstash hosts, the default port 5044 is used when a host has no port.
+	Hosts []string `mapstructure:"hosts"`
+	// LoadBalance sends the batches to all the hosts in turn instead of failing over to the next host on errors.
+	LoadBalance bool `mapstructure:"loadbalance"`
+	// Timeout is the timeout of the network operations with logstash.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CompressionLevel is the gzip compression level of the batches, 0 disables compression.
+	CompressionLevel int `mapstructure:"compression_level"`
+	// BulkMaxSize is the maximum number of events sent to logstash in a single batch.
+	BulkMaxSize int `mapstructure:"bulk_max_size"`
+	// TLS enables TLS when set, the connections are plain TCP otherwise.
+	TLS *configtls.ClientConfig `mapstructure:"tls"`
+
+	QueueSettings exporterhelper.QueueBatchConfig `mapstructure:"sending_queue"`
+	BackOffConfig configretry.BackOffConfig       `mapstructure:"retry_on_failure"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		Timeout:          30 * time.Second,
+		CompressionLevel: 3,
+		BulkMaxSize:      2048,
+		QueueSettings:    exporterhelper.NewDefaultQueueConfig(),
+		BackOffConfig:    configretry.NewDefaultBackOffConfig(),
+	}
+}
+
+// Validate checks the exporter configuration.
+func (c *Config) Validate() error {
+	if len(c.Hosts) == 0 {
+		return errors.New("hosts are required")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	if c.CompressionLevel < 0 || c.CompressionLevel > 9 {
+		return fmt.Errorf("compression_level must be between 0 and 9, got %d", c.CompressionLevel)
+	}
+	if c.BulkMaxSize <= 0 {
+		return fmt.Errorf("bulk_max_size must be positive, got %d", c.BulkMaxSize)
+	}
+	return nil
+}
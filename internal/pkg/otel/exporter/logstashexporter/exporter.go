@@ -0,0 +1,212 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstashexporter
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	v2 "github.com/elastic/go-lumber/client/v2"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+type logstashExporter struct {
+	cfg    *Config
+	logger *zap.Logger
+
+	tlsConfig *tls.Config
+
+	// mx protects the clients and next, batches are sent one at a time
+	mx      sync.Mutex
+	clients []*v2.SyncClient
+	// next is the index of the host the next batch is sent to
+	next int
+}
+
+func newLogstashExporter(cfg *Config, logger *zap.Logger) *logstashExporter {
+	return &logstashExporter{
+		cfg:     cfg,
+		logger:  logger,
+		clients: make([]*v2.SyncClient, len(cfg.Hosts)),
+	}
+}
+
+func (e *logstashExporter) start(ctx context.Context, _ component.Host) error {
+	if e.cfg.TLS == nil {
+		return nil
+	}
+	tlsConfig, err := e.cfg.TLS.LoadTLSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS configuration: %w", err)
+	}
+	e.tlsConfig = tlsConfig
+	return nil
+}
+
+func (e *logstashExporter) shutdown(_ context.Context) error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	var errs []error
+	for i := range e.clients {
+		errs = append(errs, e.closeClient(i))
+	}
+	return errors.Join(errs...)
+}
+
+// pushLogs sends the body of the log records to logstash in batches of at most BulkMaxSize events. Without load
+// balancing the batches are sent to the same host until it fails, the next host is then used. When a batch fails
+// after others were acknowledged, only the log records that were not acknowledged are returned to be retried.
+func (e *logstashExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	events := logsToEvents(ld)
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	sent := 0
+	for sent < len(events) {
+		size := min(len(events)-sent, e.cfg.BulkMaxSize)
+		if err := e.send(events[sent : sent+size]); err != nil {
+			if sent == 0 {
+				return err
+			}
+			return consumererror.NewLogs(err, logsAfter(ld, sent))
+		}
+		sent += size
+	}
+	return nil
+}
+
+// send sends a batch to the current host and fails over to the other hosts in turn until one of them acknowledges it.
+func (e *logstashExporter) send(batch []any) error {
+	var errs []error
+	for range e.clients {
+		i := e.next
+		err := e.sendTo(i, batch)
+		if err == nil {
+			if e.cfg.LoadBalance {
+				e.next = (i + 1) % len(e.clients)
+			}
+			return nil
+		}
+		e.logger.Warn("Failed to send events to logstash", zap.String("host", e.cfg.Hosts[i]), zap.Error(err))
+		errs = append(errs, fmt.Errorf("host %s: %w", e.cfg.Hosts[i], err))
+		e.next = (i + 1) % len(e.clients)
+	}
+	return fmt.Errorf("failed to send %d events to logstash: %w", len(batch), errors.Join(errs...))
+}
+
+func (e *logstashExporter) sendTo(i int, batch []any) error {
+	if e.clients[i] == nil {
+		client, err := v2.SyncDialWith(
+			e.dial,
+			hostWithPort(e.cfg.Hosts[i]),
+			v2.Timeout(e.cfg.Timeout),
+			v2.CompressionLevel(e.cfg.CompressionLevel),
+		)
+		if err != nil {
+			return err
+		}
+		e.clients[i] = client
+	}
+
+	// a batch is acknowledged as a whole, a partial acknowledgement resends the batch
+	n, err := e.clients[i].Send(batch)
+	if err == nil && n < len(batch) {
+		err = fmt.Errorf("only %d of %d events were acknowledged", n, len(batch))
+	}
+	if err != nil {
+		// the connection can't be reused after an error, it is dialed again on the next send
+		_ = e.closeClient(i)
+		return err
+	}
+	return nil
+}
+
+func (e *logstashExporter) closeClient(i int) error {
+	if e.clients[i] == nil {
+		return nil
+	}
+	err := e.clients[i].Close()
+	e.clients[i] = nil
+	return err
+}
+
+func (e *logstashExporter) dial(network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: e.cfg.Timeout}
+	if e.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, network, address, e.tlsConfig)
+	}
+	return dialer.Dial(network, address)
+}
+
+// logsToEvents returns the body of the log records as logstash events. The timestamp of the log record is added as
+// the @timestamp of the event when the body has none.
+func logsToEvents(ld plog.Logs) []any {
+	events := make([]any, 0, ld.LogRecordCount())
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, lr := range sl.LogRecords().All() {
+				events = append(events, logRecordToEvent(lr))
+			}
+		}
+	}
+	return events
+}
+
+// logsAfter returns a copy of ld without its first n log records.
+func logsAfter(ld plog.Logs, n int) plog.Logs {
+	rest := plog.NewLogs()
+	ld.CopyTo(rest)
+
+	skipped := 0
+	rest.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+				skipped++
+				return skipped <= n
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	return rest
+}
+
+func logRecordToEvent(lr plog.LogRecord) map[string]any {
+	var event map[string]any
+	if lr.Body().Type() == pcommon.ValueTypeMap {
+		event = lr.Body().Map().AsRaw()
+	} else {
+		event = map[string]any{"message": lr.Body().AsString()}
+	}
+
+	if _, ok := event["@timestamp"]; !ok {
+		ts := lr.Timestamp()
+		if ts == 0 {
+			ts = lr.ObservedTimestamp()
+		}
+		if ts != 0 {
+			event["@timestamp"] = ts.AsTime().UTC()
+		}
+	}
+	return event
+}
+
+// hostWithPort adds the default logstash port to the hosts without a port.
+func hostWithPort(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, defaultPort)
+}
@@ -0,0 +1,181 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstashexporter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	v2 "github.com/elastic/go-lumber/server/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestCreateLogsExporter(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	require.ErrorContains(t, cfg.Validate(), "hosts are required")
+
+	cfg.Hosts = []string{"localhost"}
+	require.NoError(t, cfg.Validate())
+
+	exp, err := factory.CreateLogs(context.Background(), exportertest.NewNopSettings(Type), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, exp)
+	require.NoError(t, exp.Shutdown(context.Background()))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Hosts = []string{"localhost:5044"}
+
+	cfg.CompressionLevel = 10
+	assert.EqualError(t, cfg.Validate(), "compression_level must be between 0 and 9, got 10")
+
+	cfg.CompressionLevel = 3
+	cfg.BulkMaxSize = 0
+	assert.EqualError(t, cfg.Validate(), "bulk_max_size must be positive, got 0")
+}
+
+func TestPushLogs(t *testing.T) {
+	server := newTestServer(t)
+
+	cfg := createDefaultConfig()
+	cfg.Hosts = []string{server.addr}
+	cfg.BulkMaxSize = 2
+	exp := newLogstashExporter(cfg, zap.NewNop())
+	t.Cleanup(func() { _ = exp.shutdown(context.Background()) })
+
+	ts := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, msg := range []string{"first", "second", "third"} {
+		lr := records.AppendEmpty()
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+		lr.Body().SetEmptyMap().PutStr("message", msg)
+	}
+
+	pushErr := make(chan error, 1)
+	go func() {
+		pushErr <- exp.pushLogs(context.Background(), ld)
+	}()
+
+	// the events are sent in batches of bulk_max_size events
+	var messages []any
+	for _, expected := range []int{2, 1} {
+		events := server.receive(t)
+		require.Len(t, events, expected)
+		for _, event := range events {
+			fields, ok := event.(map[string]any)
+			require.True(t, ok, "unexpected event type %T", event)
+			assert.Equal(t, "2025-06-30T12:00:00Z", fields["@timestamp"])
+			messages = append(messages, fields["message"])
+		}
+	}
+	require.NoError(t, <-pushErr)
+	assert.Equal(t, []any{"first", "second", "third"}, messages)
+}
+
+func TestPushLogsPartialFailure(t *testing.T) {
+	server := newTestServer(t)
+
+	cfg := createDefaultConfig()
+	cfg.Hosts = []string{server.addr}
+	cfg.BulkMaxSize = 2
+	cfg.Timeout = time.Second
+	exp := newLogstashExporter(cfg, zap.NewNop())
+	t.Cleanup(func() { _ = exp.shutdown(context.Background()) })
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, msg := range []string{"first", "second", "third"} {
+		records.AppendEmpty().Body().SetStr(msg)
+	}
+
+	pushErr := make(chan error, 1)
+	go func() {
+		pushErr <- exp.pushLogs(context.Background(), ld)
+	}()
+
+	// the first batch is acknowledged, the second one times out without being acknowledged
+	require.Len(t, server.receive(t), 2)
+	select {
+	case batch := <-server.server.ReceiveChan():
+		require.Len(t, batch.Events, 1)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for the second batch")
+	}
+
+	// only the records of the failed batch are retried
+	var logsErr consumererror.Logs
+	require.ErrorAs(t, <-pushErr, &logsErr)
+	unsent := logsErr.Data()
+	require.Equal(t, 1, unsent.LogRecordCount())
+	assert.Equal(t, "third", unsent.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	assert.Equal(t, 3, ld.LogRecordCount(), "the pushed logs must not change")
+}
+
+func TestPushLogsFailover(t *testing.T) {
+	// nothing listens on the first host anymore
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unavailable := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	server := newTestServer(t)
+
+	cfg := createDefaultConfig()
+	cfg.Hosts = []string{unavailable, server.addr}
+	cfg.Timeout = time.Second
+	exp := newLogstashExporter(cfg, zap.NewNop())
+	t.Cleanup(func() { _ = exp.shutdown(context.Background()) })
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("failover")
+
+	pushErr := make(chan error, 1)
+	go func() {
+		pushErr <- exp.pushLogs(context.Background(), ld)
+	}()
+
+	events := server.receive(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, "failover", events[0].(map[string]any)["message"])
+	require.NoError(t, <-pushErr)
+	assert.Equal(t, 1, exp.next, "the next batches must be sent to the available host")
+}
+
+type testServer struct {
+	addr   string
+	server *v2.Server
+}
+
+func newTestServer(t *testing.T) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server, err := v2.NewWithListener(l)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = server.Close() })
+	return &testServer{addr: l.Addr().String(), server: server}
+}
+
+// receive returns the events of the next batch received by the server and acknowledges the batch.
+func (s *testServer) receive(t *testing.T) []any {
+	select {
+	case batch := <-s.server.ReceiveChan():
+		batch.ACK()
+		return batch.Events
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for a batch")
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logstashexporter
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Type is the type of the logstash exporter.
+var Type = component.MustNewType("logstash")
+
+// NewFactory returns the factory of the logstash exporter. The exporter sends the body of the log records to logstash
+// with the lumberjack protocol, like the logstash output of the beats.
+func NewFactory() exporter.Factory {
+	return exporter.NewFactory(
+		Type,
+		func() component.Config {
+			return createDefaultConfig()
+		},
+		exporter.WithLogs(createLogsExporter, component.StabilityLevelDevelopment),
+	)
+}
+
+func createLogsExporter(ctx context.Context, set exporter.Settings, cfg component.Config) (exporter.Logs, error) {
+	lsCfg, ok := cfg.(*Config)
+	if !ok {
+		return nil, fmt.Errorf("unexpected logstash exporter configuration type %T", cfg)
+	}
+
+	exp := newLogstashExporter(lsCfg, set.Logger)
+	return exporterhelper.NewLogs(
+		ctx,
+		set,
+		cfg,
+		exp.pushLogs,
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// the timeout of the network operations is handled by the lumberjack clients
+		exporterhelper.WithTimeout(exporterhelper.TimeoutConfig{Timeout: 0}),
+		exporterhelper.WithQueue(lsCfg.QueueSettings),
+		exporterhelper.WithRetry(lsCfg.BackOffConfig),
+	)
+}
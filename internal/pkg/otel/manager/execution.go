@@ -6,6 +6,7 @@ package manager
 
 import (
 	"context"
+	"errors"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
 	"go.opentelemetry.io/collector/confmap"
@@ -17,6 +18,12 @@ type collectorExecution interface {
 	startCollector(ctx context.Context, logger *logger.Logger, cfg *confmap.Conf, errCh chan error, statusCh chan *status.AggregateStatus) (collectorHandle, error)
 }
 
+// errReloadUnsupported is returned by collector handles that cannot reload their configuration in place.
+var errReloadUnsupported = errors.New("collector does not support reloading its configuration")
+
 type collectorHandle interface {
 	Stop(ctx context.Context)
+	// Reload applies the configuration to the running collector without restarting it. When it returns an error the
+	// collector has to be restarted to apply the configuration.
+	Reload(ctx context.Context, cfg *confmap.Conf) error
 }
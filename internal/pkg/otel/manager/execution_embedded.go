@@ -65,6 +65,11 @@ type ctxHandle struct {
 	cancel          context.CancelFunc
 }
 
+// Reload is not supported, the embedded collector is restarted on configuration changes.
+func (s *ctxHandle) Reload(context.Context, *confmap.Conf) error {
+	return errReloadUnsupported
+}
+
 // Stop stops the collector
 func (s *ctxHandle) Stop(ctx context.Context) {
 	if s.cancel == nil {
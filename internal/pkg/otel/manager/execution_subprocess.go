@@ -9,12 +9,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
 	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/confmap"
@@ -22,9 +25,12 @@ import (
 
 	"github.com/elastic/elastic-agent-libs/logp"
 
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/otel/agentprovider"
 	runtimeLogger "github.com/elastic/elastic-agent/pkg/component/runtime"
 	"github.com/elastic/elastic-agent/pkg/core/logger"
 	"github.com/elastic/elastic-agent/pkg/core/process"
+	"github.com/elastic/elastic-agent/pkg/utils"
 )
 
 const (
@@ -32,6 +38,7 @@ const (
 
 	OtelSetSupervisedFlagName          = "supervised"
 	OtelSupervisedLoggingLevelFlagName = "supervised.logging.level"
+	OtelSupervisedConfigSocketFlagName = "supervised.config.socket"
 )
 
 func newSubprocessExecution(logLevel logp.Level, collectorPath string) *subprocessExecution {
@@ -79,10 +86,27 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 		return nil, fmt.Errorf("failed to inject health check extension: %w", err)
 	}
 
-	confMap := cfg.ToStringMap()
-	confBytes, err := yaml.Marshal(confMap)
+	// the configuration is served over a local socket so configuration changes are applied without restarting the
+	// collector, when the socket cannot be created the configuration is passed on stdin
+	collectorArgs := r.collectorArgs
+	var stdin io.Reader
+	configServerAddress := utils.SocketURLWithFallback(uuid.Must(uuid.NewV4()).String(), paths.TempDir())
+	configServer, err := agentprovider.NewConfigServer(logger, configServerAddress, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal config to yaml: %w", err)
+		logger.Warnf("failed to serve the supervised collector configuration, configuration changes will restart the collector: %v", err)
+		confMap := cfg.ToStringMap()
+		confBytes, err := yaml.Marshal(confMap)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal config to yaml: %w", err)
+		}
+		stdin = bytes.NewReader(confBytes)
+	} else {
+		collectorArgs = append(slices.Clone(collectorArgs), fmt.Sprintf("--%s=%s", OtelSupervisedConfigSocketFlagName, configServer.Address()))
+	}
+	closeConfigServer := func() {
+		if configServer != nil {
+			_ = configServer.Close()
+		}
 	}
 
 	stdOut := runtimeLogger.NewLogWriterWithDefaults(logger.Core(), zapcore.Level(r.logLevel))
@@ -91,11 +115,11 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 
 	procCtx, procCtxCancel := context.WithCancel(ctx)
 	processInfo, err := process.Start(r.collectorPath,
-		process.WithArgs(r.collectorArgs),
+		process.WithArgs(collectorArgs),
 		process.WithContext(procCtx),
 		process.WithEnv(os.Environ()),
 		process.WithCmdOptions(func(c *exec.Cmd) error {
-			c.Stdin = bytes.NewReader(confBytes)
+			c.Stdin = stdin
 			c.Stdout = stdOut
 			c.Stderr = stdErr
 			return nil
@@ -104,18 +128,23 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 	if err != nil {
 		// we failed to start the process
 		procCtxCancel()
+		closeConfigServer()
 		return nil, fmt.Errorf("failed to start supervised collector: %w", err)
 	}
 	logger.Infof("supervised collector started with pid: %d and healthcheck port: %d", processInfo.Process.Pid, httpHealthCheckPort)
 	if processInfo.Process == nil {
 		// this should not happen but just in case
 		procCtxCancel()
+		closeConfigServer()
 		return nil, fmt.Errorf("failed to start supervised collector: process is nil")
 	}
 
 	ctl := &procHandle{
-		processDoneCh: make(chan struct{}),
-		processInfo:   processInfo,
+		processDoneCh:       make(chan struct{}),
+		processInfo:         processInfo,
+		configServer:        configServer,
+		httpHealthCheckPort: httpHealthCheckPort,
+		reloadedCh:          make(chan struct{}, 1),
 	}
 
 	healthCheckDone := make(chan struct{})
@@ -156,6 +185,10 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 			case <-procCtx.Done():
 				reportStatus(ctx, statusCh, aggregateStatus(componentstatus.StatusStopped, nil))
 				return
+			case <-ctl.reloadedCh:
+				// the collector rebuilds its pipelines when it reloads the configuration, report the status again
+				currentStatus = aggregateStatus(componentstatus.StatusStarting, nil)
+				reportStatus(procCtx, statusCh, currentStatus)
 			case <-healthCheckPollTimer.C:
 				healthCheckPollTimer.Reset(healthCheckPollDuration)
 			case <-maxFailuresTimer.C:
@@ -174,6 +207,7 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 	go func() {
 		procState, procErr := processInfo.Process.Wait()
 		procCtxCancel()
+		closeConfigServer()
 		<-healthCheckDone
 		close(ctl.processDoneCh)
 		// using ctx instead of procCtx in the reportErr functions below is intentional. This allows us to report
@@ -196,8 +230,38 @@ func (r *subprocessExecution) startCollector(ctx context.Context, logger *logger
 }
 
 type procHandle struct {
-	processDoneCh chan struct{}
-	processInfo   *process.Info
+	processDoneCh       chan struct{}
+	processInfo         *process.Info
+	configServer        *agentprovider.ConfigServer
+	httpHealthCheckPort int
+	reloadedCh          chan struct{}
+}
+
+// Reload sends the configuration to the collector which reloads it without restarting. It returns once the collector
+// runs with the configuration, or with the error the collector failed to apply it with, the collector then exits and
+// has to be restarted with the configuration.
+func (s *procHandle) Reload(ctx context.Context, cfg *confmap.Conf) error {
+	if s.configServer == nil {
+		return errReloadUnsupported
+	}
+	select {
+	case <-s.processDoneCh:
+		return errors.New("supervised collector is not running")
+	default:
+	}
+
+	if err := injectHeathCheckV2Extension(cfg, s.httpHealthCheckPort); err != nil {
+		return fmt.Errorf("failed to inject health check extension: %w", err)
+	}
+	if err := s.configServer.Update(ctx, cfg); err != nil {
+		return err
+	}
+	select {
+	case s.reloadedCh <- struct{}{}:
+	default:
+		// a reload is already pending
+	}
+	return nil
 }
 
 // Stop stops the process. If the process is already stopped, it does nothing. If the process does not stop within
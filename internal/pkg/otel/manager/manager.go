@@ -218,6 +218,18 @@ func (m *OTelManager) Run(ctx context.Context) error {
 			m.recoveryRetries.Store(0)
 			m.cfg = cfg
 
+			if proc != nil && cfg != nil {
+				// apply the configuration to the running collector, it is restarted only when it cannot reload it
+				reloadErr := proc.Reload(ctx, cfg)
+				if reloadErr == nil {
+					reportErr(ctx, m.errCh, nil)
+					continue
+				}
+				if !errors.Is(reloadErr, errReloadUnsupported) {
+					m.logger.Warnf("collector failed to reload the configuration, restarting it: %v", reloadErr)
+				}
+			}
+
 			if proc != nil {
 				proc.Stop(ctx)
 				proc = nil
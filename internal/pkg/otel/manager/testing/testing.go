@@ -8,9 +8,11 @@ import (
 	"context"
 	"errors"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/cmd"
+	"github.com/elastic/elastic-agent/internal/pkg/otel/manager"
 )
 
 func main() {
@@ -28,7 +30,15 @@ func main() {
 		})
 	}
 
-	err := cmd.RunCollector(ctx, nil, true, "debug")
+	// the config socket is the only argument of the supervised collector this binary needs
+	var configSocket string
+	for _, arg := range os.Args[1:] {
+		if socket, ok := strings.CutPrefix(arg, "--"+manager.OtelSupervisedConfigSocketFlagName+"="); ok {
+			configSocket = socket
+		}
+	}
+
+	err := cmd.RunCollector(ctx, nil, true, "debug", configSocket)
 	if err == nil || errors.Is(err, context.Canceled) {
 		os.Exit(0)
 	}
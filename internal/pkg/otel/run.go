@@ -23,12 +23,28 @@ import (
 
 const buildDescription = "Elastic opentelemetry-collector distribution"
 
-func Run(ctx context.Context, stop chan bool, settings *otelcol.CollectorSettings) error {
+// CollectorObserver observes the collector run by Run.
+type CollectorObserver interface {
+	// CollectorStarted is called with the collector before it runs.
+	CollectorStarted(col *otelcol.Collector)
+	// CollectorStopped is called with the error the collector stopped with.
+	CollectorStopped(err error)
+}
+
+func Run(ctx context.Context, stop chan bool, settings *otelcol.CollectorSettings, observers ...CollectorObserver) (err error) {
 	fmt.Fprintln(os.Stdout, "Starting in otel mode")
 	svc, err := otelcol.NewCollector(*settings)
 	if err != nil {
 		return err
 	}
+	for _, observer := range observers {
+		observer.CollectorStarted(svc)
+	}
+	defer func() {
+		for _, observer := range observers {
+			observer.CollectorStopped(err)
+		}
+	}()
 
 	// cancel context on stop from event manager
 	cancelCtx, cancel := context.WithCancel(ctx)
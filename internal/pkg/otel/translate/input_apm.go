@@ -0,0 +1,89 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+
+	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+	"github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent/pkg/component"
+)
+
+const apmInputType = "apm"
+
+// otlpReceiverType is the type of the receiver apm inputs are translated to. It only serves the OTLP intake over HTTP
+// on the apm-server host and emits logs, metrics and traces. The intake of the APM agents, RUM and the routing of APM
+// Server to its data streams are not translated, apm inputs only run in the otel runtime when it is set explicitly.
+var otlpReceiverType = otelcomponent.MustNewType("otlp")
+
+// apmInputSupportedSettings are the apm-server settings of the apm input translated to the otlp receiver. Other
+// settings are rejected, the otlp receiver only serves the OTLP intake over HTTP, not the intake of the APM agents.
+var apmInputSupportedSettings = []string{
+	"host",
+}
+
+// apmInputConfig is the part of the apm input configuration translated to the otlp receiver. Defaults are the
+// APM Server defaults.
+type apmInputConfig struct {
+	APMServer struct {
+		Host string `config:"host"`
+	} `config:"apm-server"`
+}
+
+// getOTLPReceiversConfigForComponent returns the otlp receiver configuration for a component with an apm input.
+// APM Server runs a single server per process, a component can only have one apm input.
+func getOTLPReceiversConfigForComponent(comp *component.Component) (map[string]any, error) {
+	var inputUnits []component.Unit
+	for _, unit := range comp.Units {
+		if unit.Type == client.UnitTypeInput {
+			inputUnits = append(inputUnits, unit)
+		}
+	}
+	if len(inputUnits) != 1 {
+		return nil, fmt.Errorf("expected a single apm input for component %s, found %d", comp.ID, len(inputUnits))
+	}
+
+	receiverConfig, err := translateAPMInputToReceiver(inputUnits[0])
+	if err != nil {
+		return nil, fmt.Errorf("error translating config for input unit: %s, error: %w", inputUnits[0].ID, err)
+	}
+	return map[string]any{
+		getReceiverID(otlpReceiverType, comp.ID).String(): receiverConfig,
+	}, nil
+}
+
+// translateAPMInputToReceiver translates an apm input unit to an otlp receiver configuration.
+func translateAPMInputToReceiver(unit component.Unit) (map[string]any, error) {
+	inputCfg, err := config.NewConfigFrom(unit.Config.GetSource().AsMap())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range inputCfg.FlattenedKeys() {
+		setting, found := strings.CutPrefix(arrayIndexRegexp.ReplaceAllString(key, "$1"), "apm-server.")
+		if found && !slices.Contains(apmInputSupportedSettings, setting) {
+			return nil, fmt.Errorf("apm input setting \"apm-server.%s\" is not supported by the otel runtime", setting)
+		}
+	}
+
+	apmCfg := apmInputConfig{}
+	apmCfg.APMServer.Host = "localhost:8200"
+	if err := inputCfg.Unpack(&apmCfg); err != nil {
+		return nil, fmt.Errorf("error unpacking apm input configuration: %w", err)
+	}
+
+	return map[string]any{
+		"protocols": map[string]any{
+			"http": map[string]any{
+				"endpoint": apmCfg.APMServer.Host,
+			},
+		},
+	}, nil
+}
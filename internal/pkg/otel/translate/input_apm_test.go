@@ -0,0 +1,78 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+	"github.com/elastic/elastic-agent/pkg/component"
+)
+
+func TestTranslateAPMInputToReceiver(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputCfg       map[string]any
+		expectedCfg    map[string]any
+		expectedErrMsg string
+	}{
+		{
+			name: "defaults",
+			inputCfg: map[string]any{
+				"id":         "apm-input",
+				"type":       "apm",
+				"use_output": "default",
+			},
+			expectedCfg: map[string]any{
+				"protocols": map[string]any{
+					"http": map[string]any{"endpoint": "localhost:8200"},
+				},
+			},
+		},
+		{
+			name: "host",
+			inputCfg: map[string]any{
+				"type":       "apm",
+				"apm-server": map[string]any{"host": "0.0.0.0:8200"},
+			},
+			expectedCfg: map[string]any{
+				"protocols": map[string]any{
+					"http": map[string]any{"endpoint": "0.0.0.0:8200"},
+				},
+			},
+		},
+		{
+			name: "unsupported setting",
+			inputCfg: map[string]any{
+				"type": "apm",
+				"apm-server": map[string]any{
+					"host": "0.0.0.0:8200",
+					"auth": map[string]any{"secret_token": "secret"},
+				},
+			},
+			expectedErrMsg: `apm input setting "apm-server.auth.secret_token" is not supported by the otel runtime`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unit := component.Unit{
+				ID:     "apm-default-apm-input",
+				Type:   client.UnitTypeInput,
+				Config: component.MustExpectedConfig(tt.inputCfg),
+			}
+
+			receiverCfg, err := translateAPMInputToReceiver(unit)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCfg, receiverCfg)
+		})
+	}
+}
@@ -5,6 +5,7 @@
 package translate
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"slices"
@@ -25,6 +26,8 @@ import (
 	"github.com/elastic/elastic-agent-libs/config"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
+	"github.com/elastic/elastic-agent/internal/pkg/otel/exporter/logstashexporter"
 	"github.com/elastic/elastic-agent/pkg/component"
 	"github.com/elastic/elastic-agent/pkg/component/runtime"
 )
@@ -35,16 +38,36 @@ const OtelNamePrefix = "_agent-component/"
 
 // BeatMonitoringConfigGetter is a function that returns the monitoring configuration for a beat receiver.
 type BeatMonitoringConfigGetter func(unitID, binary string) map[string]any
-type exporterConfigTranslationFunc func(*config.C) (map[string]any, error)
+type exporterConfigTranslationFunc func(*config.C, pipeline.Signal) (map[string]any, error)
 
 var (
-	OtelSupportedOutputTypes         = []string{"elasticsearch"}
-	OtelSupportedInputTypes          = []string{"filestream", "http/metrics", "beat/metrics", "system/metrics"}
+	OtelSupportedOutputTypes         = []string{"elasticsearch", "kafka", "logstash"}
+	OtelSupportedInputTypes          = []string{"filestream", "http/metrics", "beat/metrics", "system/metrics", apmInputType}
 	configTranslationFuncForExporter = map[otelcomponent.Type]exporterConfigTranslationFunc{
 		otelcomponent.MustNewType("elasticsearch"): translateEsOutputToExporter,
+		otelcomponent.MustNewType("kafka"):         translateKafkaOutputToExporter,
+		logstashexporter.Type:                      translateLogstashOutputToExporter,
+	}
+	// receiverSignals are the signals emitted by the receivers components run in. Beat receivers only implement the
+	// logs signal and emit every event as a log record, metricbeat events included. A receiver emitting several
+	// signals, like the otlp receiver of the apm inputs set to the otel runtime, is run in a pipeline per signal.
+	receiverSignals = map[otelcomponent.Type][]pipeline.Signal{
+		otelcomponent.MustNewType(fbreceiver.Name): {pipeline.SignalLogs},
+		otelcomponent.MustNewType(mbreceiver.Name): {pipeline.SignalLogs},
+		otlpReceiverType: {pipeline.SignalLogs, pipeline.SignalMetrics, pipeline.SignalTraces},
+	}
+	// exporterSignals are the signals accepted by the exporters outputs translate to.
+	exporterSignals = map[otelcomponent.Type][]pipeline.Signal{
+		otelcomponent.MustNewType("elasticsearch"): {pipeline.SignalLogs, pipeline.SignalMetrics, pipeline.SignalTraces},
+		otelcomponent.MustNewType("kafka"):         {pipeline.SignalLogs, pipeline.SignalMetrics, pipeline.SignalTraces},
+		logstashexporter.Type:                      {pipeline.SignalLogs},
 	}
 )
 
+// otelExplicitInputTypes are the supported input types whose translation only covers part of the input, they only run
+// in the otel runtime when it is set explicitly, the auto runtime keeps them in the process runtime.
+var otelExplicitInputTypes = []string{apmInputType}
+
 // GetOtelConfig returns the Otel collector configuration for the given component model.
 // All added component and pipelines names are prefixed with OtelNamePrefix.
 // Unsupported components are quietly ignored.
@@ -74,10 +97,91 @@ func GetOtelConfig(
 	return otelConfig, nil
 }
 
-// IsComponentOtelSupported checks if the given component can be run in an Otel Collector.
-func IsComponentOtelSupported(comp *component.Component) bool {
-	return slices.Contains(OtelSupportedOutputTypes, comp.OutputType) &&
-		slices.Contains(OtelSupportedInputTypes, comp.InputType)
+// IsComponentOtelSupported checks if the given component can be run in an Otel Collector. It returns the reason
+// the component is not supported, nil when it is.
+func IsComponentOtelSupported(comp *component.Component) error {
+	if !slices.Contains(OtelSupportedInputTypes, comp.InputType) {
+		return fmt.Errorf("input type %q is not supported by the otel runtime", comp.InputType)
+	}
+	switch {
+	case comp.OutputType == "kafka" && !kafkaExporterAvailable:
+		return errors.New("output type \"kafka\" is not supported by the otel runtime in FIPS distributions, the collector has no kafka exporter")
+	case !slices.Contains(OtelSupportedOutputTypes, comp.OutputType):
+		return fmt.Errorf("output type %q is not supported by the otel runtime", comp.OutputType)
+	}
+
+	// the output settings are translated for every signal to check none is rejected
+	_, _, err := getExportersConfigForComponent(comp)
+	return err
+}
+
+// ResolveAutoRuntimeManager returns the components with the auto runtime manager resolved. Components supported by
+// the otel runtime run in the otel runtime, the others fall back to the process runtime with the reason set in
+// RuntimeFallbackReason. The components also fall back when a runtime rule of caps denies the otel runtime, caps can
+// be nil. The collector configuration of a component is generated with agentInfo before the otel runtime is chosen, a
+// component whose receivers or exporters can't be translated falls back as well, and so does a component whose input
+// type is in otelExplicitInputTypes. A resolved component sharing its ID and runtime manager with another component
+// is merged into it, as the units of a component are only split by runtime manager.
+func ResolveAutoRuntimeManager(components []component.Component, caps capabilities.Capabilities, agentInfo info.Agent) []component.Component {
+	otelDecision := capabilities.Decision{Allowed: true, Rule: -1}
+	if caps != nil {
+		otelDecision = caps.ExplainRuntime(string(component.OtelRuntimeManager))
+	}
+
+	resolved := make([]component.Component, 0, len(components))
+	for _, comp := range components {
+		if comp.RuntimeManager == component.AutoRuntimeManager {
+			comp.RuntimeManager = component.OtelRuntimeManager
+			if !otelDecision.Allowed {
+				comp.RuntimeManager = component.ProcessRuntimeManager
+				comp.RuntimeFallbackReason = fmt.Sprintf("the otel runtime is denied by capabilities.yml rule %d", otelDecision.Rule)
+			} else if comp.Err != nil {
+				// the process runtime reports the error of the component
+				comp.RuntimeManager = component.ProcessRuntimeManager
+				comp.RuntimeFallbackReason = fmt.Sprintf("component has an error: %v", comp.Err)
+			} else if err := IsComponentOtelSupported(&comp); err != nil {
+				comp.RuntimeManager = component.ProcessRuntimeManager
+				comp.RuntimeFallbackReason = err.Error()
+			} else if _, err := getCollectorConfigForComponent(&comp, agentInfo, noBeatMonitoringConfig); err != nil {
+				// the monitoring settings are only merged into the receivers, they can't make the translation fail
+				comp.RuntimeManager = component.ProcessRuntimeManager
+				comp.RuntimeFallbackReason = fmt.Sprintf("failed to translate the component to the otel runtime: %v", err)
+			} else if slices.Contains(otelExplicitInputTypes, comp.InputType) {
+				// checked last, a translation error tells why setting the otel runtime explicitly would fail as well
+				comp.RuntimeManager = component.ProcessRuntimeManager
+				comp.RuntimeFallbackReason = fmt.Sprintf("input type %q only runs in the otel runtime when it is set explicitly", comp.InputType)
+			}
+		}
+
+		idx := slices.IndexFunc(resolved, func(other component.Component) bool {
+			return other.ID == comp.ID && other.RuntimeManager == comp.RuntimeManager
+		})
+		if idx < 0 {
+			resolved = append(resolved, comp)
+			continue
+		}
+		resolved[idx] = mergeComponentUnits(resolved[idx], comp)
+	}
+	return resolved
+}
+
+// noBeatMonitoringConfig is a BeatMonitoringConfigGetter returning no monitoring settings.
+func noBeatMonitoringConfig(string, string) map[string]any {
+	return nil
+}
+
+// mergeComponentUnits returns the component with the input units of other added, both share the output unit.
+func mergeComponentUnits(comp component.Component, other component.Component) component.Component {
+	comp.Units = slices.Clone(comp.Units)
+	for _, unit := range other.Units {
+		if unit.Type == client.UnitTypeInput {
+			comp.Units = append(comp.Units, unit)
+		}
+	}
+	if comp.RuntimeFallbackReason == "" {
+		comp.RuntimeFallbackReason = other.RuntimeFallbackReason
+	}
+	return comp
 }
 
 // getSupportedComponents returns components from the given model that can be run in an Otel Collector.
@@ -85,7 +189,7 @@ func getSupportedComponents(model *component.Model) []*component.Component {
 	var supportedComponents []*component.Component
 
 	for _, comp := range model.Components {
-		if IsComponentOtelSupported(&comp) {
+		if IsComponentOtelSupported(&comp) == nil {
 			supportedComponents = append(supportedComponents, &comp)
 		}
 	}
@@ -93,14 +197,10 @@ func getSupportedComponents(model *component.Model) []*component.Component {
 	return supportedComponents
 }
 
-// getPipelineID returns the pipeline id for the given component.
-func getPipelineID(comp *component.Component) (pipeline.ID, error) {
-	signal, err := getSignalForComponent(comp)
-	if err != nil {
-		return pipeline.ID{}, err
-	}
+// getPipelineID returns the pipeline id for the given component and signal.
+func getPipelineID(comp *component.Component, signal pipeline.Signal) pipeline.ID {
 	pipelineName := fmt.Sprintf("%s%s", OtelNamePrefix, comp.ID)
-	return pipeline.NewIDWithName(signal, pipelineName), nil
+	return pipeline.NewIDWithName(signal, pipelineName)
 }
 
 // getReceiverID returns the receiver id for the given unit and exporter type.
@@ -109,9 +209,13 @@ func getReceiverID(receiverType otelcomponent.Type, unitID string) otelcomponent
 	return otelcomponent.NewIDWithName(receiverType, receiverName)
 }
 
-// getExporterID returns the exporter id for the given exporter type and output name.
-func getExporterID(exporterType otelcomponent.Type, outputName string) otelcomponent.ID {
+// getExporterID returns the exporter id for the given exporter type, output name and signal. Exporters are configured
+// for the signal they export, the signal is added to the name of the exporters of other signals than logs.
+func getExporterID(exporterType otelcomponent.Type, outputName string, signal pipeline.Signal) otelcomponent.ID {
 	exporterName := fmt.Sprintf("%s%s", OtelNamePrefix, outputName)
+	if signal != pipeline.SignalLogs {
+		exporterName = fmt.Sprintf("%s/%s", exporterName, signal)
+	}
 	return otelcomponent.NewIDWithName(exporterType, exporterName)
 }
 
@@ -123,7 +227,7 @@ func getCollectorConfigForComponent(
 	beatMonitoringConfigGetter BeatMonitoringConfigGetter,
 ) (*confmap.Conf, error) {
 
-	exportersConfig, outputQueueConfig, err := getExportersConfigForComponent(comp)
+	signalExportersConfig, outputQueueConfig, err := getExportersConfigForComponent(comp)
 	if err != nil {
 		return nil, err
 	}
@@ -132,15 +236,18 @@ func getCollectorConfigForComponent(
 	if err != nil {
 		return nil, err
 	}
-	pipelineID, err := getPipelineID(comp)
-	if err != nil {
-		return nil, err
-	}
-	pipelinesConfig := map[string]any{
-		pipelineID.String(): map[string][]string{
-			"exporters": maps.Keys(exportersConfig),
+
+	// the component gets a pipeline for each signal it emits, the receivers are shared by the pipelines and each
+	// pipeline exports to the exporters of its signal
+	exportersConfig := map[string]any{}
+	pipelinesConfig := map[string]any{}
+	for signal, signalExporters := range signalExportersConfig {
+		maps.Copy(exportersConfig, signalExporters)
+		pipelineID := getPipelineID(comp, signal)
+		pipelinesConfig[pipelineID.String()] = map[string][]string{
+			"exporters": maps.Keys(signalExporters),
 			"receivers": maps.Keys(receiversConfig),
-		},
+		}
 	}
 
 	fullConfig := map[string]any{
@@ -165,6 +272,10 @@ func getReceiversConfigForComponent(
 	if err != nil {
 		return nil, err
 	}
+	if receiverType == otlpReceiverType {
+		// the otlp receiver has no queue nor monitoring settings, the exporters queue the events
+		return getOTLPReceiversConfigForComponent(comp)
+	}
 	// this is necessary to convert policy config format to beat config format
 	defaultDataStreamType, err := getDefaultDatastreamTypeForComponent(comp)
 	if err != nil {
@@ -240,24 +351,33 @@ func getReceiversConfigForComponent(
 	}, nil
 }
 
-// getReceiversConfigForComponent returns the exporters configuration and queue settings for a component. Usually this will be a single
-// exporter, but in principle it could be more.
-func getExportersConfigForComponent(comp *component.Component) (exporterCfg map[string]any, queueCfg map[string]any, err error) {
-	exportersConfig := map[string]any{}
+// getExportersConfigForComponent returns the exporters configuration for each signal the component emits, and the queue
+// settings for a component. Usually this will be a single exporter per signal, but in principle it could be more.
+func getExportersConfigForComponent(comp *component.Component) (exporterCfg map[pipeline.Signal]map[string]any, queueCfg map[string]any, err error) {
 	exporterType, err := getExporterTypeForComponent(comp)
 	if err != nil {
 		return nil, nil, err
 	}
+	signals, err := getSignalsForComponent(comp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exportersConfig := make(map[pipeline.Signal]map[string]any, len(signals))
 	var queueSettings map[string]any
-	for _, unit := range comp.Units {
-		if unit.Type == client.UnitTypeOutput {
-			var unitExportersConfig map[string]any
-			unitExportersConfig, queueSettings, err = unitToExporterConfig(unit, exporterType, comp.InputType)
-			if err != nil {
-				return nil, nil, err
-			}
-			for k, v := range unitExportersConfig {
-				exportersConfig[k] = v
+	for _, signal := range signals {
+		if !slices.Contains(exporterSignals[exporterType], signal) {
+			return nil, nil, fmt.Errorf("exporter type %s for output type %s does not support the %s signal", exporterType, comp.OutputType, signal)
+		}
+		exportersConfig[signal] = map[string]any{}
+		for _, unit := range comp.Units {
+			if unit.Type == client.UnitTypeOutput {
+				var unitExportersConfig map[string]any
+				unitExportersConfig, queueSettings, err = unitToExporterConfig(unit, exporterType, comp.InputType, signal)
+				if err != nil {
+					return nil, nil, err
+				}
+				maps.Copy(exportersConfig[signal], unitExportersConfig)
 			}
 		}
 	}
@@ -273,20 +393,25 @@ func getBeatNameForComponent(comp *component.Component) string {
 	return comp.InputSpec.Spec.Command.Args[0]
 }
 
-// getSignalForComponent returns the otel signal for the given component. Currently, this is always logs, even for
-// metricbeat.
-func getSignalForComponent(comp *component.Component) (pipeline.Signal, error) {
-	beatName := getBeatNameForComponent(comp)
-	switch beatName {
-	case "filebeat", "metricbeat":
-		return pipeline.SignalLogs, nil
-	default:
-		return pipeline.Signal{}, fmt.Errorf("unknown otel signal for input type: %s", comp.InputType)
+// getSignalsForComponent returns the otel signals emitted by the given component, the signals of the receiver it runs
+// in.
+func getSignalsForComponent(comp *component.Component) ([]pipeline.Signal, error) {
+	receiverType, err := getReceiverTypeForComponent(comp)
+	if err != nil {
+		return nil, fmt.Errorf("unknown otel signal for input type: %s", comp.InputType)
+	}
+	signals, ok := receiverSignals[receiverType]
+	if !ok || len(signals) == 0 {
+		return nil, fmt.Errorf("unknown otel signal for input type: %s", comp.InputType)
 	}
+	return signals, nil
 }
 
 // getReceiverTypeForComponent returns the receiver type for the given component.
 func getReceiverTypeForComponent(comp *component.Component) (otelcomponent.Type, error) {
+	if comp.InputType == apmInputType {
+		return otlpReceiverType, nil
+	}
 	beatName := getBeatNameForComponent(comp)
 	switch beatName {
 	case "filebeat":
@@ -303,13 +428,17 @@ func getExporterTypeForComponent(comp *component.Component) (otelcomponent.Type,
 	switch comp.OutputType {
 	case "elasticsearch":
 		return otelcomponent.MustNewType("elasticsearch"), nil
+	case "kafka":
+		return otelcomponent.MustNewType("kafka"), nil
+	case "logstash":
+		return logstashexporter.Type, nil
 	default:
 		return otelcomponent.Type{}, fmt.Errorf("unknown otel exporter type for output type: %s", comp.OutputType)
 	}
 }
 
-// unitToExporterConfig translates a component.Unit to return an otel exporter configuration and output queue settings
-func unitToExporterConfig(unit component.Unit, exporterType otelcomponent.Type, inputType string) (exportersCfg map[string]any, queueSettings map[string]any, err error) {
+// unitToExporterConfig translates a component.Unit to return an otel exporter configuration for the signal and output queue settings
+func unitToExporterConfig(unit component.Unit, exporterType otelcomponent.Type, inputType string, signal pipeline.Signal) (exportersCfg map[string]any, queueSettings map[string]any, err error) {
 	if unit.Type == client.UnitTypeInput {
 		return nil, nil, fmt.Errorf("unit type is an input, expected output: %v", unit)
 	}
@@ -320,7 +449,7 @@ func unitToExporterConfig(unit component.Unit, exporterType otelcomponent.Type,
 	// we'd like to use the same exporter for all outputs with the same name, so we parse out the name for the unit id
 	// these will be deduplicated by the configuration merging process at the end
 	outputName := strings.TrimPrefix(unit.ID, inputType+"-") // TODO: Use a more structured approach here
-	exporterId := getExporterID(exporterType, outputName)
+	exporterId := getExporterID(exporterType, outputName, signal)
 
 	// translate the configuration
 	unitConfigMap := unit.Config.GetSource().AsMap() // this is what beats do in libbeat/management/generate.go
@@ -329,7 +458,7 @@ func unitToExporterConfig(unit component.Unit, exporterType otelcomponent.Type,
 		return nil, nil, fmt.Errorf("error translating config for output: %s, unit: %s, error: %w", outputName, unit.ID, err)
 	}
 	// Config translation function can mutate queue settings defined under output config
-	exporterConfig, err := configTranslationFunc(outputCfgC)
+	exporterConfig, err := configTranslationFunc(outputCfgC, signal)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error translating config for output: %s, unit: %s, error: %w", outputName, unit.ID, err)
 	}
@@ -395,14 +524,21 @@ func getDefaultDatastreamTypeForComponent(comp *component.Component) (string, er
 	}
 }
 
-// translateEsOutputToExporter translates an elasticsearch output configuration to an elasticsearch exporter configuration.
-func translateEsOutputToExporter(cfg *config.C) (map[string]any, error) {
+// translateEsOutputToExporter translates an elasticsearch output configuration to an elasticsearch exporter configuration
+// for the signal.
+func translateEsOutputToExporter(cfg *config.C, signal pipeline.Signal) (map[string]any, error) {
 	esConfig, err := elasticsearchtranslate.ToOTelConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
 	// dynamic indexing works by default
 
+	if signal != pipeline.SignalLogs {
+		// the bodymap mapping only supports logs, metrics and traces are indexed as otel documents
+		esConfig["mapping"] = map[string]any{"mode": "otel"}
+		return esConfig, nil
+	}
+
 	// we also want to use dynamic log ids
 	esConfig["logs_dynamic_id"] = map[string]any{"enabled": true}
 
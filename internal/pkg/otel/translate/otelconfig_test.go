@@ -5,20 +5,25 @@
 package translate
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/elastic/elastic-agent-client/v7/pkg/client"
+	"github.com/elastic/elastic-agent-libs/logp"
 	"github.com/elastic/elastic-agent-libs/mapstr"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
+	"github.com/elastic/elastic-agent/internal/pkg/capabilities"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/pipeline"
 
 	"github.com/elastic/elastic-agent/pkg/component"
@@ -69,12 +74,12 @@ func TestBeatNameToDefaultDatastreamType(t *testing.T) {
 	}
 }
 
-func TestGetSignalForComponent(t *testing.T) {
+func TestGetSignalsForComponent(t *testing.T) {
 	tests := []struct {
-		name           string
-		component      component.Component
-		expectedSignal pipeline.Signal
-		expectedError  error
+		name            string
+		component       component.Component
+		expectedSignals []pipeline.Signal
+		expectedError   error
 	}{
 		{
 			name:          "no input spec",
@@ -104,7 +109,7 @@ func TestGetSignalForComponent(t *testing.T) {
 					},
 				},
 			},
-			expectedSignal: pipeline.SignalLogs,
+			expectedSignals: []pipeline.Signal{pipeline.SignalLogs},
 		},
 		{
 			name: "metricbeat",
@@ -119,14 +124,24 @@ func TestGetSignalForComponent(t *testing.T) {
 					},
 				},
 			},
-			expectedSignal: pipeline.SignalLogs,
+			expectedSignals: []pipeline.Signal{pipeline.SignalLogs},
+		},
+		{
+			name: "apm",
+			component: component.Component{
+				InputType: "apm",
+				InputSpec: &component.InputRuntimeSpec{
+					BinaryName: "apm-server",
+				},
+			},
+			expectedSignals: []pipeline.Signal{pipeline.SignalLogs, pipeline.SignalMetrics, pipeline.SignalTraces},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			actualSignal, actualError := getSignalForComponent(&tt.component)
-			assert.Equal(t, tt.expectedSignal, actualSignal)
+			actualSignals, actualError := getSignalsForComponent(&tt.component)
+			assert.Equal(t, tt.expectedSignals, actualSignals)
 
 			if tt.expectedError != nil {
 				assert.Error(t, actualError)
@@ -655,4 +670,310 @@ func TestGetOtelConfig(t *testing.T) {
 	}
 }
 
+func TestGetOtelConfigMultipleSignals(t *testing.T) {
+	// the apm input runs in the otlp receiver, which emits logs, metrics and traces
+	model := &component.Model{
+		Components: []component.Component{
+			{
+				ID:         "apm-default",
+				InputType:  "apm",
+				OutputType: "elasticsearch",
+				InputSpec: &component.InputRuntimeSpec{
+					BinaryName: "apm-server",
+				},
+				Units: []component.Unit{
+					{
+						ID:   "apm-default-apm-input",
+						Type: client.UnitTypeInput,
+						Config: component.MustExpectedConfig(map[string]any{
+							"id":         "apm-input",
+							"type":       "apm",
+							"use_output": "default",
+							"apm-server": map[string]any{
+								"host": "0.0.0.0:8200",
+							},
+						}),
+					},
+					{
+						ID:   "apm-default",
+						Type: client.UnitTypeOutput,
+						Config: component.MustExpectedConfig(map[string]any{
+							"type":  "elasticsearch",
+							"hosts": []any{"localhost:9200"},
+						}),
+					},
+				},
+			},
+		},
+	}
+
+	actualConf, err := GetOtelConfig(model, &info.AgentInfo{}, func(_, _ string) map[string]any { return nil })
+	require.NoError(t, err)
+	require.NotNil(t, actualConf)
+
+	receivers, err := actualConf.Sub("receivers")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"otlp/_agent-component/apm-default": map[string]any{
+			"protocols": map[string]any{
+				"http": map[string]any{
+					"endpoint": "0.0.0.0:8200",
+				},
+			},
+		},
+	}, receivers.ToStringMap())
+
+	pipelines, err := actualConf.Sub("service::pipelines")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"logs/_agent-component/apm-default": map[string][]string{
+			"exporters": {"elasticsearch/_agent-component/default"},
+			"receivers": {"otlp/_agent-component/apm-default"},
+		},
+		"metrics/_agent-component/apm-default": map[string][]string{
+			"exporters": {"elasticsearch/_agent-component/default/metrics"},
+			"receivers": {"otlp/_agent-component/apm-default"},
+		},
+		"traces/_agent-component/apm-default": map[string][]string{
+			"exporters": {"elasticsearch/_agent-component/default/traces"},
+			"receivers": {"otlp/_agent-component/apm-default"},
+		},
+	}, pipelines.ToStringMap())
+
+	logsExporter, err := actualConf.Sub("exporters::elasticsearch/_agent-component/default")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"mode": "bodymap"}, logsExporter.Get("mapping"))
+	assert.Equal(t, map[string]any{"enabled": true}, logsExporter.Get("logs_dynamic_id"))
+
+	for _, signal := range []string{"metrics", "traces"} {
+		signalExporter, err := actualConf.Sub("exporters::elasticsearch/_agent-component/default/" + signal)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"mode": "otel"}, signalExporter.Get("mapping"), signal)
+		assert.Nil(t, signalExporter.Get("logs_dynamic_id"), signal)
+	}
+}
+
+func TestIsComponentOtelSupported(t *testing.T) {
+	newComponent := func(inputType, outputType string, outputCfg map[string]any) *component.Component {
+		return &component.Component{
+			ID:         inputType + "-default",
+			InputType:  inputType,
+			OutputType: outputType,
+			InputSpec: &component.InputRuntimeSpec{
+				BinaryName: "agentbeat",
+				Spec: component.InputSpec{
+					Command: &component.CommandSpec{
+						Args: []string{"filebeat"},
+					},
+				},
+			},
+			Units: []component.Unit{
+				{
+					ID:     inputType + "-default",
+					Type:   client.UnitTypeOutput,
+					Config: component.MustExpectedConfig(outputCfg),
+				},
+			},
+		}
+	}
+	type testCase struct {
+		name           string
+		component      *component.Component
+		expectedErrMsg string
+	}
+
+	tests := []testCase{
+		{
+			name: "elasticsearch",
+			component: newComponent("filestream", "elasticsearch", map[string]any{
+				"type":  "elasticsearch",
+				"hosts": []any{"localhost:9200"},
+			}),
+		},
+		{
+			name:           "unsupported input",
+			component:      newComponent("log", "elasticsearch", map[string]any{"type": "elasticsearch"}),
+			expectedErrMsg: `input type "log" is not supported by the otel runtime`,
+		},
+		{
+			name:      "logstash",
+			component: newComponent("filestream", "logstash", map[string]any{"type": "logstash", "hosts": []any{"logstash:5044"}}),
+		},
+		{
+			name: "unsupported logstash setting",
+			component: newComponent("filestream", "logstash", map[string]any{
+				"type":       "logstash",
+				"hosts":      []any{"logstash:5044"},
+				"pipelining": 2,
+			}),
+			expectedErrMsg: `error translating config for output: default, unit: filestream-default, error: logstash output setting "pipelining" is not supported by the otel runtime`,
+		},
+		{
+			name:           "apm with an exporter of logs only",
+			component:      newComponent("apm", "logstash", map[string]any{"type": "logstash", "hosts": []any{"logstash:5044"}}),
+			expectedErrMsg: `exporter type logstash for output type logstash does not support the metrics signal`,
+		},
+		{
+			name:           "unsupported output",
+			component:      newComponent("filestream", "redis", map[string]any{"type": "redis"}),
+			expectedErrMsg: `output type "redis" is not supported by the otel runtime`,
+		},
+	}
+	if kafkaExporterAvailable {
+		tests = append(tests,
+			testCase{
+				name: "kafka",
+				component: newComponent("filestream", "kafka", map[string]any{
+					"type":  "kafka",
+					"hosts": []any{"kafka1:9092"},
+					"topic": "logs",
+				}),
+			},
+			testCase{
+				name: "unsupported kafka setting",
+				component: newComponent("filestream", "kafka", map[string]any{
+					"type":  "kafka",
+					"hosts": []any{"kafka1:9092"},
+					"topic": "logs",
+					"key":   "%{[host.name]}",
+				}),
+				expectedErrMsg: `error translating config for output: default, unit: filestream-default, error: kafka output setting "key" is not supported by the otel runtime`,
+			},
+		)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := IsComponentOtelSupported(tt.component)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestResolveAutoRuntimeManager(t *testing.T) {
+	newComponent := func(id, outputType string, runtimeManager component.RuntimeManager, inputIDs ...string) component.Component {
+		comp := component.Component{
+			ID:             id,
+			InputType:      "filestream",
+			OutputType:     outputType,
+			RuntimeManager: runtimeManager,
+			InputSpec: &component.InputRuntimeSpec{
+				BinaryName: "agentbeat",
+				Spec: component.InputSpec{
+					Command: &component.CommandSpec{
+						Args: []string{"filebeat"},
+					},
+				},
+			},
+		}
+		for _, inputID := range inputIDs {
+			comp.Units = append(comp.Units, component.Unit{ID: id + "-" + inputID, Type: client.UnitTypeInput})
+		}
+		comp.Units = append(comp.Units, component.Unit{
+			ID:     id,
+			Type:   client.UnitTypeOutput,
+			Config: component.MustExpectedConfig(map[string]any{"type": outputType, "hosts": []any{"localhost:1234"}}),
+		})
+		return comp
+	}
+
+	components := []component.Component{
+		newComponent("filestream-default", "elasticsearch", component.AutoRuntimeManager, "auto"),
+		newComponent("filestream-default", "elasticsearch", component.OtelRuntimeManager, "otel"),
+		newComponent("filestream-redis", "redis", component.AutoRuntimeManager, "auto"),
+		newComponent("filestream-redis", "redis", component.ProcessRuntimeManager, "process"),
+	}
+	failing := newComponent("filestream-failing", "elasticsearch", component.AutoRuntimeManager, "auto")
+	failing.Err = errors.New("input not supported")
+	newAPMComponent := func(id string, apmServerCfg map[string]any) component.Component {
+		comp := newComponent(id, "elasticsearch", component.AutoRuntimeManager)
+		comp.InputType = "apm"
+		comp.InputSpec = &component.InputRuntimeSpec{BinaryName: "apm-server"}
+		comp.Units = append(comp.Units, component.Unit{
+			ID:     id + "-apm-input",
+			Type:   client.UnitTypeInput,
+			Config: component.MustExpectedConfig(map[string]any{"type": "apm", "apm-server": apmServerCfg}),
+		})
+		return comp
+	}
+	// the input type and output are supported, but the apm input has a setting the otlp receiver doesn't support
+	untranslatable := newAPMComponent("apm-rum", map[string]any{"host": "localhost:8200", "rum": map[string]any{"enabled": true}})
+	// the otlp receiver only serves the OTLP intake of APM Server, the otel runtime must be set explicitly
+	apm := newAPMComponent("apm-default", map[string]any{"host": "localhost:8200"})
+	components = append(components, failing, untranslatable, apm)
+
+	resolved := ResolveAutoRuntimeManager(components, nil, &info.AgentInfo{})
+	require.Len(t, resolved, 5)
+
+	unitIDs := func(comp component.Component) []string {
+		var ids []string
+		for _, unit := range comp.Units {
+			ids = append(ids, unit.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, "filestream-default", resolved[0].ID)
+	assert.Equal(t, component.OtelRuntimeManager, resolved[0].RuntimeManager)
+	assert.Empty(t, resolved[0].RuntimeFallbackReason)
+	assert.Equal(t, []string{"filestream-default-auto", "filestream-default", "filestream-default-otel"}, unitIDs(resolved[0]))
+
+	assert.Equal(t, "filestream-redis", resolved[1].ID)
+	assert.Equal(t, component.ProcessRuntimeManager, resolved[1].RuntimeManager)
+	assert.Equal(t, `output type "redis" is not supported by the otel runtime`, resolved[1].RuntimeFallbackReason)
+	assert.Equal(t, []string{"filestream-redis-auto", "filestream-redis", "filestream-redis-process"}, unitIDs(resolved[1]))
+
+	assert.Equal(t, "filestream-failing", resolved[2].ID)
+	assert.Equal(t, component.ProcessRuntimeManager, resolved[2].RuntimeManager)
+	assert.Equal(t, "component has an error: input not supported", resolved[2].RuntimeFallbackReason)
+
+	assert.Equal(t, "apm-rum", resolved[3].ID)
+	assert.Equal(t, component.ProcessRuntimeManager, resolved[3].RuntimeManager)
+	assert.Equal(t, `failed to translate the component to the otel runtime: error translating config for input unit: apm-rum-apm-input, error: apm input setting "apm-server.rum.enabled" is not supported by the otel runtime`, resolved[3].RuntimeFallbackReason)
+
+	assert.Equal(t, "apm-default", resolved[4].ID)
+	assert.Equal(t, component.ProcessRuntimeManager, resolved[4].RuntimeManager)
+	assert.Equal(t, `input type "apm" only runs in the otel runtime when it is set explicitly`, resolved[4].RuntimeFallbackReason)
+
+	assert.Equal(t, component.AutoRuntimeManager, components[0].RuntimeManager, "the given components should not change")
+	assert.Len(t, components[0].Units, 2, "the given components should not change")
+}
+
+func TestResolveAutoRuntimeManagerOtelDenied(t *testing.T) {
+	caps, err := capabilities.Load(strings.NewReader(`
+capabilities:
+- rule: allow
+  runtime: process
+- rule: deny
+  runtime: otel
+`), logp.NewLogger("testing"))
+	require.NoError(t, err)
+
+	components := []component.Component{
+		{
+			ID:             "filestream-default",
+			InputType:      "filestream",
+			OutputType:     "elasticsearch",
+			RuntimeManager: component.AutoRuntimeManager,
+		},
+		{
+			ID:             "filestream-otel",
+			InputType:      "filestream",
+			OutputType:     "elasticsearch",
+			RuntimeManager: component.OtelRuntimeManager,
+		},
+	}
+
+	resolved := ResolveAutoRuntimeManager(components, caps, &info.AgentInfo{})
+	require.Len(t, resolved, 2)
+	assert.Equal(t, component.ProcessRuntimeManager, resolved[0].RuntimeManager)
+	assert.Equal(t, "the otel runtime is denied by capabilities.yml rule 1", resolved[0].RuntimeFallbackReason)
+	assert.Equal(t, component.OtelRuntimeManager, resolved[1].RuntimeManager, "only the auto runtime should be resolved")
+	assert.Empty(t, resolved[1].RuntimeFallbackReason)
+}
+
 // TODO: Add unit tests for other config generation functions
@@ -0,0 +1,264 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+// kafkaOutputSupportedSettings are the settings of the kafka output translated to the kafka exporter. Other settings
+// are rejected, the kafka exporter would not behave as the kafka output does.
+var kafkaOutputSupportedSettings = []string{
+	"type",
+	"enabled",
+	"hosts",
+	"topic",
+	"version",
+	"client_id",
+	"username",
+	"password",
+	"sasl.mechanism",
+	"compression",
+	"compression_level",
+	"required_acks",
+	"max_message_bytes",
+	"timeout",
+	"backoff.init",
+	"backoff.max",
+	"metadata.full",
+	"metadata.refresh_frequency",
+	"metadata.retry.max",
+	"metadata.retry.backoff",
+	"ssl.enabled",
+	"ssl.certificate_authorities",
+	"ssl.certificate",
+	"ssl.key",
+	"ssl.verification_mode",
+}
+
+// arrayIndexRegexp matches the array indexes of flattened configuration keys.
+var arrayIndexRegexp = regexp.MustCompile(`\.\d+(\.|$)`)
+
+// kafkaOutputConfig is the part of the kafka output configuration translated to the kafka exporter. Defaults are the
+// kafka output defaults.
+type kafkaOutputConfig struct {
+	Hosts            []string            `config:"hosts"`
+	Topic            string              `config:"topic"`
+	Version          string              `config:"version"`
+	ClientID         string              `config:"client_id"`
+	Username         string              `config:"username"`
+	Password         string              `config:"password"`
+	SASL             kafkaSASLConfig     `config:"sasl"`
+	Compression      string              `config:"compression"`
+	CompressionLevel int                 `config:"compression_level"`
+	RequiredAcks     int                 `config:"required_acks"`
+	MaxMessageBytes  int                 `config:"max_message_bytes"`
+	Timeout          time.Duration       `config:"timeout"`
+	Backoff          kafkaBackoffConfig  `config:"backoff"`
+	Metadata         kafkaMetadataConfig `config:"metadata"`
+	SSL              *outputSSLConfig    `config:"ssl"`
+}
+
+type kafkaSASLConfig struct {
+	Mechanism string `config:"mechanism"`
+}
+
+type kafkaBackoffConfig struct {
+	Init time.Duration `config:"init"`
+	Max  time.Duration `config:"max"`
+}
+
+type kafkaMetadataConfig struct {
+	Full            bool          `config:"full"`
+	RefreshInterval time.Duration `config:"refresh_frequency"`
+	Retry           struct {
+		Max     int           `config:"max"`
+		Backoff time.Duration `config:"backoff"`
+	} `config:"retry"`
+}
+
+// outputSSLConfig is the part of the ssl settings of an output translated to the otel TLS client settings.
+type outputSSLConfig struct {
+	Enabled                *bool    `config:"enabled"`
+	CertificateAuthorities []string `config:"certificate_authorities"`
+	Certificate            string   `config:"certificate"`
+	Key                    string   `config:"key"`
+	VerificationMode       string   `config:"verification_mode"`
+}
+
+func defaultKafkaOutputConfig() kafkaOutputConfig {
+	cfg := kafkaOutputConfig{
+		Version:          "2.1.0",
+		ClientID:         "beats",
+		Compression:      "gzip",
+		CompressionLevel: 4,
+		RequiredAcks:     1,
+		MaxMessageBytes:  1000000,
+		Timeout:          30 * time.Second,
+		Backoff: kafkaBackoffConfig{
+			Init: time.Second,
+			Max:  time.Minute,
+		},
+		Metadata: kafkaMetadataConfig{
+			RefreshInterval: 10 * time.Minute,
+		},
+	}
+	cfg.Metadata.Retry.Max = 3
+	cfg.Metadata.Retry.Backoff = 250 * time.Millisecond
+	return cfg
+}
+
+// translateKafkaOutputToExporter translates a kafka output configuration to a kafka exporter configuration for the
+// signal.
+func translateKafkaOutputToExporter(cfg *config.C, signal pipeline.Signal) (map[string]any, error) {
+	if err := checkSupportedSettings("kafka", cfg, kafkaOutputSupportedSettings); err != nil {
+		return nil, err
+	}
+
+	kafkaCfg := defaultKafkaOutputConfig()
+	if err := cfg.Unpack(&kafkaCfg); err != nil {
+		return nil, fmt.Errorf("error unpacking kafka output configuration: %w", err)
+	}
+	if len(kafkaCfg.Hosts) == 0 {
+		return nil, errors.New("kafka output requires hosts")
+	}
+	if kafkaCfg.Topic == "" {
+		return nil, errors.New("kafka output requires a topic")
+	}
+	if strings.Contains(kafkaCfg.Topic, "%{") {
+		return nil, fmt.Errorf("kafka output topic %q is dynamic, dynamic topics are not supported by the kafka exporter", kafkaCfg.Topic)
+	}
+
+	producer := map[string]any{
+		"compression":       kafkaCfg.Compression,
+		"required_acks":     kafkaCfg.RequiredAcks,
+		"max_message_bytes": kafkaCfg.MaxMessageBytes,
+	}
+	// the compression level only applies to gzip in the kafka output
+	if kafkaCfg.Compression == "gzip" {
+		producer["compression_params"] = map[string]any{"level": kafkaCfg.CompressionLevel}
+	}
+
+	exporterCfg := map[string]any{
+		"brokers":          kafkaCfg.Hosts,
+		"protocol_version": kafkaCfg.Version,
+		"client_id":        kafkaCfg.ClientID,
+		"timeout":          kafkaCfg.Timeout,
+		"producer":         producer,
+		"metadata": map[string]any{
+			"full":             kafkaCfg.Metadata.Full,
+			"refresh_interval": kafkaCfg.Metadata.RefreshInterval,
+			"retry": map[string]any{
+				"max":     kafkaCfg.Metadata.Retry.Max,
+				"backoff": kafkaCfg.Metadata.Retry.Backoff,
+			},
+		},
+		"retry_on_failure": map[string]any{
+			"enabled":          true,
+			"initial_interval": kafkaCfg.Backoff.Init,
+			"max_interval":     kafkaCfg.Backoff.Max,
+		},
+	}
+
+	// log records are sent as JSON documents like the kafka output does, the raw encoding marshals their body as
+	// JSON, metrics and traces have no body and are sent as OTLP JSON
+	encoding := "otlp_json"
+	if signal == pipeline.SignalLogs {
+		encoding = "raw"
+	}
+	exporterCfg[signal.String()] = map[string]any{
+		"topic":    kafkaCfg.Topic,
+		"encoding": encoding,
+	}
+
+	if kafkaCfg.Username != "" {
+		mechanism := kafkaCfg.SASL.Mechanism
+		if mechanism == "" {
+			mechanism = "PLAIN"
+		}
+		exporterCfg["auth"] = map[string]any{
+			"sasl": map[string]any{
+				"username":  kafkaCfg.Username,
+				"password":  kafkaCfg.Password,
+				"mechanism": strings.ToUpper(mechanism),
+			},
+		}
+	}
+
+	tlsCfg, err := translateSSLToTLS(kafkaCfg.SSL)
+	if err != nil {
+		return nil, fmt.Errorf("kafka output: %w", err)
+	}
+	if tlsCfg != nil {
+		exporterCfg["tls"] = tlsCfg
+	}
+
+	return exporterCfg, nil
+}
+
+// checkSupportedSettings returns an error naming the first setting of the output configuration that is not
+// supported. Queue settings are always supported, they are promoted to the receiver.
+func checkSupportedSettings(outputType string, cfg *config.C, supported []string) error {
+	for _, key := range cfg.FlattenedKeys() {
+		key = arrayIndexRegexp.ReplaceAllString(key, "$1")
+		if key == "queue" || strings.HasPrefix(key, "queue.") {
+			continue
+		}
+		if !slices.Contains(supported, key) {
+			return fmt.Errorf("%s output setting %q is not supported by the otel runtime", outputType, key)
+		}
+	}
+	return nil
+}
+
+// translateSSLToTLS translates the ssl settings of an output to otel TLS client settings, nil when TLS is disabled.
+// Certificate authorities, certificates and keys can either be paths or PEM encoded content, like in the outputs.
+func translateSSLToTLS(ssl *outputSSLConfig) (map[string]any, error) {
+	if ssl == nil || (ssl.Enabled != nil && !*ssl.Enabled) {
+		return nil, nil
+	}
+
+	tlsCfg := map[string]any{}
+	switch len(ssl.CertificateAuthorities) {
+	case 0:
+	case 1:
+		setPEMOrFile(tlsCfg, "ca", ssl.CertificateAuthorities[0])
+	default:
+		return nil, errors.New("ssl setting \"certificate_authorities\" with more than one certificate authority is not supported by the otel runtime")
+	}
+	if ssl.Certificate != "" {
+		setPEMOrFile(tlsCfg, "cert", ssl.Certificate)
+	}
+	if ssl.Key != "" {
+		setPEMOrFile(tlsCfg, "key", ssl.Key)
+	}
+
+	switch ssl.VerificationMode {
+	case "", "full":
+	case "none":
+		tlsCfg["insecure_skip_verify"] = true
+	default:
+		return nil, fmt.Errorf("ssl verification mode %q is not supported by the otel runtime", ssl.VerificationMode)
+	}
+	return tlsCfg, nil
+}
+
+// setPEMOrFile sets the <prefix>_pem setting for PEM encoded content and the <prefix>_file setting otherwise.
+func setPEMOrFile(tlsCfg map[string]any, prefix string, value string) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		tlsCfg[prefix+"_pem"] = value
+		return
+	}
+	tlsCfg[prefix+"_file"] = value
+}
@@ -0,0 +1,10 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+//go:build requirefips
+
+package translate
+
+// kafkaExporterAvailable reports whether the collector has the kafka exporter, it is not added in FIPS distributions.
+const kafkaExporterAvailable = false
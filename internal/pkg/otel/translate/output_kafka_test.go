@@ -0,0 +1,175 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestTranslateKafkaOutputToExporter(t *testing.T) {
+	tests := []struct {
+		name           string
+		outputCfg      map[string]any
+		expectedCfg    map[string]any
+		expectedErrMsg string
+	}{
+		{
+			name: "defaults",
+			outputCfg: map[string]any{
+				"type":             "kafka",
+				"hosts":            []any{"kafka1:9092", "kafka2:9092"},
+				"topic":            "logs",
+				"queue.mem.events": 3200,
+			},
+			expectedCfg: map[string]any{
+				"brokers":          []string{"kafka1:9092", "kafka2:9092"},
+				"protocol_version": "2.1.0",
+				"client_id":        "beats",
+				"timeout":          30 * time.Second,
+				"producer": map[string]any{
+					"compression":        "gzip",
+					"compression_params": map[string]any{"level": 4},
+					"required_acks":      1,
+					"max_message_bytes":  1000000,
+				},
+				"metadata": map[string]any{
+					"full":             false,
+					"refresh_interval": 10 * time.Minute,
+					"retry": map[string]any{
+						"max":     3,
+						"backoff": 250 * time.Millisecond,
+					},
+				},
+				"retry_on_failure": map[string]any{
+					"enabled":          true,
+					"initial_interval": time.Second,
+					"max_interval":     time.Minute,
+				},
+				"logs": map[string]any{
+					"topic":    "logs",
+					"encoding": "raw",
+				},
+			},
+		},
+		{
+			name: "dynamic topic",
+			outputCfg: map[string]any{
+				"type":  "kafka",
+				"hosts": []any{"kafka1:9092"},
+				"topic": "%{[fields.topic]}",
+			},
+			expectedErrMsg: `kafka output topic "%{[fields.topic]}" is dynamic, dynamic topics are not supported by the kafka exporter`,
+		},
+		{
+			name: "unsupported setting",
+			outputCfg: map[string]any{
+				"type":           "kafka",
+				"hosts":          []any{"kafka1:9092"},
+				"topic":          "logs",
+				"partition.hash": map[string]any{"hash": []any{"host.name"}},
+			},
+			expectedErrMsg: `kafka output setting "partition.hash.hash" is not supported by the otel runtime`,
+		},
+		{
+			name: "several certificate authorities",
+			outputCfg: map[string]any{
+				"type":                        "kafka",
+				"hosts":                       []any{"kafka1:9092"},
+				"topic":                       "logs",
+				"ssl.certificate_authorities": []any{"/etc/ca1.pem", "/etc/ca2.pem"},
+			},
+			expectedErrMsg: `kafka output: ssl setting "certificate_authorities" with more than one certificate authority is not supported by the otel runtime`,
+		},
+		{
+			name: "missing topic",
+			outputCfg: map[string]any{
+				"type":  "kafka",
+				"hosts": []any{"kafka1:9092"},
+			},
+			expectedErrMsg: "kafka output requires a topic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewConfigFrom(tt.outputCfg)
+			require.NoError(t, err)
+
+			exporterCfg, err := translateKafkaOutputToExporter(cfg, pipeline.SignalLogs)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCfg, exporterCfg)
+		})
+	}
+
+	t.Run("sasl and tls", func(t *testing.T) {
+		cfg, err := config.NewConfigFrom(map[string]any{
+			"type":                        "kafka",
+			"hosts":                       []any{"kafka1:9092"},
+			"topic":                       "logs",
+			"username":                    "elastic",
+			"password":                    "changeme",
+			"sasl.mechanism":              "scram-sha-512",
+			"ssl.certificate_authorities": []any{"/etc/ca.pem"},
+			"ssl.certificate":             "-----BEGIN CERTIFICATE-----\n...",
+			"ssl.key":                     "/etc/client.key",
+			"ssl.verification_mode":       "none",
+		})
+		require.NoError(t, err)
+
+		exporterCfg, err := translateKafkaOutputToExporter(cfg, pipeline.SignalLogs)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{
+			"sasl": map[string]any{
+				"username":  "elastic",
+				"password":  "changeme",
+				"mechanism": "SCRAM-SHA-512",
+			},
+		}, exporterCfg["auth"])
+		assert.Equal(t, map[string]any{
+			"ca_file":              "/etc/ca.pem",
+			"cert_pem":             "-----BEGIN CERTIFICATE-----\n...",
+			"key_file":             "/etc/client.key",
+			"insecure_skip_verify": true,
+		}, exporterCfg["tls"])
+	})
+
+	t.Run("signals", func(t *testing.T) {
+		cfg, err := config.NewConfigFrom(map[string]any{
+			"type":  "kafka",
+			"hosts": []any{"kafka1:9092"},
+			"topic": "events",
+		})
+		require.NoError(t, err)
+
+		for signal, encoding := range map[pipeline.Signal]string{
+			pipeline.SignalLogs:    "raw",
+			pipeline.SignalMetrics: "otlp_json",
+			pipeline.SignalTraces:  "otlp_json",
+		} {
+			exporterCfg, err := translateKafkaOutputToExporter(cfg, signal)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{
+				"topic":    "events",
+				"encoding": encoding,
+			}, exporterCfg[signal.String()])
+			for _, other := range []string{"logs", "metrics", "traces"} {
+				if other != signal.String() {
+					assert.NotContains(t, exporterCfg, other)
+				}
+			}
+		}
+	})
+}
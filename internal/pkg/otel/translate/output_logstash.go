@@ -0,0 +1,102 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+// logstashOutputSupportedSettings are the settings of the logstash output translated to the logstash exporter. Other
+// settings are rejected, the logstash exporter would not behave as the logstash output does.
+var logstashOutputSupportedSettings = []string{
+	"type",
+	"enabled",
+	"hosts",
+	"loadbalance",
+	"timeout",
+	"compression_level",
+	"bulk_max_size",
+	"backoff.init",
+	"backoff.max",
+	"ssl.enabled",
+	"ssl.certificate_authorities",
+	"ssl.certificate",
+	"ssl.key",
+	"ssl.verification_mode",
+}
+
+// logstashOutputConfig is the part of the logstash output configuration translated to the logstash exporter. Defaults
+// are the logstash output defaults.
+type logstashOutputConfig struct {
+	Hosts            []string              `config:"hosts"`
+	LoadBalance      bool                  `config:"loadbalance"`
+	Timeout          time.Duration         `config:"timeout"`
+	CompressionLevel int                   `config:"compression_level"`
+	BulkMaxSize      int                   `config:"bulk_max_size"`
+	Backoff          logstashBackoffConfig `config:"backoff"`
+	SSL              *outputSSLConfig      `config:"ssl"`
+}
+
+type logstashBackoffConfig struct {
+	Init time.Duration `config:"init"`
+	Max  time.Duration `config:"max"`
+}
+
+func defaultLogstashOutputConfig() logstashOutputConfig {
+	return logstashOutputConfig{
+		Timeout:          30 * time.Second,
+		CompressionLevel: 3,
+		BulkMaxSize:      2048,
+		Backoff: logstashBackoffConfig{
+			Init: time.Second,
+			Max:  time.Minute,
+		},
+	}
+}
+
+// translateLogstashOutputToExporter translates a logstash output configuration to a logstash exporter configuration.
+// The logstash exporter only exports logs, the signal is always logs.
+func translateLogstashOutputToExporter(cfg *config.C, _ pipeline.Signal) (map[string]any, error) {
+	if err := checkSupportedSettings("logstash", cfg, logstashOutputSupportedSettings); err != nil {
+		return nil, err
+	}
+
+	lsCfg := defaultLogstashOutputConfig()
+	if err := cfg.Unpack(&lsCfg); err != nil {
+		return nil, fmt.Errorf("error unpacking logstash output configuration: %w", err)
+	}
+	if len(lsCfg.Hosts) == 0 {
+		return nil, errors.New("logstash output requires hosts")
+	}
+
+	exporterCfg := map[string]any{
+		"hosts":             lsCfg.Hosts,
+		"loadbalance":       lsCfg.LoadBalance,
+		"timeout":           lsCfg.Timeout,
+		"compression_level": lsCfg.CompressionLevel,
+		"bulk_max_size":     lsCfg.BulkMaxSize,
+		"retry_on_failure": map[string]any{
+			"enabled":          true,
+			"initial_interval": lsCfg.Backoff.Init,
+			"max_interval":     lsCfg.Backoff.Max,
+		},
+	}
+
+	tlsCfg, err := translateSSLToTLS(lsCfg.SSL)
+	if err != nil {
+		return nil, fmt.Errorf("logstash output: %w", err)
+	}
+	if tlsCfg != nil {
+		exporterCfg["tls"] = tlsCfg
+	}
+
+	return exporterCfg, nil
+}
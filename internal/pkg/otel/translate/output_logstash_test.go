@@ -0,0 +1,117 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package translate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/elastic/elastic-agent-libs/config"
+)
+
+func TestTranslateLogstashOutputToExporter(t *testing.T) {
+	tests := []struct {
+		name           string
+		outputCfg      map[string]any
+		expectedCfg    map[string]any
+		expectedErrMsg string
+	}{
+		{
+			name: "defaults",
+			outputCfg: map[string]any{
+				"type":             "logstash",
+				"hosts":            []any{"logstash1:5044", "logstash2"},
+				"queue.mem.events": 3200,
+			},
+			expectedCfg: map[string]any{
+				"hosts":             []string{"logstash1:5044", "logstash2"},
+				"loadbalance":       false,
+				"timeout":           30 * time.Second,
+				"compression_level": 3,
+				"bulk_max_size":     2048,
+				"retry_on_failure": map[string]any{
+					"enabled":          true,
+					"initial_interval": time.Second,
+					"max_interval":     time.Minute,
+				},
+			},
+		},
+		{
+			name: "tls and settings",
+			outputCfg: map[string]any{
+				"type":                        "logstash",
+				"hosts":                       []any{"logstash1:5044"},
+				"loadbalance":                 true,
+				"timeout":                     "10s",
+				"compression_level":           0,
+				"bulk_max_size":               512,
+				"backoff.init":                "2s",
+				"backoff.max":                 "30s",
+				"ssl.certificate_authorities": []any{"/etc/ca.pem"},
+				"ssl.verification_mode":       "none",
+			},
+			expectedCfg: map[string]any{
+				"hosts":             []string{"logstash1:5044"},
+				"loadbalance":       true,
+				"timeout":           10 * time.Second,
+				"compression_level": 0,
+				"bulk_max_size":     512,
+				"retry_on_failure": map[string]any{
+					"enabled":          true,
+					"initial_interval": 2 * time.Second,
+					"max_interval":     30 * time.Second,
+				},
+				"tls": map[string]any{
+					"ca_file":              "/etc/ca.pem",
+					"insecure_skip_verify": true,
+				},
+			},
+		},
+		{
+			name: "unsupported setting",
+			outputCfg: map[string]any{
+				"type":       "logstash",
+				"hosts":      []any{"logstash1:5044"},
+				"pipelining": 2,
+			},
+			expectedErrMsg: `logstash output setting "pipelining" is not supported by the otel runtime`,
+		},
+		{
+			name: "unsupported verification mode",
+			outputCfg: map[string]any{
+				"type":                  "logstash",
+				"hosts":                 []any{"logstash1:5044"},
+				"ssl.verification_mode": "certificate",
+			},
+			expectedErrMsg: `logstash output: ssl verification mode "certificate" is not supported by the otel runtime`,
+		},
+		{
+			name: "missing hosts",
+			outputCfg: map[string]any{
+				"type": "logstash",
+			},
+			expectedErrMsg: "logstash output requires hosts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewConfigFrom(tt.outputCfg)
+			require.NoError(t, err)
+
+			exporterCfg, err := translateLogstashOutputToExporter(cfg, pipeline.SignalLogs)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCfg, exporterCfg)
+		})
+	}
+}
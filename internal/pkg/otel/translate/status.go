@@ -85,7 +85,8 @@ func DropComponentStateFromOtelStatus(otelStatus *status.AggregateStatus) (*stat
 }
 
 // getOtelRuntimePipelineStatuses finds otel pipeline statuses belonging to runtime components and returns them as a map
-// from component id to pipeline status.
+// from component id to pipeline status. A component has a pipeline for each signal it emits, the statuses of its
+// pipelines are merged.
 func getOtelRuntimePipelineStatuses(otelStatus *status.AggregateStatus) (map[string]*status.AggregateStatus, error) {
 	if otelStatus == nil {
 		return map[string]*status.AggregateStatus{}, nil
@@ -108,6 +109,9 @@ func getOtelRuntimePipelineStatuses(otelStatus *status.AggregateStatus) (map[str
 			return nil, err
 		}
 		if componentID, found := strings.CutPrefix(pipelineId.Name(), OtelNamePrefix); found {
+			if existing, ok := pipelines[componentID]; ok {
+				pipelineStatus = mergeStatuses(existing, pipelineStatus)
+			}
 			pipelines[componentID] = pipelineStatus
 		}
 
@@ -135,7 +139,8 @@ func getComponentState(pipelineStatus *status.AggregateStatus, comp component.Co
 		return runtime.ComponentComponentState{}, err
 	}
 
-	// We either have one receiver and exporter, or none. Multiple receivers or exporters are a logic error.
+	// We either have one receiver, or none. Multiple receivers are a logic error. There is an exporter for each signal
+	// the component emits, the output unit gets the worst of their statuses.
 	// If there's no receiver or exporter, we simply don't set a status for it.
 	var receiverStatus, exporterStatus *status.AggregateStatus
 	if len(receiverStatuses) > 1 {
@@ -144,10 +149,10 @@ func getComponentState(pipelineStatus *status.AggregateStatus, comp component.Co
 		receiverStatus = slices.Collect(maps.Values(receiverStatuses))[0]
 	}
 
-	if len(exporterStatuses) > 1 {
-		return runtime.ComponentComponentState{}, fmt.Errorf("expected at most one exporter for component %s, found %d", comp.ID, len(exporterStatuses))
-	} else if len(exporterStatuses) == 1 {
-		exporterStatus = slices.Collect(maps.Values(exporterStatuses))[0]
+	for _, otelCompStatus := range exporterStatuses {
+		if exporterStatus == nil || statusSeverity[otelCompStatus.Status()] > statusSeverity[exporterStatus.Status()] {
+			exporterStatus = otelCompStatus
+		}
 	}
 
 	for _, unit := range comp.Units {
@@ -273,6 +278,42 @@ func otelStatusToUnitState(status componentstatus.Status) client.UnitState {
 	return statusMap[status]
 }
 
+// statusSeverity ranks otel statuses, the worst status of several otel components has the highest rank.
+var statusSeverity = map[componentstatus.Status]int{
+	componentstatus.StatusNone:             0,
+	componentstatus.StatusOK:               1,
+	componentstatus.StatusStopped:          2,
+	componentstatus.StatusStopping:         3,
+	componentstatus.StatusStarting:         4,
+	componentstatus.StatusRecoverableError: 5,
+	componentstatus.StatusPermanentError:   6,
+	componentstatus.StatusFatalError:       7,
+}
+
+// mergeStatuses merges the statuses of two pipelines of the same component. The merged status has the worst event of
+// the two, and the statuses of the otel components of both pipelines. Otel components in both pipelines, like the
+// receiver, keep their worst status.
+func mergeStatuses(first, second *status.AggregateStatus) *status.AggregateStatus {
+	merged := deepCopyStatus(first)
+	if merged.Event == nil || (second.Event != nil && statusSeverity[second.Status()] > statusSeverity[merged.Status()]) {
+		merged.Event = second.Event
+	}
+	if len(second.ComponentStatusMap) == 0 {
+		return merged
+	}
+	if merged.ComponentStatusMap == nil {
+		merged.ComponentStatusMap = make(map[string]*status.AggregateStatus, len(second.ComponentStatusMap))
+	}
+	for otelCompStatusId, otelCompStatus := range second.ComponentStatusMap {
+		if existing, ok := merged.ComponentStatusMap[otelCompStatusId]; ok {
+			merged.ComponentStatusMap[otelCompStatusId] = mergeStatuses(existing, otelCompStatus)
+			continue
+		}
+		merged.ComponentStatusMap[otelCompStatusId] = deepCopyStatus(otelCompStatus)
+	}
+	return merged
+}
+
 // parseEntityStatusId parses an entity status ID into its kind and entity ID. An entity can be a pipeline or otel component.
 // The ID is expected to be in the format "kind:entityId", where kind is either "pipeline" or the otel component type (e.g., "receiver", "exporter").
 // This format is used by the healthcheckv2 extension.
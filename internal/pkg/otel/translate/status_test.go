@@ -251,6 +251,26 @@ func TestGetOtelRuntimePipelineStatuses(t *testing.T) {
 			},
 			err: "",
 		},
+		{
+			name: "component with a pipeline per signal",
+			status: &status.AggregateStatus{
+				Event: componentstatus.NewEvent(componentstatus.StatusRecoverableError),
+				ComponentStatusMap: map[string]*status.AggregateStatus{
+					fmt.Sprintf("pipeline:logs/%ssystem-metrics", OtelNamePrefix): {
+						Event: componentstatus.NewEvent(componentstatus.StatusOK),
+					},
+					fmt.Sprintf("pipeline:metrics/%ssystem-metrics", OtelNamePrefix): {
+						Event: componentstatus.NewEvent(componentstatus.StatusRecoverableError),
+					},
+				},
+			},
+			expected: map[string]*status.AggregateStatus{
+				"system-metrics": {
+					Event: componentstatus.NewEvent(componentstatus.StatusRecoverableError),
+				},
+			},
+			err: "",
+		},
 		{
 			name: "invalid pipeline status format",
 			status: &status.AggregateStatus{
@@ -390,6 +410,37 @@ func TestGetComponentStatus(t *testing.T) {
 			},
 			err: "expected at most one receiver",
 		},
+		{
+			name: "exporter per signal",
+			status: &status.AggregateStatus{
+				Event: componentstatus.NewEvent(componentstatus.StatusRecoverableError),
+				ComponentStatusMap: map[string]*status.AggregateStatus{
+					fmt.Sprintf("receiver:metricbeatreceiver/%sinput-1", OtelNamePrefix): {
+						Event: componentstatus.NewEvent(componentstatus.StatusOK),
+					},
+					fmt.Sprintf("exporter:elasticsearch/%soutput-1", OtelNamePrefix): {
+						Event: componentstatus.NewEvent(componentstatus.StatusOK),
+					},
+					fmt.Sprintf("exporter:elasticsearch/%soutput-1/metrics", OtelNamePrefix): {
+						Event: componentstatus.NewEvent(componentstatus.StatusRecoverableError),
+					},
+				},
+			},
+			expected: runtime.ComponentComponentState{
+				Component: comp,
+				State: runtime.ComponentState{
+					State: client.UnitStateDegraded,
+					Units: map[runtime.ComponentUnitKey]runtime.ComponentUnitState{
+						{UnitID: "input-1", UnitType: client.UnitTypeInput}: {
+							State: client.UnitStateHealthy,
+						},
+						{UnitID: "output-1", UnitType: client.UnitTypeOutput}: {
+							State: client.UnitStateDegraded,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -404,11 +455,54 @@ func TestGetComponentStatus(t *testing.T) {
 				assert.Equal(t, tt.expected.Component.ID, result.Component.ID)
 				assert.Equal(t, tt.expected.State.State, result.State.State)
 				assert.Equal(t, len(tt.expected.State.Units), len(result.State.Units))
+				for unitKey, unitState := range tt.expected.State.Units {
+					assert.Equal(t, unitState.State, result.State.Units[unitKey].State, unitKey.UnitID)
+				}
 			}
 		})
 	}
 }
 
+func TestMergeStatuses(t *testing.T) {
+	receiverStatusId := fmt.Sprintf("receiver:metricbeatreceiver/%ssystem-metrics", OtelNamePrefix)
+	logsExporterStatusId := fmt.Sprintf("exporter:elasticsearch/%sdefault", OtelNamePrefix)
+	metricsExporterStatusId := fmt.Sprintf("exporter:elasticsearch/%sdefault/metrics", OtelNamePrefix)
+	logsPipelineStatus := &status.AggregateStatus{
+		Event: componentstatus.NewEvent(componentstatus.StatusOK),
+		ComponentStatusMap: map[string]*status.AggregateStatus{
+			receiverStatusId: {
+				Event: componentstatus.NewEvent(componentstatus.StatusOK),
+			},
+			logsExporterStatusId: {
+				Event: componentstatus.NewEvent(componentstatus.StatusOK),
+			},
+		},
+	}
+	metricsPipelineStatus := &status.AggregateStatus{
+		Event: componentstatus.NewPermanentErrorEvent(errors.New("permanent error")),
+		ComponentStatusMap: map[string]*status.AggregateStatus{
+			receiverStatusId: {
+				Event: componentstatus.NewEvent(componentstatus.StatusStarting),
+			},
+			metricsExporterStatusId: {
+				Event: componentstatus.NewPermanentErrorEvent(errors.New("permanent error")),
+			},
+		},
+	}
+
+	merged := mergeStatuses(logsPipelineStatus, metricsPipelineStatus)
+	assert.Equal(t, componentstatus.StatusPermanentError, merged.Status())
+	assert.EqualError(t, merged.Err(), "permanent error")
+	require.Len(t, merged.ComponentStatusMap, 3)
+	assert.Equal(t, componentstatus.StatusStarting, merged.ComponentStatusMap[receiverStatusId].Status())
+	assert.Equal(t, componentstatus.StatusOK, merged.ComponentStatusMap[logsExporterStatusId].Status())
+	assert.Equal(t, componentstatus.StatusPermanentError, merged.ComponentStatusMap[metricsExporterStatusId].Status())
+
+	// the merged statuses are left untouched
+	assert.Equal(t, componentstatus.StatusOK, logsPipelineStatus.Status())
+	assert.Len(t, logsPipelineStatus.ComponentStatusMap, 2)
+}
+
 func TestGetComponentUnitState(t *testing.T) {
 	unit := component.Unit{
 		ID:   "test-unit",
@@ -41,6 +41,7 @@ const (
 	elasticsearchType                    = "elasticsearch"
 	ProcessRuntimeManager                = RuntimeManager("process")
 	OtelRuntimeManager                   = RuntimeManager("otel")
+	AutoRuntimeManager                   = RuntimeManager("auto") // otel runtime when supported, process runtime otherwise
 	DefaultRuntimeManager RuntimeManager = ProcessRuntimeManager
 )
 
@@ -164,8 +165,14 @@ type Component struct {
 	// The logical output type, i.e. the type of output that was requested.
 	OutputType string `yaml:"output_type"`
 
+	// The name of the output in the policy, i.e. the value of use_output.
+	OutputName string `yaml:"-"`
+
 	RuntimeManager RuntimeManager `yaml:"-"`
 
+	// RuntimeFallbackReason is the reason a component with the auto runtime manager runs in the process runtime.
+	RuntimeFallbackReason string `yaml:"runtime_fallback_reason,omitempty"`
+
 	// Units that should be running inside this component.
 	Units []Unit `yaml:"units"`
 
@@ -388,6 +395,7 @@ func (r *RuntimeSpecs) componentsForInputType(
 					InputSpec:      &inputSpec,
 					InputType:      inputType,
 					OutputType:     output.outputType,
+					OutputName:     output.name,
 					Units:          units,
 					RuntimeManager: runtimeManager,
 					Features:       featureFlags.AsProto(),
@@ -417,6 +425,7 @@ func (r *RuntimeSpecs) componentsForInputType(
 					InputSpec:      &inputSpec,
 					InputType:      inputType,
 					OutputType:     output.outputType,
+					OutputName:     output.name,
 					Units:          units,
 					RuntimeManager: input.runtimeManager,
 					Features:       featureFlags.AsProto(),
@@ -684,9 +693,9 @@ func toIntermediate(policy map[string]interface{}, aliasMapping map[string]strin
 			}
 			runtimeManagerVal := RuntimeManager(runtimeManagerStr)
 			switch runtimeManagerVal {
-			case OtelRuntimeManager, ProcessRuntimeManager:
+			case OtelRuntimeManager, ProcessRuntimeManager, AutoRuntimeManager:
 			default:
-				return nil, fmt.Errorf("invalid 'inputs.%d.runtime', valid values are: %s, %s", idx, OtelRuntimeManager, ProcessRuntimeManager)
+				return nil, fmt.Errorf("invalid 'inputs.%d.runtime', valid values are: %s, %s, %s", idx, OtelRuntimeManager, ProcessRuntimeManager, AutoRuntimeManager)
 			}
 			runtimeManager = runtimeManagerVal
 			delete(input, runtimeManagerKey)
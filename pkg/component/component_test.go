@@ -273,7 +273,7 @@ func TestToComponents(t *testing.T) {
 					},
 				},
 			},
-			Err: "invalid 'inputs.0.runtime', valid values are: otel, process",
+			Err: "invalid 'inputs.0.runtime', valid values are: otel, process, auto",
 		},
 		{
 			Name:     "Invalid: inputs entry duplicate because of missing id",
@@ -1030,6 +1030,56 @@ func TestToComponents(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:     "Auto runtime manager",
+			Platform: linuxAMD64Platform,
+			Policy: map[string]interface{}{
+				"outputs": map[string]interface{}{
+					"default": map[string]interface{}{
+						"type":    "elasticsearch",
+						"enabled": true,
+					},
+				},
+				"inputs": []interface{}{
+					map[string]interface{}{
+						"type":                  "filestream",
+						"id":                    "filestream-0",
+						"_runtime_experimental": "auto",
+					},
+				},
+			},
+			Result: []Component{
+				{
+					InputType:  "filestream",
+					OutputType: "elasticsearch",
+					InputSpec: &InputRuntimeSpec{
+						InputType:  "filestream",
+						BinaryName: "testbeat",
+						BinaryPath: filepath.Join("..", "..", "specs", "testbeat"),
+					},
+					Units: []Unit{
+						{
+							ID:       "filestream-default",
+							Type:     client.UnitTypeOutput,
+							LogLevel: defaultUnitLogLevel,
+							Config: MustExpectedConfig(map[string]interface{}{
+								"type": "elasticsearch",
+							}),
+						},
+						{
+							ID:       "filestream-default-filestream-0",
+							Type:     client.UnitTypeInput,
+							LogLevel: defaultUnitLogLevel,
+							Config: MustExpectedConfig(map[string]interface{}{
+								"type": "filestream",
+								"id":   "filestream-0",
+							}),
+						},
+					},
+					RuntimeManager: AutoRuntimeManager,
+				},
+			},
+		},
 		{
 			Name:     "Simple representation (isolated units)",
 			Platform: linuxAMD64Platform,
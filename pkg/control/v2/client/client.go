@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/pkg/control"
@@ -118,6 +119,8 @@ type ComponentState struct {
 	Message     string               `json:"message" yaml:"message"`
 	Units       []ComponentUnitState `json:"units" yaml:"units"`
 	VersionInfo ComponentVersionInfo `json:"version_info" yaml:"version_info"`
+	// RuntimeFallbackReason is the reason the component runs in the process runtime when its runtime is auto.
+	RuntimeFallbackReason string `json:"runtime_fallback_reason,omitempty" yaml:"runtime_fallback_reason,omitempty"`
 }
 
 // CollectorComponent is a state of a collector component managed by the Elastic Agent.
@@ -152,6 +155,28 @@ type AgentState struct {
 	Collector      *CollectorComponent    `json:"collector,omitempty" yaml:"collector,omitempty"`
 }
 
+// UpgradePreflightCheck is the result of an upgrade preflight check.
+type UpgradePreflightCheck struct {
+	Name    string `json:"name" yaml:"name"`
+	Status  string `json:"status" yaml:"status"`
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// UpgradeResult is the result of an upgrade request.
+type UpgradeResult struct {
+	// Version is the version being upgraded to.
+	Version string
+	// ScheduledAt is when the upgrade runs when it was deferred to the next upgrade window, nil when it is
+	// triggered right away.
+	ScheduledAt *time.Time
+}
+
+// UpgradePreflightReport is the report of an upgrade dry-run.
+type UpgradePreflightReport struct {
+	Version string                  `json:"version" yaml:"version"`
+	Checks  []UpgradePreflightCheck `json:"checks" yaml:"checks"`
+}
+
 // DiagnosticFileResult is a diagnostic file result.
 type DiagnosticFileResult struct {
 	Name        string
@@ -190,6 +215,30 @@ type DiagnosticComponentResult struct {
 	Results     []DiagnosticFileResult
 }
 
+// LogsRequest selects the log entries to stream.
+type LogsRequest struct {
+	// Lines is the number of the most recent entries of the log files to stream, all the entries when negative.
+	Lines int
+	// Follow keeps streaming the new entries.
+	Follow      bool
+	ComponentID string
+	UnitID      string
+	// Level is the minimum level of the entries.
+	Level   string
+	Since   time.Time
+	Until   time.Time
+	Message string
+	// ExcludeEvents leaves out the entries of the event log files.
+	ExcludeEvents bool
+}
+
+// LogsResponse is a streamed log entry, or the number of entries that were dropped when it has no entry.
+type LogsResponse struct {
+	Entry []byte
+	// Dropped is the number of new entries dropped because they were not received fast enough.
+	Dropped int
+}
+
 // Client communicates to Elastic Agent through the control protocol.
 type Client interface {
 	// Connect connects to the running Elastic Agent.
@@ -204,8 +253,13 @@ type Client interface {
 	StateWatch(ctx context.Context) (ClientStateWatch, error)
 	// Restart triggers restarting the current running daemon.
 	Restart(ctx context.Context) error
-	// Upgrade triggers upgrade of the current running daemon.
-	Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, pgpBytes ...string) (string, error)
+	// Upgrade triggers upgrade of the current running daemon, or schedules it when outside the upgrade windows.
+	Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (UpgradeResult, error)
+	// UpgradePreflight runs the upgrade preflight checks of the current running daemon without switching versions.
+	// The report is returned along the error when a check failed.
+	UpgradePreflight(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*UpgradePreflightReport, error)
+	// Rollback triggers rollback of the current running daemon to the previous version kept on disk.
+	Rollback(ctx context.Context) error
 	// DiagnosticAgent gathers diagnostics information for the running Elastic Agent.
 	DiagnosticAgent(ctx context.Context, additionalDiags []AdditionalMetrics) ([]DiagnosticFileResult, error)
 	// DiagnosticUnits gathers diagnostics information from specific units (or all if non are provided).
@@ -216,6 +270,14 @@ type Client interface {
 	// Configure sends a new configuration to the Elastic Agent.
 	// Only works in the case that Elastic Agent is started in testing mode.
 	Configure(ctx context.Context, config string) error
+	// Logs streams the log entries of the running Elastic Agent and its components.
+	Logs(ctx context.Context, req LogsRequest) (ClientLogs, error)
+}
+
+// ClientLogs allows the log entries of the running Elastic Agent to be streamed.
+type ClientLogs interface {
+	// Recv receives the next log entry, io.EOF is returned once all the entries are received.
+	Recv() (LogsResponse, error)
 }
 
 // ClientStateWatch allows the state of the running Elastic Agent to be watched.
@@ -334,22 +396,73 @@ func (c *client) Restart(ctx context.Context) error {
 	return nil
 }
 
-// Upgrade triggers upgrade of the current running daemon.
-func (c *client) Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, pgpBytes ...string) (string, error) {
+// Upgrade triggers upgrade of the current running daemon, or schedules it when outside the upgrade windows.
+func (c *client) Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (UpgradeResult, error) {
+	res, err := c.client.Upgrade(ctx, &cproto.UpgradeRequest{
+		Version:        version,
+		SourceURI:      sourceURI,
+		SkipVerify:     skipVerify,
+		PgpBytes:       pgpBytes,
+		SkipDefaultPgp: skipDefaultPgp,
+		CosignKeys:     cosignKeys,
+	})
+	if err != nil {
+		return UpgradeResult{}, err
+	}
+	if res.Status == cproto.ActionStatus_FAILURE {
+		return UpgradeResult{}, errors.New(res.Error)
+	}
+	result := UpgradeResult{Version: res.Version}
+	if res.ScheduledAt != nil {
+		scheduledAt := res.ScheduledAt.AsTime()
+		result.ScheduledAt = &scheduledAt
+	}
+	return result, nil
+}
+
+// UpgradePreflight runs the upgrade preflight checks of the current running daemon without switching versions.
+// The report is returned along the error when a check failed.
+func (c *client) UpgradePreflight(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*UpgradePreflightReport, error) {
 	res, err := c.client.Upgrade(ctx, &cproto.UpgradeRequest{
 		Version:        version,
 		SourceURI:      sourceURI,
 		SkipVerify:     skipVerify,
 		PgpBytes:       pgpBytes,
 		SkipDefaultPgp: skipDefaultPgp,
+		CosignKeys:     cosignKeys,
+		DryRun:         true,
 	})
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	var report *UpgradePreflightReport
+	if res.Preflight != nil {
+		report = &UpgradePreflightReport{Version: res.Preflight.Version}
+		for _, check := range res.Preflight.Checks {
+			report.Checks = append(report.Checks, UpgradePreflightCheck{
+				Name:    check.Name,
+				Status:  check.Status,
+				Message: check.Message,
+			})
+		}
+	}
+	if res.Status == cproto.ActionStatus_FAILURE {
+		return report, errors.New(res.Error)
+	}
+	return report, nil
+}
+
+// Rollback triggers rollback of the current running daemon to the previous version kept on disk.
+func (c *client) Rollback(ctx context.Context) error {
+	res, err := c.client.Rollback(ctx, &cproto.Empty{})
+	if err != nil {
+		return err
 	}
 	if res.Status == cproto.ActionStatus_FAILURE {
-		return "", errors.New(res.Error)
+		return errors.New(res.Error)
 	}
-	return res.Version, nil
+	return nil
 }
 
 // DiagnosticAgent gathers diagnostics information for the running Elastic Agent.
@@ -483,6 +596,43 @@ func (c *client) Configure(ctx context.Context, config string) error {
 	return err
 }
 
+// Logs streams the log entries of the running Elastic Agent and its components.
+func (c *client) Logs(ctx context.Context, req LogsRequest) (ClientLogs, error) {
+	r := &cproto.LogsRequest{
+		Lines:         int32(req.Lines), //nolint:gosec // not going to request more lines than a 32bit integer
+		Follow:        req.Follow,
+		ComponentId:   req.ComponentID,
+		UnitId:        req.UnitID,
+		Level:         req.Level,
+		Message:       req.Message,
+		ExcludeEvents: req.ExcludeEvents,
+	}
+	if !req.Since.IsZero() {
+		r.Since = timestamppb.New(req.Since)
+	}
+	if !req.Until.IsZero() {
+		r.Until = timestamppb.New(req.Until)
+	}
+	cli, err := c.client.Logs(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return &logsReceiver{cli}, nil
+}
+
+type logsReceiver struct {
+	client cproto.ElasticAgentControl_LogsClient
+}
+
+// Recv receives the next log entry.
+func (lr *logsReceiver) Recv() (LogsResponse, error) {
+	resp, err := lr.client.Recv()
+	if err != nil {
+		return LogsResponse{}, err
+	}
+	return LogsResponse{Entry: resp.Entry, Dropped: int(resp.Dropped)}, nil //nolint:gosec // not going to drop more entries than an int
+}
+
 type stateWatcher struct {
 	client cproto.ElasticAgentControl_StateWatchClient
 }
@@ -535,11 +685,12 @@ func toState(res *cproto.StateResponse) (*AgentState, error) {
 			})
 		}
 		cs := ComponentState{
-			ID:      comp.Id,
-			Name:    comp.Name,
-			State:   comp.State,
-			Message: comp.Message,
-			Units:   units,
+			ID:                    comp.Id,
+			Name:                  comp.Name,
+			State:                 comp.State,
+			Message:               comp.Message,
+			Units:                 units,
+			RuntimeFallbackReason: comp.RuntimeFallbackReason,
 		}
 		if comp.VersionInfo != nil {
 			cs.VersionInfo = ComponentVersionInfo{
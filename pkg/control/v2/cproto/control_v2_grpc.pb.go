@@ -29,10 +29,12 @@ const (
 	ElasticAgentControl_StateWatch_FullMethodName           = "/cproto.ElasticAgentControl/StateWatch"
 	ElasticAgentControl_Restart_FullMethodName              = "/cproto.ElasticAgentControl/Restart"
 	ElasticAgentControl_Upgrade_FullMethodName              = "/cproto.ElasticAgentControl/Upgrade"
+	ElasticAgentControl_Rollback_FullMethodName             = "/cproto.ElasticAgentControl/Rollback"
 	ElasticAgentControl_DiagnosticAgent_FullMethodName      = "/cproto.ElasticAgentControl/DiagnosticAgent"
 	ElasticAgentControl_DiagnosticUnits_FullMethodName      = "/cproto.ElasticAgentControl/DiagnosticUnits"
 	ElasticAgentControl_DiagnosticComponents_FullMethodName = "/cproto.ElasticAgentControl/DiagnosticComponents"
 	ElasticAgentControl_Configure_FullMethodName            = "/cproto.ElasticAgentControl/Configure"
+	ElasticAgentControl_Logs_FullMethodName                 = "/cproto.ElasticAgentControl/Logs"
 )
 
 // ElasticAgentControlClient is the client API for ElasticAgentControl service.
@@ -52,6 +54,9 @@ type ElasticAgentControlClient interface {
 	Restart(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RestartResponse, error)
 	// Upgrade starts the upgrade process of Elastic Agent.
 	Upgrade(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*UpgradeResponse, error)
+	// Rollback starts the rollback of Elastic Agent to the previous version kept on disk after
+	// the last upgrade.
+	Rollback(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RollbackResponse, error)
 	// Gather diagnostic information for the running Elastic Agent.
 	DiagnosticAgent(ctx context.Context, in *DiagnosticAgentRequest, opts ...grpc.CallOption) (*DiagnosticAgentResponse, error)
 	// Gather diagnostic information for the running units.
@@ -65,6 +70,8 @@ type ElasticAgentControlClient interface {
 	// on any Elastic Agent that is not in TESTING_MODE will result in an error being
 	// returned and nothing occurring.
 	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Streams the log entries of the running Elastic Agent and its components.
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogsResponse], error)
 }
 
 type elasticAgentControlClient struct {
@@ -134,6 +141,16 @@ func (c *elasticAgentControlClient) Upgrade(ctx context.Context, in *UpgradeRequ
 	return out, nil
 }
 
+func (c *elasticAgentControlClient) Rollback(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RollbackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackResponse)
+	err := c.cc.Invoke(ctx, ElasticAgentControl_Rollback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *elasticAgentControlClient) DiagnosticAgent(ctx context.Context, in *DiagnosticAgentRequest, opts ...grpc.CallOption) (*DiagnosticAgentResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DiagnosticAgentResponse)
@@ -192,6 +209,25 @@ func (c *elasticAgentControlClient) Configure(ctx context.Context, in *Configure
 	return out, nil
 }
 
+func (c *elasticAgentControlClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ElasticAgentControl_ServiceDesc.Streams[3], ElasticAgentControl_Logs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogsRequest, LogsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ElasticAgentControl_LogsClient = grpc.ServerStreamingClient[LogsResponse]
+
 // ElasticAgentControlServer is the server API for ElasticAgentControl service.
 // All implementations must embed UnimplementedElasticAgentControlServer
 // for forward compatibility.
@@ -209,6 +245,9 @@ type ElasticAgentControlServer interface {
 	Restart(context.Context, *Empty) (*RestartResponse, error)
 	// Upgrade starts the upgrade process of Elastic Agent.
 	Upgrade(context.Context, *UpgradeRequest) (*UpgradeResponse, error)
+	// Rollback starts the rollback of Elastic Agent to the previous version kept on disk after
+	// the last upgrade.
+	Rollback(context.Context, *Empty) (*RollbackResponse, error)
 	// Gather diagnostic information for the running Elastic Agent.
 	DiagnosticAgent(context.Context, *DiagnosticAgentRequest) (*DiagnosticAgentResponse, error)
 	// Gather diagnostic information for the running units.
@@ -222,6 +261,8 @@ type ElasticAgentControlServer interface {
 	// on any Elastic Agent that is not in TESTING_MODE will result in an error being
 	// returned and nothing occurring.
 	Configure(context.Context, *ConfigureRequest) (*Empty, error)
+	// Streams the log entries of the running Elastic Agent and its components.
+	Logs(*LogsRequest, grpc.ServerStreamingServer[LogsResponse]) error
 	mustEmbedUnimplementedElasticAgentControlServer()
 }
 
@@ -247,6 +288,9 @@ func (UnimplementedElasticAgentControlServer) Restart(context.Context, *Empty) (
 func (UnimplementedElasticAgentControlServer) Upgrade(context.Context, *UpgradeRequest) (*UpgradeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Upgrade not implemented")
 }
+func (UnimplementedElasticAgentControlServer) Rollback(context.Context, *Empty) (*RollbackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rollback not implemented")
+}
 func (UnimplementedElasticAgentControlServer) DiagnosticAgent(context.Context, *DiagnosticAgentRequest) (*DiagnosticAgentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DiagnosticAgent not implemented")
 }
@@ -259,6 +303,9 @@ func (UnimplementedElasticAgentControlServer) DiagnosticComponents(*DiagnosticCo
 func (UnimplementedElasticAgentControlServer) Configure(context.Context, *ConfigureRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Configure not implemented")
 }
+func (UnimplementedElasticAgentControlServer) Logs(*LogsRequest, grpc.ServerStreamingServer[LogsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Logs not implemented")
+}
 func (UnimplementedElasticAgentControlServer) mustEmbedUnimplementedElasticAgentControlServer() {}
 func (UnimplementedElasticAgentControlServer) testEmbeddedByValue()                             {}
 
@@ -363,6 +410,24 @@ func _ElasticAgentControl_Upgrade_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ElasticAgentControl_Rollback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ElasticAgentControlServer).Rollback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ElasticAgentControl_Rollback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ElasticAgentControlServer).Rollback(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ElasticAgentControl_DiagnosticAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DiagnosticAgentRequest)
 	if err := dec(in); err != nil {
@@ -421,6 +486,17 @@ func _ElasticAgentControl_Configure_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ElasticAgentControl_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ElasticAgentControlServer).Logs(m, &grpc.GenericServerStream[LogsRequest, LogsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ElasticAgentControl_LogsServer = grpc.ServerStreamingServer[LogsResponse]
+
 // ElasticAgentControl_ServiceDesc is the grpc.ServiceDesc for ElasticAgentControl service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -444,6 +520,10 @@ var ElasticAgentControl_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Upgrade",
 			Handler:    _ElasticAgentControl_Upgrade_Handler,
 		},
+		{
+			MethodName: "Rollback",
+			Handler:    _ElasticAgentControl_Rollback_Handler,
+		},
 		{
 			MethodName: "DiagnosticAgent",
 			Handler:    _ElasticAgentControl_DiagnosticAgent_Handler,
@@ -469,6 +549,11 @@ var ElasticAgentControl_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _ElasticAgentControl_DiagnosticComponents_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Logs",
+			Handler:       _ElasticAgentControl_Logs_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "control_v2.proto",
 }
@@ -5,12 +5,16 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"os"
+	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/status"
@@ -29,6 +33,7 @@ import (
 	"github.com/elastic/elastic-agent-client/v7/pkg/client"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/coordinator"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/application/info"
+	"github.com/elastic/elastic-agent/internal/pkg/agent/application/paths"
 	"github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 	"github.com/elastic/elastic-agent/internal/pkg/diagnostics"
 	"github.com/elastic/elastic-agent/internal/pkg/release"
@@ -174,7 +179,11 @@ func (s *Server) Restart(_ context.Context, _ *cproto.Empty) (*cproto.RestartRes
 
 // Upgrade performs the upgrade operation.
 func (s *Server) Upgrade(ctx context.Context, request *cproto.UpgradeRequest) (*cproto.UpgradeResponse, error) {
-	err := s.coord.Upgrade(ctx, request.Version, request.SourceURI, nil, request.SkipVerify, request.SkipDefaultPgp, request.PgpBytes...)
+	if request.DryRun {
+		return s.upgradePreflight(ctx, request), nil
+	}
+
+	scheduledAt, err := s.coord.ScheduleUpgrade(ctx, request.Version, request.SourceURI, request.SkipVerify, request.SkipDefaultPgp, request.CosignKeys, request.PgpBytes...)
 	if err != nil {
 		//nolint:nilerr // ignore the error, return a failure upgrade response
 		return &cproto.UpgradeResponse{
@@ -182,9 +191,59 @@ func (s *Server) Upgrade(ctx context.Context, request *cproto.UpgradeRequest) (*
 			Error:  err.Error(),
 		}, nil
 	}
-	return &cproto.UpgradeResponse{
+	resp := &cproto.UpgradeResponse{
 		Status:  cproto.ActionStatus_SUCCESS,
 		Version: request.Version,
+	}
+	if scheduledAt != nil {
+		resp.ScheduledAt = timestamppb.New(*scheduledAt)
+	}
+	return resp, nil
+}
+
+// upgradePreflight runs the upgrade preflight checks, the response fails when a check fails.
+func (s *Server) upgradePreflight(ctx context.Context, request *cproto.UpgradeRequest) *cproto.UpgradeResponse {
+	report, err := s.coord.UpgradePreflight(ctx, request.Version, request.SourceURI, request.SkipVerify, request.SkipDefaultPgp, request.CosignKeys, request.PgpBytes...)
+	if err != nil {
+		return &cproto.UpgradeResponse{
+			Status: cproto.ActionStatus_FAILURE,
+			Error:  err.Error(),
+		}
+	}
+
+	preflight := &cproto.UpgradePreflightReport{Version: report.Version}
+	for _, check := range report.Checks {
+		preflight.Checks = append(preflight.Checks, &cproto.UpgradePreflightCheck{
+			Name:    check.Name,
+			Status:  string(check.Status),
+			Message: check.Message,
+		})
+	}
+
+	resp := &cproto.UpgradeResponse{
+		Status:    cproto.ActionStatus_SUCCESS,
+		Version:   request.Version,
+		Preflight: preflight,
+	}
+	if err := report.Err(); err != nil {
+		resp.Status = cproto.ActionStatus_FAILURE
+		resp.Error = err.Error()
+	}
+	return resp
+}
+
+// Rollback performs a rollback to the previous version kept on disk.
+func (s *Server) Rollback(ctx context.Context, _ *cproto.Empty) (*cproto.RollbackResponse, error) {
+	err := s.coord.Rollback(ctx, nil)
+	if err != nil {
+		//nolint:nilerr // ignore the error, return a failure rollback response
+		return &cproto.RollbackResponse{
+			Status: cproto.ActionStatus_FAILURE,
+			Error:  err.Error(),
+		}, nil
+	}
+	return &cproto.RollbackResponse{
+		Status: cproto.ActionStatus_SUCCESS,
 	}, nil
 }
 
@@ -333,6 +392,212 @@ func (s *Server) Configure(ctx context.Context, req *cproto.ConfigureRequest) (*
 	return &cproto.Empty{}, nil
 }
 
+// Logs streams the log entries of the Elastic Agent and its components to the client.
+//
+// The recent entries are read from the log files, including the rotated ones, and from the event log files
+// unless excluded. The new entries are taken from the internal log output, the client is told how many of them
+// were dropped when it does not receive them fast enough.
+func (s *Server) Logs(req *cproto.LogsRequest, srv cproto.ElasticAgentControl_LogsServer) error {
+	filter, err := logsFilter(req)
+	if err != nil {
+		return err
+	}
+	var match func([]byte) bool
+	if !filter.Empty() {
+		match = filter.Match
+	}
+	logsDir := filepath.Join(paths.Home(), logger.DefaultLogDirectory)
+	eventsDir := filepath.Join(logsDir, "events")
+
+	// subscribed before reading the log files, so no entry is missed between the two
+	recent, sub := logger.SubscribeInternal()
+	defer sub.Close()
+
+	entries, end, err := logger.LastEntries(logsDir, int(req.Lines), match)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var eventsEnd logger.LogFilesEnd
+	if !req.ExcludeEvents {
+		var events [][]byte
+		events, eventsEnd, err = logger.LastEntries(eventsDir, int(req.Lines), match)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		entries = mergeEntries(entries, events)
+		if req.Lines >= 0 && len(entries) > int(req.Lines) {
+			entries = entries[len(entries)-int(req.Lines):]
+		}
+	}
+	for _, line := range entries {
+		if err := srv.Send(&cproto.LogsResponse{Entry: line}); err != nil {
+			return err
+		}
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	for _, line := range entriesAfter(recent, end.Last) {
+		if filter.Match(line) {
+			if err := srv.Send(&cproto.LogsResponse{Entry: line}); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(srv.Context())
+	defer cancel()
+	events := make(chan []byte)
+	if !req.ExcludeEvents {
+		go func() {
+			err := logger.WatchLogFiles(ctx, eventsDir, eventsEnd.File, eventsEnd.Offset, &lineWriter{ctx: ctx, ch: events})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.Warnf("stopped streaming the event log files: %s", err)
+			}
+		}()
+	}
+
+	dropped := sub.Dropped()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line := <-sub.Lines():
+			if n := sub.Dropped(); n > dropped {
+				if err := srv.Send(&cproto.LogsResponse{Dropped: uint64(n - dropped)}); err != nil { //nolint:gosec // never negative
+					return err
+				}
+				dropped = n
+			}
+			if !filter.Match(line) {
+				continue
+			}
+			if err := srv.Send(&cproto.LogsResponse{Entry: line}); err != nil {
+				return err
+			}
+		case line := <-events:
+			if !filter.Match(line) {
+				continue
+			}
+			if err := srv.Send(&cproto.LogsResponse{Entry: line}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// lineWriter sends every line written to it on a channel, until its context is cancelled.
+type lineWriter struct {
+	ctx     context.Context
+	ch      chan<- []byte
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if line := data[:i]; len(line) > 0 {
+			select {
+			case w.ch <- bytes.Clone(line):
+			case <-w.ctx.Done():
+				return 0, w.ctx.Err()
+			}
+		}
+		data = data[i+1:]
+	}
+	w.partial = bytes.Clone(data)
+	return len(p), nil
+}
+
+// mergeEntries merges two lists of log entries, each sorted by timestamp, into one sorted by timestamp.
+func mergeEntries(a, b [][]byte) [][]byte {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([][]byte, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if entryTime(b[j]).Before(entryTime(a[i])) {
+			merged = append(merged, b[j])
+			j++
+		} else {
+			merged = append(merged, a[i])
+			i++
+		}
+	}
+	merged = append(merged, a[i:]...)
+	return append(merged, b[j:]...)
+}
+
+// entriesAfter returns the entries of `recent` that come after `last`. When `last` is no longer in `recent`,
+// the entries logged after it are returned.
+func entriesAfter(recent [][]byte, last []byte) [][]byte {
+	if last == nil {
+		return recent
+	}
+	for i := len(recent) - 1; i >= 0; i-- {
+		if bytes.Equal(recent[i], last) {
+			return recent[i+1:]
+		}
+	}
+	lastTime := entryTime(last)
+	for i, line := range recent {
+		if entryTime(line).After(lastTime) {
+			return recent[i:]
+		}
+	}
+	return nil
+}
+
+// entryTime returns the timestamp of a log entry, the zero time when it has none.
+func entryTime(line []byte) time.Time {
+	e, err := logger.ParseEntry(line)
+	if err != nil {
+		return time.Time{}
+	}
+	ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return ts
+}
+
+func logsFilter(req *cproto.LogsRequest) (logger.EntryFilter, error) {
+	f := logger.EntryFilter{
+		ComponentID: req.ComponentId,
+		UnitID:      req.UnitId,
+	}
+	if req.Level != "" {
+		level, ok := logger.ParseEntryLevel(req.Level)
+		if !ok {
+			return f, fmt.Errorf("invalid log level %q", req.Level)
+		}
+		f.Level = &level
+	}
+	if req.Since != nil {
+		f.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		f.Until = req.Until.AsTime()
+	}
+	if req.Message != "" {
+		re, err := regexp.Compile(req.Message)
+		if err != nil {
+			return f, fmt.Errorf("invalid message regular expression: %w", err)
+		}
+		f.Message = re
+	}
+	return f, nil
+}
+
 func stateToProto(state *coordinator.State, agentInfo info.Agent) (*cproto.StateResponse, error) {
 	var err error
 	components := make([]*cproto.ComponentState, 0, len(state.Components))
@@ -364,6 +629,7 @@ func stateToProto(state *coordinator.State, agentInfo info.Agent) (*cproto.State
 				Name: comp.State.VersionInfo.Name,
 				Meta: comp.State.VersionInfo.Meta,
 			},
+			RuntimeFallbackReason: comp.Component.RuntimeFallbackReason,
 		})
 	}
 
@@ -379,6 +645,7 @@ func stateToProto(state *coordinator.State, agentInfo info.Agent) (*cproto.State
 				ErrorMsg:        state.UpgradeDetails.Metadata.ErrorMsg,
 				RetryErrorMsg:   state.UpgradeDetails.Metadata.RetryErrorMsg,
 				Reason:          state.UpgradeDetails.Metadata.Reason,
+				FailedGate:      state.UpgradeDetails.Metadata.FailedGate,
 			},
 		}
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/elastic/elastic-agent-client/v7/pkg/client"
 	"github.com/elastic/elastic-agent-libs/logp"
@@ -99,6 +100,7 @@ func TestStateMapping(t *testing.T) {
 									Type: client.UnitTypeInput,
 								},
 							},
+							RuntimeFallbackReason: "output type \"logstash\" is not supported by the otel runtime",
 						},
 						State: runtime.ComponentState{
 							State:   client.UnitStateHealthy,
@@ -177,6 +179,7 @@ func TestStateMapping(t *testing.T) {
 						Name: "awesome-comp",
 						Meta: map[string]string{"foo": "bar"},
 					},
+					RuntimeFallbackReason: "output type \"logstash\" is not supported by the otel runtime",
 				}
 				assert.Equal(t, expectedCompState, stateResponse.Components[0])
 			}
@@ -220,3 +223,58 @@ func TestStateMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestLogsFilter(t *testing.T) {
+	since := time.Date(2025, 6, 30, 11, 0, 0, 0, time.UTC)
+	f, err := logsFilter(&cproto.LogsRequest{
+		ComponentId: "filestream-default",
+		UnitId:      "filestream-default-logs",
+		Level:       "warn",
+		Since:       timestamppb.New(since),
+		Message:     "^harvester",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "filestream-default", f.ComponentID)
+	assert.Equal(t, "filestream-default-logs", f.UnitID)
+	require.NotNil(t, f.Level)
+	assert.Equal(t, logp.WarnLevel, *f.Level)
+	assert.Equal(t, since, f.Since)
+	assert.True(t, f.Until.IsZero())
+	assert.True(t, f.Message.MatchString("harvester started"))
+
+	f, err = logsFilter(&cproto.LogsRequest{})
+	require.NoError(t, err)
+	assert.True(t, f.Empty())
+
+	_, err = logsFilter(&cproto.LogsRequest{Level: "verbose"})
+	assert.ErrorContains(t, err, "invalid log level")
+	_, err = logsFilter(&cproto.LogsRequest{Message: "(harvester"})
+	assert.ErrorContains(t, err, "invalid message regular expression")
+}
+
+func TestMergeEntries(t *testing.T) {
+	a := [][]byte{
+		[]byte(`{"@timestamp":"2025-06-30T11:00:00.000Z","message":"a1"}`),
+		[]byte(`{"@timestamp":"2025-06-30T11:00:02.000Z","message":"a2"}`),
+	}
+	b := [][]byte{
+		[]byte(`{"@timestamp":"2025-06-30T11:00:01.000Z","message":"b1"}`),
+		[]byte(`{"@timestamp":"2025-06-30T11:00:03.000Z","message":"b2"}`),
+	}
+	assert.Equal(t, [][]byte{a[0], b[0], a[1], b[1]}, mergeEntries(a, b))
+	assert.Equal(t, a, mergeEntries(a, nil))
+	assert.Equal(t, b, mergeEntries(nil, b))
+}
+
+func TestEntriesAfter(t *testing.T) {
+	recent := [][]byte{
+		[]byte(`{"@timestamp":"2025-06-30T11:00:00.000Z","message":"1"}`),
+		[]byte(`{"@timestamp":"2025-06-30T11:00:01.000Z","message":"2"}`),
+		[]byte(`{"@timestamp":"2025-06-30T11:00:02.000Z","message":"3"}`),
+	}
+	assert.Equal(t, recent, entriesAfter(recent, nil))
+	assert.Equal(t, recent[2:], entriesAfter(recent, recent[1]))
+	assert.Empty(t, entriesAfter(recent, recent[2]))
+	// no longer kept in memory
+	assert.Equal(t, recent[1:], entriesAfter(recent, []byte(`{"@timestamp":"2025-06-30T11:00:00.500Z","message":"1.5"}`)))
+}
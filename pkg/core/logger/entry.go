@@ -0,0 +1,112 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// Entry is the part of an Elastic Agent log entry that can be filtered on, as written in ECS JSON to the log
+// files.
+type Entry struct {
+	Timestamp string `json:"@timestamp"`
+	Message   string `json:"message"`
+	Component struct {
+		ID string `json:"id"`
+	} `json:"component"`
+	// components log the unit ID either nested or dotted
+	Unit struct {
+		ID string `json:"id"`
+	} `json:"unit"`
+	DottedUnitID string `json:"unit.id"`
+	LogLevel     string `json:"log.level"`
+}
+
+// ParseEntry parses a log line.
+func ParseEntry(line []byte) (Entry, error) {
+	var e Entry
+	err := json.Unmarshal(line, &e)
+	return e, err
+}
+
+// UnitID returns the ID of the unit that logged the entry, if any.
+func (e Entry) UnitID() string {
+	if e.DottedUnitID != "" {
+		return e.DottedUnitID
+	}
+	return e.Unit.ID
+}
+
+// ParseEntryLevel parses the level of a log entry, accepting both the zap and the logp names of the levels.
+func ParseEntryLevel(s string) (Level, bool) {
+	switch strings.ToLower(s) {
+	case "warn":
+		return logp.WarnLevel, true
+	case "dpanic", "panic", "fatal":
+		return logp.CriticalLevel, true
+	}
+	var level Level
+	if err := level.Unpack(s); err != nil {
+		return level, false
+	}
+	return level, true
+}
+
+// EntryFilter filters log entries on their fields, the zero value lets every entry through.
+type EntryFilter struct {
+	ComponentID string
+	UnitID      string
+	// Level is the minimum level of the entries.
+	Level   *Level
+	Since   time.Time
+	Until   time.Time
+	Message *regexp.Regexp
+}
+
+// Empty returns true if the filter lets every entry through.
+func (f EntryFilter) Empty() bool {
+	return f.ComponentID == "" && f.UnitID == "" && f.Level == nil && f.Since.IsZero() && f.Until.IsZero() && f.Message == nil
+}
+
+// Match returns true if the log line passes every set filter, lines that are not valid JSON never match.
+func (f EntryFilter) Match(line []byte) bool {
+	e, err := ParseEntry(line)
+	if err != nil {
+		return false
+	}
+	if f.ComponentID != "" && e.Component.ID != f.ComponentID {
+		return false
+	}
+	if f.UnitID != "" && e.UnitID() != f.UnitID {
+		return false
+	}
+	if f.Level != nil {
+		level, ok := ParseEntryLevel(e.LogLevel)
+		if !ok || level < *f.Level {
+			return false
+		}
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		ts, err := time.Parse(time.RFC3339Nano, e.Timestamp)
+		if err != nil {
+			return false
+		}
+		if !f.Since.IsZero() && ts.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && ts.After(f.Until) {
+			return false
+		}
+	}
+	if f.Message != nil && !f.Message.MatchString(e.Message) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"time"
+)
+
+// LogWatchInterval is the interval the log files are checked for new entries when they are watched.
+const LogWatchInterval = 500 * time.Millisecond
+
+// logFilePattern matches the names of the rotated log files, e.g. elastic-agent-20230530-1.ndjson or
+// elastic-agent-event-log-20230530.ndjson.
+var logFilePattern = regexp.MustCompile(`elastic-agent(-event-log)?-(\d+)(-\d+)?\.ndjson$`)
+
+// LogFilenames returns absolute paths to all log files in `dir` sorted in the log rotation order.
+func LogFilenames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list logs directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !logFilePattern.MatchString(e.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, e.Name()))
+	}
+
+	SortLogFilenames(paths)
+
+	return paths, nil
+}
+
+// SortLogFilenames sorts filenames in the order of log rotation
+func SortLogFilenames(filenames []string) {
+	sort.Slice(filenames, func(i, j int) bool {
+		// e.g. elastic-agent-20230515.ndjson => ["elastic-agent-20230515-1.ndjson", "20230515", "-1"]
+		iGroups := logFilePattern.FindStringSubmatch(filenames[i])
+		jGroups := logFilePattern.FindStringSubmatch(filenames[j])
+
+		switch {
+
+		// e.g. elastic-agent-20230515-1.ndjson vs elastic-agent-20230515-2.ndjson
+		case iGroups[2] == jGroups[2] && iGroups[3] != "" && jGroups[3] != "":
+			return iGroups[3] < jGroups[3]
+
+		// e.g. elastic-agent-20230515.ndjson vs elastic-agent-20230515-1.ndjson
+		case iGroups[2] == jGroups[2] && iGroups[3] != "":
+			return false
+
+		// e.g. elastic-agent-20230515-1.ndjson vs elastic-agent-20230515.ndjson
+		case iGroups[2] == jGroups[2] && jGroups[3] != "":
+			return true
+
+		// e.g. elastic-agent-20230515.ndjson vs elastic-agent-20230516.ndjson
+		default:
+			return iGroups[2] < jGroups[2]
+		}
+	})
+}
+
+// LogFilesEnd is where the log files of a directory ended when their last entries were read.
+type LogFilesEnd struct {
+	// File is the newest log file, empty when there was none.
+	File string
+	// Offset is the end of the last complete entry of File.
+	Offset int64
+	// Last is the last entry of the log files, whether it matched or not.
+	Last []byte
+}
+
+// LastEntries returns the last `n` entries of the log files in `dir` that `match`, oldest first, or all the
+// matching entries when `n` is negative. All the entries match when `match` is nil. The entries written to
+// the newest file after it was read are left to WatchLogFiles, starting at the returned end.
+func LastEntries(dir string, n int, match func([]byte) bool) ([][]byte, LogFilesEnd, error) {
+	var end LogFilesEnd
+	files, err := LogFilenames(dir)
+	if err != nil {
+		return nil, end, err
+	}
+	if len(files) == 0 {
+		return nil, end, nil
+	}
+	end.File = files[len(files)-1]
+	info, err := os.Stat(end.File)
+	if err != nil {
+		return nil, end, fmt.Errorf("failed to stat log file %q: %w", end.File, err)
+	}
+
+	// collected from the newest to the oldest
+	var entries [][]byte
+	// the newest file is always read for the end
+	for i := len(files) - 1; i >= 0 && (i == len(files)-1 || n < 0 || len(entries) < n); i-- {
+		limit := int64(-1)
+		if i == len(files)-1 {
+			limit = info.Size()
+		}
+		lines, offset, err := readLogFile(files[i], limit)
+		if errors.Is(err, fs.ErrNotExist) {
+			// removed by the rotation since it was listed
+			continue
+		}
+		if err != nil {
+			return nil, end, err
+		}
+		if i == len(files)-1 {
+			end.Offset = offset
+			if len(lines) > 0 {
+				end.Last = lines[len(lines)-1]
+			}
+		}
+		for j := len(lines) - 1; j >= 0 && (n < 0 || len(entries) < n); j-- {
+			if match == nil || match(lines[j]) {
+				entries = append(entries, lines[j])
+			}
+		}
+	}
+	slices.Reverse(entries)
+	return entries, end, nil
+}
+
+// readLogFile returns the entries of the log file and the end of the last one. Only the first `limit` bytes
+// are read when `limit` is not negative, a partly written entry at the limit is then left out.
+func readLogFile(filename string, limit int64) ([][]byte, int64, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open log file %q for reading: %w", filename, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read from log file %q: %w", filename, err)
+	}
+	if limit >= 0 {
+		data = data[:bytes.LastIndexByte(data, '\n')+1]
+	}
+
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines, int64(len(data)), nil
+}
+
+// WatchLogFiles watches the log directory `dir` for new log lines, starting with the given `startFile` at
+// its `startOffset` printing all new content to `w` until the `ctx` is cancelled.
+// Once new log lines are written to `startFile` they are printed to `w`.
+// Once a new log file is created it switches to watching the new file instead.
+// When `startFile` is empty, the first log file created in `dir` is watched from its start.
+// The new state is checked every `LogWatchInterval`.
+func WatchLogFiles(ctx context.Context, dir, startFile string, startOffset int64, w io.Writer) (err error) {
+	curFile := startFile
+	curOffset := startOffset
+
+	ticker := time.NewTicker(LogWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("watching %s interrupted: %w", dir, ctx.Err())
+		case <-ticker.C:
+			if curFile != "" {
+				size, err := fileSize(curFile)
+				if err != nil {
+					return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
+				}
+				if curOffset != size {
+					curOffset, err = tailFile(curFile, curOffset, w)
+					if err != nil {
+						return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
+					}
+				}
+			}
+
+			files, err := LogFilenames(dir)
+			if curFile == "" && errors.Is(err, fs.ErrNotExist) {
+				// the directory is created with the first log file
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to watch the logs dir %q: %w", dir, err)
+			}
+
+			i := len(files) - 1
+			for ; i >= 0; i-- {
+				if files[i] == curFile {
+					break
+				}
+			}
+			if i == len(files)-1 {
+				continue
+			}
+			curFile = files[i+1]
+			curOffset = 0
+		}
+	}
+}
+
+// fileSize returns a file size of the given file.
+func fileSize(file string) (int64, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file %q: %w", file, err)
+	}
+	return info.Size(), nil
+}
+
+// tailFile prints the tail of the `file` to `w` starting from `offset`.
+func tailFile(file string, offset int64, w io.Writer) (size int64, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file %q: %w", file, err)
+	}
+	defer f.Close()
+
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, fmt.Errorf("failed to seek to %d in file %q: %w", offset, file, err)
+	}
+
+	_, err = io.Copy(w, f)
+	if err != nil {
+		return size, fmt.Errorf("failed to print file %s: %w", file, err)
+	}
+
+	size, err = fileSize(file)
+	if err != nil {
+		return size, fmt.Errorf("failed to get file size %s: %w", file, err)
+	}
+
+	return size, nil
+}
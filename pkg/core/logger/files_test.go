@@ -0,0 +1,109 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLogLines(t *testing.T, filename string, from, to int) {
+	t.Helper()
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	defer f.Close()
+	for i := from; i <= to; i++ {
+		_, err := fmt.Fprintf(f, `{"message":"%d"}`+"\n", i)
+		require.NoError(t, err)
+	}
+}
+
+func TestLastEntries(t *testing.T) {
+	dir := t.TempDir()
+	// more entries than the internal log output keeps, across rotated files
+	writeLogLines(t, filepath.Join(dir, "elastic-agent-20230530.ndjson"), 1, 1000)
+	writeLogLines(t, filepath.Join(dir, "elastic-agent-20230530-1.ndjson"), 1001, 2000)
+	writeLogLines(t, filepath.Join(dir, "elastic-agent-20230531.ndjson"), 2001, 2500)
+	newest := filepath.Join(dir, "elastic-agent-20230531.ndjson")
+
+	entries, end, err := LastEntries(dir, 1200, nil)
+	require.NoError(t, err)
+	require.Len(t, entries, 1200)
+	assert.Equal(t, `{"message":"1301"}`, string(entries[0]))
+	assert.Equal(t, `{"message":"2500"}`, string(entries[1199]))
+	assert.Equal(t, newest, end.File)
+	assert.Equal(t, fileSizeOf(t, newest), end.Offset)
+	assert.Equal(t, `{"message":"2500"}`, string(end.Last))
+
+	entries, _, err = LastEntries(dir, -1, func(line []byte) bool { return bytes.HasSuffix(line, []byte(`00"}`)) })
+	require.NoError(t, err)
+	assert.Len(t, entries, 25)
+	assert.Equal(t, `{"message":"100"}`, string(entries[0]))
+
+	// a partly written entry is left for the watcher
+	f, err := os.OpenFile(newest, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"message":"25`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	entries, end2, err := LastEntries(dir, 0, nil)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Equal(t, end, end2)
+}
+
+func fileSizeOf(t *testing.T, filename string) int64 {
+	t.Helper()
+	info, err := os.Stat(filename)
+	require.NoError(t, err)
+	return info.Size()
+}
+
+type syncBuffer struct {
+	mx  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchLogFilesWithoutFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "events")
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	var out syncBuffer
+	done := make(chan error)
+	go func() {
+		done <- WatchLogFiles(ctx, dir, "", 0, &out)
+	}()
+
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	writeLogLines(t, filepath.Join(dir, "elastic-agent-event-log-20230530.ndjson"), 1, 2)
+	assert.Eventually(t, func() bool {
+		return out.String() == "{\"message\":\"1\"}\n{\"message\":\"2\"}\n"
+	}, 10*time.Second, LogWatchInterval)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
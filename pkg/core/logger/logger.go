@@ -202,7 +202,8 @@ func DefaultEventLoggingConfig() *Config {
 
 // MakeInternalFileOutput creates a zapcore.Core logger that cannot be changed with configuration.
 //
-// This is the logger that the spawned filebeat expects to read the log file from and ship to ES.
+// This is the logger that the spawned filebeat expects to read the log file from and ship to ES. The last
+// entries written to it are also kept in memory, see SubscribeInternal.
 func MakeInternalFileOutput(cfg *Config) (zapcore.Core, error) {
 	// defaultCfg is used to set the defaults for the file rotation of the internal logging
 	// these settings cannot be changed by a user configuration
@@ -231,7 +232,7 @@ func MakeInternalFileOutput(cfg *Config) (zapcore.Core, error) {
 	encoderConfig := ecszap.ECSCompatibleEncoderConfig(logp.JSONEncoderConfig())
 	encoderConfig.EncodeTime = UtcTimestampEncode
 	encoder := zapcore.NewJSONEncoder(encoderConfig)
-	return ecszap.WrapCore(zapcore.NewCore(encoder, rotator, internalLevelEnabler)), nil
+	return ecszap.WrapCore(zapcore.NewCore(encoder, &tailWriteSyncer{rotator, internalTail}, internalLevelEnabler)), nil
 }
 
 // UtcTimestampEncode is a zapcore.TimeEncoder that formats time.Time in ISO-8601 in UTC.
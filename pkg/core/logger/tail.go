@@ -0,0 +1,132 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logger
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// tailSize is the number of lines of the internal log output kept in memory.
+	tailSize = 1000
+	// subscriptionBuffer is the number of lines buffered for a subscriber, lines are dropped when it is full.
+	subscriptionBuffer = 256
+)
+
+// internalTail keeps the last lines written to the internal log output, it is what the control server streams
+// to the `logs` command.
+var internalTail = newTail(tailSize)
+
+// tail keeps the last lines written to it and publishes the new ones to its subscribers.
+type tail struct {
+	mx    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+	subs  map[*Subscription]struct{}
+}
+
+func newTail(size int) *tail {
+	return &tail{
+		lines: make([][]byte, size),
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+// add adds a line, without blocking on subscribers that are not keeping up.
+func (t *tail) add(line []byte) {
+	line = bytes.Clone(bytes.TrimRight(line, "\n"))
+	if len(line) == 0 {
+		return
+	}
+
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	t.lines[t.next] = line
+	t.next = (t.next + 1) % len(t.lines)
+	if t.next == 0 {
+		t.full = true
+	}
+	for s := range t.subs {
+		select {
+		case s.ch <- line:
+		default:
+			s.dropped++
+		}
+	}
+}
+
+// recent returns the kept lines, oldest first.
+func (t *tail) recent() [][]byte {
+	if !t.full {
+		return append([][]byte(nil), t.lines[:t.next]...)
+	}
+	recent := make([][]byte, 0, len(t.lines))
+	recent = append(recent, t.lines[t.next:]...)
+	return append(recent, t.lines[:t.next]...)
+}
+
+// subscribe returns the kept lines and a subscription to the lines added after them.
+func (t *tail) subscribe() ([][]byte, *Subscription) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+	s := &Subscription{
+		t:  t,
+		ch: make(chan []byte, subscriptionBuffer),
+	}
+	t.subs[s] = struct{}{}
+	return t.recent(), s
+}
+
+// Subscription receives the lines written to the internal log output.
+type Subscription struct {
+	t       *tail
+	ch      chan []byte
+	dropped int
+}
+
+// Lines returns the channel the new lines are sent on, it is closed when the subscription is closed.
+func (s *Subscription) Lines() <-chan []byte {
+	return s.ch
+}
+
+// Dropped returns the number of lines that were dropped because the subscriber was not keeping up.
+func (s *Subscription) Dropped() int {
+	s.t.mx.Lock()
+	defer s.t.mx.Unlock()
+	return s.dropped
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.t.mx.Lock()
+	defer s.t.mx.Unlock()
+	if _, ok := s.t.subs[s]; ok {
+		delete(s.t.subs, s)
+		close(s.ch)
+	}
+}
+
+// SubscribeInternal returns the last lines written to the internal log output, oldest first, and a subscription
+// to the lines written after them. The subscription must be closed once done.
+func SubscribeInternal() ([][]byte, *Subscription) {
+	return internalTail.subscribe()
+}
+
+// tailWriteSyncer writes to the wrapped zapcore.WriteSyncer and to a tail.
+type tailWriteSyncer struct {
+	zapcore.WriteSyncer
+	t *tail
+}
+
+func (w *tailWriteSyncer) Write(p []byte) (int, error) {
+	n, err := w.WriteSyncer.Write(p)
+	// the encoder writes an entry per call, it is kept even if writing it to the file failed
+	w.t.add(p)
+	return n, err
+}
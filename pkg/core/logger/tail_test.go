@@ -0,0 +1,92 @@
+// Copyright Elasticsearch B.V. and/or licensed to Elasticsearch B.V. under one
+// or more contributor license agreements. Licensed under the Elastic License 2.0;
+// you may not use this file except in compliance with the Elastic License 2.0.
+
+package logger
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func tailLines(lines [][]byte) []string {
+	s := make([]string, 0, len(lines))
+	for _, l := range lines {
+		s = append(s, string(l))
+	}
+	return s
+}
+
+func TestTail(t *testing.T) {
+	tl := newTail(3)
+	recent, sub := tl.subscribe()
+	assert.Empty(t, recent)
+	sub.Close()
+
+	tl.add([]byte("1\n"))
+	tl.add([]byte("\n"))
+	tl.add([]byte("2\n"))
+	recent, sub = tl.subscribe()
+	assert.Equal(t, []string{"1", "2"}, tailLines(recent))
+	sub.Close()
+
+	for i := 3; i <= 5; i++ {
+		tl.add([]byte(strconv.Itoa(i) + "\n"))
+	}
+	recent, sub = tl.subscribe()
+	defer sub.Close()
+	assert.Equal(t, []string{"3", "4", "5"}, tailLines(recent))
+
+	tl.add([]byte("6\n"))
+	assert.Equal(t, "6", string(<-sub.Lines()))
+}
+
+func TestTailSubscription(t *testing.T) {
+	tl := newTail(tailSize)
+	_, slow := tl.subscribe()
+	_, closed := tl.subscribe()
+	closed.Close()
+	// closing twice is a no-op
+	closed.Close()
+	_, ok := <-closed.Lines()
+	assert.False(t, ok)
+
+	for i := 0; i < subscriptionBuffer+10; i++ {
+		tl.add([]byte(strconv.Itoa(i)))
+	}
+	assert.Equal(t, 10, slow.Dropped())
+	assert.Equal(t, "0", string(<-slow.Lines()))
+	slow.Close()
+}
+
+type discardWriteSyncer struct {
+	written int
+}
+
+func (d *discardWriteSyncer) Write(p []byte) (int, error) {
+	d.written += len(p)
+	return len(p), nil
+}
+
+func (d *discardWriteSyncer) Sync() error {
+	return nil
+}
+
+func TestTailWriteSyncer(t *testing.T) {
+	out := &discardWriteSyncer{}
+	tl := newTail(tailSize)
+	var ws zapcore.WriteSyncer = &tailWriteSyncer{out, tl}
+
+	n, err := ws.Write([]byte(`{"message":"hello"}` + "\n"))
+	require.NoError(t, err)
+	assert.Equal(t, n, out.written)
+	require.NoError(t, ws.Sync())
+
+	recent, sub := tl.subscribe()
+	defer sub.Close()
+	assert.Equal(t, []string{`{"message":"hello"}`}, tailLines(recent))
+}
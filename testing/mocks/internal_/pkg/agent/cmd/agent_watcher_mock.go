@@ -9,10 +9,12 @@ package cmd
 import (
 	context "context"
 
-	mock "github.com/stretchr/testify/mock"
+	configuration "github.com/elastic/elastic-agent/internal/pkg/agent/configuration"
 
 	logp "github.com/elastic/elastic-agent-libs/logp"
 
+	mock "github.com/stretchr/testify/mock"
+
 	time "time"
 )
 
@@ -29,17 +31,17 @@ func (_m *AgentWatcher) EXPECT() *AgentWatcher_Expecter {
 	return &AgentWatcher_Expecter{mock: &_m.Mock}
 }
 
-// Watch provides a mock function with given fields: ctx, tilGrace, errorCheckInterval, log
-func (_m *AgentWatcher) Watch(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, log *logp.Logger) error {
-	ret := _m.Called(ctx, tilGrace, errorCheckInterval, log)
+// Watch provides a mock function with given fields: ctx, tilGrace, errorCheckInterval, gates, log
+func (_m *AgentWatcher) Watch(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, gates configuration.UpgradeWatcherGatesConfig, log *logp.Logger) error {
+	ret := _m.Called(ctx, tilGrace, errorCheckInterval, gates, log)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Watch")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, time.Duration, *logp.Logger) error); ok {
-		r0 = rf(ctx, tilGrace, errorCheckInterval, log)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, time.Duration, configuration.UpgradeWatcherGatesConfig, *logp.Logger) error); ok {
+		r0 = rf(ctx, tilGrace, errorCheckInterval, gates, log)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -56,14 +58,15 @@ type AgentWatcher_Watch_Call struct {
 //   - ctx context.Context
 //   - tilGrace time.Duration
 //   - errorCheckInterval time.Duration
+//   - gates configuration.UpgradeWatcherGatesConfig
 //   - log *logp.Logger
-func (_e *AgentWatcher_Expecter) Watch(ctx interface{}, tilGrace interface{}, errorCheckInterval interface{}, log interface{}) *AgentWatcher_Watch_Call {
-	return &AgentWatcher_Watch_Call{Call: _e.mock.On("Watch", ctx, tilGrace, errorCheckInterval, log)}
+func (_e *AgentWatcher_Expecter) Watch(ctx interface{}, tilGrace interface{}, errorCheckInterval interface{}, gates interface{}, log interface{}) *AgentWatcher_Watch_Call {
+	return &AgentWatcher_Watch_Call{Call: _e.mock.On("Watch", ctx, tilGrace, errorCheckInterval, gates, log)}
 }
 
-func (_c *AgentWatcher_Watch_Call) Run(run func(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, log *logp.Logger)) *AgentWatcher_Watch_Call {
+func (_c *AgentWatcher_Watch_Call) Run(run func(ctx context.Context, tilGrace time.Duration, errorCheckInterval time.Duration, gates configuration.UpgradeWatcherGatesConfig, log *logp.Logger)) *AgentWatcher_Watch_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(time.Duration), args[2].(time.Duration), args[3].(*logp.Logger))
+		run(args[0].(context.Context), args[1].(time.Duration), args[2].(time.Duration), args[3].(configuration.UpgradeWatcherGatesConfig), args[4].(*logp.Logger))
 	})
 	return _c
 }
@@ -73,7 +76,7 @@ func (_c *AgentWatcher_Watch_Call) Return(_a0 error) *AgentWatcher_Watch_Call {
 	return _c
 }
 
-func (_c *AgentWatcher_Watch_Call) RunAndReturn(run func(context.Context, time.Duration, time.Duration, *logp.Logger) error) *AgentWatcher_Watch_Call {
+func (_c *AgentWatcher_Watch_Call) RunAndReturn(run func(context.Context, time.Duration, time.Duration, configuration.UpgradeWatcherGatesConfig, *logp.Logger) error) *AgentWatcher_Watch_Call {
 	_c.Call.Return(run)
 	return _c
 }
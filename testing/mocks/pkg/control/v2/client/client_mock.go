@@ -377,6 +377,65 @@ func (_c *Client_Disconnect_Call) RunAndReturn(run func()) *Client_Disconnect_Ca
 	return _c
 }
 
+// Logs provides a mock function with given fields: ctx, req
+func (_m *Client) Logs(ctx context.Context, req client.LogsRequest) (client.ClientLogs, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logs")
+	}
+
+	var r0 client.ClientLogs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, client.LogsRequest) (client.ClientLogs, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, client.LogsRequest) client.ClientLogs); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(client.ClientLogs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, client.LogsRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_Logs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logs'
+type Client_Logs_Call struct {
+	*mock.Call
+}
+
+// Logs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req client.LogsRequest
+func (_e *Client_Expecter) Logs(ctx interface{}, req interface{}) *Client_Logs_Call {
+	return &Client_Logs_Call{Call: _e.mock.On("Logs", ctx, req)}
+}
+
+func (_c *Client_Logs_Call) Run(run func(ctx context.Context, req client.LogsRequest)) *Client_Logs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(client.LogsRequest))
+	})
+	return _c
+}
+
+func (_c *Client_Logs_Call) Return(_a0 client.ClientLogs, _a1 error) *Client_Logs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_Logs_Call) RunAndReturn(run func(context.Context, client.LogsRequest) (client.ClientLogs, error)) *Client_Logs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Restart provides a mock function with given fields: ctx
 func (_m *Client) Restart(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -423,6 +482,52 @@ func (_c *Client_Restart_Call) RunAndReturn(run func(context.Context) error) *Cl
 	return _c
 }
 
+// Rollback provides a mock function with given fields: ctx
+func (_m *Client) Rollback(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rollback")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_Rollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rollback'
+type Client_Rollback_Call struct {
+	*mock.Call
+}
+
+// Rollback is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) Rollback(ctx interface{}) *Client_Rollback_Call {
+	return &Client_Rollback_Call{Call: _e.mock.On("Rollback", ctx)}
+}
+
+func (_c *Client_Rollback_Call) Run(run func(ctx context.Context)) *Client_Rollback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_Rollback_Call) Return(_a0 error) *Client_Rollback_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_Rollback_Call) RunAndReturn(run func(context.Context) error) *Client_Rollback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // State provides a mock function with given fields: ctx
 func (_m *Client) State(ctx context.Context) (*client.AgentState, error) {
 	ret := _m.Called(ctx)
@@ -539,14 +644,14 @@ func (_c *Client_StateWatch_Call) RunAndReturn(run func(context.Context) (client
 	return _c
 }
 
-// Upgrade provides a mock function with given fields: ctx, version, sourceURI, skipVerify, skipDefaultPgp, pgpBytes
-func (_m *Client) Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, pgpBytes ...string) (string, error) {
+// Upgrade provides a mock function with given fields: ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes
+func (_m *Client) Upgrade(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (client.UpgradeResult, error) {
 	_va := make([]interface{}, len(pgpBytes))
 	for _i := range pgpBytes {
 		_va[_i] = pgpBytes[_i]
 	}
 	var _ca []interface{}
-	_ca = append(_ca, ctx, version, sourceURI, skipVerify, skipDefaultPgp)
+	_ca = append(_ca, ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys)
 	_ca = append(_ca, _va...)
 	ret := _m.Called(_ca...)
 
@@ -554,19 +659,19 @@ func (_m *Client) Upgrade(ctx context.Context, version string, sourceURI string,
 		panic("no return value specified for Upgrade")
 	}
 
-	var r0 string
+	var r0 client.UpgradeResult
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, ...string) (string, error)); ok {
-		return rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, pgpBytes...)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, []string, ...string) (client.UpgradeResult, error)); ok {
+		return rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, ...string) string); ok {
-		r0 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, pgpBytes...)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, []string, ...string) client.UpgradeResult); ok {
+		r0 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(client.UpgradeResult)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, bool, ...string) error); ok {
-		r1 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, pgpBytes...)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, bool, []string, ...string) error); ok {
+		r1 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -585,31 +690,110 @@ type Client_Upgrade_Call struct {
 //   - sourceURI string
 //   - skipVerify bool
 //   - skipDefaultPgp bool
+//   - cosignKeys []string
 //   - pgpBytes ...string
-func (_e *Client_Expecter) Upgrade(ctx interface{}, version interface{}, sourceURI interface{}, skipVerify interface{}, skipDefaultPgp interface{}, pgpBytes ...interface{}) *Client_Upgrade_Call {
+func (_e *Client_Expecter) Upgrade(ctx interface{}, version interface{}, sourceURI interface{}, skipVerify interface{}, skipDefaultPgp interface{}, cosignKeys interface{}, pgpBytes ...interface{}) *Client_Upgrade_Call {
 	return &Client_Upgrade_Call{Call: _e.mock.On("Upgrade",
-		append([]interface{}{ctx, version, sourceURI, skipVerify, skipDefaultPgp}, pgpBytes...)...)}
+		append([]interface{}{ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys}, pgpBytes...)...)}
+}
+
+func (_c *Client_Upgrade_Call) Run(run func(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string)) *Client_Upgrade_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-6)
+		for i, a := range args[6:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(bool), args[5].([]string), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *Client_Upgrade_Call) Return(_a0 client.UpgradeResult, _a1 error) *Client_Upgrade_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_Upgrade_Call) RunAndReturn(run func(context.Context, string, string, bool, bool, []string, ...string) (client.UpgradeResult, error)) *Client_Upgrade_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpgradePreflight provides a mock function with given fields: ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes
+func (_m *Client) UpgradePreflight(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string) (*client.UpgradePreflightReport, error) {
+	_va := make([]interface{}, len(pgpBytes))
+	for _i := range pgpBytes {
+		_va[_i] = pgpBytes[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpgradePreflight")
+	}
+
+	var r0 *client.UpgradePreflightReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, []string, ...string) (*client.UpgradePreflightReport, error)); ok {
+		return rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool, bool, []string, ...string) *client.UpgradePreflightReport); ok {
+		r0 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*client.UpgradePreflightReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, bool, bool, []string, ...string) error); ok {
+		r1 = rf(ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys, pgpBytes...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_UpgradePreflight_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpgradePreflight'
+type Client_UpgradePreflight_Call struct {
+	*mock.Call
+}
+
+// UpgradePreflight is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version string
+//   - sourceURI string
+//   - skipVerify bool
+//   - skipDefaultPgp bool
+//   - cosignKeys []string
+//   - pgpBytes ...string
+func (_e *Client_Expecter) UpgradePreflight(ctx interface{}, version interface{}, sourceURI interface{}, skipVerify interface{}, skipDefaultPgp interface{}, cosignKeys interface{}, pgpBytes ...interface{}) *Client_UpgradePreflight_Call {
+	return &Client_UpgradePreflight_Call{Call: _e.mock.On("UpgradePreflight",
+		append([]interface{}{ctx, version, sourceURI, skipVerify, skipDefaultPgp, cosignKeys}, pgpBytes...)...)}
 }
 
-func (_c *Client_Upgrade_Call) Run(run func(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, pgpBytes ...string)) *Client_Upgrade_Call {
+func (_c *Client_UpgradePreflight_Call) Run(run func(ctx context.Context, version string, sourceURI string, skipVerify bool, skipDefaultPgp bool, cosignKeys []string, pgpBytes ...string)) *Client_UpgradePreflight_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		variadicArgs := make([]string, len(args)-5)
-		for i, a := range args[5:] {
+		variadicArgs := make([]string, len(args)-6)
+		for i, a := range args[6:] {
 			if a != nil {
 				variadicArgs[i] = a.(string)
 			}
 		}
-		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(bool), variadicArgs...)
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool), args[4].(bool), args[5].([]string), variadicArgs...)
 	})
 	return _c
 }
 
-func (_c *Client_Upgrade_Call) Return(_a0 string, _a1 error) *Client_Upgrade_Call {
+func (_c *Client_UpgradePreflight_Call) Return(_a0 *client.UpgradePreflightReport, _a1 error) *Client_UpgradePreflight_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Client_Upgrade_Call) RunAndReturn(run func(context.Context, string, string, bool, bool, ...string) (string, error)) *Client_Upgrade_Call {
+func (_c *Client_UpgradePreflight_Call) RunAndReturn(run func(context.Context, string, string, bool, bool, []string, ...string) (*client.UpgradePreflightReport, error)) *Client_UpgradePreflight_Call {
 	_c.Call.Return(run)
 	return _c
 }